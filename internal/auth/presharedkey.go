@@ -2,11 +2,12 @@ package auth
 
 import (
 	"context"
-	"crypto/subtle"
 
 	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/secrets"
 )
 
 const (
@@ -40,7 +41,7 @@ func MustRequirePresharedKey(presharedKeys []string) grpcauth.AuthFunc {
 		}
 
 		for _, presharedKey := range presharedKeys {
-			if match := subtle.ConstantTimeCompare([]byte(presharedKey), []byte(token)); match == 1 {
+			if secrets.ConstantTimeCompare(presharedKey, token) {
 				return ctx, nil
 			}
 		}
@@ -95,7 +95,7 @@ func runExpression(
 			return nil, err
 		}
 
-		compiled, err := caveats.DeserializeCaveat(caveat.SerializedExpression)
+		compiled, err := caveats.DeserializeCaveat(caveat.SerializedExpression, caveat.ParameterTypes)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,153 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/authzed/spicedb/internal/datastore/options"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// BulkLoadInBatches implements BulkLoad for datastores without a native bulk path, by writing the
+// relationships of the iterator as CREATE mutations in batches of batchSize relationships.
+// Returns the number of relationships written.
+func BulkLoadInBatches(
+	ctx context.Context,
+	iter datastore.RelationshipIterator,
+	batchSize int,
+	writeBatch func(ctx context.Context, mutations []*core.RelationTupleUpdate) error,
+) (uint64, error) {
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("invalid bulk load batch size: %d", batchSize)
+	}
+
+	var loaded uint64
+	batch := make([]*core.RelationTupleUpdate, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := writeBatch(ctx, batch); err != nil {
+			return err
+		}
+
+		loaded += uint64(len(batch))
+		batch = make([]*core.RelationTupleUpdate, 0, batchSize)
+		return nil
+	}
+
+	for rel := iter.Next(); rel != nil; rel = iter.Next() {
+		batch = append(batch, tuple.Create(rel))
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return loaded, err
+			}
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return loaded, err
+	}
+
+	if err := flush(); err != nil {
+		return loaded, err
+	}
+	return loaded, nil
+}
+
+// DeleteRelationshipsInBatches implements BulkDeleteRelationships for datastores unable to delete
+// the relationships matching a filter server-side, by reading the matching relationships in pages
+// of batchSize relationships, sorted by resource, and writing each page as DELETE mutations before
+// reading the next. If limit is non-zero, at most limit relationships are deleted. Returns the
+// number of relationships deleted.
+//
+// Each page is read after the last relationship of the previous one, so the relationships deleted
+// need not be hidden from the reads made later in the transaction.
+func DeleteRelationshipsInBatches(
+	ctx context.Context,
+	reader datastore.Reader,
+	filter datastore.RelationshipsFilter,
+	limit uint64,
+	batchSize int,
+	writeBatch func(ctx context.Context, mutations []*core.RelationTupleUpdate) error,
+) (uint64, error) {
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("invalid bulk delete batch size: %d", batchSize)
+	}
+
+	var after *core.RelationTuple
+	return DeleteRelationshipsInLimitedBatches(ctx, limit, uint64(batchSize), func(ctx context.Context, batchLimit uint64) (uint64, error) {
+		queryOpts := []options.QueryOptionsOption{
+			options.WithLimit(&batchLimit),
+			options.WithSort(options.ByResource),
+		}
+		if after != nil {
+			queryOpts = append(queryOpts, options.WithAfter(after))
+		}
+
+		iter, err := reader.QueryRelationships(ctx, filter, queryOpts...)
+		if err != nil {
+			return 0, err
+		}
+
+		mutations := make([]*core.RelationTupleUpdate, 0, batchLimit)
+		for rel := iter.Next(); rel != nil; rel = iter.Next() {
+			mutations = append(mutations, tuple.Delete(rel))
+		}
+		err = iter.Err()
+		iter.Close()
+		if err != nil {
+			return 0, err
+		}
+
+		if len(mutations) == 0 {
+			return 0, nil
+		}
+
+		if err := writeBatch(ctx, mutations); err != nil {
+			return 0, err
+		}
+
+		after = mutations[len(mutations)-1].Tuple
+		return uint64(len(mutations)), nil
+	})
+}
+
+// DeleteRelationshipsInLimitedBatches implements BulkDeleteRelationships for datastores able to
+// delete a limited number of the relationships matching a filter server-side, by calling
+// deleteBatch with the maximum number of relationships to delete, at most batchSize, until it
+// deletes fewer relationships than that maximum. If limit is non-zero, at most limit relationships
+// are deleted. Returns the number of relationships deleted.
+func DeleteRelationshipsInLimitedBatches(
+	ctx context.Context,
+	limit uint64,
+	batchSize uint64,
+	deleteBatch func(ctx context.Context, batchLimit uint64) (uint64, error),
+) (uint64, error) {
+	if batchSize == 0 {
+		return 0, fmt.Errorf("invalid bulk delete batch size: %d", batchSize)
+	}
+
+	var deleted uint64
+	for limit == 0 || deleted < limit {
+		batchLimit := batchSize
+		if limit > 0 && limit-deleted < batchLimit {
+			batchLimit = limit - deleted
+		}
+
+		batchDeleted, err := deleteBatch(ctx, batchLimit)
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += batchDeleted
+		if batchDeleted < batchLimit {
+			break
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteRelationshipsInLimitedBatches(t *testing.T) {
+	testCases := []struct {
+		name            string
+		matching        uint64
+		limit           uint64
+		expectedDeleted uint64
+		expectedBatches []uint64
+	}{
+		{"no matches", 0, 0, 0, []uint64{10}},
+		{"fewer than a batch", 5, 0, 5, []uint64{10}},
+		{"exactly a batch", 10, 0, 10, []uint64{10, 10}},
+		{"several batches", 25, 0, 25, []uint64{10, 10, 10}},
+		{"limit within a batch", 25, 5, 5, []uint64{5}},
+		{"limit across batches", 25, 15, 15, []uint64{10, 5}},
+		{"limit above matches", 25, 40, 25, []uint64{10, 10, 10}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			remaining := tc.matching
+			var batches []uint64
+			deleted, err := DeleteRelationshipsInLimitedBatches(context.Background(), tc.limit, 10, func(ctx context.Context, batchLimit uint64) (uint64, error) {
+				batches = append(batches, batchLimit)
+
+				batchDeleted := batchLimit
+				if remaining < batchDeleted {
+					batchDeleted = remaining
+				}
+				remaining -= batchDeleted
+				return batchDeleted, nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedDeleted, deleted)
+			require.Equal(t, tc.expectedBatches, batches)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package common
+
+import "context"
+
+type cancellationKey struct{}
+
+// WithCancellationFrom returns the context with the cancellation of the caller's context
+// attached. Datastores severing the context of their queries from that of the caller, to avoid
+// closing connections when it is cancelled, can then still cancel in-flight queries on the
+// server: see CancellationFrom.
+func WithCancellationFrom(ctx context.Context, caller context.Context) context.Context {
+	return context.WithValue(ctx, cancellationKey{}, caller)
+}
+
+// CancellationFrom returns the context whose cancellation should cancel the queries issued with
+// the given context: the caller's context attached by WithCancellationFrom, if any, or otherwise
+// the context itself.
+func CancellationFrom(ctx context.Context) context.Context {
+	if caller, ok := ctx.Value(cancellationKey{}).(context.Context); ok {
+		return caller
+	}
+	return ctx
+}
@@ -18,9 +18,11 @@ type Changes[R datastore.Revision, K comparable] struct {
 }
 
 type changeRecord[R datastore.Revision] struct {
-	rev          R
-	tupleTouches map[string]*core.RelationTuple
-	tupleDeletes map[string]*core.RelationTuple
+	rev           R
+	tupleTouches  map[string]*core.RelationTuple
+	tupleDeletes  map[string]*core.RelationTuple
+	caveatWrites  map[string]*core.CaveatDefinition
+	caveatDeletes map[string]struct{}
 }
 
 // NewChanges creates a new Changes object for change tracking and de-duplication.
@@ -38,17 +40,7 @@ func (ch Changes[R, K]) AddChange(
 	tpl *core.RelationTuple,
 	op core.RelationTupleUpdate_Operation,
 ) {
-	k := ch.keyFunc(rev)
-	revisionChanges, ok := ch.records[k]
-	if !ok {
-		revisionChanges = changeRecord[R]{
-			rev,
-			make(map[string]*core.RelationTuple),
-			make(map[string]*core.RelationTuple),
-		}
-		ch.records[k] = revisionChanges
-	}
-
+	revisionChanges := ch.recordFor(rev)
 	tplKey := tuple.StringWithoutCaveat(tpl)
 
 	switch op {
@@ -68,6 +60,33 @@ func (ch Changes[R, K]) AddChange(
 	}
 }
 
+// AddCaveatWrite adds the write of a caveat definition at the revision. A caveat both deleted
+// and written at the same revision has been updated.
+func (ch Changes[R, K]) AddCaveatWrite(rev R, caveat *core.CaveatDefinition) {
+	ch.recordFor(rev).caveatWrites[caveat.Name] = caveat
+}
+
+// AddCaveatDelete adds the deletion of the caveat definition with the name at the revision.
+func (ch Changes[R, K]) AddCaveatDelete(rev R, name string) {
+	ch.recordFor(rev).caveatDeletes[name] = struct{}{}
+}
+
+func (ch Changes[R, K]) recordFor(rev R) changeRecord[R] {
+	k := ch.keyFunc(rev)
+	revisionChanges, ok := ch.records[k]
+	if !ok {
+		revisionChanges = changeRecord[R]{
+			rev,
+			make(map[string]*core.RelationTuple),
+			make(map[string]*core.RelationTuple),
+			make(map[string]*core.CaveatDefinition),
+			make(map[string]struct{}),
+		}
+		ch.records[k] = revisionChanges
+	}
+	return revisionChanges
+}
+
 // AsRevisionChanges returns the list of changes processed so far as a datastore watch
 // compatible, ordered, changelist.
 func (ch Changes[R, K]) AsRevisionChanges(lessThanFunc func(lhs, rhs K) bool) []datastore.RevisionChanges {
@@ -96,7 +115,29 @@ func (ch Changes[R, K]) AsRevisionChanges(lessThanFunc func(lhs, rhs K) bool) []
 				Tuple:     tpl,
 			})
 		}
+		changes[i].CaveatChanges = revisionChangeRecord.caveatChanges()
 	}
 
 	return changes
 }
+
+func (cr changeRecord[R]) caveatChanges() []datastore.CaveatChange {
+	var caveatChanges []datastore.CaveatChange
+	for name, definition := range cr.caveatWrites {
+		op := datastore.CaveatAdded
+		if _, ok := cr.caveatDeletes[name]; ok {
+			op = datastore.CaveatUpdated
+		}
+		caveatChanges = append(caveatChanges, datastore.CaveatChange{Operation: op, Name: name, Definition: definition})
+	}
+	for name := range cr.caveatDeletes {
+		if _, ok := cr.caveatWrites[name]; !ok {
+			caveatChanges = append(caveatChanges, datastore.CaveatChange{Operation: datastore.CaveatDeleted, Name: name})
+		}
+	}
+
+	sort.Slice(caveatChanges, func(i, j int) bool {
+		return caveatChanges[i].Name < caveatChanges[j].Name
+	})
+	return caveatChanges
+}
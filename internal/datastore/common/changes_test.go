@@ -275,6 +275,39 @@ func TestCanonicalize(t *testing.T) {
 	}
 }
 
+func TestCaveatChanges(t *testing.T) {
+	require := require.New(t)
+
+	first := &core.CaveatDefinition{Name: "first"}
+	second := &core.CaveatDefinition{Name: "second"}
+
+	ch := NewChanges(revision.DecimalKeyFunc)
+	ch.AddChange(context.Background(), rev1, tuple.MustParse(tuple1), core.RelationTupleUpdate_TOUCH)
+	ch.AddCaveatWrite(rev1, second)
+	ch.AddCaveatWrite(rev1, first)
+	ch.AddCaveatDelete(rev2, "first")
+	ch.AddCaveatWrite(rev2, second)
+	ch.AddCaveatDelete(rev2, "second")
+
+	require.Equal([]datastore.RevisionChanges{
+		{
+			Revision: rev1,
+			Changes:  []*core.RelationTupleUpdate{touch(tuple1)},
+			CaveatChanges: []datastore.CaveatChange{
+				{Operation: datastore.CaveatAdded, Name: "first", Definition: first},
+				{Operation: datastore.CaveatAdded, Name: "second", Definition: second},
+			},
+		},
+		{
+			Revision: rev2,
+			CaveatChanges: []datastore.CaveatChange{
+				{Operation: datastore.CaveatDeleted, Name: "first"},
+				{Operation: datastore.CaveatUpdated, Name: "second", Definition: second},
+			},
+		},
+	}, ch.AsRevisionChanges(revision.DecimalKeyLessThanFunc))
+}
+
 func touch(relationship string) *core.RelationTupleUpdate {
 	return &core.RelationTupleUpdate{
 		Operation: core.RelationTupleUpdate_TOUCH,
@@ -0,0 +1,253 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/internal/datastore/options"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// ObserveOperation reports the operation started at start to the observer, if any.
+func ObserveOperation(
+	ctx context.Context,
+	observer datastore.OperationObserver,
+	backend string,
+	operation string,
+	start time.Time,
+	resultSize int,
+	err error,
+) {
+	if observer == nil {
+		return
+	}
+
+	observer.ObserveOperation(ctx, datastore.OperationEvent{
+		Backend:    backend,
+		Operation:  operation,
+		Duration:   time.Since(start),
+		Err:        err,
+		ResultSize: resultSize,
+	})
+}
+
+// ObservedReader returns a reader which reports the operations made through it to the observer,
+// or the reader itself if the observer is nil. Relationship queries are reported once their
+// iterator is closed, with the number of relationships read from it.
+func ObservedReader(reader datastore.Reader, observer datastore.OperationObserver, backend string) datastore.Reader {
+	if observer == nil {
+		return reader
+	}
+	return observedReader{reader, observer, backend}
+}
+
+// ObservedReadWriteTransaction returns a transaction which reports the operations made through
+// it to the observer, or the transaction itself if the observer is nil.
+func ObservedReadWriteTransaction(
+	rwt datastore.ReadWriteTransaction,
+	observer datastore.OperationObserver,
+	backend string,
+) datastore.ReadWriteTransaction {
+	if observer == nil {
+		return rwt
+	}
+	return observedReadWriteTransaction{observedReader{rwt, observer, backend}, rwt}
+}
+
+type observedReader struct {
+	delegate datastore.Reader
+	observer datastore.OperationObserver
+	backend  string
+}
+
+func (or observedReader) observe(ctx context.Context, operation string, start time.Time, resultSize int, err error) {
+	ObserveOperation(ctx, or.observer, or.backend, operation, start, resultSize, err)
+}
+
+func (or observedReader) QueryRelationships(
+	ctx context.Context,
+	filter datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	start := time.Now()
+	iter, err := or.delegate.QueryRelationships(ctx, filter, opts...)
+	return or.observeIterator(ctx, "QueryRelationships", start, iter, err)
+}
+
+func (or observedReader) ReverseQueryRelationships(
+	ctx context.Context,
+	subjectsFilter datastore.SubjectsFilter,
+	opts ...options.ReverseQueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	start := time.Now()
+	iter, err := or.delegate.ReverseQueryRelationships(ctx, subjectsFilter, opts...)
+	return or.observeIterator(ctx, "ReverseQueryRelationships", start, iter, err)
+}
+
+func (or observedReader) observeIterator(
+	ctx context.Context,
+	operation string,
+	start time.Time,
+	iter datastore.RelationshipIterator,
+	err error,
+) (datastore.RelationshipIterator, error) {
+	if err != nil {
+		or.observe(ctx, operation, start, 0, err)
+		return iter, err
+	}
+
+	duration := time.Since(start)
+	return &observedIterator{
+		delegate: iter,
+		report: func(read int, err error) {
+			or.observer.ObserveOperation(ctx, datastore.OperationEvent{
+				Backend:    or.backend,
+				Operation:  operation,
+				Duration:   duration,
+				Err:        err,
+				ResultSize: read,
+			})
+		},
+	}, nil
+}
+
+func (or observedReader) ReadNamespace(ctx context.Context, nsName string) (*core.NamespaceDefinition, datastore.Revision, error) {
+	start := time.Now()
+	ns, rev, err := or.delegate.ReadNamespace(ctx, nsName)
+	or.observe(ctx, "ReadNamespace", start, countFound(ns != nil), err)
+	return ns, rev, err
+}
+
+func (or observedReader) ListNamespaces(ctx context.Context) ([]*core.NamespaceDefinition, error) {
+	start := time.Now()
+	nsDefs, err := or.delegate.ListNamespaces(ctx)
+	or.observe(ctx, "ListNamespaces", start, len(nsDefs), err)
+	return nsDefs, err
+}
+
+func (or observedReader) LookupNamespaces(ctx context.Context, nsNames []string) ([]*core.NamespaceDefinition, error) {
+	start := time.Now()
+	nsDefs, err := or.delegate.LookupNamespaces(ctx, nsNames)
+	or.observe(ctx, "LookupNamespaces", start, len(nsDefs), err)
+	return nsDefs, err
+}
+
+func (or observedReader) ReadCaveatByName(ctx context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
+	start := time.Now()
+	caveat, rev, err := or.delegate.ReadCaveatByName(ctx, name)
+	or.observe(ctx, "ReadCaveatByName", start, countFound(caveat != nil), err)
+	return caveat, rev, err
+}
+
+func (or observedReader) ListCaveats(ctx context.Context, caveatNames ...string) ([]*core.CaveatDefinition, error) {
+	start := time.Now()
+	caveats, err := or.delegate.ListCaveats(ctx, caveatNames...)
+	or.observe(ctx, "ListCaveats", start, len(caveats), err)
+	return caveats, err
+}
+
+func countFound(found bool) int {
+	if found {
+		return 1
+	}
+	return 0
+}
+
+type observedReadWriteTransaction struct {
+	observedReader
+	delegate datastore.ReadWriteTransaction
+}
+
+func (ort observedReadWriteTransaction) WriteRelationships(ctx context.Context, mutations []*core.RelationTupleUpdate) error {
+	start := time.Now()
+	err := ort.delegate.WriteRelationships(ctx, mutations)
+	ort.observe(ctx, "WriteRelationships", start, len(mutations), err)
+	return err
+}
+
+func (ort observedReadWriteTransaction) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
+	start := time.Now()
+	err := ort.delegate.DeleteRelationships(ctx, filter)
+	ort.observe(ctx, "DeleteRelationships", start, 0, err)
+	return err
+}
+
+func (ort observedReadWriteTransaction) WriteNamespaces(ctx context.Context, newConfigs ...*core.NamespaceDefinition) error {
+	start := time.Now()
+	err := ort.delegate.WriteNamespaces(ctx, newConfigs...)
+	ort.observe(ctx, "WriteNamespaces", start, len(newConfigs), err)
+	return err
+}
+
+func (ort observedReadWriteTransaction) DeleteNamespaces(ctx context.Context, nsNames ...string) error {
+	start := time.Now()
+	err := ort.delegate.DeleteNamespaces(ctx, nsNames...)
+	ort.observe(ctx, "DeleteNamespaces", start, len(nsNames), err)
+	return err
+}
+
+func (ort observedReadWriteTransaction) WriteCaveats(ctx context.Context, caveats []*core.CaveatDefinition) error {
+	start := time.Now()
+	err := ort.delegate.WriteCaveats(ctx, caveats)
+	ort.observe(ctx, "WriteCaveats", start, len(caveats), err)
+	return err
+}
+
+func (ort observedReadWriteTransaction) DeleteCaveats(ctx context.Context, names []string) error {
+	start := time.Now()
+	err := ort.delegate.DeleteCaveats(ctx, names)
+	ort.observe(ctx, "DeleteCaveats", start, len(names), err)
+	return err
+}
+
+func (ort observedReadWriteTransaction) BulkLoad(ctx context.Context, iter datastore.RelationshipIterator) (uint64, error) {
+	start := time.Now()
+	loaded, err := ort.delegate.BulkLoad(ctx, iter)
+	ort.observe(ctx, "BulkLoad", start, int(loaded), err)
+	return loaded, err
+}
+
+func (ort observedReadWriteTransaction) BulkDeleteRelationships(ctx context.Context, filter datastore.RelationshipsFilter, limit uint64) (uint64, error) {
+	start := time.Now()
+	deleted, err := ort.delegate.BulkDeleteRelationships(ctx, filter, limit)
+	ort.observe(ctx, "BulkDeleteRelationships", start, int(deleted), err)
+	return deleted, err
+}
+
+// observedIterator counts the relationships read from the delegate iterator, and reports them
+// once closed.
+type observedIterator struct {
+	delegate datastore.RelationshipIterator
+	report   func(read int, err error)
+	read     int
+	closed   bool
+}
+
+func (oi *observedIterator) Next() *core.RelationTuple {
+	next := oi.delegate.Next()
+	if next != nil {
+		oi.read++
+	}
+	return next
+}
+
+func (oi *observedIterator) Err() error {
+	return oi.delegate.Err()
+}
+
+func (oi *observedIterator) Close() {
+	if !oi.closed {
+		oi.closed = true
+		oi.report(oi.read, oi.delegate.Err())
+	}
+	oi.delegate.Close()
+}
+
+var (
+	_ datastore.Reader               = observedReader{}
+	_ datastore.ReadWriteTransaction = observedReadWriteTransaction{}
+	_ datastore.RelationshipIterator = &observedIterator{}
+)
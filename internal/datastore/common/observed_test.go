@@ -0,0 +1,94 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/proxy/proxy_test"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type recordingObserver struct {
+	sync.Mutex
+	events []datastore.OperationEvent
+}
+
+func (ro *recordingObserver) ObserveOperation(_ context.Context, event datastore.OperationEvent) {
+	ro.Lock()
+	defer ro.Unlock()
+	ro.events = append(ro.events, event)
+}
+
+func TestObservedReader(t *testing.T) {
+	require := require.New(t)
+
+	reader := &proxy_test.MockReader{}
+	filter := datastore.RelationshipsFilter{ResourceType: "document"}
+	reader.On("QueryRelationships", filter).Return(datastore.NewSliceRelationshipIterator([]*core.RelationTuple{
+		tuple.MustParse("document:first#viewer@user:tom"),
+		tuple.MustParse("document:second#viewer@user:tom"),
+	}), nil)
+	errNotFound := errors.New("not found")
+	reader.On("ReadNamespace", "missing").Return(nil, datastore.NoRevision, errNotFound)
+
+	observer := &recordingObserver{}
+	observed := ObservedReader(reader, observer, "test")
+	ctx := context.Background()
+
+	iter, err := observed.QueryRelationships(ctx, filter)
+	require.NoError(err)
+	require.Empty(observer.events)
+
+	read := 0
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		read++
+	}
+	require.Equal(2, read)
+	iter.Close()
+	iter.Close()
+
+	_, _, err = observed.ReadNamespace(ctx, "missing")
+	require.ErrorIs(err, errNotFound)
+
+	require.Len(observer.events, 2)
+	require.Equal("test", observer.events[0].Backend)
+	require.Equal("QueryRelationships", observer.events[0].Operation)
+	require.Equal(2, observer.events[0].ResultSize)
+	require.NoError(observer.events[0].Err)
+
+	require.Equal("ReadNamespace", observer.events[1].Operation)
+	require.Equal(0, observer.events[1].ResultSize)
+	require.ErrorIs(observer.events[1].Err, errNotFound)
+}
+
+func TestObservedReadWriteTransaction(t *testing.T) {
+	require := require.New(t)
+
+	rwt := &proxy_test.MockReadWriteTransaction{}
+	mutations := []*core.RelationTupleUpdate{
+		tuple.Create(tuple.MustParse("document:first#viewer@user:tom")),
+	}
+	rwt.On("WriteRelationships", mutations).Return(nil)
+
+	observer := &recordingObserver{}
+	observed := ObservedReadWriteTransaction(rwt, observer, "test")
+	require.NoError(observed.WriteRelationships(context.Background(), mutations))
+
+	require.Len(observer.events, 1)
+	require.Equal("WriteRelationships", observer.events[0].Operation)
+	require.Equal(1, observer.events[0].ResultSize)
+}
+
+func TestObservedWithoutObserver(t *testing.T) {
+	reader := &proxy_test.MockReader{}
+	require.Same(t, reader, ObservedReader(reader, nil, "test"))
+
+	rwt := &proxy_test.MockReadWriteTransaction{}
+	require.Same(t, rwt, ObservedReadWriteTransaction(rwt, nil, "test"))
+}
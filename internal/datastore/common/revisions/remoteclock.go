@@ -21,6 +21,7 @@ type RemoteClockRevisions struct {
 
 	gcWindowNanos          int64
 	nowFunc                RemoteNowFunction
+	optimizedNowFunc       RemoteNowFunction
 	followerReadDelayNanos int64
 	quantizationNanos      int64
 }
@@ -44,7 +45,12 @@ func NewRemoteClockRevisions(gcWindow, maxRevisionStaleness, followerReadDelay,
 }
 
 func (rcr *RemoteClockRevisions) optimizedRevisionFunc(ctx context.Context) (datastore.Revision, time.Duration, error) {
-	nowHLC, err := rcr.nowFunc(ctx)
+	nowFunc := rcr.nowFunc
+	if rcr.optimizedNowFunc != nil {
+		nowFunc = rcr.optimizedNowFunc
+	}
+
+	nowHLC, err := nowFunc(ctx)
 	if err != nil {
 		return revision.NoRevision, 0, err
 	}
@@ -67,6 +73,13 @@ func (rcr *RemoteClockRevisions) SetNowFunc(nowFunc RemoteNowFunction) {
 	rcr.nowFunc = nowFunc
 }
 
+// SetOptimizedNowFunc sets the function used to determine the revision from which optimized
+// revisions are computed, in place of the head revision. The follower read delay and the
+// quantization are applied to the revision it returns.
+func (rcr *RemoteClockRevisions) SetOptimizedNowFunc(nowFunc RemoteNowFunction) {
+	rcr.optimizedNowFunc = nowFunc
+}
+
 func (rcr *RemoteClockRevisions) CheckRevision(ctx context.Context, dsRevision datastore.Revision) error {
 	if dsRevision == datastore.NoRevision {
 		return datastore.NewInvalidRevisionErr(dsRevision, datastore.CouldNotDetermineRevision)
@@ -106,6 +106,26 @@ func TestRemoteClockOptimizedRevisions(t *testing.T) {
 	}
 }
 
+func TestRemoteClockOptimizedNowFunc(t *testing.T) {
+	require := require.New(t)
+
+	rcr := NewRemoteClockRevisions(1*time.Hour, 0, 1*time.Second, 5*time.Second)
+	rcr.SetNowFunc(func(ctx context.Context) (revision.Decimal, error) {
+		return revision.NewFromDecimal(decimal.NewFromInt(1240 * 1_000_000_000)), nil
+	})
+	rcr.SetOptimizedNowFunc(func(ctx context.Context) (revision.Decimal, error) {
+		return revision.NewFromDecimal(decimal.NewFromInt(1236 * 1_000_000_000)), nil
+	})
+
+	// The delay and quantization apply to the revision of the optimized now function.
+	optimized, err := rcr.OptimizedRevision(context.Background())
+	require.NoError(err)
+	require.True(revision.NewFromDecimal(decimal.NewFromInt(1235 * 1_000_000_000)).Equal(optimized))
+
+	// Revisions are still checked against the head revision.
+	require.NoError(rcr.CheckRevision(context.Background(), revision.NewFromDecimal(decimal.NewFromInt(1238*1_000_000_000))))
+}
+
 func TestRemoteClockCheckRevisions(t *testing.T) {
 	testCases := []struct {
 		name                string
@@ -2,8 +2,10 @@ package common
 
 import (
 	"context"
+	"errors"
 	"math"
 	"runtime"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
@@ -32,6 +34,9 @@ var (
 	// ObjIDKey is a tracing attribute representing the resource object ID.
 	ObjIDKey = attribute.Key("authzed.com/spicedb/sql/objId")
 
+	// ObjIDPrefixKey is a tracing attribute representing the prefix of resource object IDs.
+	ObjIDPrefixKey = attribute.Key("authzed.com/spicedb/sql/objIdPrefix")
+
 	// SubNamespaceNameKey is a tracing attribute representing the subject object
 	// type.
 	SubNamespaceNameKey = attribute.Key("authzed.com/spicedb/sql/subNamespaceName")
@@ -131,6 +136,18 @@ func (sqf SchemaQueryFilterer) FilterToResourceIDs(resourceIds []string) (Schema
 	return sqf, nil
 }
 
+// likePatternEscaper escapes the characters of a string matched literally in a LIKE pattern,
+// using the default escape character shared by the supported SQL databases.
+var likePatternEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// FilterToResourceIDPrefix returns a new SchemaQueryFilterer that is limited to resources with IDs
+// starting with the specified prefix.
+func (sqf SchemaQueryFilterer) FilterToResourceIDPrefix(prefix string) SchemaQueryFilterer {
+	sqf.queryBuilder = sqf.queryBuilder.Where(sq.Like{sqf.schema.ColObjectID: likePatternEscaper.Replace(prefix) + "%"})
+	sqf.tracerAttributes = append(sqf.tracerAttributes, ObjIDPrefixKey.String(prefix))
+	return sqf
+}
+
 // FilterToRelation returns a new SchemaQueryFilterer that is limited to resources with the
 // specified relation.
 func (sqf SchemaQueryFilterer) FilterToRelation(relation string) SchemaQueryFilterer {
@@ -156,6 +173,10 @@ func (sqf SchemaQueryFilterer) FilterWithRelationshipsFilter(filter datastore.Re
 		sqf = sqf.FilterToRelation(filter.OptionalResourceRelation)
 	}
 
+	if filter.OptionalResourceIDPrefix != "" {
+		sqf = sqf.FilterToResourceIDPrefix(filter.OptionalResourceIDPrefix)
+	}
+
 	if len(filter.OptionalResourceIds) > 0 {
 		usqf, err := sqf.FilterToResourceIDs(filter.OptionalResourceIds)
 		if err != nil {
@@ -315,6 +336,51 @@ func (sqf SchemaQueryFilterer) filterToUsersets(usersets []*core.ObjectAndRelati
 	return sqf
 }
 
+// resourceSortColumns returns the columns by which relationships are sorted by resource, in order.
+func (schema SchemaInformation) resourceSortColumns() []string {
+	return []string{
+		schema.ColNamespace,
+		schema.ColObjectID,
+		schema.ColRelation,
+		schema.ColUsersetNamespace,
+		schema.ColUsersetObjectID,
+		schema.ColUsersetRelation,
+	}
+}
+
+// sortedByResource returns a new SchemaQueryFilterer whose results are sorted by resource, limited
+// to the relationships sorted after the specified relationship if not nil.
+func (sqf SchemaQueryFilterer) sortedByResource(after *core.RelationTuple) SchemaQueryFilterer {
+	columns := sqf.schema.resourceSortColumns()
+
+	if after != nil {
+		values := []string{
+			after.ResourceAndRelation.Namespace,
+			after.ResourceAndRelation.ObjectId,
+			after.ResourceAndRelation.Relation,
+			after.Subject.Namespace,
+			after.Subject.ObjectId,
+			after.Subject.Relation,
+		}
+
+		// Row value comparisons are not supported by all the databases, so the comparison is
+		// expanded into a disjunction over each column.
+		afterClause := sq.Or{}
+		for i := range columns {
+			columnClause := sq.And{}
+			for j := 0; j < i; j++ {
+				columnClause = append(columnClause, sq.Eq{columns[j]: values[j]})
+			}
+			columnClause = append(columnClause, sq.Gt{columns[i]: values[i]})
+			afterClause = append(afterClause, columnClause)
+		}
+		sqf.queryBuilder = sqf.queryBuilder.Where(afterClause)
+	}
+
+	sqf.queryBuilder = sqf.queryBuilder.OrderBy(columns...)
+	return sqf
+}
+
 // Limit returns a new SchemaQueryFilterer which is limited to the specified number of results.
 func (sqf SchemaQueryFilterer) limit(limit uint64) SchemaQueryFilterer {
 	sqf.queryBuilder = sqf.queryBuilder.Limit(limit)
@@ -322,6 +388,14 @@ func (sqf SchemaQueryFilterer) limit(limit uint64) SchemaQueryFilterer {
 	return sqf
 }
 
+// LimitedSubquery returns the query of the filterer, limited to the specified number of rows, for
+// use as a subquery selecting the rows modified by a statement, such as the relationships deleted
+// in a batch by BulkDeleteRelationships. It uses question placeholders, which are replaced along
+// with those of the statement it is nested in.
+func (sqf SchemaQueryFilterer) LimitedSubquery(limit uint64) sq.SelectBuilder {
+	return sqf.limit(limit).queryBuilder.PlaceholderFormat(sq.Question)
+}
+
 // TupleQuerySplitter is a tuple query runner shared by SQL implementations of the datastore.
 type TupleQuerySplitter struct {
 	Executor         ExecuteQueryFunc
@@ -345,6 +419,15 @@ func (tqs TupleQuerySplitter) SplitAndExecuteQuery(
 		remainingLimit = int(*queryOpts.Limit)
 	}
 
+	switch {
+	case queryOpts.Sort == options.ByResource && len(queryOpts.Usersets) > 0:
+		return nil, errors.New("cannot sort relationships queried by usersets")
+	case queryOpts.Sort == options.ByResource:
+		query = query.sortedByResource(queryOpts.After)
+	case queryOpts.After != nil:
+		return nil, errors.New("cannot query relationships after a relationship without sorting them")
+	}
+
 	remainingUsersets := queryOpts.Usersets
 	for remaining := 1; remaining > 0; remaining = len(remainingUsersets) {
 		upperBound := uint16(len(remainingUsersets))
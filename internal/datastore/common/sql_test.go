@@ -81,6 +81,17 @@ func TestSchemaQueryFilterer(t *testing.T) {
 			"SELECT * WHERE ns = ? AND object_id IN (?)",
 			[]any{"sometype", "someid"},
 		},
+		{
+			"relationships filter with ID prefix",
+			func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
+				return filterer.MustFilterWithRelationshipsFilter(datastore.RelationshipsFilter{
+					ResourceType:             "sometype",
+					OptionalResourceIDPrefix: "tenant_1/",
+				})
+			},
+			"SELECT * WHERE ns = ? AND object_id LIKE ?",
+			[]any{"sometype", `tenant\_1/%`},
+		},
 		{
 			"relationships filter with no IDs",
 			func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
@@ -332,6 +343,34 @@ func TestSchemaQueryFilterer(t *testing.T) {
 			"SELECT * LIMIT 100",
 			nil,
 		},
+		{
+			"sorted by resource",
+			func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
+				return filterer.FilterToResourceType("sometype").sortedByResource(nil)
+			},
+			"SELECT * WHERE ns = ? ORDER BY ns, object_id, relation, subject_ns, subject_object_id, subject_relation",
+			[]any{"sometype"},
+		},
+		{
+			"sorted by resource after relationship",
+			func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
+				return filterer.FilterToResourceType("sometype").sortedByResource(tuple.MustParse("sometype:foo#viewer@user:bar"))
+			},
+			"SELECT * WHERE ns = ? AND ((ns > ?) OR (ns = ? AND object_id > ?) OR (ns = ? AND object_id = ? AND relation > ?) OR " +
+				"(ns = ? AND object_id = ? AND relation = ? AND subject_ns > ?) OR " +
+				"(ns = ? AND object_id = ? AND relation = ? AND subject_ns = ? AND subject_object_id > ?) OR " +
+				"(ns = ? AND object_id = ? AND relation = ? AND subject_ns = ? AND subject_object_id = ? AND subject_relation > ?)) " +
+				"ORDER BY ns, object_id, relation, subject_ns, subject_object_id, subject_relation",
+			[]any{
+				"sometype",
+				"sometype",
+				"sometype", "foo",
+				"sometype", "foo", "viewer",
+				"sometype", "foo", "viewer", "user",
+				"sometype", "foo", "viewer", "user", "bar",
+				"sometype", "foo", "viewer", "user", "bar", "...",
+			},
+		},
 		{
 			"full resources filter",
 			func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
@@ -375,3 +414,25 @@ func TestSchemaQueryFilterer(t *testing.T) {
 		})
 	}
 }
+
+func TestLimitedSubquery(t *testing.T) {
+	filterer := NewSchemaQueryFilterer(SchemaInformation{
+		TableTuple:          "tuple",
+		ColNamespace:        "ns",
+		ColObjectID:         "object_id",
+		ColRelation:         "relation",
+		ColUsersetNamespace: "subject_ns",
+		ColUsersetObjectID:  "subject_object_id",
+		ColUsersetRelation:  "subject_relation",
+	}, sq.Select("id").From("tuple").PlaceholderFormat(sq.Dollar))
+
+	subquery := filterer.FilterToResourceType("sometype").FilterToResourceIDPrefix("tenant1:").LimitedSubquery(100)
+	sql, args, err := sq.Update("tuple").
+		Set("deleted", 42).
+		Where(sq.Expr("id IN (?)", subquery)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE tuple SET deleted = $1 WHERE id IN (SELECT id FROM tuple WHERE ns = $2 AND object_id LIKE $3 LIMIT 100)", sql)
+	require.Equal(t, []any{42, "sometype", "tenant1:%"}, args)
+}
@@ -62,8 +62,9 @@ const (
 	errUnableToInstantiate = "unable to instantiate datastore: %w"
 	errRevision            = "unable to find revision: %w"
 
-	querySelectNow      = "SELECT cluster_logical_timestamp()"
-	queryShowZoneConfig = "SHOW ZONE CONFIGURATION FOR RANGE default;"
+	querySelectNow                   = "SELECT cluster_logical_timestamp()"
+	querySelectFollowerReadTimestamp = "SELECT follower_read_timestamp()"
+	queryShowZoneConfig              = "SHOW ZONE CONFIGURATION FOR RANGE default;"
 
 	livingTupleConstraint = "pk_relation_tuple"
 )
@@ -127,6 +128,12 @@ func newCRDBDatastore(url string, options ...Option) (datastore.Datastore, error
 		)
 	}
 
+	if config.enableFollowerReads {
+		if _, err := readFollowerReadRevision(initCtx, pool); err != nil {
+			return nil, fmt.Errorf(errUnableToInstantiate, fmt.Errorf("unable to enable follower reads: %w", err))
+		}
+	}
+
 	var keyer overlapKeyer
 	switch config.overlapStrategy {
 	case overlapStrategyStatic:
@@ -164,9 +171,13 @@ func newCRDBDatastore(url string, options ...Option) (datastore.Datastore, error
 		executeWithMaxRetries(config.maxRetries),
 		config.disableStats,
 		changefeedQuery,
+		config.operationObserver,
 	}
 
 	ds.RemoteClockRevisions.SetNowFunc(ds.headRevisionInternal)
+	if config.enableFollowerReads {
+		ds.RemoteClockRevisions.SetOptimizedNowFunc(ds.followerReadRevisionInternal)
+	}
 
 	return ds, nil
 }
@@ -222,6 +233,7 @@ type crdbDatastore struct {
 	disableStats      bool
 
 	beginChangefeedQuery string
+	operationObserver    datastore.OperationObserver
 }
 
 func (cds *crdbDatastore) SnapshotReader(rev datastore.Revision) datastore.Reader {
@@ -255,7 +267,8 @@ func (cds *crdbDatastore) SnapshotReader(rev datastore.Revision) datastore.Reade
 		UsersetBatchSize: cds.usersetBatchSize,
 	}
 
-	return &crdbReader{createTxFunc, querySplitter, noOverlapKeyer, nil, cds.execute}
+	reader := &crdbReader{createTxFunc, querySplitter, noOverlapKeyer, nil, cds.execute}
+	return common.ObservedReader(reader, cds.operationObserver, Engine)
 }
 
 func noCleanup(context.Context) {}
@@ -263,6 +276,16 @@ func noCleanup(context.Context) {}
 func (cds *crdbDatastore) ReadWriteTx(
 	ctx context.Context,
 	f datastore.TxUserFunc,
+) (datastore.Revision, error) {
+	start := time.Now()
+	rev, err := cds.readWriteTx(ctx, f)
+	common.ObserveOperation(ctx, cds.operationObserver, Engine, "ReadWriteTx", start, 0, err)
+	return rev, err
+}
+
+func (cds *crdbDatastore) readWriteTx(
+	ctx context.Context,
+	f datastore.TxUserFunc,
 ) (datastore.Revision, error) {
 	var commitTimestamp revision.Decimal
 	if err := cds.execute(ctx, func(ctx context.Context) error {
@@ -288,7 +311,7 @@ func (cds *crdbDatastore) ReadWriteTx(
 				0,
 			}
 
-			if err := f(rwt); err != nil {
+			if err := f(common.ObservedReadWriteTransaction(rwt, cds.operationObserver, Engine)); err != nil {
 				return err
 			}
 
@@ -383,7 +406,7 @@ func (cds *crdbDatastore) Features(ctx context.Context) (*datastore.Features, er
 	streamCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	time.AfterFunc(1*time.Second, cancel)
-	_, err = cds.pool.Exec(streamCtx, fmt.Sprintf(cds.beginChangefeedQuery, tableTuple, head))
+	_, err = cds.pool.Exec(streamCtx, fmt.Sprintf(cds.beginChangefeedQuery, tableTuple, head, ""))
 	if err != nil && errors.Is(err, context.Canceled) {
 		features.Watch.Enabled = true
 		features.Watch.Reason = ""
@@ -396,6 +419,31 @@ func (cds *crdbDatastore) Features(ctx context.Context) (*datastore.Features, er
 	return &features, nil
 }
 
+// followerReadRevisionInternal returns the most recent revision at which reads are served by
+// follower replicas.
+func (cds *crdbDatastore) followerReadRevisionInternal(ctx context.Context) (revision.Decimal, error) {
+	var followerReadRevision revision.Decimal
+	err := cds.execute(ctx, func(ctx context.Context) error {
+		var fnErr error
+		followerReadRevision, fnErr = readFollowerReadRevision(ctx, cds.pool)
+		return fnErr
+	})
+	if err != nil {
+		return revision.NoRevision, fmt.Errorf(errRevision, err)
+	}
+
+	return followerReadRevision, nil
+}
+
+func readFollowerReadRevision(ctx context.Context, conn *pgxpool.Pool) (revision.Decimal, error) {
+	var followerReadTimestamp time.Time
+	if err := conn.QueryRow(ctx, querySelectFollowerReadTimestamp).Scan(&followerReadTimestamp); err != nil {
+		return revision.NoRevision, fmt.Errorf("unable to read follower read timestamp: %w", err)
+	}
+
+	return revision.NewFromDecimal(decimal.NewFromInt(followerReadTimestamp.UnixNano())), nil
+}
+
 func readCRDBNow(ctx context.Context, tx pgx.Tx) (revision.Decimal, error) {
 	ctx, span := tracer.Start(ctx, "readCRDBNow")
 	defer span.End()
@@ -24,7 +24,10 @@ import (
 	"github.com/ory/dockertest/v3"
 	"github.com/stretchr/testify/require"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	crdbmigrations "github.com/authzed/spicedb/internal/datastore/crdb/migrations"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+	"github.com/authzed/spicedb/internal/datastore/proxy"
 	testdatastore "github.com/authzed/spicedb/internal/testserver/datastore"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/revision"
@@ -51,6 +54,88 @@ func TestCRDBDatastore(t *testing.T) {
 	}))
 }
 
+func TestCRDBRetriedSerializationFailure(t *testing.T) {
+	ds := testdatastore.RunCRDBForTesting(t, "").NewDatastore(t, func(engine, uri string) datastore.Datastore {
+		ds, err := NewCRDBDatastore(
+			uri,
+			GCWindow(24*time.Hour),
+			RevisionQuantization(0),
+			MaxRetries(0),
+		)
+		require.NoError(t, err)
+		return ds
+	})
+	defer ds.Close()
+
+	// The retries of the datastore itself are disabled, so the transaction restarts returned by
+	// CockroachDB must be retried by the retrying proxy.
+	test.SerializationConflictTest(t, proxy.NewRetryingDatastoreProxy(ds, proxy.DefaultRetryPolicy()))
+}
+
+func TestCRDBRetriedConcurrentWriterContention(t *testing.T) {
+	ds := testdatastore.RunCRDBForTesting(t, "").NewDatastore(t, func(engine, uri string) datastore.Datastore {
+		ds, err := NewCRDBDatastore(
+			uri,
+			GCWindow(24*time.Hour),
+			RevisionQuantization(0),
+		)
+		require.NoError(t, err)
+		return ds
+	})
+	defer ds.Close()
+
+	// Transaction restarts which exhaust the retries of the datastore itself are retried by the
+	// retrying proxy.
+	test.ConcurrentWriterContentionTest(t, proxy.NewRetryingDatastoreProxy(ds, proxy.DefaultRetryPolicy()))
+}
+
+func TestCRDBQueryCancellation(t *testing.T) {
+	require := require.New(t)
+
+	ds := testdatastore.RunCRDBForTesting(t, "").NewDatastore(t, func(engine, uri string) datastore.Datastore {
+		ds, err := newCRDBDatastore(uri, GCWindow(24*time.Hour), RevisionQuantization(0))
+		require.NoError(err)
+		return ds
+	})
+	defer ds.Close()
+
+	cds := ds.(*crdbDatastore)
+
+	var queryConn *pgx.Conn
+	executor := pgxcommon.NewPGXExecutor(func(ctx context.Context) (pgx.Tx, common.TxCleanupFunc, error) {
+		tx, err := cds.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+		if err != nil {
+			return nil, nil, err
+		}
+		queryConn = tx.Conn()
+		return tx, func(ctx context.Context) { _ = tx.Rollback(ctx) }, nil
+	})
+
+	// A query scanning as many columns as a relationship query, but which takes a minute to return.
+	slowQuery := "SELECT 'a', 'b', 'c', 'd', 'e', 'f', NULL::STRING, NULL::JSONB FROM (SELECT pg_sleep(60))"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	// The context is separated as by the datastore proxy, such that pgx does not close the
+	// connection once the caller's context is cancelled.
+	start := time.Now()
+	_, err := executor(proxy.SeparateContextWithCancellation(ctx), slowQuery, nil)
+	require.ErrorIs(err, context.Canceled)
+	require.Less(time.Since(start), 10*time.Second)
+	require.False(queryConn.IsClosed())
+
+	require.Eventually(func() bool {
+		var running int
+		err := cds.pool.QueryRow(
+			context.Background(),
+			"SELECT count(*) FROM crdb_internal.cluster_queries WHERE query LIKE '%pg_sleep(60)%' AND query NOT LIKE '%cluster_queries%'",
+		).Scan(&running)
+		require.NoError(err)
+		return running == 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
 func TestCRDBDatastoreWithFollowerReads(t *testing.T) {
 	followerReadDelay := time.Duration(4.8 * float64(time.Second))
 	gcWindow := 100 * time.Second
@@ -3,6 +3,8 @@ package crdb
 import (
 	"fmt"
 	"time"
+
+	"github.com/authzed/spicedb/pkg/datastore"
 )
 
 type crdbOptions struct {
@@ -15,6 +17,7 @@ type crdbOptions struct {
 	watchBufferLength           uint16
 	revisionQuantization        time.Duration
 	followerReadDelay           time.Duration
+	enableFollowerReads         bool
 	maxRevisionStalenessPercent float64
 	gcWindow                    time.Duration
 	maxRetries                  uint8
@@ -24,6 +27,7 @@ type crdbOptions struct {
 	disableStats                bool
 
 	enablePrometheusStats bool
+	operationObserver     datastore.OperationObserver
 }
 
 const (
@@ -178,6 +182,29 @@ func FollowerReadDelay(delay time.Duration) Option {
 	}
 }
 
+// EnableFollowerReads bases the revisions chosen for reads which do not require the most recent
+// data on the follower read timestamp of the cluster (`follower_read_timestamp()`), i.e. the most
+// recent timestamp at which reads can be served by the nearest replica rather than by the
+// leaseholder, avoiding cross-region latency in multi-region clusters. FollowerReadDelay is then
+// applied in addition to it.
+//
+// Snapshot reads always happen `AS OF SYSTEM TIME` the exact revision requested, which CockroachDB
+// serves from followers only if it is at least as old as the follower read timestamp. Reads at
+// the optimized revision, as used for the minimize_latency consistency, are thus follower reads,
+// while reads at more recent revisions, as for the fully_consistent consistency or for
+// at_least_as_fresh with a recent ZedToken, are never follower reads and are served by the
+// leaseholders.
+//
+// Follower reads require a CockroachDB version or license supporting them: the datastore fails to
+// start if the follower read timestamp cannot be read.
+//
+// Follower reads are disabled by default.
+func EnableFollowerReads(enabled bool) Option {
+	return func(po *crdbOptions) {
+		po.enableFollowerReads = enabled
+	}
+}
+
 // MaxRevisionStalenessPercent is the amount of time, expressed as a percentage of
 // the revision quantization window, that a previously computed rounded revision
 // can still be advertised after the next rounded revision would otherwise be ready.
@@ -240,3 +267,13 @@ func WithEnablePrometheusStats(enablePrometheusStats bool) Option {
 		po.enablePrometheusStats = enablePrometheusStats
 	}
 }
+
+// WithOperationObserver sets an observer called once each read and write operation made against
+// the datastore completes, with its duration, error and approximate result size.
+//
+// No observer is set by default, in which case operations are not observed at all.
+func WithOperationObserver(observer datastore.OperationObserver) Option {
+	return func(po *crdbOptions) {
+		po.operationObserver = observer
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
@@ -11,12 +12,20 @@ import (
 	"github.com/jzelinskie/stringz"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
+// bulkLoadBatchSize is the number of relationships written per batch by BulkLoad.
+const bulkLoadBatchSize = 1000
+
+// bulkDeleteBatchSize is the maximum number of relationships deleted per statement by
+// BulkDeleteRelationships.
+const bulkDeleteBatchSize = 1000
+
 const (
 	errUnableToWriteConfig         = "unable to write namespace config: %w"
 	errUnableToDeleteConfig        = "unable to delete namespace config: %w"
@@ -76,6 +85,17 @@ var (
 
 	queryDeleteTuples = psql.Delete(tableTuple)
 
+	tupleKeyColumns = []string{
+		colNamespace,
+		colObjectID,
+		colRelation,
+		colUsersetNamespace,
+		colUsersetObjectID,
+		colUsersetRelation,
+	}
+
+	queryTupleKeys = psql.Select(tupleKeyColumns...).From(tableTuple)
+
 	queryTouchTransaction = fmt.Sprintf(
 		"INSERT INTO %s (%s) VALUES ($1::text) ON CONFLICT (%s) DO UPDATE SET %s = now()",
 		tableTransactions,
@@ -188,6 +208,44 @@ func exactRelationshipClause(r *core.RelationTuple) sq.Eq {
 	}
 }
 
+// BulkLoad writes the relationships in batches of multi-row inserts.
+func (rwt *crdbReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.RelationshipIterator) (uint64, error) {
+	return common.BulkLoadInBatches(ctx, iter, bulkLoadBatchSize, rwt.WriteRelationships)
+}
+
+// BulkDeleteRelationships deletes the relationships matching the filter server-side, in batches of
+// relationships selected by primary key.
+func (rwt *crdbReadWriteTXN) BulkDeleteRelationships(ctx context.Context, filter datastore.RelationshipsFilter, limit uint64) (uint64, error) {
+	filterer, err := common.NewSchemaQueryFilterer(schema, queryTupleKeys).FilterWithRelationshipsFilter(filter)
+	if err != nil {
+		return 0, fmt.Errorf(errUnableToDeleteRelationships, err)
+	}
+
+	rwt.addOverlapKey(filter.ResourceType)
+	for _, selector := range filter.OptionalSubjectsSelectors {
+		if selector.OptionalSubjectType != "" {
+			rwt.addOverlapKey(selector.OptionalSubjectType)
+		}
+	}
+
+	return common.DeleteRelationshipsInLimitedBatches(ctx, limit, bulkDeleteBatchSize, func(ctx context.Context, batchLimit uint64) (uint64, error) {
+		sql, args, err := queryDeleteTuples.
+			Where(sq.Expr("("+strings.Join(tupleKeyColumns, ", ")+") IN (?)", filterer.LimitedSubquery(batchLimit))).
+			ToSql()
+		if err != nil {
+			return 0, fmt.Errorf(errUnableToDeleteRelationships, err)
+		}
+
+		modified, err := rwt.tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return 0, fmt.Errorf(errUnableToDeleteRelationships, err)
+		}
+
+		rwt.relCountChange -= modified.RowsAffected()
+		return uint64(modified.RowsAffected()), nil
+	})
+}
+
 func (rwt *crdbReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
 	// Add clauses for the ResourceFilter
 	query := queryDeleteTuples.Where(sq.Eq{colNamespace: filter.ResourceType})
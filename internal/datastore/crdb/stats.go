@@ -2,6 +2,7 @@ package crdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 
@@ -26,6 +27,15 @@ var (
 	queryReadUniqueID         = psql.Select(colUniqueID).From(tableMetadata)
 	queryRelationshipEstimate = fmt.Sprintf("SELECT COALESCE(SUM(%s), 0) FROM %s", colCount, tableCounters)
 
+	// The statistics automatically collected on the prefixes of the primary key include the
+	// distinct count of the (namespace, object_id) pairs.
+	queryObjectEstimate = fmt.Sprintf(
+		"SELECT distinct_count FROM [SHOW STATISTICS FOR TABLE %s] WHERE column_names = ARRAY['%s', '%s'] ORDER BY created DESC LIMIT 1",
+		tableTuple,
+		colNamespace,
+		colObjectID,
+	)
+
 	upsertCounterQuery = psql.Insert(tableCounters).Columns(
 		colID,
 		colCount,
@@ -41,6 +51,7 @@ func (cds *crdbDatastore) Statistics(ctx context.Context) (datastore.Stats, erro
 	var uniqueID string
 	var nsDefs []*corev1.NamespaceDefinition
 	var relCount uint64
+	var objectCount uint64
 	if err := cds.pool.BeginTxFunc(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
 		if err := tx.QueryRow(ctx, sql, args...).Scan(&uniqueID); err != nil {
 			return fmt.Errorf("unable to query unique ID: %w", err)
@@ -50,6 +61,11 @@ func (cds *crdbDatastore) Statistics(ctx context.Context) (datastore.Stats, erro
 			return fmt.Errorf("unable to read relationship count: %w", err)
 		}
 
+		// The table has no statistics until they have first been collected.
+		if err := tx.QueryRow(ctx, queryObjectEstimate).Scan(&objectCount); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("unable to read object count: %w", err)
+		}
+
 		nsDefs, err = loadAllNamespaces(ctx, tx)
 		if err != nil {
 			return fmt.Errorf("unable to read namespaces: %w", err)
@@ -63,6 +79,8 @@ func (cds *crdbDatastore) Statistics(ctx context.Context) (datastore.Stats, erro
 	return datastore.Stats{
 		UniqueID:                   uniqueID,
 		EstimatedRelationshipCount: relCount,
+		EstimatedObjectCount:       objectCount,
+		HasEstimatedObjectCount:    true,
 		ObjectTypeStatistics:       datastore.ComputeObjectTypeStats(nsDefs),
 	}, nil
 }
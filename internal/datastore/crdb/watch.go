@@ -8,26 +8,33 @@ import (
 	"sort"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/options"
 
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
 const (
-	queryChangefeed       = "EXPERIMENTAL CHANGEFEED FOR %s WITH updated, cursor = '%s', resolved = '1s', min_checkpoint_frequency = '0';"
-	queryChangefeedPreV22 = "EXPERIMENTAL CHANGEFEED FOR %s WITH updated, cursor = '%s', resolved = '1s';"
+	queryChangefeed       = "EXPERIMENTAL CHANGEFEED FOR %s WITH updated, cursor = '%s', resolved = '1s', min_checkpoint_frequency = '0'%s;"
+	queryChangefeedPreV22 = "EXPERIMENTAL CHANGEFEED FOR %s WITH updated, cursor = '%s', resolved = '1s'%s;"
+
+	// changefeedDiffOption adds the previous value of a changed row to the change, which tells
+	// apart added and updated caveats.
+	changefeedDiffOption = ", diff"
 )
 
 type changeDetails struct {
 	Resolved string
 	Updated  string
+	Before   *struct{}
 	After    *struct {
 		CaveatContext map[string]any `json:"caveat_context"`
 		CaveatName    string         `json:"caveat_name"`
 	}
 }
 
-func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
+func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Revision, opts ...options.WatchOptionsOption) (<-chan *datastore.RevisionChanges, <-chan error) {
+	watchOpts := options.NewWatchOptionsWithOptions(opts...)
 	updates := make(chan *datastore.RevisionChanges, cds.watchBufferLength)
 	errs := make(chan error, 1)
 
@@ -42,7 +49,13 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 		return updates, errs
 	}
 
-	interpolated := fmt.Sprintf(cds.beginChangefeedQuery, tableTuple, afterRevision)
+	tables := tableTuple
+	extraOptions := ""
+	if watchOpts.IncludeCaveats {
+		tables = tableTuple + ", " + tableCaveat
+		extraOptions = changefeedDiffOption
+	}
+	interpolated := fmt.Sprintf(cds.beginChangefeedQuery, tables, afterRevision, extraOptions)
 
 	go func() {
 		defer close(updates)
@@ -65,11 +78,11 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 		defer func() { go changes.Close() }()
 
 		for changes.Next() {
-			var unused interface{}
+			var tableName string
 			var changeJSON []byte
 			var primaryKeyValuesJSON []byte
 
-			if err := changes.Scan(&unused, &primaryKeyValuesJSON, &changeJSON); err != nil {
+			if err := changes.Scan(&tableName, &primaryKeyValuesJSON, &changeJSON); err != nil {
 				if errors.Is(ctx.Err(), context.Canceled) {
 					errs <- datastore.NewWatchCanceledErr()
 				} else {
@@ -106,6 +119,11 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 				})
 
 				for _, change := range toEmit {
+					if err := cds.loadChangedCaveats(ctx, change); err != nil {
+						errs <- err
+						return
+					}
+
 					select {
 					case updates <- change:
 					default:
@@ -117,18 +135,47 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 				continue
 			}
 
-			var pkValues [6]string
-			if err := json.Unmarshal(primaryKeyValuesJSON, &pkValues); err != nil {
-				errs <- err
-				return
-			}
-
 			revision, err := cds.RevisionFromString(details.Updated)
 			if err != nil {
 				errs <- fmt.Errorf("malformed update timestamp: %w", err)
 				return
 			}
 
+			pending, ok := pendingChanges[details.Updated]
+			if !ok {
+				pending = &datastore.RevisionChanges{
+					Revision: revision,
+				}
+				pendingChanges[details.Updated] = pending
+			}
+
+			if tableName == tableCaveat {
+				var caveatPKValues [1]string
+				if err := json.Unmarshal(primaryKeyValuesJSON, &caveatPKValues); err != nil {
+					errs <- err
+					return
+				}
+
+				// The definitions of the caveats written are read once the revision is resolved.
+				caveatChange := datastore.CaveatChange{Name: caveatPKValues[0]}
+				switch {
+				case details.After == nil:
+					caveatChange.Operation = datastore.CaveatDeleted
+				case details.Before != nil:
+					caveatChange.Operation = datastore.CaveatUpdated
+				default:
+					caveatChange.Operation = datastore.CaveatAdded
+				}
+				pending.CaveatChanges = append(pending.CaveatChanges, caveatChange)
+				continue
+			}
+
+			var pkValues [6]string
+			if err := json.Unmarshal(primaryKeyValuesJSON, &pkValues); err != nil {
+				errs <- err
+				return
+			}
+
 			var caveatName string
 			var caveatContext map[string]any
 			if details.After != nil && details.After.CaveatName != "" {
@@ -163,13 +210,6 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 				oneChange.Operation = core.RelationTupleUpdate_TOUCH
 			}
 
-			pending, ok := pendingChanges[details.Updated]
-			if !ok {
-				pending = &datastore.RevisionChanges{
-					Revision: revision,
-				}
-				pendingChanges[details.Updated] = pending
-			}
 			pending.Changes = append(pending.Changes, oneChange)
 		}
 		if changes.Err() != nil {
@@ -183,3 +223,29 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 	}()
 	return updates, errs
 }
+
+// loadChangedCaveats reads the definitions of the caveats written at the revision of the changes,
+// and sorts the caveat changes by name.
+func (cds *crdbDatastore) loadChangedCaveats(ctx context.Context, changes *datastore.RevisionChanges) error {
+	if len(changes.CaveatChanges) == 0 {
+		return nil
+	}
+
+	reader := cds.SnapshotReader(changes.Revision)
+	for i, caveatChange := range changes.CaveatChanges {
+		if caveatChange.Operation == datastore.CaveatDeleted {
+			continue
+		}
+
+		definition, _, err := reader.ReadCaveatByName(ctx, caveatChange.Name)
+		if err != nil {
+			return fmt.Errorf("unable to load changed caveat: %w", err)
+		}
+		changes.CaveatChanges[i].Definition = definition
+	}
+
+	sort.Slice(changes.CaveatChanges, func(i, j int) bool {
+		return changes.CaveatChanges[i].Name < changes.CaveatChanges[j].Name
+	})
+	return nil
+}
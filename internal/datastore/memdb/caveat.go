@@ -25,6 +25,29 @@ func (c *caveat) Unwrap() (*core.CaveatDefinition, error) {
 	return &definition, err
 }
 
+// caveatChangeFrom converts a change made to the caveats table into the change to report to
+// watchers.
+func caveatChangeFrom(change memdb.Change) (datastore.CaveatChange, error) {
+	if change.Deleted() {
+		return datastore.CaveatChange{
+			Operation: datastore.CaveatDeleted,
+			Name:      change.Before.(*caveat).name,
+		}, nil
+	}
+
+	written := change.After.(*caveat)
+	definition, err := written.Unwrap()
+	if err != nil {
+		return datastore.CaveatChange{}, err
+	}
+
+	op := datastore.CaveatAdded
+	if change.Updated() {
+		op = datastore.CaveatUpdated
+	}
+	return datastore.CaveatChange{Operation: op, Name: written.name, Definition: definition}, nil
+}
+
 func (r *memdbReader) ReadCaveatByName(_ context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
 	r.mustLock()
 	defer r.Unlock()
@@ -216,7 +216,17 @@ func (mdb *memdbDatastore) ReadWriteTx(
 						})
 					}
 				}
+				if change.Table == tableCaveats {
+					caveatChange, err := caveatChangeFrom(change)
+					if err != nil {
+						return datastore.NoRevision, err
+					}
+					newChanges.CaveatChanges = append(newChanges.CaveatChanges, caveatChange)
+				}
 			}
+			sort.Slice(newChanges.CaveatChanges, func(i, j int) bool {
+				return newChanges.CaveatChanges[i].Name < newChanges.CaveatChanges[j].Name
+			})
 
 			change := &changelog{
 				revisionNanos: newRevision.IntPart(),
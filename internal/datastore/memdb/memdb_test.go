@@ -11,10 +11,12 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/pkg/datastore"
 	test "github.com/authzed/spicedb/pkg/datastore/test"
 	ns "github.com/authzed/spicedb/pkg/namespace"
 	corev1 "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 type memDBTest struct{}
@@ -27,6 +29,14 @@ func TestMemdbDatastore(t *testing.T) {
 	test.All(t, memDBTest{})
 }
 
+func TestConcurrentWriterContention(t *testing.T) {
+	ds, err := NewMemdbDatastore(0, 0, DisableGC)
+	require.NoError(t, err)
+	defer ds.Close()
+
+	test.ConcurrentWriterContentionTest(t, ds)
+}
+
 func TestConcurrentWritePanic(t *testing.T) {
 	require := require.New(t)
 
@@ -77,3 +87,114 @@ func TestConcurrentWritePanic(t *testing.T) {
 	}, 1*time.Second, 10*time.Millisecond)
 	require.ErrorIs(err, recoverErr)
 }
+
+func TestStatisticsObjectCount(t *testing.T) {
+	require := require.New(t)
+
+	ds, err := NewMemdbDatastore(0, 0, 1*time.Hour)
+	require.NoError(err)
+
+	ds, _ = testfixtures.StandardDatastoreWithData(ds, require)
+
+	objects := map[string]struct{}{}
+	for _, tupleStr := range testfixtures.StandardTuples {
+		tpl := tuple.MustParse(tupleStr)
+		objects[tpl.ResourceAndRelation.Namespace+":"+tpl.ResourceAndRelation.ObjectId] = struct{}{}
+	}
+
+	stats, err := ds.Statistics(context.Background())
+	require.NoError(err)
+	require.True(stats.HasEstimatedObjectCount)
+	require.Equal(uint64(len(objects)), stats.EstimatedObjectCount)
+	require.Equal(uint64(len(testfixtures.StandardTuples)), stats.EstimatedRelationshipCount)
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rawDS, err := NewMemdbDatastore(0, 0, DisableGC)
+	require.NoError(err)
+
+	source, _ := testfixtures.StandardDatastoreWithCaveatedData(rawDS, require)
+	data, err := rawDS.(SnapshotDatastore).Snapshot()
+	require.NoError(err)
+
+	restored, err := NewMemdbDatastore(0, 0, DisableGC)
+	require.NoError(err)
+	require.NoError(restored.(SnapshotDatastore).Restore(data, false))
+
+	sourceRev, err := source.HeadRevision(ctx)
+	require.NoError(err)
+	restoredRev, err := restored.HeadRevision(ctx)
+	require.NoError(err)
+
+	sourceReader := source.SnapshotReader(sourceRev)
+	restoredReader := restored.SnapshotReader(restoredRev)
+
+	sourceNamespaces, err := sourceReader.ListNamespaces(ctx)
+	require.NoError(err)
+	restoredNamespaces, err := restoredReader.ListNamespaces(ctx)
+	require.NoError(err)
+	require.Len(restoredNamespaces, len(sourceNamespaces))
+
+	sourceCaveats, err := sourceReader.ListCaveats(ctx)
+	require.NoError(err)
+	restoredCaveats, err := restoredReader.ListCaveats(ctx)
+	require.NoError(err)
+	require.NotEmpty(restoredCaveats)
+	require.Len(restoredCaveats, len(sourceCaveats))
+
+	sourceStats, err := source.Statistics(ctx)
+	require.NoError(err)
+	restoredStats, err := restored.Statistics(ctx)
+	require.NoError(err)
+	require.Equal(sourceStats.EstimatedRelationshipCount, restoredStats.EstimatedRelationshipCount)
+
+	require.ErrorIs(restored.(SnapshotDatastore).Restore(data, false), ErrRestoreNotEmpty)
+	require.NoError(restored.(SnapshotDatastore).Restore(data, true))
+
+	require.ErrorContains(restored.(SnapshotDatastore).Restore([]byte(`{"version":99}`), true), "unsupported snapshot version")
+}
+
+type countingResultIterator struct {
+	items []interface{}
+	nexts int
+}
+
+func (it *countingResultIterator) WatchCh() <-chan struct{} {
+	return nil
+}
+
+func (it *countingResultIterator) Next() interface{} {
+	it.nexts++
+	if len(it.items) == 0 {
+		return nil
+	}
+
+	next := it.items[0]
+	it.items = it.items[1:]
+	return next
+}
+
+func TestResourceTypeBoundedIterator(t *testing.T) {
+	require := require.New(t)
+
+	underlying := &countingResultIterator{
+		items: []interface{}{
+			&relationship{namespace: "document", resourceID: "first"},
+			&relationship{namespace: "document", resourceID: "second"},
+			&relationship{namespace: "folder", resourceID: "first"},
+			&relationship{namespace: "folder", resourceID: "second"},
+		},
+	}
+
+	bounded := &resourceTypeBoundedIterator{ResultIterator: underlying, resourceType: "document"}
+	require.Equal("first", bounded.Next().(*relationship).resourceID)
+	require.Equal("second", bounded.Next().(*relationship).resourceID)
+	require.Nil(bounded.Next())
+	require.Nil(bounded.Next())
+
+	// The iteration stopped at the first relationship of another resource type.
+	require.Equal(3, underlying.nexts)
+}
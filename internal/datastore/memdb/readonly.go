@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strings"
 
 	"github.com/hashicorp/go-memdb"
 	"github.com/jzelinskie/stringz"
@@ -41,7 +42,17 @@ func (r *memdbReader) QueryRelationships(
 
 	queryOpts := options.NewQueryOptionsWithOptions(opts...)
 
-	bestIterator, err := iteratorForFilter(tx, filter)
+	var bestIterator memdb.ResultIterator
+	switch {
+	case queryOpts.Sort == options.ByResource && len(queryOpts.Usersets) > 0:
+		return nil, fmt.Errorf("cannot sort relationships queried by usersets")
+	case queryOpts.Sort == options.ByResource:
+		bestIterator, err = sortedIteratorForFilter(tx, filter, queryOpts.After)
+	case queryOpts.After != nil:
+		return nil, fmt.Errorf("cannot query relationships after a relationship without sorting them")
+	default:
+		bestIterator, err = iteratorForFilter(tx, filter)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +60,7 @@ func (r *memdbReader) QueryRelationships(
 	matchingRelationshipsFilterFunc := filterFuncForFilters(
 		filter.ResourceType,
 		filter.OptionalResourceIds,
+		filter.OptionalResourceIDPrefix,
 		filter.OptionalResourceRelation,
 		filter.OptionalSubjectsSelectors,
 		filter.OptionalCaveatName,
@@ -109,6 +121,7 @@ func (r *memdbReader) ReverseQueryRelationships(
 	matchingRelationshipsFilterFunc := filterFuncForFilters(
 		filterObjectType,
 		nil,
+		"",
 		filterRelation,
 		[]datastore.SubjectsSelector{subjectsFilter.AsSelector()},
 		"",
@@ -266,9 +279,72 @@ func iteratorForFilter(txn *memdb.Txn, filter datastore.RelationshipsFilter) (me
 	return iter, err
 }
 
+// sortedIteratorForFilter returns an iterator over the relationships of the resource type of the
+// filter sorted by resource, starting after the specified relationship if not nil. As the fields
+// of the ID index are null-terminated, the index is sorted field by field.
+func sortedIteratorForFilter(txn *memdb.Txn, filter datastore.RelationshipsFilter, after *core.RelationTuple) (memdb.ResultIterator, error) {
+	if after == nil {
+		iter, err := txn.Get(tableRelationship, indexID+"_prefix", filter.ResourceType)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get iterator for filter: %w", err)
+		}
+		return iter, nil
+	}
+
+	iter, err := txn.LowerBound(
+		tableRelationship,
+		indexID,
+		after.ResourceAndRelation.Namespace,
+		after.ResourceAndRelation.ObjectId,
+		after.ResourceAndRelation.Relation,
+		after.Subject.Namespace,
+		after.Subject.ObjectId,
+		after.Subject.Relation,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get iterator for filter: %w", err)
+	}
+
+	// The lower bound iterates to the end of the table, so stop at the first relationship of
+	// another resource type, and skip the relationship itself, which the bound includes if it
+	// still exists.
+	bounded := &resourceTypeBoundedIterator{ResultIterator: iter, resourceType: filter.ResourceType}
+	return memdb.NewFilterIterator(bounded, func(tupleRaw interface{}) bool {
+		tuple := tupleRaw.(*relationship)
+		return tuple.namespace == after.ResourceAndRelation.Namespace &&
+			tuple.resourceID == after.ResourceAndRelation.ObjectId &&
+			tuple.relation == after.ResourceAndRelation.Relation &&
+			tuple.subjectNamespace == after.Subject.Namespace &&
+			tuple.subjectObjectID == after.Subject.ObjectId &&
+			tuple.subjectRelation == after.Subject.Relation
+	}), nil
+}
+
+// resourceTypeBoundedIterator ends the iteration of relationships sorted by resource at the
+// first relationship of a resource type other than that given.
+type resourceTypeBoundedIterator struct {
+	memdb.ResultIterator
+	resourceType string
+	done         bool
+}
+
+func (it *resourceTypeBoundedIterator) Next() interface{} {
+	if it.done {
+		return nil
+	}
+
+	next := it.ResultIterator.Next()
+	if next == nil || next.(*relationship).namespace != it.resourceType {
+		it.done = true
+		return nil
+	}
+	return next
+}
+
 func filterFuncForFilters(
 	optionalResourceType string,
 	optionalResourceIds []string,
+	optionalResourceIDPrefix string,
 	optionalRelation string,
 	optionalSubjectsSelectors []datastore.SubjectsSelector,
 	optionalCaveatFilter string,
@@ -282,6 +358,8 @@ func filterFuncForFilters(
 			return true
 		case len(optionalResourceIds) > 0 && !stringz.SliceContains(optionalResourceIds, tuple.resourceID):
 			return true
+		case optionalResourceIDPrefix != "" && !strings.HasPrefix(tuple.resourceID, optionalResourceIDPrefix):
+			return true
 		case optionalRelation != "" && optionalRelation != tuple.relation:
 			return true
 		case optionalCaveatFilter != "" && (tuple.caveat == nil || tuple.caveat.caveatName != optionalCaveatFilter):
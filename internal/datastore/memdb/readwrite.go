@@ -15,6 +15,12 @@ import (
 	"github.com/authzed/spicedb/pkg/tuple"
 )
 
+// bulkLoadBatchSize is the number of relationships written per batch by BulkLoad.
+const bulkLoadBatchSize = 1000
+
+// bulkDeleteBatchSize is the number of relationships deleted per batch by BulkDeleteRelationships.
+const bulkDeleteBatchSize = 1000
+
 type memdbReadWriteTx struct {
 	memdbReader
 	newRevision datastore.Revision
@@ -104,6 +110,16 @@ func (rwt *memdbReadWriteTx) toCaveatReference(mutation *core.RelationTupleUpdat
 	return cr
 }
 
+// BulkLoad writes the relationships in batches of CREATE mutations.
+func (rwt *memdbReadWriteTx) BulkLoad(ctx context.Context, iter datastore.RelationshipIterator) (uint64, error) {
+	return common.BulkLoadInBatches(ctx, iter, bulkLoadBatchSize, rwt.WriteRelationships)
+}
+
+// BulkDeleteRelationships deletes the relationships in pages of DELETE mutations.
+func (rwt *memdbReadWriteTx) BulkDeleteRelationships(ctx context.Context, filter datastore.RelationshipsFilter, limit uint64) (uint64, error) {
+	return common.DeleteRelationshipsInBatches(ctx, rwt, filter, limit, bulkDeleteBatchSize, rwt.WriteRelationships)
+}
+
 func (rwt *memdbReadWriteTx) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
 	rwt.mustLock()
 	defer rwt.Unlock()
@@ -0,0 +1,189 @@
+package memdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// SnapshotVersion is the version of the format produced by Snapshot.
+const SnapshotVersion = 1
+
+// ErrRestoreNotEmpty is returned when restoring a snapshot into a datastore which already holds
+// data, without requesting for it to be overwritten.
+var ErrRestoreNotEmpty = errors.New("cannot restore a snapshot into a non-empty datastore")
+
+// SnapshotDatastore is a datastore whose contents can be serialized and later restored, such as
+// the memdb datastore.
+type SnapshotDatastore interface {
+	datastore.Datastore
+
+	// Snapshot serializes all the namespaces, caveats and relationships of the datastore at its
+	// head revision.
+	Snapshot() ([]byte, error)
+
+	// Restore loads the contents of a snapshot into the datastore in a single transaction. If
+	// the datastore is not empty, ErrRestoreNotEmpty is returned unless overwrite is set, in
+	// which case all its existing contents are replaced.
+	Restore(data []byte, overwrite bool) error
+}
+
+// serializedSnapshot is the versioned envelope in which a snapshot is serialized. Definitions and
+// relationships are kept as their serialized protobuf messages.
+type serializedSnapshot struct {
+	Version       int      `json:"version"`
+	Namespaces    [][]byte `json:"namespaces"`
+	Caveats       [][]byte `json:"caveats"`
+	Relationships [][]byte `json:"relationships"`
+}
+
+func (mdb *memdbDatastore) Snapshot() ([]byte, error) {
+	mdb.RLock()
+	db := mdb.db
+	mdb.RUnlock()
+
+	if db == nil {
+		return nil, fmt.Errorf("datastore is closed")
+	}
+
+	tx := db.Txn(false)
+	defer tx.Abort()
+
+	snap := serializedSnapshot{Version: SnapshotVersion}
+
+	err := forEachRow(tx, tableNamespace, func(row interface{}) error {
+		snap.Namespaces = append(snap.Namespaces, row.(*namespace).configBytes)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = forEachRow(tx, tableCaveats, func(row interface{}) error {
+		snap.Caveats = append(snap.Caveats, row.(*caveat).definition)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = forEachRow(tx, tableRelationship, func(row interface{}) error {
+		rt, err := row.(*relationship).RelationTuple()
+		if err != nil {
+			return err
+		}
+		serialized, err := rt.MarshalVT()
+		if err != nil {
+			return err
+		}
+		snap.Relationships = append(snap.Relationships, serialized)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(snap)
+}
+
+func (mdb *memdbDatastore) Restore(data []byte, overwrite bool) error {
+	var snap serializedSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("unable to decode snapshot: %w", err)
+	}
+
+	if snap.Version < 1 || snap.Version > SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+
+	nsDefs := make([]*core.NamespaceDefinition, 0, len(snap.Namespaces))
+	for _, serialized := range snap.Namespaces {
+		nsDef := &core.NamespaceDefinition{}
+		if err := nsDef.UnmarshalVT(serialized); err != nil {
+			return fmt.Errorf("unable to decode snapshot namespace: %w", err)
+		}
+		nsDefs = append(nsDefs, nsDef)
+	}
+
+	caveats := make([]*core.CaveatDefinition, 0, len(snap.Caveats))
+	for _, serialized := range snap.Caveats {
+		caveat := &core.CaveatDefinition{}
+		if err := caveat.UnmarshalVT(serialized); err != nil {
+			return fmt.Errorf("unable to decode snapshot caveat: %w", err)
+		}
+		caveats = append(caveats, caveat)
+	}
+
+	mutations := make([]*core.RelationTupleUpdate, 0, len(snap.Relationships))
+	for _, serialized := range snap.Relationships {
+		rt := &core.RelationTuple{}
+		if err := rt.UnmarshalVT(serialized); err != nil {
+			return fmt.Errorf("unable to decode snapshot relationship: %w", err)
+		}
+		mutations = append(mutations, tuple.Touch(rt))
+	}
+
+	_, err := mdb.ReadWriteTx(context.Background(), func(rwt datastore.ReadWriteTransaction) error {
+		mrwt := rwt.(*memdbReadWriteTx)
+		mrwt.mustLock()
+		defer mrwt.Unlock()
+
+		tx, err := mrwt.txSource()
+		if err != nil {
+			return err
+		}
+
+		for _, table := range []string{tableNamespace, tableCaveats, tableRelationship} {
+			found, err := tx.First(table, indexID)
+			if err != nil {
+				return err
+			}
+			if found == nil {
+				continue
+			}
+			if !overwrite {
+				return ErrRestoreNotEmpty
+			}
+			if _, err := tx.DeleteAll(table, indexID); err != nil {
+				return fmt.Errorf("unable to clear existing data: %w", err)
+			}
+		}
+
+		for i, nsDef := range nsDefs {
+			entry := &namespace{nsDef.Name, snap.Namespaces[i], mrwt.newRevision}
+			if err := tx.Insert(tableNamespace, entry); err != nil {
+				return err
+			}
+		}
+
+		if err := mrwt.writeCaveat(tx, caveats); err != nil {
+			return err
+		}
+
+		return mrwt.write(tx, mutations...)
+	})
+	return err
+}
+
+func forEachRow(tx *memdb.Txn, table string, f func(row interface{}) error) error {
+	it, err := tx.LowerBound(table, indexID)
+	if err != nil {
+		return err
+	}
+
+	for row := it.Next(); row != nil; row = it.Next() {
+		if err := f(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ SnapshotDatastore = &memdbDatastore{}
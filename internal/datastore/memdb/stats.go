@@ -13,7 +13,7 @@ func (mdb *memdbDatastore) Statistics(ctx context.Context) (datastore.Stats, err
 		return datastore.Stats{}, fmt.Errorf("unable to compute head revision: %w", err)
 	}
 
-	count, err := mdb.countRelationships(ctx)
+	count, objectCount, err := mdb.countRelationships(ctx)
 	if err != nil {
 		return datastore.Stats{}, fmt.Errorf("unable to count relationships: %w", err)
 	}
@@ -26,11 +26,14 @@ func (mdb *memdbDatastore) Statistics(ctx context.Context) (datastore.Stats, err
 	return datastore.Stats{
 		UniqueID:                   mdb.uniqueID,
 		EstimatedRelationshipCount: count,
+		EstimatedObjectCount:       objectCount,
+		HasEstimatedObjectCount:    true,
 		ObjectTypeStatistics:       datastore.ComputeObjectTypeStats(objTypes),
 	}, nil
 }
 
-func (mdb *memdbDatastore) countRelationships(ctx context.Context) (uint64, error) {
+// countRelationships returns the number of relationships and of unique objects, exactly.
+func (mdb *memdbDatastore) countRelationships(ctx context.Context) (uint64, uint64, error) {
 	mdb.RLock()
 	defer mdb.RUnlock()
 
@@ -39,13 +42,25 @@ func (mdb *memdbDatastore) countRelationships(ctx context.Context) (uint64, erro
 
 	it, err := txn.LowerBound(tableRelationship, indexID)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	var count uint64
+	objects := map[string]map[string]struct{}{}
 	for row := it.Next(); row != nil; row = it.Next() {
 		count++
+
+		rel := row.(*relationship)
+		if _, ok := objects[rel.namespace]; !ok {
+			objects[rel.namespace] = map[string]struct{}{}
+		}
+		objects[rel.namespace][rel.resourceID] = struct{}{}
+	}
+
+	var objectCount uint64
+	for _, ids := range objects {
+		objectCount += uint64(len(ids))
 	}
 
-	return count, nil
+	return count, objectCount, nil
 }
@@ -7,13 +7,15 @@ import (
 
 	"github.com/hashicorp/go-memdb"
 
+	"github.com/authzed/spicedb/internal/datastore/options"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/revision"
 )
 
 const errWatchError = "watch error: %w"
 
-func (mdb *memdbDatastore) Watch(ctx context.Context, afterRevision datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
+func (mdb *memdbDatastore) Watch(ctx context.Context, afterRevision datastore.Revision, opts ...options.WatchOptionsOption) (<-chan *datastore.RevisionChanges, <-chan error) {
+	watchOpts := options.NewWatchOptionsWithOptions(opts...)
 	ar := afterRevision.(revision.Decimal)
 
 	updates := make(chan *datastore.RevisionChanges, mdb.watchBufferLength)
@@ -37,6 +39,13 @@ func (mdb *memdbDatastore) Watch(ctx context.Context, afterRevision datastore.Re
 
 			// Write the staged updates to the channel
 			for _, changeToWrite := range stagedUpdates {
+				if !watchOpts.IncludeCaveats && len(changeToWrite.CaveatChanges) > 0 {
+					changeToWrite = &datastore.RevisionChanges{
+						Revision: changeToWrite.Revision,
+						Changes:  changeToWrite.Changes,
+					}
+				}
+
 				select {
 				case updates <- changeToWrite:
 				default:
@@ -50,7 +50,6 @@ const (
 
 	errUnableToInstantiate = "unable to instantiate datastore: %w"
 	liveDeletedTxnID       = uint64(math.MaxInt64)
-	batchDeleteSize        = 1000
 	noLastInsertID         = 0
 	seedingTimeout         = 10 * time.Second
 
@@ -193,6 +192,7 @@ func newMySQLDatastore(uri string, options ...Option) (*Datastore, error) {
 		gcWindow:               config.gcWindow,
 		gcInterval:             config.gcInterval,
 		gcTimeout:              config.gcMaxOperationTime,
+		gcBatchSize:            config.gcBatchSize,
 		gcCtx:                  gcCtx,
 		cancelGc:               cancelGc,
 		watchBufferLength:      config.watchBufferLength,
@@ -411,6 +411,7 @@ type Datastore struct {
 	gcWindow             time.Duration
 	gcInterval           time.Duration
 	gcTimeout            time.Duration
+	gcBatchSize          uint64
 	watchBufferLength    uint16
 	usersetBatchSize     uint16
 	maxRetries           uint8
@@ -82,7 +82,7 @@ func createDatastoreTest(b testdatastore.RunningEngineForTest, tf datastoreTestF
 }
 
 func TestMySQLDatastore(t *testing.T) {
-	b := testdatastore.RunMySQLForTesting(t, "")
+	b := testdatastore.RunMySQLForTesting(t, "", migrate.Head)
 	dst := datastoreTester{b: b, t: t}
 	test.All(t, test.DatastoreTesterFunc(dst.createDatastore))
 
@@ -87,7 +87,7 @@ func (mds *Datastore) DeleteBeforeTx(
 // - query was reworked to make it compatible with Vitess
 // - API differences with PSQL driver
 func (mds *Datastore) batchDelete(ctx context.Context, tableName string, filter sqlFilter) (int64, error) {
-	query, args, err := sb.Delete(tableName).Where(filter).Limit(batchDeleteSize).ToSql()
+	query, args, err := sb.Delete(tableName).Where(filter).Limit(mds.gcBatchSize).ToSql()
 	if err != nil {
 		return -1, err
 	}
@@ -104,7 +104,7 @@ func (mds *Datastore) batchDelete(ctx context.Context, tableName string, filter
 			return deletedCount, err
 		}
 		deletedCount += rowsDeleted
-		if rowsDeleted < batchDeleteSize {
+		if rowsDeleted < int64(mds.gcBatchSize) {
 			break
 		}
 	}
@@ -7,10 +7,13 @@ import (
 
 const (
 	errQuantizationTooLarge = "revision quantization interval (%s) must be less than GC window (%s)"
+	errGCWindowTooSmall     = "GC window (%s) must be greater than the maximum staleness of optimized revisions (%s)"
+	errInvalidGCBatchSize   = "GC batch size must be greater than zero"
 
 	defaultGarbageCollectionWindow           = 24 * time.Hour
 	defaultGarbageCollectionInterval         = time.Minute * 3
 	defaultGarbageCollectionMaxOperationTime = time.Minute
+	defaultGarbageCollectionBatchSize        = 1000
 	defaultMaxOpenConns                      = 20
 	defaultConnMaxIdleTime                   = 30 * time.Minute
 	defaultConnMaxLifetime                   = 30 * time.Minute
@@ -27,6 +30,7 @@ type mysqlOptions struct {
 	revisionQuantization        time.Duration
 	gcWindow                    time.Duration
 	gcInterval                  time.Duration
+	gcBatchSize                 uint64
 	gcMaxOperationTime          time.Duration
 	maxRevisionStalenessPercent float64
 	watchBufferLength           uint16
@@ -51,6 +55,7 @@ func generateConfig(options []Option) (mysqlOptions, error) {
 		gcWindow:                    defaultGarbageCollectionWindow,
 		gcInterval:                  defaultGarbageCollectionInterval,
 		gcMaxOperationTime:          defaultGarbageCollectionMaxOperationTime,
+		gcBatchSize:                 defaultGarbageCollectionBatchSize,
 		watchBufferLength:           defaultWatchBufferLength,
 		maxOpenConns:                defaultMaxOpenConns,
 		connMaxIdleTime:             defaultConnMaxIdleTime,
@@ -76,6 +81,18 @@ func generateConfig(options []Option) (mysqlOptions, error) {
 		)
 	}
 
+	// Optimized revisions can be as old as the quantization interval plus the maximum staleness,
+	// and must not be garbage collected while they may still be used.
+	maxRevisionAge := computed.revisionQuantization +
+		time.Duration(float64(computed.revisionQuantization.Nanoseconds())*computed.maxRevisionStalenessPercent)
+	if maxRevisionAge >= computed.gcWindow {
+		return computed, fmt.Errorf(errGCWindowTooSmall, computed.gcWindow, maxRevisionAge)
+	}
+
+	if computed.gcBatchSize == 0 {
+		return computed, fmt.Errorf(errInvalidGCBatchSize)
+	}
+
 	return computed, nil
 }
 
@@ -231,6 +248,17 @@ func GCEnabled(isGCEnabled bool) Option {
 	}
 }
 
+// GCBatchSize is the maximum number of rows deleted by each statement of a garbage collection
+// pass, which deletes the expired rows in batches rather than in a single statement locking
+// the table.
+//
+// This value defaults to 1000.
+func GCBatchSize(batchSize uint64) Option {
+	return func(mo *mysqlOptions) {
+		mo.gcBatchSize = batchSize
+	}
+}
+
 // GCMaxOperationTime is the maximum operation time of a garbage collection
 // pass before it times out.
 //
@@ -25,10 +25,11 @@ type QueryBuilder struct {
 	QueryChangedQuery     sq.SelectBuilder
 	CountTupleQuery       sq.SelectBuilder
 
-	WriteCaveatQuery  sq.InsertBuilder
-	ReadCaveatQuery   sq.SelectBuilder
-	ListCaveatsQuery  sq.SelectBuilder
-	DeleteCaveatQuery sq.UpdateBuilder
+	WriteCaveatQuery         sq.InsertBuilder
+	ReadCaveatQuery          sq.SelectBuilder
+	ListCaveatsQuery         sq.SelectBuilder
+	DeleteCaveatQuery        sq.UpdateBuilder
+	QueryChangedCaveatsQuery sq.SelectBuilder
 }
 
 // NewQueryBuilder returns a new QueryBuilder instance. The migration
@@ -60,6 +61,7 @@ func NewQueryBuilder(driver *migrations.MySQLDriver) *QueryBuilder {
 	builder.ListCaveatsQuery = listCaveats(driver.Caveat())
 	builder.WriteCaveatQuery = writeCaveat(driver.Caveat())
 	builder.DeleteCaveatQuery = deleteCaveat(driver.Caveat())
+	builder.QueryChangedCaveatsQuery = queryChangedCaveats(driver.Caveat())
 
 	return &builder
 }
@@ -80,6 +82,10 @@ func writeCaveat(tableCaveat string) sq.InsertBuilder {
 	)
 }
 
+func queryChangedCaveats(tableCaveat string) sq.SelectBuilder {
+	return sb.Select(colName, colCaveatDefinition, colCreatedTxn, colDeletedTxn).From(tableCaveat)
+}
+
 func readCaveat(tableCaveat string) sq.SelectBuilder {
 	return sb.Select(colCaveatDefinition, colCreatedTxn).From(tableCaveat)
 }
@@ -21,6 +21,13 @@ import (
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
+// bulkLoadBatchSize is the number of relationships written per batch by BulkLoad.
+const bulkLoadBatchSize = 1000
+
+// bulkDeleteBatchSize is the maximum number of relationships deleted per statement by
+// BulkDeleteRelationships.
+const bulkDeleteBatchSize = 1000
+
 const (
 	errUnableToWriteRelationships  = "unable to write relationships: %w"
 	errUnableToDeleteRelationships = "unable to delete relationships: %w"
@@ -156,6 +163,42 @@ func (rwt *mysqlReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 	return nil
 }
 
+// BulkLoad writes the relationships in batches of multi-row inserts.
+func (rwt *mysqlReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.RelationshipIterator) (uint64, error) {
+	return common.BulkLoadInBatches(ctx, iter, bulkLoadBatchSize, rwt.WriteRelationships)
+}
+
+// BulkDeleteRelationships marks the relationships matching the filter as deleted server-side, in
+// batches of relationships selected by ID. The IDs are selected through a derived table, as MySQL
+// supports neither limits in IN subqueries nor subqueries reading the table being updated.
+func (rwt *mysqlReadWriteTXN) BulkDeleteRelationships(ctx context.Context, filter datastore.RelationshipsFilter, limit uint64) (uint64, error) {
+	filterer, err := common.NewSchemaQueryFilterer(schema, rwt.filterer(rwt.QueryTupleIdsQuery)).FilterWithRelationshipsFilter(filter)
+	if err != nil {
+		return 0, fmt.Errorf(errUnableToDeleteRelationships, err)
+	}
+
+	return common.DeleteRelationshipsInLimitedBatches(ctx, limit, bulkDeleteBatchSize, func(ctx context.Context, batchLimit uint64) (uint64, error) {
+		querySQL, args, err := rwt.DeleteTupleQuery.
+			Set(colDeletedTxn, rwt.newTxnID).
+			Where(sq.Expr(colID+" IN (SELECT "+colID+" FROM (?) AS batch)", filterer.LimitedSubquery(batchLimit))).
+			ToSql()
+		if err != nil {
+			return 0, fmt.Errorf(errUnableToDeleteRelationships, err)
+		}
+
+		result, err := rwt.tx.ExecContext(ctx, querySQL, args...)
+		if err != nil {
+			return 0, fmt.Errorf(errUnableToDeleteRelationships, err)
+		}
+
+		modified, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf(errUnableToDeleteRelationships, err)
+		}
+		return uint64(modified), nil
+	})
+}
+
 func (rwt *mysqlReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
 	// TODO (@vroldanbet) dupe from postgres datastore - need to refactor
 	// Add clauses for the ResourceFilter
@@ -2,6 +2,8 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
@@ -16,6 +18,13 @@ const (
 	informationSchemaTablesTable     = "INFORMATION_SCHEMA.TABLES"
 	informationSchemaTableNameColumn = "table_name"
 
+	informationSchemaStatisticsTable       = "INFORMATION_SCHEMA.STATISTICS"
+	informationSchemaCardinalityColumn     = "cardinality"
+	informationSchemaIndexNameColumn       = "index_name"
+	informationSchemaSeqInIndexColumn      = "seq_in_index"
+	relationTupleLivingIndex               = "uq_relation_tuple_living"
+	relationTupleLivingIndexObjectIDColumn = 2
+
 	metadataIDColumn       = "id"
 	metadataUniqueIDColumn = "unique_id"
 )
@@ -60,6 +69,11 @@ func (mds *Datastore) Statistics(ctx context.Context) (datastore.Stats, error) {
 		}
 	}
 
+	objectCount, err := mds.estimatedObjectCount(ctx)
+	if err != nil {
+		return datastore.Stats{}, err
+	}
+
 	nsQuery := mds.ReadNamespaceQuery.Where(squirrel.Eq{colDeletedTxn: liveDeletedTxnID})
 
 	tx, err := mds.db.BeginTx(ctx, nil)
@@ -77,9 +91,39 @@ func (mds *Datastore) Statistics(ctx context.Context) (datastore.Stats, error) {
 		UniqueID:                   uniqueID,
 		ObjectTypeStatistics:       datastore.ComputeObjectTypeStats(nsDefs),
 		EstimatedRelationshipCount: count,
+		EstimatedObjectCount:       objectCount,
+		HasEstimatedObjectCount:    true,
 	}, nil
 }
 
+// estimatedObjectCount returns the cardinality of the (namespace, object_id) prefix of the
+// index on living relationships, as estimated by the index statistics.
+func (mds *Datastore) estimatedObjectCount(ctx context.Context) (uint64, error) {
+	query, args, err := sb.
+		Select(informationSchemaCardinalityColumn).
+		From(informationSchemaStatisticsTable).
+		Where(squirrel.Eq{
+			informationSchemaTableNameColumn:  mds.driver.RelationTuple(),
+			informationSchemaIndexNameColumn:  relationTupleLivingIndex,
+			informationSchemaSeqInIndexColumn: relationTupleLivingIndexObjectIDColumn,
+		}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var cardinality sql.NullInt64
+	err = mds.db.QueryRowContext(ctx, query, args...).Scan(&cardinality)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("unable to read object count: %w", err)
+	}
+
+	if !cardinality.Valid || cardinality.Int64 < 0 {
+		return 0, nil
+	}
+	return uint64(cardinality.Int64), nil
+}
+
 func (mds *Datastore) getUniqueID(ctx context.Context) (string, error) {
 	sql, args, err := sb.Select(metadataUniqueIDColumn).From(mds.driver.Metadata()).ToSql()
 	if err != nil {
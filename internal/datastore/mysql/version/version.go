@@ -0,0 +1,6 @@
+package version
+
+// MinimumSupportedMySQLVersion is the minimum version of MySQL supported for this driver.
+//
+// NOTE: must match a tag on DockerHub for the `mysql` image.
+const MinimumSupportedMySQLVersion = "5.7"
@@ -3,9 +3,11 @@ package mysql
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/options"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/revision"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -22,7 +24,8 @@ const (
 // All events following afterRevision will be sent to the caller.
 //
 // TODO (@vroldanbet) dupe from postgres datastore - need to refactor
-func (mds *Datastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
+func (mds *Datastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revision, opts ...options.WatchOptionsOption) (<-chan *datastore.RevisionChanges, <-chan error) {
+	watchOpts := options.NewWatchOptionsWithOptions(opts...)
 	afterRevision := afterRevisionRaw.(revision.Decimal)
 
 	updates := make(chan *datastore.RevisionChanges, mds.watchBufferLength)
@@ -37,7 +40,7 @@ func (mds *Datastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revi
 		for {
 			var stagedUpdates []datastore.RevisionChanges
 			var err error
-			stagedUpdates, currentTxn, err = mds.loadChanges(ctx, currentTxn)
+			stagedUpdates, currentTxn, err = mds.loadChanges(ctx, currentTxn, watchOpts.IncludeCaveats)
 			if err != nil {
 				if errors.Is(ctx.Err(), context.Canceled) {
 					errs <- datastore.NewWatchCanceledErr()
@@ -81,6 +84,7 @@ func (mds *Datastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revi
 func (mds *Datastore) loadChanges(
 	ctx context.Context,
 	afterRevision uint64,
+	includeCaveats bool,
 ) (changes []datastore.RevisionChanges, newRevision uint64, err error) {
 	newRevision, err = mds.loadRevision(ctx)
 	if err != nil {
@@ -91,7 +95,7 @@ func (mds *Datastore) loadChanges(
 		return
 	}
 
-	sql, args, err := mds.QueryChangedQuery.Where(sq.Or{
+	changedWithin := sq.Or{
 		sq.And{
 			sq.Gt{colCreatedTxn: afterRevision},
 			sq.LtOrEq{colCreatedTxn: newRevision},
@@ -100,7 +104,9 @@ func (mds *Datastore) loadChanges(
 			sq.Gt{colDeletedTxn: afterRevision},
 			sq.LtOrEq{colDeletedTxn: newRevision},
 		},
-	}).ToSql()
+	}
+
+	sql, args, err := mds.QueryChangedQuery.Where(changedWithin).ToSql()
 	if err != nil {
 		return
 	}
@@ -158,7 +164,62 @@ func (mds *Datastore) loadChanges(
 		return
 	}
 
+	if includeCaveats {
+		if err = mds.loadCaveatChanges(ctx, changedWithin, afterRevision, newRevision, stagedChanges); err != nil {
+			return
+		}
+	}
+
 	changes = stagedChanges.AsRevisionChanges(revision.DecimalKeyLessThanFunc)
 
 	return
 }
+
+// loadCaveatChanges adds the caveat definitions written and deleted by the transactions after
+// afterRevision, up to and including newRevision, to the staged changes. As a written caveat
+// replaces the row of its previous definition, a caveat updated by a transaction is both written
+// and deleted by it.
+func (mds *Datastore) loadCaveatChanges(
+	ctx context.Context,
+	changedWithin sq.Sqlizer,
+	afterRevision uint64,
+	newRevision uint64,
+	stagedChanges common.Changes[revision.Decimal, int64],
+) error {
+	sql, args, err := mds.QueryChangedCaveatsQuery.Where(changedWithin).ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := mds.db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return datastore.NewWatchCanceledErr()
+		}
+		return err
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	for rows.Next() {
+		var name string
+		var serializedDef []byte
+		var createdTxn uint64
+		var deletedTxn uint64
+		if err := rows.Scan(&name, &serializedDef, &createdTxn, &deletedTxn); err != nil {
+			return err
+		}
+
+		if createdTxn > afterRevision && createdTxn <= newRevision {
+			def := &core.CaveatDefinition{}
+			if err := def.UnmarshalVT(serializedDef); err != nil {
+				return fmt.Errorf("unable to parse changed caveat: %w", err)
+			}
+			stagedChanges.AddCaveatWrite(revisionFromTransaction(createdTxn), def)
+		}
+
+		if deletedTxn > afterRevision && deletedTxn <= newRevision {
+			stagedChanges.AddCaveatDelete(revisionFromTransaction(deletedTxn), name)
+		}
+	}
+	return rows.Err()
+}
@@ -4,20 +4,49 @@ import (
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
-//go:generate go run github.com/ecordell/optgen -output zz_generated.query_options.go . QueryOptions ReverseQueryOptions
+//go:generate go run github.com/ecordell/optgen -output zz_generated.query_options.go . QueryOptions ReverseQueryOptions WatchOptions
 
 // QueryOptions are the options that can affect the results of a normal forward query.
 type QueryOptions struct {
 	Limit    *uint64
 	Usersets []*core.ObjectAndRelation
+
+	// Sort is the order in which the relationships are returned. Unless set, relationships are
+	// returned in no particular order.
+	Sort SortOrder
+
+	// After, if set, restricts the results to the relationships sorted after it, for reading
+	// relationships page by page. It requires Sort to be set, and cannot be combined with
+	// Usersets.
+	After *core.RelationTuple
 }
 
+// SortOrder is the order in which the relationships read by a query are returned.
+type SortOrder int8
+
+const (
+	// Unsorted returns the relationships in no particular order, which is the cheapest to read.
+	Unsorted SortOrder = iota
+
+	// ByResource sorts the relationships by resource type, resource ID, relation, subject type,
+	// subject ID and subject relation, in that order. The values are compared as by the backing
+	// database, so the order is only consistent across reads from the same datastore engine.
+	ByResource
+)
+
 // ReverseQueryOptions are the options that can affect the results of a reverse query.
 type ReverseQueryOptions struct {
 	ReverseLimit *uint64
 	ResRelation  *ResourceRelation
 }
 
+// WatchOptions are the options that can affect the changes emitted by a watch.
+type WatchOptions struct {
+	// IncludeCaveats opts into the changes made to caveat definitions, in addition to those
+	// made to relationships.
+	IncludeCaveats bool
+}
+
 // ResourceRelation combines a resource object type and relation.
 type ResourceRelation struct {
 	Namespace string
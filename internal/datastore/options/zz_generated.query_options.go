@@ -19,6 +19,8 @@ func (q *QueryOptions) ToOption() QueryOptionsOption {
 	return func(to *QueryOptions) {
 		to.Limit = q.Limit
 		to.Usersets = q.Usersets
+		to.Sort = q.Sort
+		to.After = q.After
 	}
 }
 
@@ -51,6 +53,20 @@ func SetUsersets(usersets []*v1.ObjectAndRelation) QueryOptionsOption {
 	}
 }
 
+// WithSort returns an option that can set Sort on a QueryOptions
+func WithSort(sort SortOrder) QueryOptionsOption {
+	return func(q *QueryOptions) {
+		q.Sort = sort
+	}
+}
+
+// WithAfter returns an option that can set After on a QueryOptions
+func WithAfter(after *v1.RelationTuple) QueryOptionsOption {
+	return func(q *QueryOptions) {
+		q.After = after
+	}
+}
+
 type ReverseQueryOptionsOption func(r *ReverseQueryOptions)
 
 // NewReverseQueryOptionsWithOptions creates a new ReverseQueryOptions with the passed in options set
@@ -91,3 +107,36 @@ func WithResRelation(resRelation *ResourceRelation) ReverseQueryOptionsOption {
 		r.ResRelation = resRelation
 	}
 }
+
+type WatchOptionsOption func(w *WatchOptions)
+
+// NewWatchOptionsWithOptions creates a new WatchOptions with the passed in options set
+func NewWatchOptionsWithOptions(opts ...WatchOptionsOption) *WatchOptions {
+	w := &WatchOptions{}
+	for _, o := range opts {
+		o(w)
+	}
+	return w
+}
+
+// ToOption returns a new WatchOptionsOption that sets the values from the passed in WatchOptions
+func (w *WatchOptions) ToOption() WatchOptionsOption {
+	return func(to *WatchOptions) {
+		to.IncludeCaveats = w.IncludeCaveats
+	}
+}
+
+// WatchOptionsWithOptions configures an existing WatchOptions with the passed in options set
+func WatchOptionsWithOptions(w *WatchOptions, opts ...WatchOptionsOption) *WatchOptions {
+	for _, o := range opts {
+		o(w)
+	}
+	return w
+}
+
+// WithIncludeCaveats returns an option that can set IncludeCaveats on a WatchOptions
+func WithIncludeCaveats(includeCaveats bool) WatchOptionsOption {
+	return func(w *WatchOptions) {
+		w.IncludeCaveats = includeCaveats
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/internal/logging"
@@ -12,6 +13,7 @@ import (
 
 	zerologadapter "github.com/jackc/pgx-zerolog"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/tracelog"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -19,6 +21,9 @@ import (
 
 const (
 	errUnableToQueryTuples = "unable to query tuples: %w"
+
+	// cancelRequestTimeout bounds the time spent sending a cancel request for an abandoned query.
+	cancelRequestTimeout = 5 * time.Second
 )
 
 // NewPGXExecutor creates an executor that uses the pgx library to make the specified queries.
@@ -38,9 +43,13 @@ func NewPGXExecutor(txSource TxFactory) common.ExecuteQueryFunc {
 // queryTuples queries tuples for the given query and transaction.
 func queryTuples(ctx context.Context, sqlStatement string, args []any, span trace.Span, tx pgx.Tx) ([]*corev1.RelationTuple, error) {
 	span.AddEvent("DB transaction established")
+
+	stopCancelling := cancelQueryOnDone(ctx, tx.Conn().PgConn())
+	defer stopCancelling()
+
 	rows, err := tx.Query(ctx, sqlStatement, args...)
 	if err != nil {
-		return nil, fmt.Errorf(errUnableToQueryTuples, err)
+		return nil, queryError(ctx, err)
 	}
 	defer rows.Close()
 
@@ -65,7 +74,7 @@ func queryTuples(ctx context.Context, sqlStatement string, args []any, span trac
 			&caveatCtx,
 		)
 		if err != nil {
-			return nil, fmt.Errorf(errUnableToQueryTuples, err)
+			return nil, queryError(ctx, err)
 		}
 
 		nextTuple.Caveat, err = common.ContextualizedCaveatFrom(caveatName.String, caveatCtx)
@@ -75,13 +84,59 @@ func queryTuples(ctx context.Context, sqlStatement string, args []any, span trac
 		tuples = append(tuples, nextTuple)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf(errUnableToQueryTuples, err)
+		return nil, queryError(ctx, err)
 	}
 
 	span.AddEvent("Tuples loaded", trace.WithAttributes(attribute.Int("tupleCount", len(tuples))))
 	return tuples, nil
 }
 
+// cancelQueryOnDone asks the server to cancel the query running on the connection once the
+// context whose cancellation applies to it is done (see common.CancellationFrom), if before the
+// returned function is called. Queries issued through the separating context proxy run with a
+// context which is never done, such that the query fails on the server with the connection kept
+// open, rather than pgx closing the connection without the server noticing until it next writes
+// to it.
+//
+// No goroutine is started unless the context can be done. The returned function must be called
+// once the query has completed, and before the connection is reused: if a cancel request was
+// sent, it waits for the request to have been delivered, so that it cannot reach a later query.
+func cancelQueryOnDone(ctx context.Context, conn *pgconn.PgConn) func() {
+	cancellation := common.CancellationFrom(ctx)
+	if cancellation.Done() == nil {
+		return func() {}
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-cancellation.Done():
+			cancelCtx, cancel := context.WithTimeout(context.Background(), cancelRequestTimeout)
+			defer cancel()
+			if err := conn.CancelRequest(cancelCtx); err != nil {
+				logging.Ctx(ctx).Debug().Err(err).Msg("unable to cancel query")
+			}
+		case <-stop:
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+// queryError returns the error of a query, reporting the cancellation of the context applying to
+// it, rather than the error the server returned once the query was cancelled.
+func queryError(ctx context.Context, err error) error {
+	if cancelErr := common.CancellationFrom(ctx).Err(); cancelErr != nil {
+		return fmt.Errorf(errUnableToQueryTuples, cancelErr)
+	}
+	return fmt.Errorf(errUnableToQueryTuples, err)
+}
+
 // ConfigurePGXLogger sets zerolog global logger into the connection pool configuration, and maps
 // info level events to debug, as they are rather verbose for SpiceDB's info level
 func ConfigurePGXLogger(connConfig *pgx.ConnConfig) {
@@ -97,6 +152,11 @@ func ConfigurePGXLogger(connConfig *pgx.ConnConfig) {
 				if ok && errors.Is(err, context.Canceled) {
 					return
 				}
+
+				// nor those cancelled on the server once the caller's context was done
+				if ok && common.CancellationFrom(ctx).Err() != nil {
+					return
+				}
 			}
 			logger.Log(ctx, level, msg, data)
 		}
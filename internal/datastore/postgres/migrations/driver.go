@@ -12,7 +12,17 @@ import (
 	"github.com/authzed/spicedb/pkg/migrate"
 )
 
-const postgresMissingTableErrorCode = "42P01"
+const (
+	postgresMissingTableErrorCode = "42P01"
+
+	createChecksumTable = `CREATE TABLE IF NOT EXISTS spicedb_migration_checksum (
+		version_num VARCHAR NOT NULL PRIMARY KEY,
+		checksum VARCHAR NOT NULL
+	)`
+
+	writeChecksum = `INSERT INTO spicedb_migration_checksum (version_num, checksum) VALUES ($1, $2)
+		ON CONFLICT (version_num) DO UPDATE SET checksum = EXCLUDED.checksum`
+)
 
 // AlembicPostgresDriver implements a schema migration facility for use in
 // SpiceDB's Postgres datastore.
@@ -38,7 +48,7 @@ func NewAlembicPostgresDriver(url string) (*AlembicPostgresDriver, error) {
 }
 
 // Conn returns the underlying pgx.Conn instance for this driver
-func (apd *AlembicPostgresDriver) Conn() *pgx.Conn {
+func (apd *AlembicPostgresDriver) Conn() Conn {
 	return apd.db
 }
 
@@ -88,4 +98,50 @@ func (apd *AlembicPostgresDriver) WriteVersion(ctx context.Context, tx pgx.Tx, v
 	return nil
 }
 
-var _ migrate.Driver[*pgx.Conn, pgx.Tx] = &AlembicPostgresDriver{}
+// Checksums returns the stored checksums of the applied migrations, by version. They are stored
+// alongside the Alembic version table, which is left untouched for compatibility.
+func (apd *AlembicPostgresDriver) Checksums(ctx context.Context) (map[string]string, error) {
+	checksums := make(map[string]string)
+
+	rows, err := apd.db.Query(ctx, "SELECT version_num, checksum FROM spicedb_migration_checksum")
+	if err != nil {
+		return nil, fmt.Errorf("unable to load migration checksums: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version, checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, fmt.Errorf("unable to load migration checksums: %w", err)
+		}
+		checksums[version] = checksum
+	}
+
+	if err := rows.Err(); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == postgresMissingTableErrorCode {
+			return checksums, nil
+		}
+		return nil, fmt.Errorf("unable to load migration checksums: %w", err)
+	}
+
+	return checksums, nil
+}
+
+// WriteChecksum stores the checksum of the applied migration with the version.
+func (apd *AlembicPostgresDriver) WriteChecksum(ctx context.Context, tx pgx.Tx, version, checksum string) error {
+	if _, err := tx.Exec(ctx, createChecksumTable); err != nil {
+		return fmt.Errorf("unable to create migration checksum table: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, writeChecksum, version, checksum); err != nil {
+		return fmt.Errorf("unable to write migration checksum: %w", err)
+	}
+
+	return nil
+}
+
+var (
+	_ migrate.Driver[Conn, pgx.Tx]   = &AlembicPostgresDriver{}
+	_ migrate.ChecksumDriver[pgx.Tx] = &AlembicPostgresDriver{}
+)
@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/authzed/spicedb/pkg/migrate"
+)
+
+const errReadsDatabase = "reads from the database to compute the statements it executes"
+
+// DryRunConn returns a connection handler recording the statements executed through it.
+func (apd *AlembicPostgresDriver) DryRunConn(recorder *migrate.StatementRecorder) Conn {
+	return recordingConn{recorder}
+}
+
+// DryRunTx returns a transaction recording the statements executed through it. Queries fail with
+// a migrate.NonReplayableError, as their results cannot be known without running the migration.
+func (apd *AlembicPostgresDriver) DryRunTx(recorder *migrate.StatementRecorder) pgx.Tx {
+	return recordingTx{recorder: recorder}
+}
+
+type recordingConn struct {
+	recorder *migrate.StatementRecorder
+}
+
+func (rc recordingConn) Exec(_ context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	rc.recorder.Record(sql, arguments...)
+	return pgconn.CommandTag{}, nil
+}
+
+// recordingTx implements the methods of pgx.Tx used by migrations; the others must not be called.
+type recordingTx struct {
+	pgx.Tx
+	recorder *migrate.StatementRecorder
+}
+
+func (rt recordingTx) Exec(_ context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	rt.recorder.Record(sql, arguments...)
+	return pgconn.CommandTag{}, nil
+}
+
+func (rt recordingTx) Query(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+	return nil, migrate.NewNonReplayableErr(errReadsDatabase)
+}
+
+func (rt recordingTx) QueryRow(_ context.Context, _ string, _ ...any) pgx.Row {
+	return errRow{migrate.NewNonReplayableErr(errReadsDatabase)}
+}
+
+type errRow struct {
+	err error
+}
+
+func (er errRow) Scan(_ ...any) error {
+	return er.err
+}
+
+var (
+	_ migrate.DryRunDriver[Conn, pgx.Tx] = &AlembicPostgresDriver{}
+	_ Conn                               = recordingConn{}
+	_ pgx.Tx                             = recordingTx{}
+)
@@ -1,15 +1,23 @@
 package migrations
 
 import (
+	"context"
+
 	"github.com/authzed/spicedb/pkg/migrate"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// Conn is the connection handler passed to migrations which cannot run in a transaction.
+type Conn interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
 var (
-	noNonatomicMigration migrate.MigrationFunc[*pgx.Conn]
+	noNonatomicMigration migrate.MigrationFunc[Conn]
 	noTxMigration        migrate.TxMigrationFunc[pgx.Tx]
 )
 
 // DatabaseMigrations implements a migration manager for the Postgres Driver.
-var DatabaseMigrations = migrate.NewManager[*AlembicPostgresDriver, *pgx.Conn, pgx.Tx]()
+var DatabaseMigrations = migrate.NewManager[*AlembicPostgresDriver, Conn, pgx.Tx]()
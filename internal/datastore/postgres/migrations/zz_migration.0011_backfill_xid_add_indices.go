@@ -4,8 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgconn"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/migrate"
@@ -118,13 +117,20 @@ var dropBackfillIndices = []string{
 
 func init() {
 	if err := DatabaseMigrations.Register("backfill-xid-add-indices", "add-xid-columns",
-		func(ctx context.Context, conn *pgx.Conn) error {
+		func(ctx context.Context, conn Conn) error {
 			for _, stmt := range addBackfillIndices {
 				if _, err := conn.Exec(ctx, stmt); err != nil {
 					return err
 				}
 			}
 
+			// The backfill statements are repeated until they no longer update any row, which they
+			// do not when dry-run.
+			migrate.MarkNonReplayable(ctx, "backfills rows in batches until none remain")
+
+			// The number of rows to backfill is not known without a full scan, so no total estimate
+			// is reported with the progress.
+			var processed uint64
 			batchSize := ctx.Value(migrate.BackfillBatchSize).(uint64)
 			for _, stmt := range backfills {
 				concreteStmt := fmt.Sprintf(stmt, batchSize)
@@ -136,6 +142,8 @@ func init() {
 
 				for r, err = conn.Exec(ctx, concreteStmt); err == nil && r.RowsAffected() > 0; r, err = conn.Exec(ctx, concreteStmt) {
 					log.Ctx(ctx).Debug().Int64("count", r.RowsAffected()).Msg("updated rows")
+					processed += uint64(r.RowsAffected())
+					migrate.ReportBackfillProgress(ctx, "backfill-xid-add-indices", processed, 0)
 				}
 				if err != nil {
 					return err
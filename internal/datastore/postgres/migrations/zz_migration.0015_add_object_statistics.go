@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// createObjectStatistics has ANALYZE estimate the number of distinct (namespace, object_id)
+// pairs of the relationships, which per-column statistics cannot provide.
+const createObjectStatistics = `CREATE STATISTICS relation_tuple_objects (ndistinct)
+	ON namespace, object_id
+	FROM relation_tuple;`
+
+func init() {
+	if err := DatabaseMigrations.Register("add-object-statistics", "drop-bigserial-ids",
+		noNonatomicMigration,
+		func(ctx context.Context, tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, createObjectStatistics)
+			return err
+		}); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
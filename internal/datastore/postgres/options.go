@@ -3,6 +3,8 @@ package postgres
 import (
 	"fmt"
 	"time"
+
+	"github.com/authzed/spicedb/pkg/datastore"
 )
 
 type postgresOptions struct {
@@ -13,10 +15,15 @@ type postgresOptions struct {
 	minOpenConns                *int
 	maxRevisionStalenessPercent float64
 
+	prewarmConns        int
+	prewarmTimeout      time.Duration
+	allowPrewarmFailure bool
+
 	watchBufferLength    uint16
 	revisionQuantization time.Duration
 	gcWindow             time.Duration
 	gcInterval           time.Duration
+	gcBatchSize          uint64
 	gcMaxOperationTime   time.Duration
 	splitAtUsersetCount  uint16
 	maxRetries           uint8
@@ -27,7 +34,8 @@ type postgresOptions struct {
 
 	migrationPhase string
 
-	logger *tracingLogger
+	logger            *tracingLogger
+	operationObserver datastore.OperationObserver
 }
 
 type migrationPhase uint8
@@ -46,17 +54,22 @@ var migrationPhases = map[string]migrationPhase{
 
 const (
 	errQuantizationTooLarge = "revision quantization interval (%s) must be less than GC window (%s)"
+	errGCWindowTooSmall     = "GC window (%s) must be greater than the maximum staleness of optimized revisions (%s)"
+	errInvalidGCBatchSize   = "GC batch size must be greater than zero"
+	errInvalidPrewarmConns  = "number of connections to pre-warm must not be negative"
 
 	defaultWatchBufferLength                 = 128
 	defaultGarbageCollectionWindow           = 24 * time.Hour
 	defaultGarbageCollectionInterval         = time.Minute * 3
 	defaultGarbageCollectionMaxOperationTime = time.Minute
+	defaultGarbageCollectionBatchSize        = 1000
 	defaultUsersetBatchSize                  = 1024
 	defaultQuantization                      = 5 * time.Second
 	defaultMaxRevisionStalenessPercent       = 0.1
 	defaultEnablePrometheusStats             = false
 	defaultMaxRetries                        = 10
 	defaultGCEnabled                         = true
+	defaultPrewarmTimeout                    = 10 * time.Second
 )
 
 // Option provides the facility to configure how clients within the
@@ -68,6 +81,7 @@ func generateConfig(options []Option) (postgresOptions, error) {
 		gcWindow:                    defaultGarbageCollectionWindow,
 		gcInterval:                  defaultGarbageCollectionInterval,
 		gcMaxOperationTime:          defaultGarbageCollectionMaxOperationTime,
+		gcBatchSize:                 defaultGarbageCollectionBatchSize,
 		watchBufferLength:           defaultWatchBufferLength,
 		splitAtUsersetCount:         defaultUsersetBatchSize,
 		revisionQuantization:        defaultQuantization,
@@ -75,6 +89,7 @@ func generateConfig(options []Option) (postgresOptions, error) {
 		enablePrometheusStats:       defaultEnablePrometheusStats,
 		maxRetries:                  defaultMaxRetries,
 		gcEnabled:                   defaultGCEnabled,
+		prewarmTimeout:              defaultPrewarmTimeout,
 	}
 
 	for _, option := range options {
@@ -90,6 +105,22 @@ func generateConfig(options []Option) (postgresOptions, error) {
 		)
 	}
 
+	// Optimized revisions can be as old as the quantization interval plus the maximum staleness,
+	// and must not be garbage collected while they may still be used.
+	maxRevisionAge := computed.revisionQuantization +
+		time.Duration(float64(computed.revisionQuantization.Nanoseconds())*computed.maxRevisionStalenessPercent)
+	if maxRevisionAge >= computed.gcWindow {
+		return computed, fmt.Errorf(errGCWindowTooSmall, computed.gcWindow, maxRevisionAge)
+	}
+
+	if computed.gcBatchSize == 0 {
+		return computed, fmt.Errorf(errInvalidGCBatchSize)
+	}
+
+	if computed.prewarmConns < 0 {
+		return computed, fmt.Errorf(errInvalidPrewarmConns)
+	}
+
 	if _, ok := migrationPhases[computed.migrationPhase]; !ok {
 		return computed, fmt.Errorf("unknown migration phase: %s", computed.migrationPhase)
 	}
@@ -155,6 +186,40 @@ func MinOpenConns(conns int) Option {
 	}
 }
 
+// PrewarmConns is the number of connections of the pool opened and validated while the datastore
+// is constructed, rather than lazily as requests come in, to avoid slow requests on cold starts.
+// It cannot exceed the maximum size of the pool. Connections in excess of the minimum size of the
+// pool may be closed by the health check once idle for ConnMaxIdleTime.
+//
+// Construction fails if the connections cannot be established within the PrewarmTimeout, unless
+// AllowPrewarmFailure is set.
+//
+// This value defaults to zero.
+func PrewarmConns(conns int) Option {
+	return func(po *postgresOptions) {
+		po.prewarmConns = conns
+	}
+}
+
+// PrewarmTimeout is the maximum duration of the pre-warming of the connections of the pool.
+//
+// This value defaults to 10 seconds.
+func PrewarmTimeout(timeout time.Duration) Option {
+	return func(po *postgresOptions) {
+		po.prewarmTimeout = timeout
+	}
+}
+
+// AllowPrewarmFailure marks whether construction of the datastore succeeds, with a warning, if
+// the connections configured by PrewarmConns cannot all be established.
+//
+// Failing to pre-warm the connections fails construction by default.
+func AllowPrewarmFailure(allow bool) Option {
+	return func(po *postgresOptions) {
+		po.allowPrewarmFailure = allow
+	}
+}
+
 // WatchBufferLength is the number of entries that can be stored in the watch
 // buffer while awaiting read by the client.
 //
@@ -205,6 +270,17 @@ func GCInterval(interval time.Duration) Option {
 	}
 }
 
+// GCBatchSize is the maximum number of rows deleted by each statement of a garbage collection
+// pass, which deletes the expired rows in batches rather than in a single statement locking
+// the table.
+//
+// This value defaults to 1000.
+func GCBatchSize(batchSize uint64) Option {
+	return func(po *postgresOptions) {
+		po.gcBatchSize = batchSize
+	}
+}
+
 // GCMaxOperationTime is the maximum operation time of a garbage collection
 // pass before it times out.
 //
@@ -234,6 +310,16 @@ func WithEnablePrometheusStats(enablePrometheusStats bool) Option {
 	}
 }
 
+// WithOperationObserver sets an observer called once each read and write operation made against
+// the datastore completes, with its duration, error and approximate result size.
+//
+// No observer is set by default, in which case operations are not observed at all.
+func WithOperationObserver(observer datastore.OperationObserver) Option {
+	return func(po *postgresOptions) {
+		po.operationObserver = observer
+	}
+}
+
 // EnableTracing enables trace-level logging for the Postgres clients being
 // used by the datastore.
 //
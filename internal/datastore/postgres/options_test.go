@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateConfigGC(t *testing.T) {
+	testCases := []struct {
+		name          string
+		options       []Option
+		expectedError string
+	}{
+		{"defaults", nil, ""},
+		{"batch size", []Option{GCBatchSize(10)}, ""},
+		{"zero batch size", []Option{GCBatchSize(0)}, "GC batch size must be greater than zero"},
+		{
+			"window within staleness",
+			[]Option{RevisionQuantization(5 * time.Second), GCWindow(5400 * time.Millisecond)},
+			"GC window (5.4s) must be greater than the maximum staleness of optimized revisions (5.5s)",
+		},
+		{
+			"window beyond staleness",
+			[]Option{RevisionQuantization(5 * time.Second), GCWindow(6 * time.Second)},
+			"",
+		},
+		{"no quantization", []Option{RevisionQuantization(0), GCWindow(time.Millisecond)}, ""},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := generateConfig(tc.options)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotZero(t, config.gcBatchSize)
+		})
+	}
+}
+
+func TestGenerateConfigPrewarm(t *testing.T) {
+	testCases := []struct {
+		name          string
+		options       []Option
+		expectedError string
+	}{
+		{"defaults", nil, ""},
+		{"prewarm", []Option{PrewarmConns(10)}, ""},
+		{"negative", []Option{PrewarmConns(-1)}, "number of connections to pre-warm must not be negative"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := generateConfig(tc.options)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, defaultPrewarmTimeout, config.prewarmTimeout)
+		})
+	}
+}
@@ -45,6 +45,7 @@ const (
 	colCreatedXid        = "created_xid"
 	colDeletedXid        = "deleted_xid"
 	colSnapshot          = "snapshot"
+	colCtid              = "ctid"
 	colObjectID          = "object_id"
 	colRelation          = "relation"
 	colUsersetNamespace  = "userset_namespace"
@@ -70,8 +71,6 @@ const (
 
 	tracingDriverName = "postgres-tracing"
 
-	batchDeleteSize = 1000
-
 	pgSerializationFailure      = "40001"
 	pgUniqueConstraintViolation = "23505"
 
@@ -146,6 +145,14 @@ func newPostgresDatastore(
 
 	configurePool(config, pgxConfig)
 
+	if config.prewarmConns > int(pgxConfig.MaxConns) {
+		return nil, fmt.Errorf(errUnableToInstantiate, fmt.Errorf(
+			"number of connections to pre-warm (%d) exceeds the maximum number of connections (%d)",
+			config.prewarmConns,
+			pgxConfig.MaxConns,
+		))
+	}
+
 	initializationContext, cancelInit := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancelInit()
 
@@ -154,6 +161,17 @@ func newPostgresDatastore(
 		return nil, fmt.Errorf(errUnableToInstantiate, err)
 	}
 
+	if config.prewarmConns > 0 {
+		prewarmed, err := prewarmPool(dbpool, config.prewarmConns, config.prewarmTimeout)
+		if err != nil {
+			if !config.allowPrewarmFailure {
+				dbpool.Close()
+				return nil, fmt.Errorf(errUnableToInstantiate, fmt.Errorf("unable to pre-warm %d connections: %w", config.prewarmConns, err))
+			}
+			log.Warn().Err(err).Int("prewarmed", prewarmed).Int("requested", config.prewarmConns).Msg("unable to pre-warm all requested connections")
+		}
+	}
+
 	// Verify that the server supports commit timestamps
 	var trackTSOn string
 	if err := dbpool.
@@ -215,6 +233,7 @@ func newPostgresDatastore(
 		gcWindow:                config.gcWindow,
 		gcInterval:              config.gcInterval,
 		gcTimeout:               config.gcMaxOperationTime,
+		gcBatchSize:             config.gcBatchSize,
 		analyzeBeforeStatistics: config.analyzeBeforeStatistics,
 		usersetBatchSize:        config.splitAtUsersetCount,
 		watchEnabled:            watchEnabled,
@@ -222,6 +241,7 @@ func newPostgresDatastore(
 		cancelGc:                cancelGc,
 		readTxOptions:           pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly},
 		maxRetries:              config.maxRetries,
+		operationObserver:       config.operationObserver,
 	}
 
 	datastore.SetOptimizedRevisionFunc(datastore.optimizedRevisionFunc)
@@ -245,6 +265,50 @@ func newPostgresDatastore(
 	return datastore, nil
 }
 
+// prewarmPool opens and validates the given number of connections of the pool, by acquiring them
+// all at once, and returns the number of connections validated.
+func prewarmPool(pool *pgxpool.Pool, conns int, timeout time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	acquired := make([]*pgxpool.Conn, conns)
+	defer func() {
+		for _, conn := range acquired {
+			if conn != nil {
+				conn.Release()
+			}
+		}
+	}()
+
+	validated := make([]bool, conns)
+	g, gCtx := errgroup.WithContext(ctx)
+	for i := range acquired {
+		i := i
+		g.Go(func() error {
+			conn, err := pool.Acquire(gCtx)
+			if err != nil {
+				return err
+			}
+			acquired[i] = conn
+
+			if err := conn.Ping(gCtx); err != nil {
+				return err
+			}
+			validated[i] = true
+			return nil
+		})
+	}
+	err := g.Wait()
+
+	var count int
+	for _, ok := range validated {
+		if ok {
+			count++
+		}
+	}
+	return count, err
+}
+
 func configurePool(config postgresOptions, pgxConfig *pgxpool.Config) {
 	if config.maxOpenConns != nil {
 		pgxConfig.MaxConns = int32(*config.maxOpenConns)
@@ -284,11 +348,13 @@ type pgDatastore struct {
 	gcWindow                time.Duration
 	gcInterval              time.Duration
 	gcTimeout               time.Duration
+	gcBatchSize             uint64
 	usersetBatchSize        uint16
 	analyzeBeforeStatistics bool
 	readTxOptions           pgx.TxOptions
 	maxRetries              uint8
 	watchEnabled            bool
+	operationObserver       datastore.OperationObserver
 
 	gcGroup  *errgroup.Group
 	gcCtx    context.Context
@@ -318,11 +384,12 @@ func (pgd *pgDatastore) SnapshotReader(revRaw datastore.Revision) datastore.Read
 		UsersetBatchSize: pgd.usersetBatchSize,
 	}
 
-	return &pgReader{
+	reader := &pgReader{
 		createTxFunc,
 		querySplitter,
 		buildLivingObjectFilterForRevision(rev),
 	}
+	return common.ObservedReader(reader, pgd.operationObserver, Engine)
 }
 
 func noCleanup(context.Context) {}
@@ -332,6 +399,16 @@ func noCleanup(context.Context) {}
 func (pgd *pgDatastore) ReadWriteTx(
 	ctx context.Context,
 	fn datastore.TxUserFunc,
+) (datastore.Revision, error) {
+	start := time.Now()
+	rev, err := pgd.readWriteTx(ctx, fn)
+	common.ObserveOperation(ctx, pgd.operationObserver, Engine, "ReadWriteTx", start, 0, err)
+	return rev, err
+}
+
+func (pgd *pgDatastore) readWriteTx(
+	ctx context.Context,
+	fn datastore.TxUserFunc,
 ) (datastore.Revision, error) {
 	var err error
 	for i := uint8(0); i <= pgd.maxRetries; i++ {
@@ -362,7 +439,7 @@ func (pgd *pgDatastore) ReadWriteTx(
 				newXID,
 			}
 
-			return fn(rwt)
+			return fn(common.ObservedReadWriteTransaction(rwt, pgd.operationObserver, Engine))
 		})
 		if err != nil {
 			if errorRetryable(err) {
@@ -16,6 +16,8 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+	"github.com/authzed/spicedb/internal/datastore/proxy"
 	"github.com/authzed/spicedb/internal/testfixtures"
 	testdatastore "github.com/authzed/spicedb/internal/testserver/datastore"
 	"github.com/authzed/spicedb/pkg/datastore"
@@ -109,6 +111,56 @@ func TestPostgresDatastore(t *testing.T) {
 				MigrationPhase(config.migrationPhase),
 			))
 
+			t.Run("QueryCancellation", createDatastoreTest(
+				b,
+				QueryCancellationTest,
+				RevisionQuantization(0),
+				GCWindow(1*time.Millisecond),
+				WatchBufferLength(1),
+				MigrationPhase(config.migrationPhase),
+			))
+
+			t.Run("RetriedSerializationFailure", createDatastoreTest(
+				b,
+				RetriedSerializationFailureTest,
+				RevisionQuantization(0),
+				GCWindow(1*time.Millisecond),
+				WatchBufferLength(1),
+				MaxRetries(0),
+				MigrationPhase(config.migrationPhase),
+			))
+
+			t.Run("RetriedConcurrentWriterContention", createDatastoreTest(
+				b,
+				RetriedConcurrentWriterContentionTest,
+				RevisionQuantization(0),
+				GCWindow(1*time.Millisecond),
+				WatchBufferLength(1),
+				MigrationPhase(config.migrationPhase),
+			))
+
+			t.Run("StatisticsObjectCount", createDatastoreTest(
+				b,
+				StatisticsObjectCountTest,
+				RevisionQuantization(0),
+				GCWindow(1*time.Millisecond),
+				WatchBufferLength(1),
+				DebugAnalyzeBeforeStatistics(),
+				MigrationPhase(config.migrationPhase),
+			))
+
+			t.Run("PrewarmConns", createDatastoreTest(
+				b,
+				PrewarmConnsTest,
+				RevisionQuantization(0),
+				GCWindow(1*time.Millisecond),
+				WatchBufferLength(1),
+				MaxOpenConns(10),
+				MinOpenConns(0),
+				PrewarmConns(5),
+				MigrationPhase(config.migrationPhase),
+			))
+
 			t.Run("QuantizedRevisions", func(t *testing.T) {
 				QuantizedRevisionTest(t, b)
 			})
@@ -117,6 +169,12 @@ func TestPostgresDatastore(t *testing.T) {
 				WatchNotEnabledTest(t, b)
 			})
 
+			if config.targetMigration == migrate.Head {
+				t.Run("MigratedSchema", func(t *testing.T) {
+					MigratedSchemaTest(t, b)
+				})
+			}
+
 			if config.migrationPhase == "" {
 				t.Run("RevisionInversion", createDatastoreTest(
 					b,
@@ -704,6 +762,139 @@ func WatchNotEnabledTest(t *testing.T, b testdatastore.RunningEngineForTest) {
 	require.Contains(err.Error(), "track_commit_timestamp=on")
 }
 
+// RetriedSerializationFailureTest tests that the serialization failures returned by postgres are
+// retried by the retrying proxy, with the retries of the datastore itself disabled.
+func RetriedSerializationFailureTest(t *testing.T, ds datastore.Datastore) {
+	test.SerializationConflictTest(t, proxy.NewRetryingDatastoreProxy(ds, proxy.DefaultRetryPolicy()))
+}
+
+// RetriedConcurrentWriterContentionTest tests that conflicting writers all commit through the
+// retrying proxy, which retries the serialization failures returned by postgres.
+func RetriedConcurrentWriterContentionTest(t *testing.T, ds datastore.Datastore) {
+	test.ConcurrentWriterContentionTest(t, proxy.NewRetryingDatastoreProxy(ds, proxy.DefaultRetryPolicy()))
+}
+
+// MigratedSchemaTest tests that the migrations through head leave the tables with the expected
+// columns, indexes and constraints.
+func MigratedSchemaTest(t *testing.T, b testdatastore.RunningEngineForTest) {
+	require := require.New(t)
+
+	var schema testdatastore.PostgresSchema
+	ds := b.NewDatastore(t, func(engine, uri string) datastore.Datastore {
+		schema = testdatastore.IntrospectPostgresSchema(t, uri)
+
+		ds, err := newPostgresDatastore(uri)
+		require.NoError(err)
+		return ds
+	})
+	defer ds.Close()
+
+	relationTuple, ok := schema[tableTuple]
+	require.True(ok)
+
+	// The transaction IDs replaced the serial IDs, and are required.
+	require.NotContains(relationTuple.Columns, "id")
+	require.Equal(testdatastore.PostgresColumn{
+		DataType: "xid8",
+		Nullable: false,
+		Default:  "pg_current_xact_id()",
+	}, relationTuple.Columns[colCreatedXid])
+
+	require.Equal(testdatastore.PrimaryKeyConstraint, relationTuple.Constraints["pk_relation_tuple"].Type)
+	require.Equal(testdatastore.UniqueConstraint, relationTuple.Constraints["uq_relation_tuple_living_xid"].Type)
+
+	living, ok := relationTuple.Indexes["uq_relation_tuple_living_xid"]
+	require.True(ok)
+	require.True(living.Unique)
+	require.False(living.Primary)
+	require.Equal([]string{
+		colNamespace, colObjectID, colRelation,
+		colUsersetNamespace, colUsersetObjectID, colUsersetRelation,
+		colDeletedXid,
+	}, living.Columns)
+
+	require.True(relationTuple.Indexes["pk_relation_tuple"].Primary)
+}
+
+// QueryCancellationTest tests that cancelling the caller's context of a relationship query
+// terminates the query on the server rather than letting it run to completion, while keeping the
+// connection it ran on in the pool.
+func QueryCancellationTest(t *testing.T, ds datastore.Datastore) {
+	require := require.New(t)
+
+	pgd := ds.(*pgDatastore)
+	rev, err := pgd.HeadRevision(context.Background())
+	require.NoError(err)
+
+	// A query scanning as many columns as a relationship query, but which takes a minute to return.
+	slowQuery := "SELECT 'a', 'b', 'c', 'd', 'e', 'f', NULL::text, NULL::jsonb FROM pg_sleep(60)"
+	txSource := pgd.SnapshotReader(rev).(*pgReader).txSource
+
+	var queryConn *pgx.Conn
+	executor := pgxcommon.NewPGXExecutor(func(ctx context.Context) (pgx.Tx, common.TxCleanupFunc, error) {
+		tx, cleanup, err := txSource(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		queryConn = tx.Conn()
+		return tx, cleanup, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	// The context is separated as by the datastore proxy, such that pgx does not close the
+	// connection once the caller's context is cancelled.
+	start := time.Now()
+	_, err = executor(proxy.SeparateContextWithCancellation(ctx), slowQuery, nil)
+	require.ErrorIs(err, context.Canceled)
+	require.Less(time.Since(start), 10*time.Second)
+	require.False(queryConn.IsClosed())
+
+	require.Eventually(func() bool {
+		var running int
+		err := pgd.dbpool.QueryRow(
+			context.Background(),
+			"SELECT COUNT(*) FROM pg_stat_activity WHERE query LIKE '%pg_sleep(60)%' AND pid <> pg_backend_pid()",
+		).Scan(&running)
+		require.NoError(err)
+		return running == 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func StatisticsObjectCountTest(t *testing.T, ds datastore.Datastore) {
+	require := require.New(t)
+
+	ds, _ = testfixtures.StandardDatastoreWithData(ds, require)
+
+	objects := map[string]struct{}{}
+	for _, tupleStr := range testfixtures.StandardTuples {
+		tpl := tuple.MustParse(tupleStr)
+		objects[tpl.ResourceAndRelation.Namespace+":"+tpl.ResourceAndRelation.ObjectId] = struct{}{}
+	}
+
+	stats, err := ds.Statistics(context.Background())
+	require.NoError(err)
+	require.True(stats.HasEstimatedObjectCount)
+	require.Equal(uint64(len(objects)), stats.EstimatedObjectCount)
+}
+
+func PrewarmConnsTest(t *testing.T, ds datastore.Datastore) {
+	require := require.New(t)
+
+	// With no minimum size, the pool only holds the pre-warmed connections once constructed.
+	pgd := ds.(*pgDatastore)
+	require.GreaterOrEqual(pgd.dbpool.Stat().TotalConns(), int32(5))
+
+	var open int
+	err := pgd.dbpool.QueryRow(
+		context.Background(),
+		"SELECT COUNT(*) FROM pg_stat_activity WHERE datname = current_database() AND backend_type = 'client backend'",
+	).Scan(&open)
+	require.NoError(err)
+	require.GreaterOrEqual(open, 5)
+}
+
 func BenchmarkPostgresQuery(b *testing.B) {
 	req := require.New(b)
 
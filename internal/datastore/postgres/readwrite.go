@@ -11,6 +11,7 @@ import (
 	"github.com/jzelinskie/stringz"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -23,6 +24,10 @@ const (
 	errUnableToDeleteRelationships = "unable to delete relationships: %w"
 )
 
+// bulkDeleteBatchSize is the maximum number of relationships deleted per statement by
+// BulkDeleteRelationships.
+const bulkDeleteBatchSize = 1000
+
 var (
 	writeNamespace = psql.Insert(tableNamespace).Columns(
 		colNamespace,
@@ -45,6 +50,8 @@ var (
 	)
 
 	deleteTuple = psql.Update(tableTuple).Where(sq.Eq{colDeletedXid: liveDeletedTxnID})
+
+	queryTupleLocations = psql.Select(colCtid).From(tableTuple)
 )
 
 type pgReadWriteTXN struct {
@@ -123,6 +130,95 @@ func (rwt *pgReadWriteTXN) WriteRelationships(ctx context.Context, mutations []*
 	return nil
 }
 
+// BulkLoad writes the relationships with a COPY into the tuple table, whose created
+// transaction defaults to that of the transaction.
+func (rwt *pgReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.RelationshipIterator) (uint64, error) {
+	source := &relationshipCopySource{iter: iter}
+	loaded, err := rwt.tx.CopyFrom(ctx, pgx.Identifier{tableTuple}, copyTupleColumns, source)
+	if err != nil {
+		// If a unique constraint violation is returned, then a relationship loaded already exists.
+		if cerr := pgxcommon.ConvertToWriteConstraintError(livingTupleConstraint, err); cerr != nil {
+			return 0, cerr
+		}
+
+		return 0, fmt.Errorf(errUnableToWriteRelationships, err)
+	}
+
+	return uint64(loaded), nil
+}
+
+// BulkDeleteRelationships marks the relationships matching the filter as deleted server-side, in
+// batches of relationships selected by their physical location.
+func (rwt *pgReadWriteTXN) BulkDeleteRelationships(ctx context.Context, filter datastore.RelationshipsFilter, limit uint64) (uint64, error) {
+	filterer, err := common.NewSchemaQueryFilterer(schema, currentlyLivingObjects(queryTupleLocations)).FilterWithRelationshipsFilter(filter)
+	if err != nil {
+		return 0, fmt.Errorf(errUnableToDeleteRelationships, err)
+	}
+
+	return common.DeleteRelationshipsInLimitedBatches(ctx, limit, bulkDeleteBatchSize, func(ctx context.Context, batchLimit uint64) (uint64, error) {
+		sql, args, err := deleteTuple.
+			Set(colDeletedXid, rwt.newXID).
+			Where(sq.Expr(colCtid+" IN (?)", filterer.LimitedSubquery(batchLimit))).
+			ToSql()
+		if err != nil {
+			return 0, fmt.Errorf(errUnableToDeleteRelationships, err)
+		}
+
+		result, err := rwt.tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return 0, fmt.Errorf(errUnableToDeleteRelationships, err)
+		}
+
+		return uint64(result.RowsAffected()), nil
+	})
+}
+
+var copyTupleColumns = []string{
+	colNamespace,
+	colObjectID,
+	colRelation,
+	colUsersetNamespace,
+	colUsersetObjectID,
+	colUsersetRelation,
+	colCaveatContextName,
+	colCaveatContext,
+}
+
+// relationshipCopySource adapts a RelationshipIterator into a pgx.CopyFromSource.
+type relationshipCopySource struct {
+	iter    datastore.RelationshipIterator
+	current *core.RelationTuple
+}
+
+func (s *relationshipCopySource) Next() bool {
+	s.current = s.iter.Next()
+	return s.current != nil
+}
+
+func (s *relationshipCopySource) Values() ([]any, error) {
+	var caveatName string
+	var caveatContext map[string]any
+	if s.current.Caveat != nil {
+		caveatName = s.current.Caveat.CaveatName
+		caveatContext = s.current.Caveat.Context.AsMap()
+	}
+
+	return []any{
+		s.current.ResourceAndRelation.Namespace,
+		s.current.ResourceAndRelation.ObjectId,
+		s.current.ResourceAndRelation.Relation,
+		s.current.Subject.Namespace,
+		s.current.Subject.ObjectId,
+		s.current.Subject.Relation,
+		caveatName,
+		caveatContext,
+	}, nil
+}
+
+func (s *relationshipCopySource) Err() error {
+	return s.iter.Err()
+}
+
 func (rwt *pgReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
 	// Add clauses for the ResourceFilter
 	query := deleteTuple.Where(sq.Eq{colNamespace: filter.ResourceType})
@@ -6,6 +6,8 @@ import (
 
 	"github.com/jackc/pgtype"
 	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore"
 )
 
 const (
@@ -75,6 +77,14 @@ func TestRevisionOrdering(t *testing.T) {
 			require.Equal(tc.relationship == gt, lhs.GreaterThan(rhs))
 
 			require.Equal(tc.relationship == concurrent, !lhs.LessThan(rhs) && !lhs.GreaterThan(rhs) && !lhs.Equal(rhs))
+
+			compared, err := datastore.CompareRevisions(datastore.EngineRevision{Engine: Engine, Revision: lhs}, datastore.EngineRevision{Engine: Engine, Revision: rhs})
+			if tc.relationship == concurrent {
+				require.ErrorAs(err, &datastore.ErrIncomparableRevisions{})
+			} else {
+				require.NoError(err)
+				require.Equal(map[comparisonResult]int{equal: 0, lt: -1, gt: 1}[tc.relationship], compared)
+			}
 		})
 	}
 }
@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
@@ -18,6 +20,16 @@ const (
 	tablePGClass = "pg_class"
 	colReltuples = "reltuples"
 	colRelname   = "relname"
+
+	tablePGStatsExt   = "pg_stats_ext"
+	colNDistinct      = "n_distinct"
+	colSchemaname     = "schemaname"
+	colTablename      = "tablename"
+	colStatisticsName = "statistics_name"
+
+	// statisticsObjects is the name of the extended statistics of the relationships created by
+	// the add-object-statistics migration, estimating the distinct (namespace, object_id) pairs.
+	statisticsObjects = "relation_tuple_objects"
 )
 
 var (
@@ -26,6 +38,14 @@ var (
 				Select(colReltuples).
 				From(tablePGClass).
 				Where(sq.Eq{colRelname: tableTuple})
+
+	// n_distinct is a pg_ndistinct, rendered as a JSON object mapping the attribute numbers of
+	// the combination of columns to its estimated number of distinct values.
+	queryEstimatedDistinctObjects = psql.
+					Select(colNDistinct + "::text").
+					From(tablePGStatsExt).
+					Where(sq.Eq{colTablename: tableTuple, colStatisticsName: statisticsObjects}).
+					Where(colSchemaname + " = current_schema()")
 )
 
 func (pgd *pgDatastore) Statistics(ctx context.Context) (datastore.Stats, error) {
@@ -39,6 +59,11 @@ func (pgd *pgDatastore) Statistics(ctx context.Context) (datastore.Stats, error)
 		return datastore.Stats{}, fmt.Errorf("unable to prepare row count sql: %w", err)
 	}
 
+	distinctSQL, distinctArgs, err := queryEstimatedDistinctObjects.ToSql()
+	if err != nil {
+		return datastore.Stats{}, fmt.Errorf("unable to prepare distinct objects sql: %w", err)
+	}
+
 	filterer := func(original sq.SelectBuilder) sq.SelectBuilder {
 		return original.Where(sq.Eq{colDeletedXid: liveDeletedTxnID})
 	}
@@ -46,6 +71,7 @@ func (pgd *pgDatastore) Statistics(ctx context.Context) (datastore.Stats, error)
 	var uniqueID string
 	var nsDefs []*corev1.NamespaceDefinition
 	var relCount int64
+	var nDistinct *string
 	if err := pgd.dbpool.BeginTxFunc(ctx, pgd.readTxOptions, func(tx pgx.Tx) error {
 		if pgd.analyzeBeforeStatistics {
 			if _, err := tx.Exec(ctx, "ANALYZE "+tableTuple); err != nil {
@@ -68,6 +94,11 @@ func (pgd *pgDatastore) Statistics(ctx context.Context) (datastore.Stats, error)
 			return fmt.Errorf("unable to read relationship count: %w", err)
 		}
 
+		// The statistics are not available until the table has first been analyzed.
+		if err := tx.QueryRow(ctx, distinctSQL, distinctArgs...).Scan(&nDistinct); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("unable to read distinct object count: %w", err)
+		}
+
 		return nil
 	}); err != nil {
 		return datastore.Stats{}, err
@@ -79,9 +110,26 @@ func (pgd *pgDatastore) Statistics(ctx context.Context) (datastore.Stats, error)
 		relCountUint = uint64(relCount)
 	}
 
+	var objectCount uint64
+	if nDistinct != nil {
+		var estimates map[string]float64
+		if err := json.Unmarshal([]byte(*nDistinct), &estimates); err != nil {
+			return datastore.Stats{}, fmt.Errorf("unable to parse distinct object count: %w", err)
+		}
+
+		// The statistics cover a single combination of columns.
+		for _, estimate := range estimates {
+			if estimate > 0 {
+				objectCount = uint64(estimate)
+			}
+		}
+	}
+
 	return datastore.Stats{
 		UniqueID:                   uniqueID,
 		ObjectTypeStatistics:       datastore.ComputeObjectTypeStats(nsDefs),
 		EstimatedRelationshipCount: relCountUint,
+		EstimatedObjectCount:       objectCount,
+		HasEstimatedObjectCount:    true,
 	}, nil
 }
@@ -11,6 +11,7 @@ import (
 	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/options"
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
@@ -43,12 +44,21 @@ var (
 		colCreatedXid,
 		colDeletedXid,
 	).From(tableTuple)
+
+	queryChangedCaveats = psql.Select(
+		colCaveatName,
+		colCaveatDefinition,
+		colCreatedXid,
+		colDeletedXid,
+	).From(tableCaveat)
 )
 
 func (pgd *pgDatastore) Watch(
 	ctx context.Context,
 	afterRevisionRaw datastore.Revision,
+	opts ...options.WatchOptionsOption,
 ) (<-chan *datastore.RevisionChanges, <-chan error) {
+	watchOpts := options.NewWatchOptionsWithOptions(opts...)
 	updates := make(chan *datastore.RevisionChanges, pgd.watchBufferLength)
 	errs := make(chan error, 1)
 
@@ -77,7 +87,7 @@ func (pgd *pgDatastore) Watch(
 			}
 
 			if len(newTxns) > 0 {
-				changesToWrite, err := pgd.loadChanges(ctx, newTxns)
+				changesToWrite, err := pgd.loadChanges(ctx, newTxns, watchOpts.IncludeCaveats)
 				if err != nil {
 					if errors.Is(ctx.Err(), context.Canceled) {
 						errs <- datastore.NewWatchCanceledErr()
@@ -148,7 +158,7 @@ func (pgd *pgDatastore) getNewRevisions(
 	return ids, nil
 }
 
-func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []postgresRevision) ([]datastore.RevisionChanges, error) {
+func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []postgresRevision, includeCaveats bool) ([]datastore.RevisionChanges, error) {
 	min := revisions[0].tx.Uint
 	max := revisions[0].tx.Uint
 	filter := make(map[uint64]int, len(revisions))
@@ -163,7 +173,7 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []postgresRev
 		filter[rev.tx.Uint] = i
 	}
 
-	sql, args, err := queryChanged.Where(sq.Or{
+	changedWithin := sq.Or{
 		sq.And{
 			sq.LtOrEq{colCreatedXid: max},
 			sq.GtOrEq{colCreatedXid: min},
@@ -172,7 +182,9 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []postgresRev
 			sq.LtOrEq{colDeletedXid: max},
 			sq.GtOrEq{colDeletedXid: min},
 		},
-	}).ToSql()
+	}
+
+	sql, args, err := queryChanged.Where(changedWithin).ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("unable to prepare changes SQL: %w", err)
 	}
@@ -229,8 +241,59 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []postgresRev
 		return nil, fmt.Errorf("unable to load changes for XID: %w", err)
 	}
 
+	if includeCaveats {
+		if err := pgd.loadCaveatChanges(ctx, changedWithin, filter, tracked); err != nil {
+			return nil, err
+		}
+	}
+
 	reconciledChanges := tracked.AsRevisionChanges(func(lhs, rhs uint64) bool {
 		return filter[lhs] < filter[rhs]
 	})
 	return reconciledChanges, nil
 }
+
+// loadCaveatChanges adds the caveat definitions written and deleted by the transactions in the
+// filter to the tracked changes. As a written caveat replaces the row of its previous definition,
+// a caveat updated by a transaction is both written and deleted by it.
+func (pgd *pgDatastore) loadCaveatChanges(
+	ctx context.Context,
+	changedWithin sq.Sqlizer,
+	filter map[uint64]int,
+	tracked common.Changes[postgresRevision, uint64],
+) error {
+	sql, args, err := queryChangedCaveats.Where(changedWithin).ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to prepare caveat changes SQL: %w", err)
+	}
+
+	rows, err := pgd.dbpool.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("unable to load caveat changes for XID: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var serializedDef []byte
+		var createdXID, deletedXID xid8
+		if err := rows.Scan(&name, &serializedDef, &createdXID, &deletedXID); err != nil {
+			return fmt.Errorf("unable to parse changed caveat: %w", err)
+		}
+
+		if _, found := filter[createdXID.Uint]; found {
+			def := &core.CaveatDefinition{}
+			if err := def.UnmarshalVT(serializedDef); err != nil {
+				return fmt.Errorf("unable to parse changed caveat: %w", err)
+			}
+			tracked.AddCaveatWrite(postgresRevision{createdXID, noXmin}, def)
+		}
+		if _, found := filter[deletedXID.Uint]; found {
+			tracked.AddCaveatDelete(postgresRevision{deletedXID, noXmin}, name)
+		}
+	}
+	if rows.Err() != nil {
+		return fmt.Errorf("unable to load caveat changes for XID: %w", rows.Err())
+	}
+	return nil
+}
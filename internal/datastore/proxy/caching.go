@@ -8,6 +8,8 @@ import (
 	"unsafe"
 
 	"github.com/dustin/go-humanize"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/singleflight"
 
@@ -26,6 +28,23 @@ const (
 	namespaceDefinitionMinimumSize      = 150
 )
 
+// caveatDefinitionSizeVTMultiplier is the multiplier used for estimating the in-memory cost of a
+// CaveatDefinition based on its on-wire size. Caveat definitions are mostly made of their
+// serialized expression, which is held as-is in memory.
+const caveatDefinitionSizeVTMultiplier = 2
+
+const (
+	definitionKindNamespace = "namespace"
+	definitionKindCaveat    = "caveat"
+)
+
+var definitionCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "definition_cache_lookups_total",
+	Help:      "total number of lookups of namespace and caveat definitions in the definition cache, by result",
+}, []string{"kind", "result"})
+
 // DatastoreProxyTestCache returns a cache used for testing.
 func DatastoreProxyTestCache(t testing.TB) cache.Cache {
 	cache, err := cache.NewCache(&cache.Config{
@@ -36,8 +55,14 @@ func DatastoreProxyTestCache(t testing.TB) cache.Cache {
 	return cache
 }
 
-// NewCachingDatastoreProxy creates a new datastore proxy which caches namespace definitions that
-// are loaded at specific datastore revisions.
+// NewCachingDatastoreProxy creates a new datastore proxy which caches namespace and caveat
+// definitions that are loaded at specific datastore revisions, in the given cache. Passing a nil
+// cache disables caching.
+//
+// Definitions are cached by name and by the revision at which they were read, and are only ever
+// served to readers at that same revision. As the definitions at a revision never change, cached
+// entries never need to be invalidated as definitions are written: they are only evicted by the
+// cache once its capacity is reached.
 func NewCachingDatastoreProxy(delegate datastore.Datastore, c cache.Cache) datastore.Datastore {
 	if c == nil {
 		c = cache.NoopCache()
@@ -50,8 +75,8 @@ func NewCachingDatastoreProxy(delegate datastore.Datastore, c cache.Cache) datas
 
 type nsCachingProxy struct {
 	datastore.Datastore
-	c           cache.Cache
-	readNsGroup singleflight.Group
+	c         cache.Cache
+	readGroup singleflight.Group
 }
 
 func (p *nsCachingProxy) Close() error {
@@ -84,40 +109,86 @@ func (r *nsCachingReader) ReadNamespace(
 	ctx context.Context,
 	nsName string,
 ) (*core.NamespaceDefinition, datastore.Revision, error) {
-	// Check the nsCache.
-	nsRevisionKey := nsName + "@" + r.rev.String()
-
-	loadedRaw, found := r.p.c.Get(nsRevisionKey)
-	if !found {
-		// We couldn't use the cached entry, load one
-		var err error
-		loadedRaw, err, _ = r.p.readNsGroup.Do(nsRevisionKey, func() (any, error) {
-			// sever the context so that another branch doesn't cancel the
-			// single-flighted namespace read
-			loaded, updatedRev, err := r.Reader.ReadNamespace(SeparateContextWithTracing(ctx), nsName)
-			if err != nil && !errors.Is(err, &datastore.ErrNamespaceNotFound{}) {
-				// Propagate this error to the caller
-				return nil, err
-			}
-
-			entry := &cacheEntry{loaded, updatedRev, err}
-			r.p.c.Set(nsRevisionKey, entry, entry.Size())
-
-			// We have to call wait here or else Ristretto may not have the key
-			// available to a subsequent caller.
-			r.p.c.Wait()
-
-			return entry, nil
-		})
-		if err != nil {
-			return nil, datastore.NoRevision, err
+	loaded, err := r.readCached(ctx, definitionKindNamespace, nsName+"@"+r.rev.String(), func(ctx context.Context) (*cacheEntry, error) {
+		loaded, updatedRev, err := r.Reader.ReadNamespace(ctx, nsName)
+		if err != nil && !errors.Is(err, &datastore.ErrNamespaceNotFound{}) {
+			// Propagate this error to the caller
+			return nil, err
 		}
+
+		entry := &cacheEntry{namespaceDefinition: loaded, updated: updatedRev, notFound: err}
+		return entry, nil
+	})
+	if err != nil {
+		return nil, datastore.NoRevision, err
 	}
 
-	loaded := loadedRaw.(*cacheEntry)
 	return loaded.namespaceDefinition, loaded.updated, loaded.notFound
 }
 
+func (r *nsCachingReader) ReadCaveatByName(
+	ctx context.Context,
+	name string,
+) (*core.CaveatDefinition, datastore.Revision, error) {
+	// Caveat names are prefixed to avoid collisions with namespaces, whose names cannot contain
+	// colons.
+	loaded, err := r.readCached(ctx, definitionKindCaveat, "caveat:"+name+"@"+r.rev.String(), func(ctx context.Context) (*cacheEntry, error) {
+		loaded, updatedRev, err := r.Reader.ReadCaveatByName(ctx, name)
+		if err != nil && !errors.As(err, &datastore.ErrCaveatNameNotFound{}) {
+			// Propagate this error to the caller
+			return nil, err
+		}
+
+		entry := &cacheEntry{caveatDefinition: loaded, updated: updatedRev, notFound: err}
+		return entry, nil
+	})
+	if err != nil {
+		return nil, datastore.NoRevision, err
+	}
+
+	return loaded.caveatDefinition, loaded.updated, loaded.notFound
+}
+
+// readCached returns the cache entry with the key, loading it with the loader, single-flighted
+// across concurrent readers, if it is not cached.
+func (r *nsCachingReader) readCached(
+	ctx context.Context,
+	kind string,
+	key string,
+	loader func(ctx context.Context) (*cacheEntry, error),
+) (*cacheEntry, error) {
+	loadedRaw, found := r.p.c.Get(key)
+	if found {
+		definitionCacheLookups.WithLabelValues(kind, "hit").Inc()
+		return loadedRaw.(*cacheEntry), nil
+	}
+
+	definitionCacheLookups.WithLabelValues(kind, "miss").Inc()
+
+	// We couldn't use the cached entry, load one
+	loadedRaw, err, _ := r.p.readGroup.Do(key, func() (any, error) {
+		// sever the context so that another branch doesn't cancel the
+		// single-flighted definition read
+		entry, err := loader(SeparateContextWithTracing(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		r.p.c.Set(key, entry, entry.Size())
+
+		// We have to call wait here or else Ristretto may not have the key
+		// available to a subsequent caller.
+		r.p.c.Wait()
+
+		return entry, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loadedRaw.(*cacheEntry), nil
+}
+
 type nsCachingRWT struct {
 	datastore.ReadWriteTransaction
 	namespaceCache *sync.Map
@@ -164,13 +235,18 @@ func (rwt *nsCachingRWT) WriteNamespaces(ctx context.Context, newConfigs ...*cor
 	return nil
 }
 
+// cacheEntry is a cached namespace or caveat definition, only one of which is set.
 type cacheEntry struct {
 	namespaceDefinition *core.NamespaceDefinition
+	caveatDefinition    *core.CaveatDefinition
 	updated             datastore.Revision
 	notFound            error
 }
 
 func (c *cacheEntry) Size() int64 {
+	if c.caveatDefinition != nil {
+		return int64(c.caveatDefinition.SizeVT()*caveatDefinitionSizeVTMultiplier) + int64(unsafe.Sizeof(c))
+	}
 	return estimatedNamespaceDefinitionSize(c.namespaceDefinition.SizeVT()) + int64(unsafe.Sizeof(c))
 }
 
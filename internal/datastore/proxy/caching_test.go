@@ -102,6 +102,47 @@ func TestSnapshotNamespaceCaching(t *testing.T) {
 	twoReader.AssertExpectations(t)
 }
 
+func TestSnapshotCaveatCaching(t *testing.T) {
+	dsMock := &proxy_test.MockDatastore{}
+
+	caveatOne := &core.CaveatDefinition{Name: "somecaveat", SerializedExpression: []byte("one")}
+	caveatTwo := &core.CaveatDefinition{Name: "somecaveat", SerializedExpression: []byte("two")}
+
+	oneReader := &proxy_test.MockReader{}
+	dsMock.On("SnapshotReader", one).Return(oneReader)
+	oneReader.On("ReadCaveatByName", "somecaveat").Return(caveatOne, old, nil).Once()
+	oneReader.On("ReadCaveatByName", "missing").Return(nil, datastore.NoRevision, datastore.NewCaveatNameNotFoundErr("missing")).Once()
+
+	twoReader := &proxy_test.MockReader{}
+	dsMock.On("SnapshotReader", two).Return(twoReader)
+	twoReader.On("ReadCaveatByName", "somecaveat").Return(caveatTwo, one, nil).Once()
+
+	require := require.New(t)
+	ctx := context.Background()
+
+	ds := NewCachingDatastoreProxy(dsMock, DatastoreProxyTestCache(t))
+
+	for i := 0; i < 2; i++ {
+		loaded, updated, err := ds.SnapshotReader(one).ReadCaveatByName(ctx, "somecaveat")
+		require.NoError(err)
+		require.Equal(caveatOne, loaded)
+		require.True(old.Equal(updated))
+
+		_, _, err = ds.SnapshotReader(one).ReadCaveatByName(ctx, "missing")
+		require.ErrorAs(err, &datastore.ErrCaveatNameNotFound{})
+
+		// A definition is never served from another revision than the one it was read at.
+		loaded, updated, err = ds.SnapshotReader(two).ReadCaveatByName(ctx, "somecaveat")
+		require.NoError(err)
+		require.Equal(caveatTwo, loaded)
+		require.True(one.Equal(updated))
+	}
+
+	dsMock.AssertExpectations(t)
+	oneReader.AssertExpectations(t)
+	twoReader.AssertExpectations(t)
+}
+
 func TestRWTNamespaceCaching(t *testing.T) {
 	dsMock := &proxy_test.MockDatastore{}
 	rwtMock := &proxy_test.MockReadWriteTransaction{}
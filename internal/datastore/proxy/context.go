@@ -5,6 +5,7 @@ import (
 
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/internal/datastore/options"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
@@ -27,11 +28,21 @@ func SeparateContextWithTracing(ctx context.Context) context.Context {
 	return ctxWithObservability
 }
 
+// SeparateContextWithCancellation severs the context as SeparateContextWithTracing does, while
+// attaching the caller's context, such that datastores can cancel the queries issued with it on
+// the server once the caller's context is done, without closing their connection.
+func SeparateContextWithCancellation(ctx context.Context) context.Context {
+	return common.WithCancellationFrom(SeparateContextWithTracing(ctx), ctx)
+}
+
 // NewSeparatingContextDatastoreProxy severs any timeouts in the context being
 // passed to the datastore and only retains tracing metadata.
 //
 // This is useful for datastores that do not want to close connections when a
-// cancel or deadline occurs.
+// cancel or deadline occurs. As relationship queries can scan large amounts of
+// data, their context also carries the cancellation of the caller's context, in
+// order for datastores to cancel the in-flight query on the server rather than
+// holding on to a connection until it completes.
 func NewSeparatingContextDatastoreProxy(d datastore.Datastore) datastore.Datastore {
 	return &ctxProxy{d}
 }
@@ -58,8 +69,8 @@ func (p *ctxProxy) RevisionFromString(serialized string) (datastore.Revision, er
 	return p.delegate.RevisionFromString(serialized)
 }
 
-func (p *ctxProxy) Watch(ctx context.Context, afterRevision datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
-	return p.delegate.Watch(ctx, afterRevision)
+func (p *ctxProxy) Watch(ctx context.Context, afterRevision datastore.Revision, opts ...options.WatchOptionsOption) (<-chan *datastore.RevisionChanges, <-chan error) {
+	return p.delegate.Watch(ctx, afterRevision, opts...)
 }
 
 func (p *ctxProxy) Features(ctx context.Context) (*datastore.Features, error) {
@@ -108,11 +119,11 @@ func (r *ctxReader) ReadNamespace(ctx context.Context, nsName string) (*core.Nam
 }
 
 func (r *ctxReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
-	return r.delegate.QueryRelationships(SeparateContextWithTracing(ctx), filter, options...)
+	return r.delegate.QueryRelationships(SeparateContextWithCancellation(ctx), filter, options...)
 }
 
 func (r *ctxReader) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, options ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
-	return r.delegate.ReverseQueryRelationships(SeparateContextWithTracing(ctx), subjectsFilter, options...)
+	return r.delegate.ReverseQueryRelationships(SeparateContextWithCancellation(ctx), subjectsFilter, options...)
 }
 
 var (
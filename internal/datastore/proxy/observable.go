@@ -105,8 +105,8 @@ func (p *observableProxy) RevisionFromString(serialized string) (datastore.Revis
 	return p.delegate.RevisionFromString(serialized)
 }
 
-func (p *observableProxy) Watch(ctx context.Context, afterRevision datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
-	return p.delegate.Watch(ctx, afterRevision)
+func (p *observableProxy) Watch(ctx context.Context, afterRevision datastore.Revision, opts ...options.WatchOptionsOption) (<-chan *datastore.RevisionChanges, <-chan error) {
+	return p.delegate.Watch(ctx, afterRevision, opts...)
 }
 
 func (p *observableProxy) Features(ctx context.Context) (*datastore.Features, error) {
@@ -255,6 +255,23 @@ func (rwt *observableRWT) WriteRelationships(ctx context.Context, mutations []*c
 	return rwt.delegate.WriteRelationships(ctx, mutations)
 }
 
+func (rwt *observableRWT) BulkLoad(ctx context.Context, iter datastore.RelationshipIterator) (uint64, error) {
+	ctx, closer := observe(ctx, "BulkLoad")
+	defer closer()
+
+	return rwt.delegate.BulkLoad(ctx, iter)
+}
+
+func (rwt *observableRWT) BulkDeleteRelationships(ctx context.Context, filter datastore.RelationshipsFilter, limit uint64) (uint64, error) {
+	ctx, closer := observe(ctx, "BulkDeleteRelationships", trace.WithAttributes(
+		common.ObjNamespaceNameKey.String(filter.ResourceType),
+		attribute.Int64("limit", int64(limit)),
+	))
+	defer closer()
+
+	return rwt.delegate.BulkDeleteRelationships(ctx, filter, limit)
+}
+
 func (rwt *observableRWT) WriteNamespaces(ctx context.Context, newConfigs ...*core.NamespaceDefinition) error {
 	nsNames := make([]string, 0, len(newConfigs))
 	for _, ns := range newConfigs {
@@ -54,7 +54,7 @@ func (dm *MockDatastore) RevisionFromString(s string) (datastore.Revision, error
 	return args.Get(0).(datastore.Revision), args.Error(1)
 }
 
-func (dm *MockDatastore) Watch(ctx context.Context, afterRevision datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
+func (dm *MockDatastore) Watch(ctx context.Context, afterRevision datastore.Revision, opts ...options.WatchOptionsOption) (<-chan *datastore.RevisionChanges, <-chan error) {
 	args := dm.Called(afterRevision)
 	return args.Get(0).(<-chan *datastore.RevisionChanges), args.Get(1).(<-chan error)
 }
@@ -148,8 +148,14 @@ func (dm *MockReader) LookupNamespaces(ctx context.Context, nsNames []string) ([
 }
 
 func (dm *MockReader) ReadCaveatByName(ctx context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
-	// TODO implement me
-	panic("implement me")
+	args := dm.Called(name)
+
+	var def *core.CaveatDefinition
+	if args.Get(0) != nil {
+		def = args.Get(0).(*core.CaveatDefinition)
+	}
+
+	return def, args.Get(1).(datastore.Revision), args.Error(2)
 }
 
 func (dm *MockReader) ListCaveats(ctx context.Context, caveatNames ...string) ([]*core.CaveatDefinition, error) {
@@ -230,6 +236,16 @@ func (dm *MockReadWriteTransaction) WriteRelationships(ctx context.Context, muta
 	return args.Error(0)
 }
 
+func (dm *MockReadWriteTransaction) BulkLoad(ctx context.Context, iter datastore.RelationshipIterator) (uint64, error) {
+	args := dm.Called(iter)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (dm *MockReadWriteTransaction) BulkDeleteRelationships(ctx context.Context, filter datastore.RelationshipsFilter, limit uint64) (uint64, error) {
+	args := dm.Called(filter, limit)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
 func (dm *MockReadWriteTransaction) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
 	args := dm.Called(filter)
 	return args.Error(0)
@@ -0,0 +1,133 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+const (
+	// https://www.postgresql.org/docs/current/errcodes-appendix.html, also returned by CockroachDB
+	// for its transaction restarts.
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+
+	// https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+	mysqlLockWaitTimeout = 1205
+	mysqlDeadlock        = 1213
+
+	errRetriesExhausted = "transaction failed after %d attempts: %w"
+	errRetryCanceled    = "transaction canceled while retrying after %d attempts: %w (last attempt failed with: %v)"
+)
+
+// RetryPolicy configures the retries of the read-write transactions made through a retrying
+// datastore proxy.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a transaction is run, including the first.
+	MaxAttempts uint8
+
+	// InitialBackoff is the delay before the first retry, which is doubled for each further
+	// retry up to MaxBackoff.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the maximum delay between two attempts.
+	MaxBackoff time.Duration
+
+	// JitterFactor randomizes each delay by up to this fraction of it, between 0 and 1.
+	JitterFactor float64
+
+	// IsRetryable returns whether a transaction which failed with the error can be retried. If
+	// nil, IsRetryableError is used.
+	IsRetryable func(error) bool
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured: up to five attempts,
+// with delays starting at 10ms and capped at one second.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		JitterFactor:   0.5,
+	}
+}
+
+// IsRetryableError returns whether the error is a serialization failure, deadlock or aborted
+// transaction returned by one of the supported backends, after which the transaction can be
+// run again.
+func IsRetryableError(err error) bool {
+	var pgerr *pgconn.PgError
+	if errors.As(err, &pgerr) {
+		return pgerr.SQLState() == pgSerializationFailure || pgerr.SQLState() == pgDeadlockDetected
+	}
+
+	var mysqlerr *mysql.MySQLError
+	if errors.As(err, &mysqlerr) {
+		return mysqlerr.Number == mysqlDeadlock || mysqlerr.Number == mysqlLockWaitTimeout
+	}
+
+	return spanner.ErrCode(err) == codes.Aborted
+}
+
+type retryingProxy struct {
+	datastore.Datastore
+	policy RetryPolicy
+}
+
+// NewRetryingDatastoreProxy creates a proxy which retries the read-write transactions of the
+// delegate datastore failing with a retryable error, with exponential backoff and jitter, as
+// configured by the policy. Once the attempts are exhausted, the last error is returned annotated
+// with the number of attempts made.
+//
+// The transaction function may be run more than once, and must therefore be safe to re-run: it
+// must not have side effects outside of the transaction, nor depend on state mutated by an
+// earlier attempt.
+func NewRetryingDatastoreProxy(delegate datastore.Datastore, policy RetryPolicy) datastore.Datastore {
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = 1
+	}
+	if policy.IsRetryable == nil {
+		policy.IsRetryable = IsRetryableError
+	}
+	return retryingProxy{Datastore: delegate, policy: policy}
+}
+
+func (rp retryingProxy) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc) (datastore.Revision, error) {
+	backoff := rp.policy.InitialBackoff
+	for attempt := uint8(1); ; attempt++ {
+		rev, err := rp.Datastore.ReadWriteTx(ctx, f)
+		if err == nil || !rp.policy.IsRetryable(err) {
+			// The error is returned unmodified, as it may not support unwrapping.
+			return rev, err
+		}
+
+		if attempt >= rp.policy.MaxAttempts {
+			return datastore.NoRevision, fmt.Errorf(errRetriesExhausted, attempt, err)
+		}
+
+		log.Ctx(ctx).Debug().Err(err).Uint8("attempt", attempt).Msg("retrying read-write transaction")
+
+		timer := time.NewTimer(common.WithJitter(rp.policy.JitterFactor, backoff))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return datastore.NoRevision, fmt.Errorf(errRetryCanceled, attempt, ctx.Err(), err)
+		}
+
+		backoff *= 2
+		if backoff > rp.policy.MaxBackoff {
+			backoff = rp.policy.MaxBackoff
+		}
+	}
+}
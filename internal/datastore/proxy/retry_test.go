@@ -0,0 +1,135 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/proxy/proxy_test"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+var errTestRetryable = errors.New("retryable")
+
+func newRetryTestPolicy(maxAttempts uint8) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+		JitterFactor:   0.5,
+		IsRetryable: func(err error) bool {
+			return errors.Is(err, errTestRetryable)
+		},
+	}
+}
+
+func TestRetryingProxySucceedsAfterRetries(t *testing.T) {
+	require := require.New(t)
+
+	delegate := &proxy_test.MockDatastore{}
+	delegate.On("ReadWriteTx").Return(&proxy_test.MockReadWriteTransaction{}, expectedRevision, nil)
+
+	ds := NewRetryingDatastoreProxy(delegate, newRetryTestPolicy(5))
+
+	attempts := 0
+	rev, err := ds.ReadWriteTx(context.Background(), func(rwt datastore.ReadWriteTransaction) error {
+		attempts++
+		if attempts < 3 {
+			return errTestRetryable
+		}
+		return nil
+	})
+	require.NoError(err)
+	require.True(expectedRevision.Equal(rev))
+	require.Equal(3, attempts)
+}
+
+func TestRetryingProxyExhaustsAttempts(t *testing.T) {
+	require := require.New(t)
+
+	delegate := &proxy_test.MockDatastore{}
+	delegate.On("ReadWriteTx").Return(&proxy_test.MockReadWriteTransaction{}, expectedRevision, nil)
+
+	ds := NewRetryingDatastoreProxy(delegate, newRetryTestPolicy(3))
+
+	attempts := 0
+	rev, err := ds.ReadWriteTx(context.Background(), func(rwt datastore.ReadWriteTransaction) error {
+		attempts++
+		return errTestRetryable
+	})
+	require.ErrorIs(err, errTestRetryable)
+	require.ErrorContains(err, "transaction failed after 3 attempts")
+	require.Equal(datastore.NoRevision, rev)
+	require.Equal(3, attempts)
+}
+
+func TestRetryingProxyStopsWhenCanceled(t *testing.T) {
+	require := require.New(t)
+
+	delegate := &proxy_test.MockDatastore{}
+	delegate.On("ReadWriteTx").Return(&proxy_test.MockReadWriteTransaction{}, expectedRevision, nil)
+
+	policy := newRetryTestPolicy(5)
+	policy.InitialBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+	ds := NewRetryingDatastoreProxy(delegate, policy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	rev, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		attempts++
+		cancel()
+		return errTestRetryable
+	})
+	require.ErrorIs(err, context.Canceled)
+	require.ErrorContains(err, "transaction canceled while retrying after 1 attempts")
+	require.ErrorContains(err, errTestRetryable.Error())
+	require.Equal(datastore.NoRevision, rev)
+	require.Equal(1, attempts)
+}
+
+func TestRetryingProxyDoesNotRetryOtherErrors(t *testing.T) {
+	require := require.New(t)
+
+	delegate := &proxy_test.MockDatastore{}
+	delegate.On("ReadWriteTx").Return(&proxy_test.MockReadWriteTransaction{}, expectedRevision, nil)
+
+	ds := NewRetryingDatastoreProxy(delegate, newRetryTestPolicy(3))
+
+	errOther := errors.New("other")
+	attempts := 0
+	_, err := ds.ReadWriteTx(context.Background(), func(rwt datastore.ReadWriteTransaction) error {
+		attempts++
+		return errOther
+	})
+	require.Equal(errOther, err)
+	require.Equal(1, attempts)
+}
+
+func TestIsRetryableError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"postgres serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"postgres deadlock", &pgconn.PgError{Code: "40P01"}, true},
+		{"postgres unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"mysql deadlock", &mysql.MySQLError{Number: 1213}, true},
+		{"mysql lock wait timeout", &mysql.MySQLError{Number: 1205}, true},
+		{"mysql duplicate entry", &mysql.MySQLError{Number: 1062}, false},
+		{"other", errors.New("other"), false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.retryable, IsRetryableError(tc.err))
+		})
+	}
+}
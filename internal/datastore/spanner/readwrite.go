@@ -11,11 +11,18 @@ import (
 	"github.com/jzelinskie/stringz"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
+// bulkLoadBatchSize is the number of relationships written per batch by BulkLoad.
+const bulkLoadBatchSize = 1000
+
+// bulkDeleteBatchSize is the number of relationships deleted per batch by BulkDeleteRelationships.
+const bulkDeleteBatchSize = 1000
+
 type spannerReadWriteTXN struct {
 	spannerReader
 	spannerRWT *spanner.ReadWriteTransaction
@@ -63,6 +70,19 @@ func (rwt spannerReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 	return nil
 }
 
+// BulkLoad writes the relationships in batches of buffered insert mutations. Note that all the
+// mutations of a transaction count towards the Spanner limit on mutations per commit, so very
+// large loads must be split across transactions.
+func (rwt spannerReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.RelationshipIterator) (uint64, error) {
+	return common.BulkLoadInBatches(ctx, iter, bulkLoadBatchSize, rwt.WriteRelationships)
+}
+
+// BulkDeleteRelationships deletes the relationships in pages of DELETE mutations. The relationships
+// are read rather than deleted through DML, as the changelog records each relationship deleted.
+func (rwt spannerReadWriteTXN) BulkDeleteRelationships(ctx context.Context, filter datastore.RelationshipsFilter, limit uint64) (uint64, error) {
+	return common.DeleteRelationshipsInBatches(ctx, rwt, filter, limit, bulkDeleteBatchSize, rwt.WriteRelationships)
+}
+
 func (rwt spannerReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
 	err := deleteWithFilter(ctx, rwt.spannerRWT, filter)
 	if err != nil {
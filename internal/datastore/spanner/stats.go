@@ -16,7 +16,7 @@ var queryRelationshipEstimate = fmt.Sprintf("SELECT SUM(%s) FROM %s", colCount,
 
 func (sd spannerDatastore) Statistics(ctx context.Context) (datastore.Stats, error) {
 	idRows := sd.client.Single().Read(
-		context.Background(),
+		ctx,
 		tableMetadata,
 		spanner.AllKeys(),
 		[]string{colUniqueID},
@@ -55,10 +55,13 @@ func (sd spannerDatastore) Statistics(ctx context.Context) (datastore.Stats, err
 		}
 	}
 
+	// Spanner maintains no statistics from which to estimate the number of unique objects
+	// without a full scan, so none is reported.
 	return datastore.Stats{
 		UniqueID:                   uniqueID,
 		ObjectTypeStatistics:       datastore.ComputeObjectTypeStats(allNamespaces),
 		EstimatedRelationshipCount: uint64(estimate),
+		HasEstimatedObjectCount:    false,
 	}, nil
 }
 
@@ -9,6 +9,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/options"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/revision"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -20,12 +21,18 @@ const (
 
 var queryChanged = sql.Select(allChangelogCols...).From(tableChangelog)
 
-func (sd spannerDatastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
+func (sd spannerDatastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revision, opts ...options.WatchOptionsOption) (<-chan *datastore.RevisionChanges, <-chan error) {
 	afterRevision := afterRevisionRaw.(revision.Decimal)
 
 	updates := make(chan *datastore.RevisionChanges, sd.config.watchBufferLength)
 	errs := make(chan error, 1)
 
+	// Deleted caveats are removed from the caveat table, and no changelog is kept for them.
+	if options.NewWatchOptionsWithOptions(opts...).IncludeCaveats {
+		errs <- datastore.NewWatchDisabledErr("spanner does not support watching caveat changes")
+		return updates, errs
+	}
+
 	go func() {
 		defer close(updates)
 		defer close(errs)
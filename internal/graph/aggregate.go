@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/datastore/options"
+	"github.com/authzed/spicedb/pkg/caveats"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// withAggregateCounts returns the relationship with the relationship count parameters of its
+// caveat, if any, populated in the context of the caveat. The relationships are counted for the
+// resource of the relationship itself, so that a caveat on a relationship reached through another
+// is evaluated against the counts of its own resource.
+func (cp *caveatPruner) withAggregateCounts(ctx context.Context, tpl *core.RelationTuple) (*core.RelationTuple, error) {
+	if tpl.Caveat == nil || tpl.Caveat.CaveatName == "" {
+		return tpl, nil
+	}
+
+	// Leave any error to be reported when the caveat is computed.
+	found, err := cp.lookupCaveat(ctx, tpl.Caveat.CaveatName)
+	if err != nil {
+		return tpl, nil
+	}
+
+	if len(found.countedRelations) == 0 {
+		return tpl, nil
+	}
+
+	caveatContext := tpl.Caveat.Context.AsMap()
+	for paramName, relationName := range found.countedRelations {
+		count, err := cp.countRelationships(ctx, tpl.ResourceAndRelation.Namespace, tpl.ResourceAndRelation.ObjectId, relationName)
+		if err != nil {
+			return nil, err
+		}
+
+		caveatContext[paramName] = count
+	}
+
+	contextStruct, err := structpb.NewStruct(caveatContext)
+	if err != nil {
+		return nil, err
+	}
+
+	counted := tpl.CloneVT()
+	counted.Caveat.Context = contextStruct
+	return counted, nil
+}
+
+// countRelationships returns the number of relationships of the resource and relation, up to
+// caveats.MaximumAggregateCount.
+func (cp *caveatPruner) countRelationships(ctx context.Context, resourceType string, resourceID string, relationName string) (int64, error) {
+	key := tuple.StringONR(&core.ObjectAndRelation{
+		Namespace: resourceType,
+		ObjectId:  resourceID,
+		Relation:  relationName,
+	})
+	if count, ok := cp.counts[key]; ok {
+		return count, nil
+	}
+
+	limit := uint64(caveats.MaximumAggregateCount)
+	it, err := cp.reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType:             resourceType,
+		OptionalResourceIds:      []string{resourceID},
+		OptionalResourceRelation: relationName,
+	}, options.WithLimit(&limit))
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	var count int64
+	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
+		count++
+	}
+
+	if it.Err() != nil {
+		return 0, it.Err()
+	}
+
+	cp.counts[key] = count
+	return count, nil
+}
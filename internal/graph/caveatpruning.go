@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"context"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/authzed/spicedb/pkg/caveats"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// caveatPruner evaluates the caveats of relationships found while checking, using only the
+// context written on each relationship, to prune relationships whose caveat can never pass.
+//
+// As the context written on a relationship takes precedence over the context supplied with the
+// check, a caveat which evaluates to a definite false over the relationship's context alone is
+// false for any check context: the relationship cannot grant membership, and any dispatch
+// through it is wasted work. A branch left without relationships returns no members, which in
+// turn allows an intersection or exclusion to return early and cancel its in-flight siblings.
+// Only caveats whose referenced parameters are all written on the relationship are evaluated, as
+// a parameter otherwise missing would take its default value rather than the one the check
+// context may supply. Caveats which are not evaluated, partially evaluated, true, or which fail
+// to be read or evaluated are left to be computed once the check has completed, as before, which
+// reports any error.
+//
+// The pruner also populates the relationship count parameters of the caveats, which
+// must be done before a relationship is pruned, as the counts are part of its context.
+type caveatPruner struct {
+	reader  datastore.Reader
+	caveats map[string]*prunableCaveat
+	counts  map[string]int64
+}
+
+type prunableCaveat struct {
+	compiled             *caveats.CompiledCaveat
+	parameterTypes       map[string]*core.CaveatTypeReference
+	referencedParameters []string
+
+	// countedRelations maps the relationship count parameters to the relations they count.
+	countedRelations map[string]string
+}
+
+func newCaveatPruner(reader datastore.Reader) *caveatPruner {
+	return &caveatPruner{
+		reader:  reader,
+		caveats: map[string]*prunableCaveat{},
+		counts:  map[string]int64{},
+	}
+}
+
+func (cp *caveatPruner) lookupCaveat(ctx context.Context, caveatName string) (*prunableCaveat, error) {
+	if found, ok := cp.caveats[caveatName]; ok {
+		return found, nil
+	}
+
+	caveat, _, err := cp.reader.ReadCaveatByName(ctx, caveatName)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := caveats.DeserializeCaveat(caveat.SerializedExpression, caveat.ParameterTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	countedRelations := map[string]string{}
+	for paramName, paramType := range caveat.ParameterTypes {
+		if relationName, ok := caveats.AggregateCountRelation(paramName, paramType); ok {
+			countedRelations[paramName] = relationName
+		}
+	}
+
+	referencedParameters := compiled.ReferencedParameters(maps.Keys(caveat.ParameterTypes)).AsSlice()
+
+	found := &prunableCaveat{compiled, caveat.ParameterTypes, referencedParameters, countedRelations}
+	cp.caveats[caveatName] = found
+	return found, nil
+}
+
+// denies returns true if the caveat on the relationship, if any, is definitely false regardless
+// of the context supplied with the check.
+func (cp *caveatPruner) denies(ctx context.Context, tpl *core.RelationTuple) bool {
+	if tpl.Caveat == nil || tpl.Caveat.CaveatName == "" {
+		return false
+	}
+
+	// Leave any error to be reported when the caveat is computed with the full context.
+	found, err := cp.lookupCaveat(ctx, tpl.Caveat.CaveatName)
+	if err != nil {
+		return false
+	}
+
+	relationshipContext := tpl.Caveat.Context.AsMap()
+	for _, parameterName := range found.referencedParameters {
+		if _, ok := relationshipContext[parameterName]; !ok {
+			return false
+		}
+	}
+
+	typedParameters, err := caveats.ConvertContextToParameters(
+		relationshipContext,
+		found.parameterTypes,
+		caveats.SkipUnknownParameters,
+	)
+	if err != nil {
+		return false
+	}
+
+	result, err := caveats.EvaluateCaveat(found.compiled, typedParameters)
+	if err != nil {
+		return false
+	}
+
+	return !result.IsPartial() && !result.Value()
+}
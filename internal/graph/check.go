@@ -236,6 +236,7 @@ func (cc *ConcurrentChecker) checkDirect(ctx context.Context, crc currentRequest
 	}
 
 	foundResources := NewMembershipSet()
+	pruner := newCaveatPruner(ds)
 
 	// If the direct subject or a wildcard form can be found, issue a query for just that
 	// subject.
@@ -299,6 +300,15 @@ func (cc *ConcurrentChecker) checkDirect(ctx context.Context, crc currentRequest
 				)
 			}
 
+			tpl, err = pruner.withAggregateCounts(ctx, tpl)
+			if err != nil {
+				return checkResultError(NewCheckFailureErr(err), emptyMetadata)
+			}
+
+			if pruner.denies(ctx, tpl) {
+				continue
+			}
+
 			foundResources.AddDirectMember(tpl.ResourceAndRelation.ObjectId, tpl.Caveat)
 			if crc.resultsSetting == v1.DispatchCheckRequest_ALLOW_SINGLE_RESULT && foundResources.HasDeterminedMember() {
 				return checkResultsForMembership(foundResources, emptyMetadata)
@@ -356,6 +366,16 @@ func (cc *ConcurrentChecker) checkDirect(ctx context.Context, crc currentRequest
 			return checkResultError(NewCheckFailureErr(fmt.Errorf("got a terminal for a non-terminal query")), emptyMetadata)
 		}
 
+		tpl, err = pruner.withAggregateCounts(ctx, tpl)
+		if err != nil {
+			return checkResultError(NewCheckFailureErr(err), emptyMetadata)
+		}
+
+		// Skip dispatching through relationships whose caveat can never pass.
+		if pruner.denies(ctx, tpl) {
+			continue
+		}
+
 		subjectsToDispatch.Add(tpl.Subject)
 		relationshipsBySubjectONR.Add(tuple.StringONR(tpl.Subject), tpl)
 	}
@@ -548,6 +568,7 @@ func (cc *ConcurrentChecker) checkTupleToUserset(ctx context.Context, crc curren
 	}
 	defer it.Close()
 
+	pruner := newCaveatPruner(ds)
 	subjectsToDispatch := tuple.NewONRByTypeSet()
 	relationshipsBySubjectONR := util.NewMultiMap[string, *core.RelationTuple]()
 	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
@@ -555,6 +576,16 @@ func (cc *ConcurrentChecker) checkTupleToUserset(ctx context.Context, crc curren
 			return checkResultError(NewCheckFailureErr(it.Err()), emptyMetadata)
 		}
 
+		tpl, err = pruner.withAggregateCounts(ctx, tpl)
+		if err != nil {
+			return checkResultError(NewCheckFailureErr(err), emptyMetadata)
+		}
+
+		// Skip dispatching through relationships whose caveat can never pass.
+		if pruner.denies(ctx, tpl) {
+			continue
+		}
+
 		subjectsToDispatch.Add(tpl.Subject)
 		relationshipsBySubjectONR.Add(tuple.StringONR(tpl.Subject), tpl)
 	}
@@ -105,7 +105,7 @@ func TestComputeCheckWithCaveats(t *testing.T) {
 						"anothercondition": "15",
 					},
 					v1.ResourceCheckResult_CAVEATED_MEMBER,
-					[]string{"somecondition"},
+					[]string{"somebool", "somecondition"},
 					"",
 				},
 				{
@@ -975,3 +975,195 @@ func caveatedRelationTuple(relationTuple string, caveatName string, context map[
 	}
 	return c
 }
+
+func TestComputeCheckPrunesDenyingCaveat(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	dispatch := graph.NewLocalOnlyDispatcher(10)
+	ctx := log.Logger.WithContext(datastoremw.ContextWithHandle(context.Background()))
+	require.NoError(t, datastoremw.SetInContext(ctx, ds))
+
+	revision, err := writeCaveatedTuples(ctx, t, ds, `
+	definition user {}
+
+	definition group {
+		relation member: user
+	}
+
+	definition document {
+		relation viewer: group#member with testcaveat
+		relation editor: user
+		permission edit_and_view = viewer & editor
+	}
+
+	caveat testcaveat(somecondition int, other int) {
+		somecondition == 42 && other == 1
+	}
+	`, []caveatedUpdate{
+		{core.RelationTupleUpdate_CREATE, "group:first#member@user:tom", "", nil},
+		{core.RelationTupleUpdate_CREATE, "group:second#member@user:tom", "", nil},
+		{core.RelationTupleUpdate_CREATE, "document:denied#viewer@group:first#member", "testcaveat", map[string]any{"somecondition": 41, "other": 1}},
+		{core.RelationTupleUpdate_CREATE, "document:denied#editor@user:tom", "", nil},
+		{core.RelationTupleUpdate_CREATE, "document:unpruned#viewer@group:first#member", "testcaveat", map[string]any{"somecondition": 41}},
+		{core.RelationTupleUpdate_CREATE, "document:allowed#viewer@group:second#member", "testcaveat", map[string]any{"somecondition": 42}},
+		{core.RelationTupleUpdate_CREATE, "document:allowed#editor@user:tom", "", nil},
+	})
+	require.NoError(t, err)
+
+	check := func(resourceID string, permission string, caveatContext map[string]any) (*v1.ResourceCheckResult, *v1.ResponseMeta) {
+		result, meta, err := computed.ComputeCheck(ctx, dispatch,
+			computed.CheckParameters{
+				ResourceType: &core.RelationReference{
+					Namespace: "document",
+					Relation:  permission,
+				},
+				Subject: &core.ObjectAndRelation{
+					Namespace: "user",
+					ObjectId:  "tom",
+					Relation:  "...",
+				},
+				CaveatContext: caveatContext,
+				AtRevision:    revision,
+				MaximumDepth:  50,
+				DebugOption:   computed.NoDebugging,
+			},
+			resourceID,
+		)
+		require.NoError(t, err)
+		return result, meta
+	}
+
+	// The caveat on the allowed document's relationship depends on the check context, so the
+	// group is dispatched.
+	result, meta := check("allowed", "viewer", map[string]any{"other": 1})
+	require.Equal(t, v1.ResourceCheckResult_MEMBER, result.Membership)
+	require.Equal(t, uint32(2), meta.DispatchCount)
+
+	// The caveat on the denied document's relationship is false for any check context, so the
+	// group is never dispatched.
+	result, meta = check("denied", "viewer", map[string]any{"other": 1})
+	require.Equal(t, v1.ResourceCheckResult_NOT_MEMBER, result.Membership)
+	require.Equal(t, uint32(1), meta.DispatchCount)
+
+	// A caveat is only pruned when all its parameters are written on the relationship, so the
+	// group is dispatched even though this caveat is false for any value of the missing one.
+	result, meta = check("unpruned", "viewer", map[string]any{"other": 1})
+	require.Equal(t, v1.ResourceCheckResult_NOT_MEMBER, result.Membership)
+	require.Equal(t, uint32(2), meta.DispatchCount)
+
+	// The relationship context takes precedence over the check context, so it cannot override
+	// the denial.
+	result, _ = check("denied", "viewer", map[string]any{"somecondition": 42, "other": 1})
+	require.Equal(t, v1.ResourceCheckResult_NOT_MEMBER, result.Membership)
+
+	// The pruned branch of the intersection is empty, so the intersection finds no members.
+	result, _ = check("denied", "edit_and_view", map[string]any{"other": 1})
+	require.Equal(t, v1.ResourceCheckResult_NOT_MEMBER, result.Membership)
+	result, _ = check("allowed", "edit_and_view", map[string]any{"other": 1})
+	require.Equal(t, v1.ResourceCheckResult_MEMBER, result.Membership)
+}
+
+func TestComputeCheckWithAggregateCount(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	dispatch := graph.NewLocalOnlyDispatcher(10)
+	ctx := log.Logger.WithContext(datastoremw.ContextWithHandle(context.Background()))
+	require.NoError(t, datastoremw.SetInContext(ctx, ds))
+
+	revision, err := writeCaveatedTuples(ctx, t, ds, `
+	definition user {}
+
+	definition org {
+		relation admin: user
+		relation member: user with fewer_admins
+	}
+
+	definition document {
+		relation editor: user
+		relation org: org
+		relation viewer: user with fewer_editors
+		relation commenter: user with has_invites
+		permission view = viewer + org->member
+	}
+
+	caveat fewer_editors(editor_count relationship_count, max_editors int) {
+		editor_count < max_editors
+	}
+
+	caveat fewer_admins(admin_count relationship_count) {
+		admin_count < 2
+	}
+
+	caveat has_invites(invite_count int) {
+		invite_count > 0
+	}
+	`, []caveatedUpdate{
+		{core.RelationTupleUpdate_CREATE, "document:few#editor@user:sarah", "", nil},
+		{core.RelationTupleUpdate_CREATE, "document:few#editor@user:fred", "", nil},
+		{core.RelationTupleUpdate_CREATE, "document:few#viewer@user:tom", "fewer_editors", map[string]any{"max_editors": 3}},
+		{core.RelationTupleUpdate_CREATE, "document:many#editor@user:sarah", "", nil},
+		{core.RelationTupleUpdate_CREATE, "document:many#editor@user:fred", "", nil},
+		{core.RelationTupleUpdate_CREATE, "document:many#editor@user:jane", "", nil},
+		{core.RelationTupleUpdate_CREATE, "document:many#viewer@user:tom", "fewer_editors", map[string]any{"max_editors": 3}},
+		{core.RelationTupleUpdate_CREATE, "document:fewadmins#org@org:small", "", nil},
+		{core.RelationTupleUpdate_CREATE, "document:fewadmins#editor@user:sarah", "", nil},
+		{core.RelationTupleUpdate_CREATE, "document:fewadmins#editor@user:fred", "", nil},
+		{core.RelationTupleUpdate_CREATE, "org:small#admin@user:sarah", "", nil},
+		{core.RelationTupleUpdate_CREATE, "org:small#member@user:tom", "fewer_admins", nil},
+		{core.RelationTupleUpdate_CREATE, "document:manyadmins#org@org:large", "", nil},
+		{core.RelationTupleUpdate_CREATE, "org:large#admin@user:sarah", "", nil},
+		{core.RelationTupleUpdate_CREATE, "org:large#admin@user:fred", "", nil},
+		{core.RelationTupleUpdate_CREATE, "org:large#member@user:tom", "fewer_admins", nil},
+		{core.RelationTupleUpdate_CREATE, "document:few#commenter@user:tom", "has_invites", nil},
+	})
+	require.NoError(t, err)
+
+	check := func(resourceID string, permission string, caveatContext map[string]any) *v1.ResourceCheckResult {
+		result, _, err := computed.ComputeCheck(ctx, dispatch,
+			computed.CheckParameters{
+				ResourceType: &core.RelationReference{
+					Namespace: "document",
+					Relation:  permission,
+				},
+				Subject: &core.ObjectAndRelation{
+					Namespace: "user",
+					ObjectId:  "tom",
+					Relation:  "...",
+				},
+				CaveatContext: caveatContext,
+				AtRevision:    revision,
+				MaximumDepth:  50,
+				DebugOption:   computed.NoDebugging,
+			},
+			resourceID,
+		)
+		require.NoError(t, err)
+		return result
+	}
+
+	// Below the threshold.
+	result := check("few", "viewer", nil)
+	require.Equal(t, v1.ResourceCheckResult_MEMBER, result.Membership)
+
+	// At or above the threshold.
+	result = check("many", "viewer", nil)
+	require.Equal(t, v1.ResourceCheckResult_NOT_MEMBER, result.Membership)
+
+	// A count supplied in the context is ignored.
+	result = check("many", "viewer", map[string]any{"editor_count": 1})
+	require.Equal(t, v1.ResourceCheckResult_NOT_MEMBER, result.Membership)
+
+	// The caveat on the membership of the org counts the admins of the org, rather than anything
+	// on the document being checked.
+	result = check("fewadmins", "view", nil)
+	require.Equal(t, v1.ResourceCheckResult_MEMBER, result.Membership)
+
+	result = check("manyadmins", "view", nil)
+	require.Equal(t, v1.ResourceCheckResult_NOT_MEMBER, result.Membership)
+
+	// A parameter named as a count but not of type relationship_count is an ordinary parameter.
+	result = check("few", "commenter", map[string]any{"invite_count": 2})
+	require.Equal(t, v1.ResourceCheckResult_MEMBER, result.Membership)
+}
@@ -14,7 +14,7 @@ import (
 // definition, including usage of the parameters.
 func ValidateCaveatDefinition(caveat *core.CaveatDefinition) error {
 	// Ensure all parameters are used by the caveat expression itself.
-	deserialized, err := caveats.DeserializeCaveat(caveat.SerializedExpression)
+	deserialized, err := caveats.DeserializeCaveat(caveat.SerializedExpression, nil)
 	if err != nil {
 		return newTypeErrorWithSource(
 			fmt.Errorf("could not decode caveat `%s`: %w", caveat.Name, err),
@@ -33,7 +33,7 @@ func ValidateCaveatDefinition(caveat *core.CaveatDefinition) error {
 
 	referencedNames := deserialized.ReferencedParameters(maps.Keys(caveat.ParameterTypes))
 	for paramName, paramType := range caveat.ParameterTypes {
-		_, err := caveattypes.DecodeParameterType(paramType)
+		decoded, err := caveattypes.DecodeParameterType(paramType)
 		if err != nil {
 			return newTypeErrorWithSource(
 				fmt.Errorf("type error for parameter `%s` for caveat `%s`: %w", paramName, caveat.Name, err),
@@ -42,6 +42,15 @@ func ValidateCaveatDefinition(caveat *core.CaveatDefinition) error {
 			)
 		}
 
+		// Ensure relationship count parameters name the relation they count.
+		if _, ok := caveats.AggregateCountRelation(paramName, paramType); !ok && decoded.String() == caveattypes.RelationshipCountType.String() {
+			return newTypeErrorWithSource(
+				fmt.Errorf("parameter `%s` for caveat `%s` is of type `%s` and must be named `<relation>%s`", paramName, caveat.Name, caveattypes.RelationshipCountType.String(), caveats.AggregateCountSuffix),
+				caveat,
+				paramName,
+			)
+		}
+
 		if !referencedNames.Has(paramName) {
 			return newTypeErrorWithSource(
 				NewUnusedCaveatParameterErr(caveat.Name, paramName),
@@ -51,5 +60,19 @@ func ValidateCaveatDefinition(caveat *core.CaveatDefinition) error {
 		}
 	}
 
+	// Ensure all parameters referenced by the caveat expression are declared.
+	withParameterTypes, err := caveats.DeserializeCaveat(caveat.SerializedExpression, caveat.ParameterTypes)
+	if err != nil {
+		return newTypeErrorWithSource(
+			fmt.Errorf("could not decode caveat `%s`: %w", caveat.Name, err),
+			caveat,
+			caveat.Name,
+		)
+	}
+
+	if err := withParameterTypes.Validate(); err != nil {
+		return newTypeErrorWithSource(err, caveat, caveat.Name)
+	}
+
 	return nil
 }
@@ -44,6 +44,39 @@ func TestValidateCaveatDefinition(t *testing.T) {
 			},
 			"could not decode caveat",
 		},
+		{
+			withoutParameterType(ns.MustCaveatDefinition(caveats.MustEnvForVariables(
+				map[string]caveattypes.VariableType{
+					"someCondition":  caveattypes.IntType,
+					"otherCondition": caveattypes.IntType,
+				},
+			), "undeclared", "someCondition == otherCondition"), "otherCondition"),
+			"caveat `undeclared` references undeclared parameter(s) `otherCondition`",
+		},
+		{
+			ns.MustCaveatDefinition(caveats.MustEnvForVariables(
+				map[string]caveattypes.VariableType{
+					"editor_count": caveattypes.RelationshipCountType,
+				},
+			), "validcount", "editor_count < 3"),
+			"",
+		},
+		{
+			ns.MustCaveatDefinition(caveats.MustEnvForVariables(
+				map[string]caveattypes.VariableType{
+					"editor_count": caveattypes.StringType,
+				},
+			), "ordinarycount", "editor_count == 'three'"),
+			"",
+		},
+		{
+			ns.MustCaveatDefinition(caveats.MustEnvForVariables(
+				map[string]caveattypes.VariableType{
+					"editors": caveattypes.RelationshipCountType,
+				},
+			), "invalidcount", "editors < 3"),
+			"parameter `editors` for caveat `invalidcount` is of type `relationship_count` and must be named `<relation>_count`",
+		},
 	}
 
 	for _, tc := range tcs {
@@ -58,3 +91,8 @@ func TestValidateCaveatDefinition(t *testing.T) {
 		})
 	}
 }
+
+func withoutParameterType(caveat *core.CaveatDefinition, paramName string) *core.CaveatDefinition {
+	delete(caveat.ParameterTypes, paramName)
+	return caveat
+}
@@ -6,6 +6,7 @@ import (
 
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 
+	"github.com/authzed/spicedb/pkg/caveats"
 	"github.com/authzed/spicedb/pkg/graph"
 	nspkg "github.com/authzed/spicedb/pkg/namespace"
 	iv1 "github.com/authzed/spicedb/pkg/proto/impl/v1"
@@ -505,7 +506,7 @@ func (nts *TypeSystem) Validate(ctx context.Context) (*ValidatedNamespaceTypeSys
 
 			// Check the caveat, if any.
 			if allowedRelation.GetRequiredCaveat() != nil {
-				_, err := nts.resolver.LookupCaveat(ctx, allowedRelation.GetRequiredCaveat().CaveatName)
+				caveat, err := nts.resolver.LookupCaveat(ctx, allowedRelation.GetRequiredCaveat().CaveatName)
 				if err != nil {
 					return nil, newTypeErrorWithSource(
 						fmt.Errorf("could not lookup caveat `%s` for relation `%s`: %w", allowedRelation.GetRequiredCaveat().CaveatName, relation.Name, err),
@@ -513,6 +514,23 @@ func (nts *TypeSystem) Validate(ctx context.Context) (*ValidatedNamespaceTypeSys
 						source,
 					)
 				}
+
+				// Ensure any relationship count parameters count a relation of this namespace.
+				for paramName, paramType := range caveat.ParameterTypes {
+					countedRelation, ok := caveats.AggregateCountRelation(paramName, paramType)
+					if !ok {
+						continue
+					}
+
+					counted, ok := nts.relationMap[countedRelation]
+					if !ok || counted.UsersetRewrite != nil {
+						return nil, newTypeErrorWithSource(
+							fmt.Errorf("caveat `%s` on relation `%s` has relationship count parameter `%s`, but `%s` is not a relation under definition `%s`", caveat.Name, relation.Name, paramName, countedRelation, nts.nsDef.Name),
+							allowedRelation,
+							source,
+						)
+					}
+				}
 			}
 		}
 	}
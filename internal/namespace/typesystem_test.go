@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/authzed/spicedb/pkg/caveats"
+	caveattypes "github.com/authzed/spicedb/pkg/caveats/types"
 
 	"github.com/stretchr/testify/require"
 
@@ -17,6 +18,12 @@ import (
 
 func TestTypeSystem(t *testing.T) {
 	emptyEnv := caveats.NewEnvironment()
+	countEnv := caveats.MustEnvForVariables(map[string]caveattypes.VariableType{
+		"editor_count": caveattypes.RelationshipCountType,
+	})
+	ordinaryCountEnv := caveats.MustEnvForVariables(map[string]caveattypes.VariableType{
+		"editor_count": caveattypes.IntType,
+	})
 
 	testCases := []struct {
 		name            string
@@ -280,6 +287,65 @@ func TestTypeSystem(t *testing.T) {
 			},
 			"",
 		},
+		{
+			"caveat counting a relation",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("editor", nil, ns.AllowedRelation("user", "...")),
+				ns.MustRelation("viewer", nil, ns.AllowedRelationWithCaveat("user", "...", ns.AllowedCaveat("fewereditors"))),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("user"),
+			},
+			[]*core.CaveatDefinition{
+				ns.MustCaveatDefinition(countEnv, "fewereditors", "editor_count < 3"),
+			},
+			"",
+		},
+		{
+			"caveat counting an unknown relation",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("viewer", nil, ns.AllowedRelationWithCaveat("user", "...", ns.AllowedCaveat("fewereditors"))),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("user"),
+			},
+			[]*core.CaveatDefinition{
+				ns.MustCaveatDefinition(countEnv, "fewereditors", "editor_count < 3"),
+			},
+			"caveat `fewereditors` on relation `viewer` has relationship count parameter `editor_count`, but `editor` is not a relation under definition `document`",
+		},
+		{
+			"caveat with an ordinary parameter named as a count",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("viewer", nil, ns.AllowedRelationWithCaveat("user", "...", ns.AllowedCaveat("fewereditors"))),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("user"),
+			},
+			[]*core.CaveatDefinition{
+				ns.MustCaveatDefinition(ordinaryCountEnv, "fewereditors", "editor_count < 3"),
+			},
+			"",
+		},
+		{
+			"caveat counting a permission",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("owner", nil, ns.AllowedRelation("user", "...")),
+				ns.MustRelation("editor", ns.Union(ns.ComputedUserset("owner"))),
+				ns.MustRelation("viewer", nil, ns.AllowedRelationWithCaveat("user", "...", ns.AllowedCaveat("fewereditors"))),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("user"),
+			},
+			[]*core.CaveatDefinition{
+				ns.MustCaveatDefinition(countEnv, "fewereditors", "editor_count < 3"),
+			},
+			"caveat `fewereditors` on relation `viewer` has relationship count parameter `editor_count`, but `editor` is not a relation under definition `document`",
+		},
 		{
 			"valid optional caveat",
 			ns.Namespace(
@@ -0,0 +1,203 @@
+package relationships
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/authzed/spicedb/pkg/caveats"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// ContextIncompatibility describes a stored relationship whose caveat context is not compatible
+// with the current definition of its caveat.
+type ContextIncompatibility struct {
+	// Relationship is the relationship, without its caveat.
+	Relationship string `json:"relationship"`
+
+	// CaveatName is the name of the caveat on the relationship.
+	CaveatName string `json:"caveat_name"`
+
+	// ParameterName is the name of the context parameter which failed validation, if any.
+	ParameterName string `json:"parameter_name,omitempty"`
+
+	// Reason is a human-readable description of the incompatibility.
+	Reason string `json:"reason"`
+}
+
+// ContextAuditReport is the report produced by AuditStoredCaveatContexts.
+type ContextAuditReport struct {
+	// ResourceTypesScanned is the number of resource types whose relationships were read.
+	ResourceTypesScanned int `json:"resource_types_scanned"`
+
+	// RelationshipsScanned is the number of relationships read.
+	RelationshipsScanned uint64 `json:"relationships_scanned"`
+
+	// CaveatedRelationships is the number of relationships read with a caveat.
+	CaveatedRelationships uint64 `json:"caveated_relationships"`
+
+	// Incompatibilities are the relationships found with an incompatible caveat context, sorted
+	// by relationship.
+	Incompatibilities []ContextIncompatibility `json:"incompatibilities"`
+}
+
+// ContextAuditProgress is reported after the relationships of each resource type are audited.
+type ContextAuditProgress struct {
+	// ResourceType is the resource type whose audit completed.
+	ResourceType string
+
+	// CompletedResourceTypes is the number of resource types audited so far.
+	CompletedResourceTypes int
+
+	// TotalResourceTypes is the total number of resource types to audit.
+	TotalResourceTypes int
+
+	// RelationshipsScanned is the number of relationships read so far.
+	RelationshipsScanned uint64
+}
+
+// ContextAuditConfig is the configuration for AuditStoredCaveatContexts.
+type ContextAuditConfig struct {
+	// Concurrency is the maximum number of resource types whose relationships are read
+	// concurrently. Defaults to 1.
+	Concurrency int
+
+	// OnProgress, if specified, is invoked after the relationships of each resource type have
+	// been audited. Invocations are never concurrent.
+	OnProgress func(ContextAuditProgress)
+}
+
+// AuditStoredCaveatContexts streams every relationship in the reader, validating the context of
+// each caveated relationship against the current definition of its caveat, and returns a report of
+// all incompatibilities found. It is intended for one-time audits, such as before a migration
+// changing caveat parameters; relationships written via the API are validated at write time.
+func AuditStoredCaveatContexts(ctx context.Context, reader datastore.Reader, config ContextAuditConfig) (*ContextAuditReport, error) {
+	caveatDefs, err := reader.ListCaveats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list caveats: %w", err)
+	}
+
+	caveatsByName := make(map[string]*core.CaveatDefinition, len(caveatDefs))
+	for _, caveatDef := range caveatDefs {
+		caveatsByName[caveatDef.Name] = caveatDef
+	}
+
+	nsDefs, err := reader.ListNamespaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list namespaces: %w", err)
+	}
+
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var lock sync.Mutex
+	report := &ContextAuditReport{ResourceTypesScanned: len(nsDefs)}
+	completed := 0
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for _, nsDef := range nsDefs {
+		resourceType := nsDef.Name
+		g.Go(func() error {
+			resourceReport, err := auditResourceType(gctx, reader, resourceType, caveatsByName)
+			if err != nil {
+				return fmt.Errorf("could not audit relationships of `%s`: %w", resourceType, err)
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			report.RelationshipsScanned += resourceReport.RelationshipsScanned
+			report.CaveatedRelationships += resourceReport.CaveatedRelationships
+			report.Incompatibilities = append(report.Incompatibilities, resourceReport.Incompatibilities...)
+			completed++
+
+			if config.OnProgress != nil {
+				config.OnProgress(ContextAuditProgress{
+					ResourceType:           resourceType,
+					CompletedResourceTypes: completed,
+					TotalResourceTypes:     len(nsDefs),
+					RelationshipsScanned:   report.RelationshipsScanned,
+				})
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(report.Incompatibilities, func(i, j int) bool {
+		if report.Incompatibilities[i].Relationship == report.Incompatibilities[j].Relationship {
+			return report.Incompatibilities[i].ParameterName < report.Incompatibilities[j].ParameterName
+		}
+		return report.Incompatibilities[i].Relationship < report.Incompatibilities[j].Relationship
+	})
+	return report, nil
+}
+
+func auditResourceType(ctx context.Context, reader datastore.Reader, resourceType string, caveatsByName map[string]*core.CaveatDefinition) (*ContextAuditReport, error) {
+	iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: resourceType})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	report := &ContextAuditReport{}
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		report.RelationshipsScanned++
+		if tpl.Caveat == nil || tpl.Caveat.CaveatName == "" {
+			continue
+		}
+
+		report.CaveatedRelationships++
+		if incompatibility := validateStoredContext(tpl, caveatsByName); incompatibility != nil {
+			report.Incompatibilities = append(report.Incompatibilities, *incompatibility)
+		}
+	}
+
+	if iter.Err() != nil {
+		return nil, iter.Err()
+	}
+
+	return report, nil
+}
+
+func validateStoredContext(tpl *core.RelationTuple, caveatsByName map[string]*core.CaveatDefinition) *ContextIncompatibility {
+	incompatibility := &ContextIncompatibility{
+		Relationship: tuple.StringWithoutCaveat(tpl),
+		CaveatName:   tpl.Caveat.CaveatName,
+	}
+
+	caveatDef, ok := caveatsByName[tpl.Caveat.CaveatName]
+	if !ok {
+		incompatibility.Reason = fmt.Sprintf("caveat `%s` not found", tpl.Caveat.CaveatName)
+		return incompatibility
+	}
+
+	_, err := caveats.ConvertContextToParameters(
+		tpl.Caveat.Context.AsMap(),
+		caveatDef.ParameterTypes,
+		caveats.ErrorForUnknownParameters,
+	)
+	if err == nil {
+		return nil
+	}
+
+	var conversionErr caveats.ParameterConversionErr
+	if errors.As(err, &conversionErr) {
+		incompatibility.ParameterName = conversionErr.DetailsMetadata()["parameter_name"]
+	}
+
+	incompatibility.Reason = err.Error()
+	return incompatibility
+}
@@ -0,0 +1,61 @@
+package relationships
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestAuditStoredCaveatContexts(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+
+	ds, _ := testfixtures.StandardDatastoreWithCaveatedData(rawDS, require)
+
+	// Write relationships with incompatible contexts directly, bypassing validation.
+	revision, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE,
+		tuple.MustParse(`document:badtype#caveated_viewer@user:tom[test:{"expectedSecret":42}]`),
+		tuple.MustParse(`document:unknownparam#caveated_viewer@user:tom[test:{"unknownParam":"hi"}]`),
+		tuple.MustParse(`document:missingcaveat#caveated_viewer@user:tom[missing]`),
+	)
+	require.NoError(err)
+
+	var progress []ContextAuditProgress
+	report, err := AuditStoredCaveatContexts(ctx, ds.SnapshotReader(revision), ContextAuditConfig{
+		Concurrency: 2,
+		OnProgress: func(p ContextAuditProgress) {
+			progress = append(progress, p)
+		},
+	})
+	require.NoError(err)
+
+	expectedCount := uint64(len(testfixtures.StandardTuples) + 3)
+	require.Equal(expectedCount, report.RelationshipsScanned)
+	require.Equal(expectedCount, report.CaveatedRelationships)
+
+	require.Len(report.Incompatibilities, 3)
+	require.Equal("document:badtype#caveated_viewer@user:tom", report.Incompatibilities[0].Relationship)
+	require.Equal("expectedSecret", report.Incompatibilities[0].ParameterName)
+	require.Contains(report.Incompatibilities[0].Reason, "could not convert context parameter `expectedSecret`")
+
+	require.Equal("document:missingcaveat#caveated_viewer@user:tom", report.Incompatibilities[1].Relationship)
+	require.Equal("missing", report.Incompatibilities[1].CaveatName)
+	require.Equal("caveat `missing` not found", report.Incompatibilities[1].Reason)
+
+	require.Equal("document:unknownparam#caveated_viewer@user:tom", report.Incompatibilities[2].Relationship)
+	require.Equal("unknown parameter `unknownParam`", report.Incompatibilities[2].Reason)
+
+	require.Len(progress, report.ResourceTypesScanned)
+	require.Equal(report.ResourceTypesScanned, progress[len(progress)-1].CompletedResourceTypes)
+	require.Equal(expectedCount, progress[len(progress)-1].RelationshipsScanned)
+}
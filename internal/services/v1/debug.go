@@ -9,6 +9,7 @@ import (
 	"google.golang.org/protobuf/types/known/structpb"
 
 	cexpr "github.com/authzed/spicedb/internal/caveats"
+	"github.com/authzed/spicedb/pkg/caveats"
 	"github.com/authzed/spicedb/pkg/datastore"
 	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
@@ -17,12 +18,13 @@ import (
 )
 
 // convertCheckDispatchDebugInformation converts dispatch debug information found in the response metadata
-// into DebugInformation returnable to the API.
+// into DebugInformation returnable to the API, with the given mask applied to the caveat context values.
 func convertCheckDispatchDebugInformation(
 	ctx context.Context,
 	caveatContext map[string]any,
 	metadata *dispatch.ResponseMeta,
 	reader datastore.Reader,
+	contextMask *caveats.ContextMask,
 ) (*v1.DebugInformation, error) {
 	debugInfo := metadata.DebugInfo
 	if debugInfo == nil {
@@ -52,7 +54,7 @@ func convertCheckDispatchDebugInformation(
 		return nil, err
 	}
 
-	converted, err := convertCheckTrace(ctx, caveatContext, debugInfo.Check, reader)
+	converted, err := convertCheckTrace(ctx, caveatContext, debugInfo.Check, reader, contextMask)
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +65,7 @@ func convertCheckDispatchDebugInformation(
 	}, nil
 }
 
-func convertCheckTrace(ctx context.Context, caveatContext map[string]any, ct *dispatch.CheckDebugTrace, reader datastore.Reader) (*v1.CheckDebugTrace, error) {
+func convertCheckTrace(ctx context.Context, caveatContext map[string]any, ct *dispatch.CheckDebugTrace, reader datastore.Reader, contextMask *caveats.ContextMask) (*v1.CheckDebugTrace, error) {
 	permissionType := v1.CheckDebugTrace_PERMISSION_TYPE_UNSPECIFIED
 	if ct.ResourceRelationType == dispatch.CheckDebugTrace_PERMISSION {
 		permissionType = v1.CheckDebugTrace_PERMISSION_TYPE_PERMISSION
@@ -110,7 +112,7 @@ func convertCheckTrace(ctx context.Context, caveatContext map[string]any, ct *di
 			}
 		}
 
-		contextStruct, err := structpb.NewStruct(computedResult.ContextValues())
+		contextStruct, err := structpb.NewStruct(contextMask.Apply(computedResult.ContextValues()))
 		if err != nil {
 			return nil, err
 		}
@@ -137,7 +139,7 @@ func convertCheckTrace(ctx context.Context, caveatContext map[string]any, ct *di
 	if len(ct.SubProblems) > 0 {
 		subProblems := make([]*v1.CheckDebugTrace, 0, len(ct.SubProblems))
 		for _, subProblem := range ct.SubProblems {
-			converted, err := convertCheckTrace(ctx, caveatContext, subProblem, reader)
+			converted, err := convertCheckTrace(ctx, caveatContext, subProblem, reader, contextMask)
 			if err != nil {
 				return nil, err
 			}
@@ -475,3 +475,68 @@ func TestCheckPermissionWithDebug(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckPermissionWithMaskedDebug(t *testing.T) {
+	req := require.New(t)
+	conn, cleanup, _, revision := testserver.NewTestServerWithConfig(req, testTimedeltas[0], memdb.DisableGC, true,
+		testserver.ServerConfig{
+			MaxUpdatesPerWrite:          1000,
+			MaxPreconditionsCount:       1000,
+			DebugMaskedCaveatParameters: []string{"secret"},
+		},
+		func(ds datastore.Datastore, require *require.Assertions) (datastore.Datastore, datastore.Revision) {
+			return tf.DatastoreFromSchemaAndTestRelationships(ds, `definition user {}
+
+			caveat somecaveat(secret string, somecondition int) {
+				secret == "sesame" && somecondition == 42
+			}
+
+			definition document {
+				relation viewer: user with somecaveat
+				permission view = viewer
+			}
+			`, []*core.RelationTuple{
+				tuple.MustParse("document:first#viewer@user:sarah[somecaveat]"),
+			}, req)
+		})
+
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	ctx := requestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestDebugInformation)
+
+	caveatContext, err := structpb.NewStruct(map[string]any{
+		"secret":        "sesame",
+		"somecondition": 42,
+	})
+	req.NoError(err)
+
+	var trailer metadata.MD
+	checkResp, err := client.CheckPermission(ctx, &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtLeastAsFresh{
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+			},
+		},
+		Resource:   obj("document", "first"),
+		Permission: "view",
+		Subject:    sub("user", "sarah", ""),
+		Context:    caveatContext,
+	}, grpc.Trailer(&trailer))
+	req.NoError(err)
+
+	// The masked value must still be used for evaluation.
+	req.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, checkResp.Permissionship)
+
+	encodedDebugInfo, err := responsemeta.GetResponseTrailerMetadataOrNil(trailer, responsemeta.DebugInformation)
+	req.NoError(err)
+	req.NotNil(encodedDebugInfo)
+	req.NotContains(*encodedDebugInfo, "sesame")
+
+	debugInfo := &v1.DebugInformation{}
+	req.NoError(protojson.Unmarshal([]byte(*encodedDebugInfo), debugInfo))
+
+	renderedContext := debugInfo.Check.CaveatEvaluationInfo.Context.AsMap()
+	req.Equal("***", renderedContext["secret"])
+	req.Equal(float64(42), renderedContext["somecondition"])
+}
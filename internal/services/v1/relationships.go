@@ -17,6 +17,7 @@ import (
 	"github.com/authzed/spicedb/internal/namespace"
 	"github.com/authzed/spicedb/internal/relationships"
 	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/caveats"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/middleware/consistency"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
@@ -38,6 +39,10 @@ type PermissionsServerConfig struct {
 	// MaximumAPIDepth is the default/starting depth remaining for API calls made
 	// to the permissions server.
 	MaximumAPIDepth uint32
+
+	// DebugContextMask is the mask applied to the caveat context values rendered
+	// in debug information. It does not affect the values used for evaluation.
+	DebugContextMask *caveats.ContextMask
 }
 
 // NewPermissionsServer creates a PermissionsServiceServer instance.
@@ -50,6 +55,7 @@ func NewPermissionsServer(
 		MaxPreconditionsCount: defaultIfZero(config.MaxPreconditionsCount, 1000),
 		MaxUpdatesPerWrite:    defaultIfZero(config.MaxUpdatesPerWrite, 1000),
 		MaximumAPIDepth:       defaultIfZero(config.MaximumAPIDepth, 50),
+		DebugContextMask:      config.DebugContextMask,
 	}
 
 	return &permissionServer{
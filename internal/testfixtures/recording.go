@@ -0,0 +1,256 @@
+package testfixtures
+
+import (
+	"context"
+	"sync"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/options"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// CallKind is the kind of a datastore call recorded by a RecordingDatastore.
+type CallKind string
+
+const (
+	CallQueryRelationships        CallKind = "QueryRelationships"
+	CallReverseQueryRelationships CallKind = "ReverseQueryRelationships"
+	CallReadNamespace             CallKind = "ReadNamespace"
+	CallListNamespaces            CallKind = "ListNamespaces"
+	CallLookupNamespaces          CallKind = "LookupNamespaces"
+	CallReadCaveatByName          CallKind = "ReadCaveatByName"
+	CallListCaveats               CallKind = "ListCaveats"
+	CallOptimizedRevision         CallKind = "OptimizedRevision"
+	CallHeadRevision              CallKind = "HeadRevision"
+	CallCheckRevision             CallKind = "CheckRevision"
+)
+
+// RecordedCall is a read or revision request made to a RecordingDatastore.
+type RecordedCall struct {
+	// Kind is the kind of the call.
+	Kind CallKind
+
+	// Revision is the revision of the snapshot read, or that checked by CheckRevision. It is nil
+	// for reads made within a read-write transaction, and for the other revision requests.
+	Revision datastore.Revision
+
+	// Names are the names of the namespaces or caveats read, if any.
+	Names []string
+
+	// RelationshipsFilter is the filter of a QueryRelationships call.
+	RelationshipsFilter *datastore.RelationshipsFilter
+
+	// SubjectsFilter is the filter of a ReverseQueryRelationships call.
+	SubjectsFilter *datastore.SubjectsFilter
+}
+
+// RecordingDatastore is a proxy which records the read and revision requests made to the
+// delegate datastore, for tests asserting on the queries issued by their callers. It is
+// otherwise transparent: the results of the delegate are returned unchanged.
+type RecordingDatastore struct {
+	datastore.Datastore
+
+	lock  sync.Mutex
+	calls []RecordedCall
+}
+
+// NewRecordingDatastore creates a proxy which records the reads made to the delegate datastore.
+func NewRecordingDatastore(delegate datastore.Datastore) *RecordingDatastore {
+	return &RecordingDatastore{Datastore: delegate}
+}
+
+// Calls returns the calls recorded, in the order in which they were made.
+func (rd *RecordingDatastore) Calls() []RecordedCall {
+	rd.lock.Lock()
+	defer rd.lock.Unlock()
+
+	calls := make([]RecordedCall, len(rd.calls))
+	copy(calls, rd.calls)
+	return calls
+}
+
+// CallsOfKind returns the calls of the given kind recorded, in the order in which they were made.
+func (rd *RecordingDatastore) CallsOfKind(kind CallKind) []RecordedCall {
+	return rd.CallsMatching(func(call RecordedCall) bool {
+		return call.Kind == kind
+	})
+}
+
+// CallsMatching returns the calls recorded for which the matcher returns true.
+func (rd *RecordingDatastore) CallsMatching(matcher func(RecordedCall) bool) []RecordedCall {
+	var matching []RecordedCall
+	for _, call := range rd.Calls() {
+		if matcher(call) {
+			matching = append(matching, call)
+		}
+	}
+	return matching
+}
+
+// RelationshipQueriesMatching returns the QueryRelationships calls recorded whose filter matches.
+func (rd *RecordingDatastore) RelationshipQueriesMatching(matcher func(datastore.RelationshipsFilter) bool) []RecordedCall {
+	return rd.CallsMatching(func(call RecordedCall) bool {
+		return call.Kind == CallQueryRelationships && matcher(*call.RelationshipsFilter)
+	})
+}
+
+// Reset clears the calls recorded.
+func (rd *RecordingDatastore) Reset() {
+	rd.lock.Lock()
+	defer rd.lock.Unlock()
+
+	rd.calls = nil
+}
+
+// RequireCallCount fails the test if the number of calls of the given kind recorded is not that
+// expected.
+func (rd *RecordingDatastore) RequireCallCount(t require.TestingT, kind CallKind, expected int) {
+	require.Len(t, rd.CallsOfKind(kind), expected, "unexpected number of %s calls", kind)
+}
+
+// RequireRelationshipQuery fails the test if no QueryRelationships call with the given filter
+// has been recorded.
+func (rd *RecordingDatastore) RequireRelationshipQuery(t require.TestingT, filter datastore.RelationshipsFilter) {
+	var filters []datastore.RelationshipsFilter
+	for _, call := range rd.CallsOfKind(CallQueryRelationships) {
+		filters = append(filters, *call.RelationshipsFilter)
+	}
+	require.Contains(t, filters, filter, "no relationships query with the expected filter")
+}
+
+func (rd *RecordingDatastore) record(call RecordedCall) {
+	rd.lock.Lock()
+	defer rd.lock.Unlock()
+
+	rd.calls = append(rd.calls, call)
+}
+
+func (rd *RecordingDatastore) SnapshotReader(revision datastore.Revision) datastore.Reader {
+	return recordingReader{rd.Datastore.SnapshotReader(revision), rd, revision}
+}
+
+func (rd *RecordingDatastore) ReadWriteTx(
+	ctx context.Context,
+	f datastore.TxUserFunc,
+) (datastore.Revision, error) {
+	return rd.Datastore.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return f(recordingReadWriteTransaction{rwt, recordingReader{rwt, rd, nil}})
+	})
+}
+
+func (rd *RecordingDatastore) OptimizedRevision(ctx context.Context) (datastore.Revision, error) {
+	rd.record(RecordedCall{Kind: CallOptimizedRevision})
+	return rd.Datastore.OptimizedRevision(ctx)
+}
+
+func (rd *RecordingDatastore) HeadRevision(ctx context.Context) (datastore.Revision, error) {
+	rd.record(RecordedCall{Kind: CallHeadRevision})
+	return rd.Datastore.HeadRevision(ctx)
+}
+
+func (rd *RecordingDatastore) CheckRevision(ctx context.Context, revision datastore.Revision) error {
+	rd.record(RecordedCall{Kind: CallCheckRevision, Revision: revision})
+	return rd.Datastore.CheckRevision(ctx, revision)
+}
+
+type recordingReader struct {
+	delegate datastore.Reader
+	recorder *RecordingDatastore
+	revision datastore.Revision
+}
+
+func (rr recordingReader) QueryRelationships(ctx context.Context,
+	filter datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	rr.recorder.record(RecordedCall{Kind: CallQueryRelationships, Revision: rr.revision, RelationshipsFilter: &filter})
+	return rr.delegate.QueryRelationships(ctx, filter, opts...)
+}
+
+func (rr recordingReader) ReverseQueryRelationships(ctx context.Context,
+	subjectsFilter datastore.SubjectsFilter,
+	opts ...options.ReverseQueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	rr.recorder.record(RecordedCall{Kind: CallReverseQueryRelationships, Revision: rr.revision, SubjectsFilter: &subjectsFilter})
+	return rr.delegate.ReverseQueryRelationships(ctx, subjectsFilter, opts...)
+}
+
+func (rr recordingReader) ReadNamespace(
+	ctx context.Context,
+	nsName string,
+) (*core.NamespaceDefinition, datastore.Revision, error) {
+	rr.recorder.record(RecordedCall{Kind: CallReadNamespace, Revision: rr.revision, Names: []string{nsName}})
+	return rr.delegate.ReadNamespace(ctx, nsName)
+}
+
+func (rr recordingReader) ListNamespaces(ctx context.Context) ([]*core.NamespaceDefinition, error) {
+	rr.recorder.record(RecordedCall{Kind: CallListNamespaces, Revision: rr.revision})
+	return rr.delegate.ListNamespaces(ctx)
+}
+
+func (rr recordingReader) LookupNamespaces(ctx context.Context, nsNames []string) ([]*core.NamespaceDefinition, error) {
+	rr.recorder.record(RecordedCall{Kind: CallLookupNamespaces, Revision: rr.revision, Names: nsNames})
+	return rr.delegate.LookupNamespaces(ctx, nsNames)
+}
+
+func (rr recordingReader) ReadCaveatByName(ctx context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
+	rr.recorder.record(RecordedCall{Kind: CallReadCaveatByName, Revision: rr.revision, Names: []string{name}})
+	return rr.delegate.ReadCaveatByName(ctx, name)
+}
+
+func (rr recordingReader) ListCaveats(ctx context.Context, caveatNames ...string) ([]*core.CaveatDefinition, error) {
+	rr.recorder.record(RecordedCall{Kind: CallListCaveats, Revision: rr.revision, Names: caveatNames})
+	return rr.delegate.ListCaveats(ctx, caveatNames...)
+}
+
+// recordingReadWriteTransaction records the reads made within a read-write transaction, and
+// passes the writes onward.
+type recordingReadWriteTransaction struct {
+	datastore.ReadWriteTransaction
+	reader recordingReader
+}
+
+func (rrwt recordingReadWriteTransaction) QueryRelationships(ctx context.Context,
+	filter datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	return rrwt.reader.QueryRelationships(ctx, filter, opts...)
+}
+
+func (rrwt recordingReadWriteTransaction) ReverseQueryRelationships(ctx context.Context,
+	subjectsFilter datastore.SubjectsFilter,
+	opts ...options.ReverseQueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	return rrwt.reader.ReverseQueryRelationships(ctx, subjectsFilter, opts...)
+}
+
+func (rrwt recordingReadWriteTransaction) ReadNamespace(
+	ctx context.Context,
+	nsName string,
+) (*core.NamespaceDefinition, datastore.Revision, error) {
+	return rrwt.reader.ReadNamespace(ctx, nsName)
+}
+
+func (rrwt recordingReadWriteTransaction) ListNamespaces(ctx context.Context) ([]*core.NamespaceDefinition, error) {
+	return rrwt.reader.ListNamespaces(ctx)
+}
+
+func (rrwt recordingReadWriteTransaction) LookupNamespaces(ctx context.Context, nsNames []string) ([]*core.NamespaceDefinition, error) {
+	return rrwt.reader.LookupNamespaces(ctx, nsNames)
+}
+
+func (rrwt recordingReadWriteTransaction) ReadCaveatByName(ctx context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
+	return rrwt.reader.ReadCaveatByName(ctx, name)
+}
+
+func (rrwt recordingReadWriteTransaction) ListCaveats(ctx context.Context, caveatNames ...string) ([]*core.CaveatDefinition, error) {
+	return rrwt.reader.ListCaveats(ctx, caveatNames...)
+}
+
+var (
+	_ datastore.Datastore            = &RecordingDatastore{}
+	_ datastore.Reader               = recordingReader{}
+	_ datastore.ReadWriteTransaction = recordingReadWriteTransaction{}
+)
@@ -0,0 +1,64 @@
+package testfixtures
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+func TestRecordingDatastore(t *testing.T) {
+	require := require.New(t)
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+
+	ds, revision := StandardDatastoreWithData(rawDS, require)
+	recording := NewRecordingDatastore(ds)
+	ctx := context.Background()
+
+	_, err = recording.HeadRevision(ctx)
+	require.NoError(err)
+
+	reader := recording.SnapshotReader(revision)
+	_, _, err = reader.ReadNamespace(ctx, "document")
+	require.NoError(err)
+
+	filter := datastore.RelationshipsFilter{
+		ResourceType:             "document",
+		OptionalResourceIds:      []string{"masterplan"},
+		OptionalResourceRelation: "viewer",
+	}
+	iter, err := reader.QueryRelationships(ctx, filter)
+	require.NoError(err)
+	iter.Close()
+
+	_, err = recording.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.ListNamespaces(ctx)
+		return err
+	})
+	require.NoError(err)
+
+	recording.RequireCallCount(t, CallHeadRevision, 1)
+	recording.RequireCallCount(t, CallReadNamespace, 1)
+	recording.RequireCallCount(t, CallQueryRelationships, 1)
+	recording.RequireCallCount(t, CallListNamespaces, 1)
+	recording.RequireRelationshipQuery(t, filter)
+
+	calls := recording.Calls()
+	require.Len(calls, 4)
+	require.Equal([]string{"document"}, calls[1].Names)
+	require.True(revision.Equal(calls[1].Revision))
+	require.Nil(calls[3].Revision)
+
+	matching := recording.RelationshipQueriesMatching(func(filter datastore.RelationshipsFilter) bool {
+		return filter.ResourceType == "folder"
+	})
+	require.Empty(matching)
+
+	recording.Reset()
+	require.Empty(recording.Calls())
+}
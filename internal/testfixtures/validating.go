@@ -191,6 +191,45 @@ func (vrwt validatingReadWriteTransaction) WriteRelationships(ctx context.Contex
 	return vrwt.delegate.WriteRelationships(ctx, mutations)
 }
 
+func (vrwt validatingReadWriteTransaction) BulkLoad(ctx context.Context, iter datastore.RelationshipIterator) (uint64, error) {
+	return vrwt.delegate.BulkLoad(ctx, &validatingRelationshipIterator{delegate: iter})
+}
+
+// validatingRelationshipIterator validates the relationships of the delegate iterator as they are
+// read, ending the iteration with an error at the first invalid relationship.
+type validatingRelationshipIterator struct {
+	delegate datastore.RelationshipIterator
+	err      error
+}
+
+func (vri *validatingRelationshipIterator) Next() *core.RelationTuple {
+	if vri.err != nil {
+		return nil
+	}
+
+	rel := vri.delegate.Next()
+	if rel == nil {
+		return nil
+	}
+
+	if err := validateUpdatesToWrite(tuple.Create(rel)); err != nil {
+		vri.err = err
+		return nil
+	}
+	return rel
+}
+
+func (vri *validatingRelationshipIterator) Err() error {
+	if vri.err != nil {
+		return vri.err
+	}
+	return vri.delegate.Err()
+}
+
+func (vri *validatingRelationshipIterator) Close() {
+	vri.delegate.Close()
+}
+
 func (vrwt validatingReadWriteTransaction) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
 	if err := filter.Validate(); err != nil {
 		return err
@@ -199,6 +238,14 @@ func (vrwt validatingReadWriteTransaction) DeleteRelationships(ctx context.Conte
 	return vrwt.delegate.DeleteRelationships(ctx, filter)
 }
 
+func (vrwt validatingReadWriteTransaction) BulkDeleteRelationships(ctx context.Context, filter datastore.RelationshipsFilter, limit uint64) (uint64, error) {
+	if filter.ResourceType == "" {
+		return 0, fmt.Errorf("missing resource type in bulk delete filter")
+	}
+
+	return vrwt.delegate.BulkDeleteRelationships(ctx, filter, limit)
+}
+
 func (vrwt validatingReadWriteTransaction) WriteCaveats(ctx context.Context, caveats []*core.CaveatDefinition) error {
 	return vrwt.delegate.WriteCaveats(ctx, caveats)
 }
@@ -0,0 +1,44 @@
+//go:build docker
+// +build docker
+
+package datastore
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// containerLogs returns the stdout and stderr output of the container, or a note explaining why
+// the output could not be retrieved.
+func containerLogs(pool *dockertest.Pool, resource *dockertest.Resource) string {
+	var stdout, stderr bytes.Buffer
+	err := pool.Client.Logs(docker.LogsOptions{
+		Container:    resource.Container.ID,
+		OutputStream: &stdout,
+		ErrorStream:  &stderr,
+		Stdout:       true,
+		Stderr:       true,
+	})
+	if err != nil {
+		return fmt.Sprintf("<could not retrieve container logs: %s>", err)
+	}
+
+	return fmt.Sprintf("--- stdout ---\n%s\n--- stderr ---\n%s", stdout.String(), stderr.String())
+}
+
+// logContainerOnFailure logs the output of the container should the test have failed.
+func logContainerOnFailure(t testing.TB, pool *dockertest.Pool, resource *dockertest.Resource) {
+	if t.Failed() {
+		t.Logf("logs of container %s:\n%s", resource.Container.Name, containerLogs(pool, resource))
+	}
+}
+
+// purgeContainer purges the container, first logging its output should the test have failed.
+func purgeContainer(t testing.TB, pool *dockertest.Pool, resource *dockertest.Resource) error {
+	logContainerOnFailure(t, pool, resource)
+	return pool.Purge(resource)
+}
@@ -23,36 +23,90 @@ const (
 	CRDBTestVersionTag = "v22.2.0"
 
 	enableRangefeeds = `SET CLUSTER SETTING kv.rangefeed.enabled = true;`
+	setFollowerReads = `SET CLUSTER SETTING kv.closed_timestamp.follower_reads_enabled = %t;`
 )
 
 type crdbTester struct {
-	conn     *pgx.Conn
-	hostname string
-	creds    string
-	port     string
+	conn            *pgx.Conn
+	hostname        string
+	creds           string
+	port            string
+	targetMigration string
+}
+
+// CockroachTestOptions are the options for the CockroachDB node run for testing.
+type CockroachTestOptions struct {
+	// VersionTag is the tag of the cockroachdb/cockroach image to run.
+	VersionTag string
+
+	// Insecure starts the node with `--insecure`. A secure node requires certificates, which
+	// must be given via ExtraFlags.
+	Insecure bool
+
+	// EnableRangefeeds enables rangefeeds, which are required by the Watch API.
+	EnableRangefeeds bool
+
+	// EnableFollowerReads enables follower reads, used for queries with a follower read delay.
+	EnableFollowerReads bool
+
+	// ExtraFlags are additional flags given to `cockroach start-single-node`.
+	ExtraFlags []string
+}
+
+// DefaultCockroachTestOptions are the options used by RunCockroachForTesting.
+var DefaultCockroachTestOptions = CockroachTestOptions{
+	VersionTag:          CRDBTestVersionTag,
+	Insecure:            true,
+	EnableRangefeeds:    true,
+	EnableFollowerReads: true,
 }
 
 // RunCRDBForTesting returns a RunningEngineForTest for CRDB
 func RunCRDBForTesting(t testing.TB, bridgeNetworkName string) RunningEngineForTest {
+	return RunCockroachForTesting(t, bridgeNetworkName, migrate.Head)
+}
+
+// RunCockroachForTesting returns a RunningEngineForTest for CRDB, whose datastores are migrated
+// to the target migration.
+func RunCockroachForTesting(t testing.TB, bridgeNetworkName string, targetMigration string) RunningEngineForTest {
+	return RunCockroachForTestingWithOptions(t, bridgeNetworkName, targetMigration, DefaultCockroachTestOptions)
+}
+
+// RunCockroachForTestingWithOptions returns a RunningEngineForTest for CRDB, running the node
+// with the given options, whose datastores are migrated to the target migration.
+func RunCockroachForTestingWithOptions(t testing.TB, bridgeNetworkName string, targetMigration string, options CockroachTestOptions) RunningEngineForTest {
 	pool, err := dockertest.NewPool("")
 	require.NoError(t, err)
 
+	tag := options.VersionTag
+	if tag == "" {
+		tag = CRDBTestVersionTag
+	}
+
+	cmd := []string{"start-single-node"}
+	if options.Insecure {
+		cmd = append(cmd, "--insecure")
+	}
+	cmd = append(cmd, "--max-offset=50ms")
+	cmd = append(cmd, options.ExtraFlags...)
+
 	name := fmt.Sprintf("crds-%s", uuid.New().String())
 	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
 		Name:       name,
 		Repository: "cockroachdb/cockroach",
-		Tag:        CRDBTestVersionTag,
-		Cmd:        []string{"start-single-node", "--insecure", "--max-offset=50ms"},
+		Tag:        tag,
+		Cmd:        cmd,
 		NetworkID:  bridgeNetworkName,
 	})
 	require.NoError(t, err)
 
 	builder := &crdbTester{
-		hostname: "localhost",
-		creds:    "root:fake",
+		hostname:        "localhost",
+		creds:           "root:fake",
+		targetMigration: targetMigration,
 	}
 	t.Cleanup(func() {
-		require.NoError(t, pool.Purge(resource))
+		require.NoError(t, purgeContainer(t, pool, resource))
 	})
 
 	port := resource.GetPort(fmt.Sprintf("%d/tcp", 26257))
@@ -63,8 +117,14 @@ func RunCRDBForTesting(t testing.TB, bridgeNetworkName string) RunningEngineForT
 		builder.port = port
 	}
 
+	var settings []string
+	if options.EnableRangefeeds {
+		settings = append(settings, enableRangefeeds)
+	}
+	settings = append(settings, fmt.Sprintf(setFollowerReads, options.EnableFollowerReads))
+
 	uri := fmt.Sprintf("postgres://%s@localhost:%s/defaultdb?sslmode=disable", builder.creds, port)
-	require.NoError(t, pool.Retry(func() error {
+	require.NoError(t, waitForBoot(t, pool, resource, name, func() error {
 		var err error
 		ctx, cancelConnect := context.WithTimeout(context.Background(), dockerBootTimeout)
 		defer cancelConnect()
@@ -72,10 +132,16 @@ func RunCRDBForTesting(t testing.TB, bridgeNetworkName string) RunningEngineForT
 		if err != nil {
 			return err
 		}
-		ctx, cancelRangeFeeds := context.WithTimeout(context.Background(), dockerBootTimeout)
-		defer cancelRangeFeeds()
-		_, err = builder.conn.Exec(ctx, enableRangefeeds)
-		return err
+
+		for _, setting := range settings {
+			ctx, cancelSetting := context.WithTimeout(context.Background(), dockerBootTimeout)
+			_, err = builder.conn.Exec(ctx, setting)
+			cancelSetting()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	}))
 
 	return builder
@@ -105,7 +171,7 @@ func (r *crdbTester) NewDatastore(t testing.TB, initFunc InitFunc) datastore.Dat
 
 	migrationDriver, err := crdbmigrations.NewCRDBDriver(connectStr)
 	require.NoError(t, err)
-	require.NoError(t, crdbmigrations.CRDBMigrations.Run(context.Background(), migrationDriver, migrate.Head, migrate.LiveRun))
+	require.NoError(t, crdbmigrations.CRDBMigrations.Run(context.Background(), migrationDriver, r.targetMigration, migrate.LiveRun))
 
 	return initFunc("cockroachdb", connectStr)
 }
@@ -4,16 +4,57 @@
 package datastore
 
 import (
+	"fmt"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ory/dockertest/v3"
 	"github.com/stretchr/testify/require"
 
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/migrate"
 )
 
-const dockerBootTimeout = 10 * time.Second
+// DockerBootTimeoutEnvVar is the environment variable which, if set to a duration such as `30s`,
+// overrides the timeout of each attempt to connect to a test server container while it boots.
+const DockerBootTimeoutEnvVar = "SPICEDB_TEST_DOCKER_BOOT_TIMEOUT"
+
+const defaultDockerBootTimeout = 10 * time.Second
+
+var dockerBootTimeout = bootTimeoutFromEnv()
+
+func bootTimeoutFromEnv() time.Duration {
+	value, ok := os.LookupEnv(DockerBootTimeoutEnvVar)
+	if !ok || value == "" {
+		return defaultDockerBootTimeout
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		panic(fmt.Sprintf("invalid value `%s` for %s: a positive duration is required", value, DockerBootTimeoutEnvVar))
+	}
+	return timeout
+}
+
+// waitForBoot retries the operation against the container until it succeeds or the pool gives
+// up, logging the time spent waiting should the container never become ready. The output of the
+// container is attached to the error returned, to diagnose why it never became ready.
+func waitForBoot(t testing.TB, pool *dockertest.Pool, resource *dockertest.Resource, name string, operation func() error) error {
+	// Ensure a raised boot timeout is not cut short by the pool's overall retry budget.
+	if pool.MaxWait < 2*dockerBootTimeout {
+		pool.MaxWait = 2 * dockerBootTimeout
+	}
+
+	start := time.Now()
+	err := pool.Retry(operation)
+	if err != nil {
+		t.Logf("container %s was not ready after %s (boot timeout %s per attempt): %s", name, time.Since(start), dockerBootTimeout, err)
+		return fmt.Errorf("container %s failed to become ready: %w\ncontainer logs:\n%s", name, err, containerLogs(pool, resource))
+	}
+	return nil
+}
 
 // InitFunc initializes a datastore instance from a uri that has been
 // generated from a TestDatastoreBuilder
@@ -55,20 +96,33 @@ func RunDatastoreEngine(t testing.TB, engine string) RunningEngineForTest {
 // specified, then the hostnames returned by the engines are those to be called from another
 // container on the bridge.
 func RunDatastoreEngineWithBridge(t testing.TB, engine string, bridgeNetworkName string) RunningEngineForTest {
+	rde, err := RunDatastoreEngineForTesting(t, engine, bridgeNetworkName, migrate.Head)
+	require.NoError(t, err)
+	return rde
+}
+
+// SupportedEngines are the names of the datastore engines which can be run for testing.
+var SupportedEngines = []string{"cockroachdb", "memory", "mysql", "postgres", "spanner"}
+
+// RunDatastoreEngineForTesting runs the datastore engine with the given name on a specific
+// bridge, whose datastores are migrated to the target migration. Returns an error if the engine
+// is unknown, or if it does not support the options given.
+func RunDatastoreEngineForTesting(t testing.TB, engine string, bridgeNetworkName string, targetMigration string) (RunningEngineForTest, error) {
 	switch engine {
 	case "memory":
-		require.Equal(t, "", bridgeNetworkName, "memory datastore does not support bridge networking")
-		return RunMemoryForTesting(t)
+		if bridgeNetworkName != "" {
+			return nil, fmt.Errorf("memory datastore does not support bridge networking")
+		}
+		return RunMemoryForTesting(t), nil
 	case "cockroachdb":
-		return RunCRDBForTesting(t, bridgeNetworkName)
+		return RunCockroachForTesting(t, bridgeNetworkName, targetMigration), nil
 	case "postgres":
-		return RunPostgresForTesting(t, bridgeNetworkName, migrate.Head)
+		return RunPostgresForTesting(t, bridgeNetworkName, targetMigration), nil
 	case "mysql":
-		return RunMySQLForTesting(t, bridgeNetworkName)
+		return RunMySQLForTesting(t, bridgeNetworkName, targetMigration), nil
 	case "spanner":
-		return RunSpannerForTesting(t, bridgeNetworkName)
+		return RunSpannerForTesting(t, bridgeNetworkName, targetMigration), nil
 	default:
-		t.Fatalf("found missing engine for RunDatastoreEngine: %s", engine)
-		return nil
+		return nil, fmt.Errorf("unknown datastore engine `%s`: supported engines are %s", engine, strings.Join(SupportedEngines, ", "))
 	}
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/authzed/spicedb/internal/datastore/mysql/migrations"
+	"github.com/authzed/spicedb/internal/datastore/mysql/version"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/migrate"
 	"github.com/authzed/spicedb/pkg/secrets"
@@ -37,12 +38,25 @@ type mysqlTester struct {
 type MySQLTesterOptions struct {
 	Prefix                 string
 	MigrateForNewDatastore bool
+
+	// TargetMigration is the migration to which new datastores are migrated. Defaults to the
+	// head migration if empty.
+	TargetMigration string
+
+	// ExtraServerFlags are passed to the MySQL server on startup, in addition to the defaults,
+	// e.g. `--gtid-mode=ON` or `--default-time-zone=+02:00`.
+	ExtraServerFlags []string
 }
 
 // RunMySQLForTesting returns a RunningEngineForTest for the mysql driver
-// backed by a MySQL instance with RunningEngineForTest options - no prefix is added, and datastore migration is run.
-func RunMySQLForTesting(t testing.TB, bridgeNetworkName string) RunningEngineForTest {
-	return RunMySQLForTestingWithOptions(t, MySQLTesterOptions{Prefix: "", MigrateForNewDatastore: true}, bridgeNetworkName)
+// backed by a MySQL instance with RunningEngineForTest options - no prefix is added, and datastore
+// migration is run up to the target migration.
+func RunMySQLForTesting(t testing.TB, bridgeNetworkName string, targetMigration string) RunningEngineForTest {
+	return RunMySQLForTestingWithOptions(t, MySQLTesterOptions{
+		Prefix:                 "",
+		MigrateForNewDatastore: true,
+		TargetMigration:        targetMigration,
+	}, bridgeNetworkName)
 }
 
 // RunMySQLForTestingWithOptions returns a RunningEngineForTest for the mysql driver
@@ -55,11 +69,11 @@ func RunMySQLForTestingWithOptions(t testing.TB, options MySQLTesterOptions, bri
 	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
 		Name:       name,
 		Repository: "mysql",
-		Tag:        "5",
+		Tag:        version.MinimumSupportedMySQLVersion,
 		Platform:   "linux/amd64", // required because the mysql:5 image does not have arm support
 		Env:        []string{"MYSQL_ROOT_PASSWORD=secret"},
 		// increase max connections (default 151) to accommodate tests using the same docker container
-		Cmd:       []string{"--max-connections=500"},
+		Cmd:       append([]string{"--max-connections=500"}, options.ExtraServerFlags...),
 		NetworkID: bridgeNetworkName,
 	})
 	require.NoError(t, err)
@@ -69,7 +83,7 @@ func RunMySQLForTestingWithOptions(t testing.TB, options MySQLTesterOptions, bri
 		options: options,
 	}
 	t.Cleanup(func() {
-		require.NoError(t, pool.Purge(resource))
+		require.NoError(t, purgeContainer(t, pool, resource))
 	})
 
 	port := resource.GetPort(fmt.Sprintf("%d/tcp", mysqlPort))
@@ -81,7 +95,7 @@ func RunMySQLForTestingWithOptions(t testing.TB, options MySQLTesterOptions, bri
 	}
 
 	dsn := fmt.Sprintf("%s@(localhost:%s)/mysql?parseTime=true", builder.creds, port)
-	require.NoError(t, pool.Retry(func() error {
+	require.NoError(t, waitForBoot(t, pool, resource, name, func() error {
 		var err error
 		builder.db, err = sql.Open("mysql", dsn)
 		if err != nil {
@@ -115,7 +129,11 @@ func (mb *mysqlTester) NewDatabase(t testing.TB) string {
 func (mb *mysqlTester) runMigrate(t testing.TB, dsn string) {
 	driver, err := migrations.NewMySQLDriverFromDSN(dsn, mb.options.Prefix)
 	require.NoError(t, err, "failed to create migration driver: %s", err)
-	err = migrations.Manager.Run(context.Background(), driver, migrate.Head, migrate.LiveRun)
+	targetMigration := mb.options.TargetMigration
+	if targetMigration == "" {
+		targetMigration = migrate.Head
+	}
+	err = migrations.Manager.Run(context.Background(), driver, targetMigration, migrate.LiveRun)
 	require.NoError(t, err, "failed to run migration: %s", err)
 }
 
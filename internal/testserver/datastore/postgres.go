@@ -6,6 +6,7 @@ package datastore
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/google/uuid"
@@ -21,11 +22,21 @@ import (
 )
 
 type postgresTester struct {
-	conn            *pgx.Conn
 	hostname        string
 	port            string
 	creds           string
 	targetMigration string
+
+	// extensions are the extensions installed in each new database before it is migrated.
+	extensions []string
+
+	// lock guards the connection, which cannot be used concurrently, and the templates.
+	lock sync.Mutex
+	conn *pgx.Conn
+
+	// templates are the names of the template databases, by the migration to which they have
+	// been migrated. An empty name records that the template could not be created.
+	templates map[string]string
 }
 
 // RunPostgresForTesting returns a RunningEngineForTest for postgres
@@ -34,6 +45,51 @@ func RunPostgresForTesting(t testing.TB, bridgeNetworkName string, targetMigrati
 }
 
 func RunPostgresForTestingWithCommitTimestamps(t testing.TB, bridgeNetworkName string, targetMigration string, withCommitTimestamps bool) RunningEngineForTest {
+	return runPostgresForTesting(t, bridgeNetworkName, targetMigration, withCommitTimestamps, nil)
+}
+
+// RunPostgresForTestingWithExtensions returns a RunningEngineForTest for postgres, whose
+// datastores have the given extensions installed before being migrated, e.g. `uuid-ossp`. The
+// creation of a datastore fails should an extension not be available in the postgres image.
+func RunPostgresForTestingWithExtensions(t testing.TB, bridgeNetworkName string, targetMigration string, extensions []string) RunningEngineForTest {
+	return runPostgresForTesting(t, bridgeNetworkName, targetMigration, true, extensions)
+}
+
+func runPostgresForTesting(t testing.TB, bridgeNetworkName string, targetMigration string, withCommitTimestamps bool, extensions []string) RunningEngineForTest {
+	builder, container := startPostgres(t, bridgeNetworkName, withCommitTimestamps)
+	builder.targetMigration = targetMigration
+	builder.extensions = extensions
+	t.Cleanup(func() {
+		require.NoError(t, container.purge(t))
+	})
+
+	// Migrate the template database immediately, so that it is ready for the first datastore.
+	builder.lock.Lock()
+	defer builder.lock.Unlock()
+	if _, err := builder.template(targetMigration); err != nil {
+		t.Logf("could not create the template database, datastores will be migrated individually: %s", err)
+	}
+	return builder
+}
+
+// postgresContainer is a running postgres container.
+type postgresContainer struct {
+	pool     *dockertest.Pool
+	resource *dockertest.Resource
+}
+
+// logOnFailure logs the output of the container should the given test have failed.
+func (c postgresContainer) logOnFailure(t testing.TB) {
+	logContainerOnFailure(t, c.pool, c.resource)
+}
+
+// purge purges the container, first logging its output should the given test have failed.
+func (c postgresContainer) purge(t testing.TB) error {
+	return purgeContainer(t, c.pool, c.resource)
+}
+
+// startPostgres starts a postgres container, returning the tester for it and the container.
+func startPostgres(t testing.TB, bridgeNetworkName string, withCommitTimestamps bool) (*postgresTester, postgresContainer) {
 	pool, err := dockertest.NewPool("")
 	require.NoError(t, err)
 
@@ -55,13 +111,11 @@ func RunPostgresForTestingWithCommitTimestamps(t testing.TB, bridgeNetworkName s
 	require.NoError(t, err)
 
 	builder := &postgresTester{
-		hostname:        "localhost",
-		creds:           "postgres:secret",
-		targetMigration: targetMigration,
+		hostname:  "localhost",
+		creds:     "postgres:secret",
+		templates: map[string]string{},
 	}
-	t.Cleanup(func() {
-		require.NoError(t, pool.Purge(resource))
-	})
+	container := postgresContainer{pool, resource}
 
 	port := resource.GetPort(fmt.Sprintf("%d/tcp", 5432))
 	if bridgeNetworkName != "" {
@@ -72,7 +126,7 @@ func RunPostgresForTestingWithCommitTimestamps(t testing.TB, bridgeNetworkName s
 	}
 
 	uri := fmt.Sprintf("postgres://%s@localhost:%s/defaultdb?sslmode=disable", builder.creds, port)
-	require.NoError(t, pool.Retry(func() error {
+	err = waitForBoot(t, pool, resource, name, func() error {
 		var err error
 		ctx, cancelConnect := context.WithTimeout(context.Background(), dockerBootTimeout)
 		defer cancelConnect()
@@ -81,35 +135,159 @@ func RunPostgresForTestingWithCommitTimestamps(t testing.TB, bridgeNetworkName s
 			return err
 		}
 		return nil
-	}))
-	return builder
+	})
+	if err != nil {
+		require.NoError(t, container.purge(t))
+		require.NoError(t, err)
+	}
+	return builder, container
 }
 
 func (b *postgresTester) NewDatabase(t testing.TB) string {
-	uniquePortion, err := secrets.TokenHex(4)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	newDBName, err := b.createDatabase("")
 	require.NoError(t, err)
 
+	return b.connectionString(newDBName)
+}
+
+// createDatabase creates a new database with a unique name, cloned from the template database if
+// one is given. Must be called with the lock held.
+func (b *postgresTester) createDatabase(template string) (string, error) {
+	uniquePortion, err := secrets.TokenHex(4)
+	if err != nil {
+		return "", err
+	}
+
 	newDBName := "db" + uniquePortion
+	statement := "CREATE DATABASE " + newDBName
+	if template != "" {
+		statement += " TEMPLATE " + template
+	}
+
+	if _, err := b.conn.Exec(context.Background(), statement); err != nil {
+		return "", err
+	}
+	return newDBName, nil
+}
+
+// template returns the name of the template database migrated to the target migration, creating
+// it if necessary. Must be called with the lock held.
+func (b *postgresTester) template(targetMigration string) (string, error) {
+	if template, ok := b.templates[targetMigration]; ok {
+		if template == "" {
+			return "", fmt.Errorf("the template database for migration `%s` could not be created", targetMigration)
+		}
+		return template, nil
+	}
+
+	template, err := b.migrateTemplate(targetMigration)
+	b.templates[targetMigration] = template
+	return template, err
+}
 
-	_, err = b.conn.Exec(context.Background(), "CREATE DATABASE "+newDBName)
+func (b *postgresTester) migrateTemplate(targetMigration string) (string, error) {
+	template, err := b.createDatabase("")
+	if err != nil {
+		return "", err
+	}
+
+	if err := b.installExtensions(template); err != nil {
+		return "", err
+	}
+
+	migrationDriver, err := pgmigrations.NewAlembicPostgresDriver(b.connectionString(template))
+	if err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+	if err := pgmigrations.DatabaseMigrations.Run(ctx, migrationDriver, targetMigration, migrate.LiveRun, migrate.WithBackfillBatchSize(1000)); err != nil {
+		_ = migrationDriver.Close(ctx)
+		return "", err
+	}
+
+	// A database cannot be used as a template while any connection to it is open.
+	if err := migrationDriver.Close(ctx); err != nil {
+		return "", err
+	}
+	return template, nil
+}
+
+// newMigratedDatabase returns the connection string to a new database migrated to the target
+// migration, cloned from the template database should it be available, and migrated otherwise.
+func (b *postgresTester) newMigratedDatabase(t testing.TB, targetMigration string) string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	template, err := b.template(targetMigration)
+	if err == nil {
+		var newDBName string
+		newDBName, err = b.createDatabase(template)
+		if err == nil {
+			return b.connectionString(newDBName)
+		}
+	}
+	t.Logf("could not clone the template database, migrating a new database instead: %s", err)
+
+	newDBName, err := b.createDatabase("")
 	require.NoError(t, err)
 
+	require.NoError(t, b.installExtensions(newDBName))
+
+	connectStr := b.connectionString(newDBName)
+	migrationDriver, err := pgmigrations.NewAlembicPostgresDriver(connectStr)
+	require.NoError(t, err)
+	require.NoError(t, pgmigrations.DatabaseMigrations.Run(context.Background(), migrationDriver, targetMigration, migrate.LiveRun, migrate.WithBackfillBatchSize(1000)))
+
+	return connectStr
+}
+
+// installExtensions installs the extensions of the tester in the database.
+func (b *postgresTester) installExtensions(dbName string) error {
+	if len(b.extensions) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerBootTimeout)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, b.connectionString(dbName))
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	for _, extension := range b.extensions {
+		var available bool
+		err := conn.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pg_available_extensions WHERE name = $1)", extension).Scan(&available)
+		if err != nil {
+			return fmt.Errorf("could not check the availability of extension `%s`: %w", extension, err)
+		}
+
+		if !available {
+			return fmt.Errorf("extension `%s` is not available in the postgres %s image", extension, pgversion.MinimumSupportedPostgresVersion)
+		}
+
+		if _, err := conn.Exec(ctx, "CREATE EXTENSION IF NOT EXISTS "+pgx.Identifier{extension}.Sanitize()); err != nil {
+			return fmt.Errorf("could not create extension `%s`: %w", extension, err)
+		}
+	}
+	return nil
+}
+
+func (b *postgresTester) connectionString(dbName string) string {
 	return fmt.Sprintf(
 		"postgres://%s@%s:%s/%s?sslmode=disable",
 		b.creds,
 		b.hostname,
 		b.port,
-		newDBName,
+		dbName,
 	)
 }
 
 func (b *postgresTester) NewDatastore(t testing.TB, initFunc InitFunc) datastore.Datastore {
-	connectStr := b.NewDatabase(t)
-
-	migrationDriver, err := pgmigrations.NewAlembicPostgresDriver(connectStr)
-	require.NoError(t, err)
-	ctx := context.WithValue(context.Background(), migrate.BackfillBatchSize, uint64(1000))
-	require.NoError(t, pgmigrations.DatabaseMigrations.Run(ctx, migrationDriver, b.targetMigration, migrate.LiveRun))
-
-	return initFunc("postgres", connectStr)
+	return initFunc("postgres", b.newMigratedDatabase(t, b.targetMigration))
 }
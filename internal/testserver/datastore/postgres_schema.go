@@ -0,0 +1,166 @@
+//go:build docker
+// +build docker
+
+package datastore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// PostgresSchema is the structure of the tables of the public schema of a postgres database, by
+// table name, for asserting on the effects of migrations.
+type PostgresSchema map[string]PostgresTable
+
+// PostgresTable is the structure of a postgres table.
+type PostgresTable struct {
+	// Columns are the columns of the table, by name.
+	Columns map[string]PostgresColumn
+
+	// Indexes are the indexes of the table, by name, including those backing constraints.
+	Indexes map[string]PostgresIndex
+
+	// Constraints are the constraints of the table, by name, excluding NOT NULL constraints,
+	// which are reported on the columns.
+	Constraints map[string]PostgresConstraint
+}
+
+// PostgresColumn is the structure of a column of a postgres table.
+type PostgresColumn struct {
+	// DataType is the type of the column, as reported by information_schema, e.g. `bigint`.
+	DataType string
+	Nullable bool
+
+	// Default is the expression of the default value of the column, empty if none.
+	Default string
+}
+
+// PostgresIndex is the structure of an index of a postgres table.
+type PostgresIndex struct {
+	// Columns are the key columns of the index, followed by its included columns. Key expressions
+	// are reported as empty names.
+	Columns []string
+	Unique  bool
+	Primary bool
+
+	// Definition is the statement creating the index, as reconstructed by postgres.
+	Definition string
+}
+
+// PostgresConstraintType is the type of a postgres constraint.
+type PostgresConstraintType string
+
+const (
+	PrimaryKeyConstraint PostgresConstraintType = "PRIMARY KEY"
+	UniqueConstraint     PostgresConstraintType = "UNIQUE"
+	ForeignKeyConstraint PostgresConstraintType = "FOREIGN KEY"
+	CheckConstraint      PostgresConstraintType = "CHECK"
+	ExclusionConstraint  PostgresConstraintType = "EXCLUDE"
+)
+
+// PostgresConstraint is a constraint of a postgres table.
+type PostgresConstraint struct {
+	Type PostgresConstraintType
+
+	// Definition is the definition of the constraint, as reconstructed by postgres.
+	Definition string
+}
+
+const (
+	queryIntrospectColumns = `
+		SELECT table_name, column_name, data_type, is_nullable = 'YES', COALESCE(column_default, '')
+		FROM information_schema.columns
+		WHERE table_schema = 'public'`
+
+	queryIntrospectIndexes = `
+		SELECT t.relname, i.relname, ix.indisunique, ix.indisprimary, pg_get_indexdef(ix.indexrelid),
+			ARRAY(
+				SELECT COALESCE(a.attname, '')
+				FROM unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+				LEFT JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			)
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = 'public'`
+
+	queryIntrospectConstraints = `
+		SELECT t.relname, c.conname, c.contype::text, pg_get_constraintdef(c.oid)
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = 'public'`
+)
+
+var constraintTypes = map[string]PostgresConstraintType{
+	"p": PrimaryKeyConstraint,
+	"u": UniqueConstraint,
+	"f": ForeignKeyConstraint,
+	"c": CheckConstraint,
+	"x": ExclusionConstraint,
+}
+
+// IntrospectPostgresSchema reads the structure of the tables of the public schema of the postgres
+// database at the connection string, from information_schema and pg_catalog.
+func IntrospectPostgresSchema(t testing.TB, connectionString string) PostgresSchema {
+	ctx, cancel := context.WithTimeout(context.Background(), dockerBootTimeout)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, connectionString)
+	require.NoError(t, err)
+	defer conn.Close(ctx)
+
+	schema := PostgresSchema{}
+	table := func(name string) PostgresTable {
+		found, ok := schema[name]
+		if !ok {
+			found = PostgresTable{
+				Columns:     map[string]PostgresColumn{},
+				Indexes:     map[string]PostgresIndex{},
+				Constraints: map[string]PostgresConstraint{},
+			}
+			schema[name] = found
+		}
+		return found
+	}
+
+	rows, err := conn.Query(ctx, queryIntrospectColumns)
+	require.NoError(t, err)
+	for rows.Next() {
+		var tableName, columnName string
+		var column PostgresColumn
+		require.NoError(t, rows.Scan(&tableName, &columnName, &column.DataType, &column.Nullable, &column.Default))
+		table(tableName).Columns[columnName] = column
+	}
+	require.NoError(t, rows.Err())
+
+	rows, err = conn.Query(ctx, queryIntrospectIndexes)
+	require.NoError(t, err)
+	for rows.Next() {
+		var tableName, indexName string
+		var index PostgresIndex
+		require.NoError(t, rows.Scan(&tableName, &indexName, &index.Unique, &index.Primary, &index.Definition, &index.Columns))
+		table(tableName).Indexes[indexName] = index
+	}
+	require.NoError(t, rows.Err())
+
+	rows, err = conn.Query(ctx, queryIntrospectConstraints)
+	require.NoError(t, err)
+	for rows.Next() {
+		var tableName, constraintName, constraintType string
+		var constraint PostgresConstraint
+		require.NoError(t, rows.Scan(&tableName, &constraintName, &constraintType, &constraint.Definition))
+
+		constraint.Type = constraintTypes[constraintType]
+		require.NotEmpty(t, constraint.Type, "unknown type `%s` of constraint `%s`", constraintType, constraintName)
+		table(tableName).Constraints[constraintName] = constraint
+	}
+	require.NoError(t, rows.Err())
+
+	return schema
+}
@@ -0,0 +1,86 @@
+//go:build docker
+// +build docker
+
+package datastore
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// sharedPostgres is a postgres container shared by the tests of the process.
+type sharedPostgres struct {
+	tester    *postgresTester
+	container postgresContainer
+}
+
+var (
+	sharedPostgresLock      sync.Mutex
+	sharedPostgresInstances = map[string]*sharedPostgres{}
+)
+
+type sharedPostgresEngine struct {
+	shared          *sharedPostgres
+	targetMigration string
+}
+
+// RunPostgresForTestingShared returns a RunningEngineForTest for postgres, backed by a container
+// shared by all the tests of the process running on the same bridge network. The container is
+// started by the first test, and kept running until PurgeSharedPostgres is called. The output of
+// the container is logged to any test using it which fails.
+//
+// Each datastore is created by cloning a template database, migrated once to the target
+// migration, which avoids running the migrations for each test.
+func RunPostgresForTestingShared(t testing.TB, bridgeNetworkName string, targetMigration string) RunningEngineForTest {
+	sharedPostgresLock.Lock()
+	defer sharedPostgresLock.Unlock()
+
+	shared, ok := sharedPostgresInstances[bridgeNetworkName]
+	if !ok {
+		tester, container := startPostgres(t, bridgeNetworkName, true)
+		shared = &sharedPostgres{
+			tester:    tester,
+			container: container,
+		}
+		sharedPostgresInstances[bridgeNetworkName] = shared
+	}
+
+	t.Cleanup(func() {
+		shared.container.logOnFailure(t)
+	})
+
+	return &sharedPostgresEngine{shared: shared, targetMigration: targetMigration}
+}
+
+// PurgeSharedPostgres purges the containers started by RunPostgresForTestingShared. It must be
+// called once all the tests of the process have completed, typically from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		code := m.Run()
+//		if err := datastore.PurgeSharedPostgres(); err != nil {
+//			log.Printf("could not purge the shared postgres containers: %s", err)
+//		}
+//		os.Exit(code)
+//	}
+func PurgeSharedPostgres() error {
+	sharedPostgresLock.Lock()
+	defer sharedPostgresLock.Unlock()
+
+	for bridgeNetworkName, shared := range sharedPostgresInstances {
+		if err := shared.container.pool.Purge(shared.container.resource); err != nil {
+			return err
+		}
+		delete(sharedPostgresInstances, bridgeNetworkName)
+	}
+	return nil
+}
+
+func (e *sharedPostgresEngine) NewDatabase(t testing.TB) string {
+	return e.shared.tester.NewDatabase(t)
+}
+
+func (e *sharedPostgresEngine) NewDatastore(t testing.TB, initFunc InitFunc) datastore.Datastore {
+	return initFunc("postgres", e.shared.tester.newMigratedDatabase(t, e.targetMigration))
+}
@@ -0,0 +1,88 @@
+//go:build docker
+// +build docker
+
+package datastore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// NewDatastoreWithSeed returns a new logical datastore of the running engine, initialized with
+// the initFunc, into which the schema and relationships have been written in a single
+// transaction. The revision returned is that at which the seeded data was written, which can
+// be used to pin the consistency of reads. Any failure to seed the datastore fails the test.
+func NewDatastoreWithSeed(
+	t testing.TB,
+	engine RunningEngineForTest,
+	initFunc InitFunc,
+	schema string,
+	relationships []*core.RelationTuple,
+) (datastore.Datastore, datastore.Revision) {
+	ds := engine.NewDatastore(t, initFunc)
+	revision := seedDatastore(t, ds, schema, relationships)
+	return ds, revision
+}
+
+func seedDatastore(t testing.TB, ds datastore.Datastore, schema string, relationships []*core.RelationTuple) datastore.Revision {
+	emptyDefaultPrefix := ""
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schema,
+	}, &emptyDefaultPrefix)
+	require.NoError(t, err, "failed to compile seed schema: %s", err)
+
+	ctx := context.Background()
+	revision, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		if len(compiled.CaveatDefinitions) > 0 {
+			if err := rwt.WriteCaveats(ctx, compiled.CaveatDefinitions); err != nil {
+				return err
+			}
+		}
+
+		resolver := namespace.ResolverForDatastoreReader(rwt).WithPredefinedElements(namespace.PredefinedElements{
+			Namespaces: compiled.ObjectDefinitions,
+			Caveats:    compiled.CaveatDefinitions,
+		})
+		for _, nsDef := range compiled.ObjectDefinitions {
+			ts, err := namespace.NewNamespaceTypeSystem(nsDef, resolver)
+			if err != nil {
+				return err
+			}
+
+			vts, err := ts.Validate(ctx)
+			if err != nil {
+				return err
+			}
+
+			if err := namespace.AnnotateNamespace(vts); err != nil {
+				return err
+			}
+
+			if err := rwt.WriteNamespaces(ctx, nsDef); err != nil {
+				return err
+			}
+		}
+
+		if len(relationships) == 0 {
+			return nil
+		}
+
+		mutations := make([]*core.RelationTupleUpdate, 0, len(relationships))
+		for _, rel := range relationships {
+			mutations = append(mutations, tuple.Create(rel.CloneVT()))
+		}
+		return rwt.WriteRelationships(ctx, mutations)
+	})
+	require.NoError(t, err, "failed to seed datastore: %s", err)
+	return revision
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"os"
 	"testing"
-	"time"
 
 	database "cloud.google.com/go/spanner/admin/database/apiv1"
 	adminpb "cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
@@ -25,15 +24,17 @@ import (
 )
 
 type spannerTest struct {
-	hostname string
+	hostname        string
+	targetMigration string
 }
 
-// RunSpannerForTesting returns a RunningEngineForTest for spanner
-func RunSpannerForTesting(t testing.TB, bridgeNetworkName string) RunningEngineForTest {
+// RunSpannerForTesting returns a RunningEngineForTest for spanner, backed by the Cloud Spanner
+// emulator, whose datastores are migrated to the target migration.
+func RunSpannerForTesting(t testing.TB, bridgeNetworkName string, targetMigration string) RunningEngineForTest {
 	pool, err := dockertest.NewPool("")
 	require.NoError(t, err)
 
-	name := fmt.Sprintf("postgres-%s", uuid.New().String())
+	name := fmt.Sprintf("spanner-%s", uuid.New().String())
 	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
 		Name:         name,
 		Repository:   "gcr.io/cloud-spanner-emulator/emulator",
@@ -44,14 +45,14 @@ func RunSpannerForTesting(t testing.TB, bridgeNetworkName string) RunningEngineF
 	require.NoError(t, err)
 
 	t.Cleanup(func() {
-		require.NoError(t, pool.Purge(resource))
+		require.NoError(t, purgeContainer(t, pool, resource))
 	})
 
 	port := resource.GetPort("9010/tcp")
 	spannerEmulatorAddr := fmt.Sprintf("localhost:%s", port)
 	require.NoError(t, os.Setenv("SPANNER_EMULATOR_HOST", spannerEmulatorAddr))
 
-	require.NoError(t, pool.Retry(func() error {
+	require.NoError(t, waitForBoot(t, pool, resource, name, func() error {
 		ctx, cancel := context.WithTimeout(context.Background(), dockerBootTimeout)
 		defer cancel()
 
@@ -75,7 +76,7 @@ func RunSpannerForTesting(t testing.TB, bridgeNetworkName string) RunningEngineF
 		return err
 	}))
 
-	builder := &spannerTest{}
+	builder := &spannerTest{targetMigration: targetMigration}
 	if bridgeNetworkName != "" {
 		builder.hostname = name
 	}
@@ -87,6 +88,8 @@ func (b *spannerTest) ExternalEnvVars() []string {
 	return []string{fmt.Sprintf("SPANNER_EMULATOR_HOST=%s:9010", b.hostname)}
 }
 
+// NewDatabase creates a new instance, and a database within it, which are dropped on cleanup of
+// the test.
 func (b *spannerTest) NewDatabase(t testing.TB) string {
 	t.Logf("using spanner emulator, host: %s", os.Getenv("SPANNER_EMULATOR_HOST"))
 
@@ -95,7 +98,7 @@ func (b *spannerTest) NewDatabase(t testing.TB) string {
 
 	newInstanceName := fmt.Sprintf("fake-instance-%s", uniquePortion)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), dockerBootTimeout)
 	defer cancel()
 
 	instancesClient, err := instances.NewInstanceAdminClient(ctx)
@@ -129,6 +132,24 @@ func (b *spannerTest) NewDatabase(t testing.TB) string {
 
 	db, err := op.Wait(ctx)
 	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), dockerBootTimeout)
+		defer cancel()
+
+		adminClient, err := database.NewDatabaseAdminClient(ctx)
+		require.NoError(t, err)
+		defer adminClient.Close()
+
+		require.NoError(t, adminClient.DropDatabase(ctx, &adminpb.DropDatabaseRequest{Database: db.Name}))
+
+		instancesClient, err := instances.NewInstanceAdminClient(ctx)
+		require.NoError(t, err)
+		defer instancesClient.Close()
+
+		require.NoError(t, instancesClient.DeleteInstance(ctx, &instance.DeleteInstanceRequest{Name: spannerInstance.Name}))
+	})
+
 	return db.Name
 }
 
@@ -138,7 +159,7 @@ func (b *spannerTest) NewDatastore(t testing.TB, initFunc InitFunc) datastore.Da
 	migrationDriver, err := migrations.NewSpannerDriver(db, "", os.Getenv("SPANNER_EMULATOR_HOST"))
 	require.NoError(t, err)
 
-	err = migrations.SpannerMigrations.Run(context.Background(), migrationDriver, migrate.Head, migrate.LiveRun)
+	err = migrations.SpannerMigrations.Run(context.Background(), migrationDriver, b.targetMigration, migrate.LiveRun)
 	require.NoError(t, err)
 
 	return initFunc("spanner", db)
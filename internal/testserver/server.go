@@ -23,6 +23,10 @@ import (
 type ServerConfig struct {
 	MaxUpdatesPerWrite    uint16
 	MaxPreconditionsCount uint16
+
+	// DebugMaskedCaveatParameters are the names of the caveat parameters whose values are masked
+	// in debug information.
+	DebugMaskedCaveatParameters []string
 }
 
 // NewTestServer creates a new test server, using defaults for the config.
@@ -71,6 +75,7 @@ func NewTestServerWithConfig(require *require.Assertions,
 		server.WithMetricsAPI(util.HTTPServerConfig{Enabled: false}),
 		server.WithDispatchServer(util.GRPCServerConfig{Enabled: false}),
 		server.WithExperimentalCaveatsEnabled(true),
+		server.SetDebugMaskedCaveatParameters(config.DebugMaskedCaveatParameters),
 		server.SetMiddlewareModification([]server.MiddlewareModification{
 			{
 				Operation: server.OperationReplaceAllUnsafe,
@@ -0,0 +1,54 @@
+package caveats
+
+import (
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// AggregateCountSuffix is the suffix of the names of relationship count parameters, which hold
+// the number of relationships of a resource, for policies such as "the resource has fewer than N
+// editors".
+//
+// A caveat opts into a relationship count by declaring a parameter of type `relationship_count`,
+// named `<relation>_count`. Any relationship written with the caveat must then be on a resource
+// type having a relation (not a permission) named `<relation>`. Parameters of any other type are
+// ordinary parameters, whatever their name.
+//
+// When a check reads a relationship with such a caveat, the parameter is populated with the
+// number of relationships written for the resource of that relationship and the counted
+// relation, as of the revision of the check. Every relationship is counted, regardless of any
+// caveat on it, and subject sets are counted as a single relationship rather than expanded. As
+// the count is written into the context of the relationship, it takes precedence over any value
+// supplied for the parameter in the check context.
+//
+// Partiality: counts are only populated by checks, before the caveat of the relationship is
+// evaluated, so a check never evaluates a caveat while its count is pending. Elsewhere, e.g. when
+// looking up resources or subjects, the count is not populated: unless supplied in the context,
+// the parameter is missing, and the caveat evaluates as partial with the parameter reported as
+// missing, yielding a conditional result rather than a denial.
+//
+// Cost: the count query is not part of the evaluation cost of the caveat, which only covers the
+// evaluation of the expression with the count as input. Each count reads at most
+// MaximumAggregateCount relationships from the datastore, and is made at most once per resource
+// and counted relation in each dispatched check, including for relationships whose caveat is
+// then found to deny. Counts saturate at MaximumAggregateCount, so a comparison against a
+// threshold is only exact for thresholds up to that value.
+const AggregateCountSuffix = "_count"
+
+// MaximumAggregateCount is the largest value populated for a relationship count parameter.
+// Counting stops once this many relationships have been found.
+const MaximumAggregateCount = 1000
+
+// AggregateCountRelation returns the name of the relation counted by the given caveat parameter,
+// and whether the parameter is a relationship count parameter: of type `relationship_count` and
+// named with the AggregateCountSuffix.
+func AggregateCountRelation(parameterName string, parameterType *core.CaveatTypeReference) (string, bool) {
+	if parameterType.GetTypeName() != types.RelationshipCountType.String() {
+		return "", false
+	}
+
+	relationName := strings.TrimSuffix(parameterName, AggregateCountSuffix)
+	return relationName, relationName != parameterName && relationName != ""
+}
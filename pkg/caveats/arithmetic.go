@@ -0,0 +1,174 @@
+package caveats
+
+import (
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/operators"
+	celtypes "github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// zeroOperandErrMessages are the messages returned by CEL when a division or modulus by zero
+// occurs. CEL does not return typed errors for these, so the messages are matched as-is, and are
+// pinned by tests against the version of CEL in use.
+var zeroOperandErrMessages = map[string]string{
+	"division by zero": operators.Divide,
+	"modulus by zero":  operators.Modulo,
+}
+
+// overflowErrMessages are the messages returned by CEL when an arithmetic operation overflows
+// the range of its type.
+var overflowErrMessages = map[string]struct{}{
+	"integer overflow":          {},
+	"unsigned integer overflow": {},
+	"duration overflow":         {},
+	"timestamp overflow":        {},
+}
+
+// arithmeticOperatorSymbols maps the CEL operator function names to their symbols.
+var arithmeticOperatorSymbols = map[string]string{
+	operators.Add:      "+",
+	operators.Subtract: "-",
+	operators.Multiply: "*",
+	operators.Divide:   "/",
+	operators.Modulo:   "%",
+	operators.Negate:   "-",
+}
+
+// asArithmeticError converts the given evaluation error into a CaveatArithmeticError if it
+// represents a division or modulus by zero, or an arithmetic overflow. The operation and its
+// operands are recovered from the tracked evaluation state, when available.
+func asArithmeticError(caveat *CompiledCaveat, details *cel.EvalDetails, err error) (CaveatArithmeticError, bool) {
+	message := strings.TrimSpace(err.Error())
+
+	if function, ok := zeroOperandErrMessages[message]; ok {
+		var operands []any
+		if details != nil {
+			_, operands = findCall(caveat.ast.Expr(), zeroOperandCall(details, function))
+		}
+
+		return newCaveatArithmeticError(err, caveat.name, arithmeticOperatorSymbols[function], operands), true
+	}
+
+	if _, ok := overflowErrMessages[message]; ok {
+		var function string
+		var operands []any
+		if details != nil {
+			function, operands = findCall(caveat.ast.Expr(), overflowingCall(details))
+		}
+
+		return newCaveatOverflowError(err, caveat.name, arithmeticOperatorSymbols[function], operands), true
+	}
+
+	return CaveatArithmeticError{}, false
+}
+
+// callMatcher returns the values of the operands of the call if it is the one searched for.
+type callMatcher func(callID int64, call *exprpb.Expr_Call) ([]any, bool)
+
+// zeroOperandCall matches a call of the given function whose right-hand operand evaluated to
+// zero.
+func zeroOperandCall(details *cel.EvalDetails, function string) callMatcher {
+	return func(_ int64, call *exprpb.Expr_Call) ([]any, bool) {
+		if call.Function != function || len(call.Args) != 2 {
+			return nil, false
+		}
+
+		rhs, ok := details.State().Value(call.Args[1].Id)
+		if !ok || !isZeroValue(rhs) {
+			return nil, false
+		}
+
+		lhs, ok := details.State().Value(call.Args[0].Id)
+		if !ok {
+			return nil, false
+		}
+		return []any{lhs.Value(), rhs.Value()}, true
+	}
+}
+
+// overflowingCall matches a call of an arithmetic operator which evaluated to an error while its
+// operands did not, i.e. the operation from which the error originates.
+func overflowingCall(details *cel.EvalDetails) callMatcher {
+	return func(callID int64, call *exprpb.Expr_Call) ([]any, bool) {
+		if _, ok := arithmeticOperatorSymbols[call.Function]; !ok {
+			return nil, false
+		}
+
+		result, ok := details.State().Value(callID)
+		if !ok || !celtypes.IsError(result) {
+			return nil, false
+		}
+
+		operands := make([]any, 0, len(call.Args))
+		for _, arg := range call.Args {
+			operand, ok := details.State().Value(arg.Id)
+			if !ok || celtypes.IsUnknownOrError(operand) {
+				return nil, false
+			}
+			operands = append(operands, operand.Value())
+		}
+		return operands, true
+	}
+}
+
+// findCall walks the expression looking for a call accepted by the matcher, returning its
+// function and the values of its operands.
+func findCall(expr *exprpb.Expr, matches callMatcher) (string, []any) {
+	if expr == nil {
+		return "", nil
+	}
+
+	var children []*exprpb.Expr
+	switch t := expr.ExprKind.(type) {
+	case *exprpb.Expr_SelectExpr:
+		children = []*exprpb.Expr{t.SelectExpr.Operand}
+
+	case *exprpb.Expr_CallExpr:
+		if operands, ok := matches(expr.Id, t.CallExpr); ok {
+			return t.CallExpr.Function, operands
+		}
+
+		children = append([]*exprpb.Expr{t.CallExpr.Target}, t.CallExpr.Args...)
+
+	case *exprpb.Expr_ListExpr:
+		children = t.ListExpr.Elements
+
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range t.StructExpr.Entries {
+			children = append(children, entry.Value)
+		}
+
+	case *exprpb.Expr_ComprehensionExpr:
+		children = []*exprpb.Expr{
+			t.ComprehensionExpr.IterRange,
+			t.ComprehensionExpr.AccuInit,
+			t.ComprehensionExpr.LoopCondition,
+			t.ComprehensionExpr.LoopStep,
+			t.ComprehensionExpr.Result,
+		}
+	}
+
+	for _, child := range children {
+		if function, operands := findCall(child, matches); operands != nil {
+			return function, operands
+		}
+	}
+
+	return "", nil
+}
+
+func isZeroValue(val ref.Val) bool {
+	switch v := val.(type) {
+	case celtypes.Int:
+		return v == 0
+	case celtypes.Uint:
+		return v == 0
+	case celtypes.Double:
+		return v == 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,86 @@
+package caveats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCELArithmeticErrorMessages ensures the messages of the arithmetic errors returned by CEL are
+// those matched by asArithmeticError, so that a change in CEL is caught rather than silently
+// reporting the errors as generic evaluation errors.
+func TestCELArithmeticErrorMessages(t *testing.T) {
+	tcs := []struct {
+		name            string
+		varType         *cel.Type
+		exprString      string
+		context         map[string]any
+		expectedMessage string
+	}{
+		{
+			"integer division by zero",
+			cel.IntType,
+			"a / b",
+			map[string]any{"a": int64(42), "b": int64(0)},
+			"division by zero",
+		},
+		{
+			"integer modulus by zero",
+			cel.IntType,
+			"a % b",
+			map[string]any{"a": int64(42), "b": int64(0)},
+			"modulus by zero",
+		},
+		{
+			"unsigned integer division by zero",
+			cel.UintType,
+			"a / b",
+			map[string]any{"a": uint64(42), "b": uint64(0)},
+			"division by zero",
+		},
+		{
+			"unsigned integer modulus by zero",
+			cel.UintType,
+			"a % b",
+			map[string]any{"a": uint64(42), "b": uint64(0)},
+			"modulus by zero",
+		},
+		{
+			"integer overflow",
+			cel.IntType,
+			"a + b",
+			map[string]any{"a": int64(math.MaxInt64), "b": int64(1)},
+			"integer overflow",
+		},
+		{
+			"unsigned integer overflow",
+			cel.UintType,
+			"a - b",
+			map[string]any{"a": uint64(0), "b": uint64(1)},
+			"unsigned integer overflow",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			env, err := cel.NewEnv(cel.Variable("a", tc.varType), cel.Variable("b", tc.varType))
+			require.NoError(t, err)
+
+			ast, issues := env.Compile(tc.exprString)
+			require.NoError(t, issues.Err())
+
+			program, err := env.Program(ast)
+			require.NoError(t, err)
+
+			_, _, err = program.Eval(tc.context)
+			require.Error(t, err)
+			require.Equal(t, tc.expectedMessage, err.Error())
+
+			_, isZeroOperand := zeroOperandErrMessages[err.Error()]
+			_, isOverflow := overflowErrMessages[err.Error()]
+			require.True(t, isZeroOperand || isOverflow, "message `%s` is not matched", err.Error())
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package caveats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// BatchEvaluationFailure is the failure of a single caveat in a call to EvaluateCaveats.
+type BatchEvaluationFailure struct {
+	// Index is the index of the caveat in the caveats given to EvaluateCaveats.
+	Index int
+
+	// CaveatName is the name of the caveat.
+	CaveatName string
+
+	// Err is the error returned by the evaluation of the caveat.
+	Err error
+}
+
+// CaveatBatchEvaluationError is the error returned by EvaluateCaveats when one or more caveats
+// fail to evaluate.
+type CaveatBatchEvaluationError struct {
+	failures []BatchEvaluationFailure
+}
+
+// Failures returns the failures of the caveats which failed to evaluate, in the order of the
+// caveats given.
+func (err CaveatBatchEvaluationError) Failures() []BatchEvaluationFailure {
+	return err.failures
+}
+
+func (err CaveatBatchEvaluationError) Error() string {
+	messages := make([]string, 0, len(err.failures))
+	for _, failure := range err.failures {
+		messages = append(messages, fmt.Sprintf("caveat `%s` (at index %d): %s", failure.CaveatName, failure.Index, failure.Err))
+	}
+
+	return fmt.Sprintf("%d caveat(s) failed to evaluate: %s", len(err.failures), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the error of the first caveat which failed to evaluate.
+func (err CaveatBatchEvaluationError) Unwrap() error {
+	return err.failures[0].Err
+}
+
+// BatchCaveatResult is the outcome of the evaluation of a single caveat in a call to
+// EvaluateEachCaveat: either its result, or the error which prevented its evaluation.
+type BatchCaveatResult struct {
+	Result *CaveatResult
+	Err    error
+}
+
+// FirstBatchError returns the error of the first caveat which failed to evaluate, if any, for
+// callers wishing to fail on any error rather than handle each caveat's outcome.
+func FirstBatchError(results []BatchCaveatResult) error {
+	for _, result := range results {
+		if result.Err != nil {
+			return result.Err
+		}
+	}
+	return nil
+}
+
+// EvaluateEachCaveat evaluates each of the compiled caveats with the same context values, and
+// returns the outcome of each, in the order given, so that the failure of a caveat does not hide
+// the results of the others and callers can decide how to handle each failure. The context values
+// are prepared for evaluation once and shared by all the evaluations, which is cheaper than calling
+// EvaluateCaveatWithConfig for each caveat. The configuration applies to each evaluation
+// individually.
+//
+// Caveats declaring default values for parameters missing from the context values are evaluated
+// with their own copy of the context values, including the defaults.
+//
+// An error is only returned if the context values cannot be prepared, in which case no caveat is
+// evaluated.
+func EvaluateEachCaveat(ctx context.Context, caveats []*CompiledCaveat, contextValues map[string]any, config *EvaluationConfig) ([]BatchCaveatResult, error) {
+	activation, err := newEvaluationActivation(contextValues, config)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchCaveatResult, len(caveats))
+	for index, caveat := range caveats {
+		caveatActivation := activation
+		if needsDefaultValues(caveat, contextValues) {
+			caveatActivation, err = newEvaluationActivation(withDefaultValues(caveat, contextValues), config)
+			if err != nil {
+				results[index].Err = err
+				continue
+			}
+		}
+
+		results[index].Result, results[index].Err = evaluateCaveat(ctx, caveat, caveatActivation, config, nil)
+	}
+
+	return results, nil
+}
+
+// EvaluateCaveats evaluates each of the compiled caveats with the same context values, and
+// returns one result per caveat, in the order given, as EvaluateEachCaveat does.
+//
+// All the caveats are evaluated, even if some fail: should any fail, the results are returned
+// with a nil entry for each failed caveat, along with a CaveatBatchEvaluationError naming them.
+func EvaluateCaveats(ctx context.Context, caveats []*CompiledCaveat, contextValues map[string]any, config *EvaluationConfig) ([]*CaveatResult, error) {
+	outcomes, err := EvaluateEachCaveat(ctx, caveats, contextValues, config)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*CaveatResult, len(caveats))
+	var failures []BatchEvaluationFailure
+	for index, outcome := range outcomes {
+		if outcome.Err != nil {
+			failures = append(failures, BatchEvaluationFailure{
+				Index:      index,
+				CaveatName: caveats[index].name,
+				Err:        outcome.Err,
+			})
+			continue
+		}
+
+		results[index] = outcome.Result
+	}
+
+	if len(failures) > 0 {
+		return results, CaveatBatchEvaluationError{failures}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,115 @@
+package caveats
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+func TestEvaluateCaveats(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.IntType,
+		"c": types.IntType,
+	})
+
+	compile := func(exprString, name string) *CompiledCaveat {
+		compiled, err := CompileCaveatWithName(env, exprString, name)
+		require.NoError(t, err)
+		return compiled
+	}
+
+	caveats := []*CompiledCaveat{
+		compile("a == 1", "first"),
+		compile("a / b == 1", "divides"),
+		compile("a == 2 && c == 3", "shortcircuits"),
+		compile("c == 3", "missing"),
+		compile("a % b == 0", "modulus"),
+	}
+
+	results, err := EvaluateCaveats(context.Background(), caveats, map[string]any{
+		"a": int64(1),
+		"b": int64(0),
+	}, nil)
+	require.Error(t, err)
+	require.Len(t, results, len(caveats))
+
+	require.True(t, results[0].Value())
+	require.Nil(t, results[1])
+
+	require.False(t, results[2].IsPartial())
+	require.False(t, results[2].Value())
+
+	require.True(t, results[3].IsPartial())
+	missingVarNames, err2 := results[3].MissingVarNames()
+	require.NoError(t, err2)
+	require.Equal(t, []string{"c"}, missingVarNames)
+
+	require.Nil(t, results[4])
+
+	var batchErr CaveatBatchEvaluationError
+	require.True(t, errors.As(err, &batchErr))
+	require.Len(t, batchErr.Failures(), 2)
+	require.Equal(t, 1, batchErr.Failures()[0].Index)
+	require.Equal(t, "divides", batchErr.Failures()[0].CaveatName)
+	require.Equal(t, 4, batchErr.Failures()[1].Index)
+	require.Equal(t, "modulus", batchErr.Failures()[1].CaveatName)
+	require.Equal(t, "2 caveat(s) failed to evaluate: caveat `divides` (at index 1): caveat `divides` could not be evaluated: integer division by zero; caveat `modulus` (at index 4): caveat `modulus` could not be evaluated: integer modulus by zero", err.Error())
+
+	var arithmeticErr CaveatArithmeticError
+	require.True(t, errors.As(err, &arithmeticErr))
+
+	// Without failures, all the results are returned.
+	results, err = EvaluateCaveats(context.Background(), caveats, map[string]any{
+		"a": int64(1),
+		"b": int64(1),
+		"c": int64(3),
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, results, len(caveats))
+	for index, expected := range []bool{true, true, false, true, true} {
+		require.Equal(t, expected, results[index].Value(), "mismatch for caveat %d", index)
+	}
+}
+
+func TestEvaluateEachCaveat(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.IntType,
+	})
+
+	compile := func(exprString, name string) *CompiledCaveat {
+		compiled, err := CompileCaveatWithName(env, exprString, name)
+		require.NoError(t, err)
+		return compiled
+	}
+
+	caveats := []*CompiledCaveat{
+		compile("a / b == 1", "divides"),
+		compile("a == 1", "first"),
+		compile("a % b == 0", "modulus"),
+	}
+
+	results, err := EvaluateEachCaveat(context.Background(), caveats, map[string]any{
+		"a": int64(1),
+		"b": int64(0),
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, results, len(caveats))
+
+	require.Nil(t, results[0].Result)
+	require.ErrorContains(t, results[0].Err, "integer division by zero")
+
+	require.NoError(t, results[1].Err)
+	require.True(t, results[1].Result.Value())
+
+	require.Nil(t, results[2].Result)
+	require.ErrorContains(t, results[2].Err, "integer modulus by zero")
+
+	require.Equal(t, results[0].Err, FirstBatchError(results))
+	require.NoError(t, FirstBatchError(results[1:2]))
+}
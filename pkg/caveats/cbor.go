@@ -0,0 +1,396 @@
+package caveats
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+// maxCBORNestingDepth is the maximum depth of nested arrays and maps decoded from CBOR.
+const maxCBORNestingDepth = 32
+
+const (
+	cborMajorUnsigned = 0
+	cborMajorNegative = 1
+	cborMajorBytes    = 2
+	cborMajorText     = 3
+	cborMajorArray    = 4
+	cborMajorMap      = 5
+	cborMajorTag      = 6
+	cborMajorSimple   = 7
+
+	cborIndefinite = 31
+	cborBreak      = 0xff
+
+	cborTagDateTimeString = 0
+	cborTagEpochDateTime  = 1
+)
+
+// CaveatContextFromCBOR decodes a CBOR (RFC 8949) encoded map into a context map suitable for
+// ConvertContextToParameters.
+//
+// The decoded values follow the same conventions as context decoded from JSON or a Struct, so
+// that the same coercion rules apply when converting to the declared parameter types:
+//   - integers decode to int64 (or uint64 if too large for an int64), and floats to float64; the
+//     parameter conversion then coerces them to the declared numeric type, rejecting any with a
+//     fractional part for int and uint parameters.
+//   - byte strings decode to their standard base64 encoding, as expected for bytes parameters.
+//   - date/time tags (0 and 1) decode to RFC 3339 strings, as expected for timestamp parameters.
+//   - null and undefined decode to nil.
+//
+// Map keys must be text strings, and must be unique within a map. Epoch date/time tags must hold
+// a finite number of seconds.
+func CaveatContextFromCBOR(data []byte) (map[string]any, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty CBOR context")
+	}
+
+	decoder := &cborDecoder{data: data}
+	decoded, err := decoder.decodeValue(0)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode CBOR context: %w", err)
+	}
+
+	if decoder.pos != len(data) {
+		return nil, fmt.Errorf("could not decode CBOR context: found %d trailing bytes", len(data)-decoder.pos)
+	}
+
+	contextMap, ok := decoded.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("CBOR context must be a map, found %T", decoded)
+	}
+
+	return contextMap, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) readByte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, fmt.Errorf("unexpected end of data")
+	}
+
+	b := d.data[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *cborDecoder) readBytes(length uint64) ([]byte, error) {
+	if length > uint64(len(d.data)-d.pos) {
+		return nil, fmt.Errorf("unexpected end of data")
+	}
+
+	read := d.data[d.pos : d.pos+int(length)]
+	d.pos += int(length)
+	return read, nil
+}
+
+func (d *cborDecoder) peekBreak() bool {
+	return d.pos < len(d.data) && d.data[d.pos] == cborBreak
+}
+
+// readArgument reads the argument following an initial byte with the given additional
+// information. Returns true if the item is of indefinite length.
+func (d *cborDecoder) readArgument(info byte) (uint64, bool, error) {
+	switch {
+	case info < 24:
+		return uint64(info), false, nil
+
+	case info == 24:
+		b, err := d.readByte()
+		return uint64(b), false, err
+
+	case info == 25:
+		b, err := d.readBytes(2)
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(binary.BigEndian.Uint16(b)), false, nil
+
+	case info == 26:
+		b, err := d.readBytes(4)
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(binary.BigEndian.Uint32(b)), false, nil
+
+	case info == 27:
+		b, err := d.readBytes(8)
+		if err != nil {
+			return 0, false, err
+		}
+		return binary.BigEndian.Uint64(b), false, nil
+
+	case info == cborIndefinite:
+		return 0, true, nil
+
+	default:
+		return 0, false, fmt.Errorf("invalid additional information %d", info)
+	}
+}
+
+func (d *cborDecoder) decodeValue(depth int) (any, error) {
+	if depth > maxCBORNestingDepth {
+		return nil, fmt.Errorf("maximum nesting depth of %d exceeded", maxCBORNestingDepth)
+	}
+
+	initial, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	major := initial >> 5
+	info := initial & 0x1f
+
+	if major == cborMajorSimple {
+		return d.decodeSimple(info)
+	}
+
+	argument, indefinite, err := d.readArgument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	if indefinite && (major == cborMajorUnsigned || major == cborMajorNegative || major == cborMajorTag) {
+		return nil, fmt.Errorf("invalid indefinite length for major type %d", major)
+	}
+
+	switch major {
+	case cborMajorUnsigned:
+		if argument > math.MaxInt64 {
+			return argument, nil
+		}
+		return int64(argument), nil
+
+	case cborMajorNegative:
+		if argument > math.MaxInt64 {
+			return nil, fmt.Errorf("negative integer overflows int64")
+		}
+		return -1 - int64(argument), nil
+
+	case cborMajorBytes:
+		b, err := d.decodeString(major, argument, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+
+	case cborMajorText:
+		b, err := d.decodeString(major, argument, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			return nil, fmt.Errorf("invalid UTF-8 in text string")
+		}
+		return string(b), nil
+
+	case cborMajorArray:
+		return d.decodeArray(argument, indefinite, depth)
+
+	case cborMajorMap:
+		return d.decodeMap(argument, indefinite, depth)
+
+	case cborMajorTag:
+		return d.decodeTagged(argument, depth)
+
+	default:
+		return nil, fmt.Errorf("unknown major type %d", major)
+	}
+}
+
+func (d *cborDecoder) decodeSimple(info byte) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+
+	case 21:
+		return true, nil
+
+	case 22, 23:
+		return nil, nil
+
+	case 25:
+		b, err := d.readBytes(2)
+		if err != nil {
+			return nil, err
+		}
+		return halfToFloat64(binary.BigEndian.Uint16(b)), nil
+
+	case 26:
+		b, err := d.readBytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+
+	case 27:
+		b, err := d.readBytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+
+	case cborIndefinite:
+		return nil, fmt.Errorf("unexpected break")
+
+	default:
+		return nil, fmt.Errorf("unsupported simple value %d", info)
+	}
+}
+
+func (d *cborDecoder) decodeString(major byte, length uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		return d.readBytes(length)
+	}
+
+	// Indefinite length strings are a sequence of definite length chunks of the same major type.
+	var built []byte
+	for !d.peekBreak() {
+		initial, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+
+		if initial>>5 != major {
+			return nil, fmt.Errorf("invalid chunk of major type %d in indefinite length string", initial>>5)
+		}
+
+		chunkLength, chunkIndefinite, err := d.readArgument(initial & 0x1f)
+		if err != nil {
+			return nil, err
+		}
+		if chunkIndefinite {
+			return nil, fmt.Errorf("nested indefinite length string")
+		}
+
+		chunk, err := d.readBytes(chunkLength)
+		if err != nil {
+			return nil, err
+		}
+		built = append(built, chunk...)
+	}
+
+	d.pos++ // break
+	return built, nil
+}
+
+func (d *cborDecoder) decodeArray(length uint64, indefinite bool, depth int) ([]any, error) {
+	if !indefinite && length > uint64(len(d.data)-d.pos) {
+		return nil, fmt.Errorf("array length %d exceeds remaining data", length)
+	}
+
+	items := make([]any, 0, length)
+	for index := uint64(0); indefinite || index < length; index++ {
+		if indefinite && d.peekBreak() {
+			d.pos++
+			break
+		}
+
+		item, err := d.decodeValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (d *cborDecoder) decodeMap(length uint64, indefinite bool, depth int) (map[string]any, error) {
+	if !indefinite && length > uint64(len(d.data)-d.pos) {
+		return nil, fmt.Errorf("map length %d exceeds remaining data", length)
+	}
+
+	entries := make(map[string]any, length)
+	for index := uint64(0); indefinite || index < length; index++ {
+		if indefinite && d.peekBreak() {
+			d.pos++
+			break
+		}
+
+		if d.pos < len(d.data) && d.data[d.pos]>>5 != cborMajorText {
+			return nil, fmt.Errorf("map keys must be text strings, found major type %d", d.data[d.pos]>>5)
+		}
+
+		key, err := d.decodeValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		keyString := key.(string)
+		if _, ok := entries[keyString]; ok {
+			return nil, fmt.Errorf("duplicate map key `%s`", keyString)
+		}
+
+		value, err := d.decodeValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		entries[keyString] = value
+	}
+	return entries, nil
+}
+
+func (d *cborDecoder) decodeTagged(tag uint64, depth int) (any, error) {
+	value, err := d.decodeValue(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case cborTagDateTimeString:
+		if _, ok := value.(string); !ok {
+			return nil, fmt.Errorf("date/time tag requires a text string, found %T", value)
+		}
+		return value, nil
+
+	case cborTagEpochDateTime:
+		var timestamp time.Time
+		switch t := value.(type) {
+		case int64:
+			timestamp = time.Unix(t, 0)
+		case float64:
+			if math.IsNaN(t) || math.IsInf(t, 0) || t >= math.MaxInt64 || t < math.MinInt64 {
+				return nil, fmt.Errorf("epoch date/time tag requires a finite number of seconds, found %v", t)
+			}
+			seconds, fraction := math.Modf(t)
+			timestamp = time.Unix(int64(seconds), int64(fraction*1e9))
+		default:
+			return nil, fmt.Errorf("epoch date/time tag requires a number, found %T", value)
+		}
+		return timestamp.UTC().Format(time.RFC3339Nano), nil
+
+	default:
+		// Other tags carry no information used by caveat context, so the tagged value is used as-is.
+		return value, nil
+	}
+}
+
+func halfToFloat64(half uint16) float64 {
+	exponent := int((half >> 10) & 0x1f)
+	mantissa := float64(half & 0x3ff)
+
+	var value float64
+	switch exponent {
+	case 0:
+		value = math.Ldexp(mantissa, -24)
+	case 0x1f:
+		if mantissa == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mantissa+1024, exponent-25)
+	}
+
+	if half&0x8000 != 0 {
+		return -value
+	}
+	return value
+}
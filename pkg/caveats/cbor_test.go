@@ -0,0 +1,281 @@
+package caveats
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+// encodeCBOR is a minimal CBOR encoder used to produce round-trip inputs for tests.
+func encodeCBOR(t *testing.T, value any) []byte {
+	header := func(major byte, argument uint64) []byte {
+		switch {
+		case argument < 24:
+			return []byte{major<<5 | byte(argument)}
+		case argument <= math.MaxUint8:
+			return []byte{major<<5 | 24, byte(argument)}
+		case argument <= math.MaxUint16:
+			return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(argument))
+		case argument <= math.MaxUint32:
+			return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(argument))
+		default:
+			return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, argument)
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return []byte{0xf6}
+	case bool:
+		if v {
+			return []byte{0xf5}
+		}
+		return []byte{0xf4}
+	case int64:
+		if v < 0 {
+			return header(cborMajorNegative, uint64(-1-v))
+		}
+		return header(cborMajorUnsigned, uint64(v))
+	case uint64:
+		return header(cborMajorUnsigned, v)
+	case float64:
+		return binary.BigEndian.AppendUint64([]byte{0xfb}, math.Float64bits(v))
+	case string:
+		return append(header(cborMajorText, uint64(len(v))), v...)
+	case []byte:
+		return append(header(cborMajorBytes, uint64(len(v))), v...)
+	case []any:
+		encoded := header(cborMajorArray, uint64(len(v)))
+		for _, item := range v {
+			encoded = append(encoded, encodeCBOR(t, item)...)
+		}
+		return encoded
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		encoded := header(cborMajorMap, uint64(len(v)))
+		for _, key := range keys {
+			encoded = append(encoded, encodeCBOR(t, key)...)
+			encoded = append(encoded, encodeCBOR(t, v[key])...)
+		}
+		return encoded
+	default:
+		require.Failf(t, "unsupported type", "%T", value)
+		return nil
+	}
+}
+
+func TestCaveatContextFromCBORRoundTrip(t *testing.T) {
+	input := map[string]any{
+		"anint":     int64(42),
+		"negative":  int64(-1000000),
+		"bigint":    int64(math.MaxInt64),
+		"biguint":   uint64(math.MaxUint64),
+		"adouble":   1.5,
+		"wholedbl":  3.0,
+		"astring":   "hello world",
+		"abool":     true,
+		"anull":     nil,
+		"somebytes": []byte("hi there"),
+		"alist":     []any{int64(1), "two", 3.5},
+		"amap": map[string]any{
+			"nested": map[string]any{
+				"value": false,
+			},
+		},
+	}
+
+	decoded, err := CaveatContextFromCBOR(encodeCBOR(t, input))
+	require.NoError(t, err)
+
+	expected := map[string]any{
+		"anint":     int64(42),
+		"negative":  int64(-1000000),
+		"bigint":    int64(math.MaxInt64),
+		"biguint":   uint64(math.MaxUint64),
+		"adouble":   1.5,
+		"wholedbl":  3.0,
+		"astring":   "hello world",
+		"abool":     true,
+		"anull":     nil,
+		"somebytes": "aGkgdGhlcmU=",
+		"alist":     []any{int64(1), "two", 3.5},
+		"amap": map[string]any{
+			"nested": map[string]any{
+				"value": false,
+			},
+		},
+	}
+	require.Equal(t, expected, decoded)
+}
+
+func TestCaveatContextFromCBORParameterConversion(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"count":     types.IntType,
+		"limit":     types.UIntType,
+		"ratio":     types.DoubleType,
+		"secret":    types.BytesType,
+		"timestamp": types.TimestampType,
+	})
+
+	decoded, err := CaveatContextFromCBOR(encodeCBOR(t, map[string]any{
+		"count":     3.0,
+		"limit":     uint64(math.MaxUint64),
+		"ratio":     int64(2),
+		"secret":    []byte{0x1, 0x2},
+		"timestamp": "2022-01-01T10:00:00Z",
+	}))
+	require.NoError(t, err)
+
+	converted, err := ConvertContextToParameters(decoded, env.EncodedParametersTypes(), ErrorForUnknownParameters)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), converted["count"])
+	require.Equal(t, uint64(math.MaxUint64), converted["limit"])
+	require.Equal(t, 2.0, converted["ratio"])
+	require.Equal(t, []byte{0x1, 0x2}, converted["secret"])
+
+	// A float with a fractional part cannot be used for an int, as with JSON.
+	decoded, err = CaveatContextFromCBOR(encodeCBOR(t, map[string]any{
+		"count": 3.5,
+	}))
+	require.NoError(t, err)
+
+	_, err = ConvertContextToParameters(decoded, env.EncodedParametersTypes(), ErrorForUnknownParameters)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "a int value is required, but found numeric value `3.5`")
+}
+
+func TestCaveatContextFromCBOREncodings(t *testing.T) {
+	tcs := []struct {
+		name          string
+		data          []byte
+		expected      map[string]any
+		expectedError string
+	}{
+		{
+			"half float",
+			[]byte{0xa1, 0x61, 'a', 0xf9, 0x3c, 0x00},
+			map[string]any{"a": 1.0},
+			"",
+		},
+		{
+			"single float",
+			[]byte{0xa1, 0x61, 'a', 0xfa, 0x47, 0xc3, 0x50, 0x00},
+			map[string]any{"a": 100000.0},
+			"",
+		},
+		{
+			"indefinite length array",
+			[]byte{0xa1, 0x61, 'a', 0x9f, 0x01, 0x02, 0xff},
+			map[string]any{"a": []any{int64(1), int64(2)}},
+			"",
+		},
+		{
+			"indefinite length map and string",
+			[]byte{0xbf, 0x61, 'a', 0x7f, 0x62, 's', 't', 0x61, 'r', 0xff, 0xff},
+			map[string]any{"a": "str"},
+			"",
+		},
+		{
+			"epoch timestamp tag",
+			[]byte{0xa1, 0x61, 'a', 0xc1, 0x1a, 0x51, 0x4b, 0x67, 0xb0},
+			map[string]any{"a": "2013-03-21T20:04:00Z"},
+			"",
+		},
+		{
+			"empty",
+			[]byte{},
+			nil,
+			"empty CBOR context",
+		},
+		{
+			"not a map",
+			[]byte{0x01},
+			nil,
+			"CBOR context must be a map",
+		},
+		{
+			"non-string key",
+			[]byte{0xa1, 0x01, 0x02},
+			nil,
+			"map keys must be text strings",
+		},
+		{
+			"negative integer overflow",
+			[]byte{0xa1, 0x61, 'a', 0x3b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			nil,
+			"negative integer overflows int64",
+		},
+		{
+			"truncated",
+			[]byte{0xa1, 0x61, 'a', 0x19, 0x01},
+			nil,
+			"unexpected end of data",
+		},
+		{
+			"duplicate key",
+			[]byte{0xa2, 0x61, 'a', 0x01, 0x61, 'a', 0x02},
+			nil,
+			"duplicate map key `a`",
+		},
+		{
+			"nested duplicate key",
+			[]byte{0xa1, 0x61, 'a', 0xbf, 0x61, 'b', 0x01, 0x61, 'b', 0x02, 0xff},
+			nil,
+			"duplicate map key `b`",
+		},
+		{
+			"NaN epoch timestamp tag",
+			[]byte{0xa1, 0x61, 'a', 0xc1, 0xf9, 0x7e, 0x00},
+			nil,
+			"epoch date/time tag requires a finite number of seconds",
+		},
+		{
+			"infinite epoch timestamp tag",
+			[]byte{0xa1, 0x61, 'a', 0xc1, 0xf9, 0x7c, 0x00},
+			nil,
+			"epoch date/time tag requires a finite number of seconds",
+		},
+		{
+			"negative infinite epoch timestamp tag",
+			[]byte{0xa1, 0x61, 'a', 0xc1, 0xf9, 0xfc, 0x00},
+			nil,
+			"epoch date/time tag requires a finite number of seconds",
+		},
+		{
+			"out of range epoch timestamp tag",
+			[]byte{0xa1, 0x61, 'a', 0xc1, 0xfb, 0x7e, 0x37, 0xe4, 0x3c, 0x88, 0x00, 0x75, 0x9c},
+			nil,
+			"epoch date/time tag requires a finite number of seconds",
+		},
+		{
+			"trailing bytes",
+			[]byte{0xa0, 0x00},
+			nil,
+			"found 1 trailing bytes",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, err := CaveatContextFromCBOR(tc.data)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, decoded)
+		})
+	}
+}
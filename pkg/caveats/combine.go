@@ -0,0 +1,129 @@
+package caveats
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"golang.org/x/exp/maps"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// CombinationOp is the boolean operator used to combine caveats.
+type CombinationOp int
+
+const (
+	// CombineAnd combines caveats into their conjunction.
+	CombineAnd CombinationOp = iota
+
+	// CombineOr combines caveats into their disjunction.
+	CombineOr
+)
+
+func (op CombinationOp) operator() (string, error) {
+	switch op {
+	case CombineAnd:
+		return "&&", nil
+	case CombineOr:
+		return "||", nil
+	default:
+		return "", fmt.Errorf("unknown caveat combination operator: %d", op)
+	}
+}
+
+// CombineCaveats combines the compiled caveats with the given operator into a new compiled
+// caveat, whose parameters are those of all the caveats. Returns an error if the same parameter
+// is declared with different types by two caveats. Should two caveats declare different default
+// values for the same parameter, that of the later caveat is used.
+//
+// The combined caveat is compiled under the environment of the first caveat, extended with the
+// parameters of the others: any custom functions called by the other caveats must therefore also
+// be declared in the environment of the first. Caveats which have been deserialized without
+// their parameter types cannot be combined, as their parameters are unknown.
+func CombineCaveats(op CombinationOp, caveats ...*CompiledCaveat) (*CompiledCaveat, error) {
+	operator, err := op.operator()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(caveats) == 0 {
+		return nil, fmt.Errorf("at least one caveat is required to combine")
+	}
+
+	names := make([]string, 0, len(caveats))
+	exprStrings := make([]string, 0, len(caveats))
+	parameterTypes := map[string]*core.CaveatTypeReference{}
+	sensitivities := map[string]string{}
+	defaults := map[string]any{}
+	impureFunctions := map[string]struct{}{}
+	for _, caveat := range caveats {
+		if caveat.parameterTypes == nil {
+			return nil, fmt.Errorf("the parameter types of caveat `%s` are unknown", caveat.name)
+		}
+
+		for paramName, paramType := range caveat.parameterTypes {
+			existing, ok := parameterTypes[paramName]
+			if ok && !existing.EqualVT(paramType) {
+				return nil, fmt.Errorf("parameter `%s` of caveat `%s` has a type conflicting with that of another caveat", paramName, caveat.name)
+			}
+			parameterTypes[paramName] = paramType
+		}
+
+		maps.Copy(sensitivities, caveat.sensitivities)
+		maps.Copy(defaults, caveat.defaults)
+		maps.Copy(impureFunctions, caveat.impureFunctions)
+
+		exprString, err := caveat.ExprString()
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, caveat.name)
+		exprStrings = append(exprStrings, "("+exprString+")")
+	}
+
+	var variables []cel.EnvOption
+	for paramName, paramType := range parameterTypes {
+		if _, ok := caveats[0].parameterTypes[paramName]; ok {
+			continue
+		}
+
+		varType, err := types.DecodeParameterType(paramType)
+		if err != nil {
+			return nil, err
+		}
+		variables = append(variables, cel.Variable(paramName, varType.CelType()))
+	}
+
+	celEnv, err := caveats[0].celEnv.Extend(variables...)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.Join(names, " "+operator+" ")
+	source := common.NewStringSource(strings.Join(exprStrings, " "+operator+" "), name)
+	ast, issues := celEnv.CompileSource(source)
+	if issues != nil && issues.Err() != nil {
+		return nil, newCompilationErrors(issues, source)
+	}
+
+	if len(sensitivities) == 0 {
+		sensitivities = nil
+	}
+	if len(defaults) == 0 {
+		defaults = nil
+	}
+
+	return &CompiledCaveat{
+		celEnv:          celEnv,
+		ast:             ast,
+		name:            name,
+		sensitivities:   sensitivities,
+		impureFunctions: impureFunctions,
+		parameterTypes:  parameterTypes,
+		defaults:        defaults,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package caveats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+func TestCombineCaveats(t *testing.T) {
+	first, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.IntType,
+	}), "a + b > 10", "first")
+	require.NoError(t, err)
+
+	second, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"b": types.IntType,
+		"c": types.StringType,
+	}), "b < 100 || c == 'admin'", "second")
+	require.NoError(t, err)
+
+	combined, err := CombineCaveats(CombineAnd, first, second)
+	require.NoError(t, err)
+	require.Equal(t, "first && second", combined.Name())
+
+	exprString, err := combined.ExprString()
+	require.NoError(t, err)
+	require.Equal(t, "a + b > 10 && (b < 100 || c == \"admin\")", exprString)
+
+	result, err := EvaluateCaveat(combined, map[string]any{"a": 5, "b": 10, "c": "user"})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+
+	result, err = EvaluateCaveat(combined, map[string]any{"a": 5, "b": 200, "c": "user"})
+	require.NoError(t, err)
+	require.False(t, result.Value())
+
+	// The combined caveat can be partially evaluated.
+	result, err = EvaluateCaveat(combined, map[string]any{"a": 5, "b": 200})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	missingVarNames, err := result.MissingVarNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"c"}, missingVarNames)
+
+	combined, err = CombineCaveats(CombineOr, first, second)
+	require.NoError(t, err)
+
+	result, err = EvaluateCaveat(combined, map[string]any{"a": 1, "b": 2, "c": "user"})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+
+	// The combined caveat can be serialized.
+	serialized, err := combined.Serialize()
+	require.NoError(t, err)
+
+	deserialized, err := DeserializeCaveat(serialized, nil)
+	require.NoError(t, err)
+
+	result, err = EvaluateCaveat(deserialized, map[string]any{"a": 20, "b": 200, "c": "user"})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+}
+
+func TestCombineCaveatsConflictingParameters(t *testing.T) {
+	first, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+	}), "a > 10", "first")
+	require.NoError(t, err)
+
+	second, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.StringType,
+	}), "a == 'hi'", "second")
+	require.NoError(t, err)
+
+	_, err = CombineCaveats(CombineAnd, first, second)
+	require.ErrorContains(t, err, "parameter `a` of caveat `second` has a type conflicting")
+}
+
+func TestCombineNoCaveats(t *testing.T) {
+	_, err := CombineCaveats(CombineAnd)
+	require.Error(t, err)
+}
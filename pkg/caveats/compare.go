@@ -0,0 +1,235 @@
+package caveats
+
+import (
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// EvaluationOutcome is the outcome of evaluating a caveat with a context.
+type EvaluationOutcome int
+
+const (
+	// OutcomeFalse indicates the caveat evaluated to false.
+	OutcomeFalse EvaluationOutcome = iota
+
+	// OutcomeTrue indicates the caveat evaluated to true.
+	OutcomeTrue
+
+	// OutcomePartial indicates the caveat could only be partially evaluated.
+	OutcomePartial
+
+	// OutcomeError indicates the evaluation of the caveat failed.
+	OutcomeError
+)
+
+// String returns the name of the outcome.
+func (o EvaluationOutcome) String() string {
+	switch o {
+	case OutcomeFalse:
+		return "false"
+	case OutcomeTrue:
+		return "true"
+	case OutcomePartial:
+		return "partial"
+	case OutcomeError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// EvaluationDiff is a difference in the outcome of evaluating two caveats with the same context.
+type EvaluationDiff struct {
+	// ContextIndex is the index of the context in the contexts given to CompareEvaluations.
+	ContextIndex int
+
+	// Context is the context with which both caveats were evaluated.
+	Context map[string]any
+
+	// OldOutcome is the outcome of evaluating the old caveat.
+	OldOutcome EvaluationOutcome
+
+	// NewOutcome is the outcome of evaluating the new caveat.
+	NewOutcome EvaluationOutcome
+
+	// OldErr is the error returned evaluating the old caveat, if OldOutcome is OutcomeError.
+	OldErr error
+
+	// NewErr is the error returned evaluating the new caveat, if NewOutcome is OutcomeError.
+	NewErr error
+}
+
+// CompareEvaluations evaluates both the old and new caveats with each of the given contexts and
+// returns the differences in outcome, such as a result changing from true to false, becoming
+// partial or failing with an error. Contexts producing the same outcome for both caveats are not
+// included. Intended for quantifying the change in behavior of a caveat against sample contexts
+// before changing its definition.
+func CompareEvaluations(oldCaveat, newCaveat *CompiledCaveat, contexts []map[string]any) []EvaluationDiff {
+	var diffs []EvaluationDiff
+	for index, contextValues := range contexts {
+		oldOutcome, oldErr := evaluationOutcome(oldCaveat, contextValues)
+		newOutcome, newErr := evaluationOutcome(newCaveat, contextValues)
+		if oldOutcome == newOutcome {
+			continue
+		}
+
+		diffs = append(diffs, EvaluationDiff{
+			ContextIndex: index,
+			Context:      contextValues,
+			OldOutcome:   oldOutcome,
+			NewOutcome:   newOutcome,
+			OldErr:       oldErr,
+			NewErr:       newErr,
+		})
+	}
+	return diffs
+}
+
+func evaluationOutcome(caveat *CompiledCaveat, contextValues map[string]any) (EvaluationOutcome, error) {
+	result, err := EvaluateCaveat(caveat, contextValues)
+	switch {
+	case err != nil:
+		return OutcomeError, err
+	case result.IsPartial():
+		return OutcomePartial, nil
+	case result.Value():
+		return OutcomeTrue, nil
+	default:
+		return OutcomeFalse, nil
+	}
+}
+
+// ParameterTypeChange is a parameter declared with a different type by two caveats.
+type ParameterTypeChange struct {
+	Name    string
+	OldType string
+	NewType string
+}
+
+// CaveatDiff is the difference between the definitions of two caveats, as found by DiffCaveats.
+type CaveatDiff struct {
+	// ExpressionChanged indicates that the expression of the caveat changed.
+	ExpressionChanged bool
+
+	// AddedParameters are the names of the parameters declared by the new caveat only, sorted.
+	AddedParameters []string
+
+	// RemovedParameters are the names of the parameters declared by the old caveat only, sorted.
+	RemovedParameters []string
+
+	// RetypedParameters are the parameters declared by both caveats with different types, sorted
+	// by name.
+	RetypedParameters []ParameterTypeChange
+
+	// ChangedDefaults are the names of the parameters declared by both caveats whose default
+	// value was added, removed or changed, sorted.
+	ChangedDefaults []string
+}
+
+// HasChanges returns whether the caveats differ.
+func (cd CaveatDiff) HasChanges() bool {
+	return cd.ExpressionChanged || len(cd.AddedParameters) > 0 || len(cd.RemovedParameters) > 0 ||
+		len(cd.RetypedParameters) > 0 || len(cd.ChangedDefaults) > 0
+}
+
+// IsBackwardCompatible returns whether context values valid for the parameters of the old caveat,
+// such as those stored on relationships, remain valid for the new caveat. Removing or retyping a
+// parameter is breaking, while adding a parameter is not, as its value can be supplied when
+// checking. A change of expression or of default values alone is not considered breaking, as it
+// changes the meaning of the caveat but not the context values it accepts.
+func (cd CaveatDiff) IsBackwardCompatible() bool {
+	return len(cd.RemovedParameters) == 0 && len(cd.RetypedParameters) == 0
+}
+
+// DiffCaveats returns the difference between the definitions of the old and new caveats, for
+// tooling reporting the changes to a schema. Expressions are compared in their string form, so
+// changes in formatting alone are not reported. Returns an error if the parameter types of either
+// caveat are unknown.
+func DiffCaveats(oldCaveat, newCaveat *CompiledCaveat) (CaveatDiff, error) {
+	for _, caveat := range []*CompiledCaveat{oldCaveat, newCaveat} {
+		if caveat.parameterTypes == nil {
+			return CaveatDiff{}, fmt.Errorf("the parameter types of caveat `%s` are unknown", caveat.name)
+		}
+	}
+
+	oldExprString, err := oldCaveat.ExprString()
+	if err != nil {
+		return CaveatDiff{}, err
+	}
+
+	newExprString, err := newCaveat.ExprString()
+	if err != nil {
+		return CaveatDiff{}, err
+	}
+
+	diff := CaveatDiff{ExpressionChanged: oldExprString != newExprString}
+
+	oldDefaults, err := oldCaveat.defaultsStruct()
+	if err != nil {
+		return CaveatDiff{}, err
+	}
+
+	newDefaults, err := newCaveat.defaultsStruct()
+	if err != nil {
+		return CaveatDiff{}, err
+	}
+
+	for paramName, oldType := range oldCaveat.parameterTypes {
+		newType, ok := newCaveat.parameterTypes[paramName]
+		if !ok {
+			diff.RemovedParameters = append(diff.RemovedParameters, paramName)
+			continue
+		}
+
+		if !proto.Equal(oldDefaults.GetFields()[paramName], newDefaults.GetFields()[paramName]) {
+			diff.ChangedDefaults = append(diff.ChangedDefaults, paramName)
+		}
+
+		if oldType.EqualVT(newType) {
+			continue
+		}
+
+		oldTypeString, err := parameterTypeString(oldType)
+		if err != nil {
+			return CaveatDiff{}, fmt.Errorf("parameter `%s`: %w", paramName, err)
+		}
+
+		newTypeString, err := parameterTypeString(newType)
+		if err != nil {
+			return CaveatDiff{}, fmt.Errorf("parameter `%s`: %w", paramName, err)
+		}
+
+		diff.RetypedParameters = append(diff.RetypedParameters, ParameterTypeChange{
+			Name:    paramName,
+			OldType: oldTypeString,
+			NewType: newTypeString,
+		})
+	}
+
+	for paramName := range newCaveat.parameterTypes {
+		if _, ok := oldCaveat.parameterTypes[paramName]; !ok {
+			diff.AddedParameters = append(diff.AddedParameters, paramName)
+		}
+	}
+
+	sort.Strings(diff.AddedParameters)
+	sort.Strings(diff.RemovedParameters)
+	sort.Strings(diff.ChangedDefaults)
+	sort.Slice(diff.RetypedParameters, func(i, j int) bool {
+		return diff.RetypedParameters[i].Name < diff.RetypedParameters[j].Name
+	})
+	return diff, nil
+}
+
+func parameterTypeString(paramType *core.CaveatTypeReference) (string, error) {
+	varType, err := types.DecodeParameterType(paramType)
+	if err != nil {
+		return "", err
+	}
+	return varType.String(), nil
+}
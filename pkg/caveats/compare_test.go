@@ -0,0 +1,136 @@
+package caveats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+func TestCompareEvaluations(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.IntType,
+	})
+
+	old, err := compileCaveat(env, "a > 10")
+	require.NoError(t, err)
+
+	updated, err := compileCaveat(env, "a > 20 && a / b > 1")
+	require.NoError(t, err)
+
+	contexts := []map[string]any{
+		{"a": 5, "b": 1},  // false -> false
+		{"a": 15, "b": 1}, // true -> false
+		{"a": 25, "b": 1}, // true -> true
+		{"a": 25},         // true -> partial
+		{"a": 25, "b": 0}, // true -> error
+	}
+
+	diffs := CompareEvaluations(old, updated, contexts)
+	require.Len(t, diffs, 3)
+
+	require.Equal(t, 1, diffs[0].ContextIndex)
+	require.Equal(t, contexts[1], diffs[0].Context)
+	require.Equal(t, OutcomeTrue, diffs[0].OldOutcome)
+	require.Equal(t, OutcomeFalse, diffs[0].NewOutcome)
+
+	require.Equal(t, 3, diffs[1].ContextIndex)
+	require.Equal(t, OutcomeTrue, diffs[1].OldOutcome)
+	require.Equal(t, OutcomePartial, diffs[1].NewOutcome)
+
+	require.Equal(t, 4, diffs[2].ContextIndex)
+	require.Equal(t, OutcomeTrue, diffs[2].OldOutcome)
+	require.Equal(t, OutcomeError, diffs[2].NewOutcome)
+	require.NoError(t, diffs[2].OldErr)
+	require.Error(t, diffs[2].NewErr)
+	require.Equal(t, "error", diffs[2].NewOutcome.String())
+
+	require.Empty(t, CompareEvaluations(old, old, contexts))
+}
+
+func TestDiffCaveats(t *testing.T) {
+	compile := func(vars map[string]types.VariableType, exprString string) *CompiledCaveat {
+		compiled, err := compileCaveat(MustEnvForVariables(vars), exprString)
+		require.NoError(t, err)
+		return compiled
+	}
+
+	oldCaveat := compile(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.StringType,
+		"c": types.IntType,
+	}, "a == 1 && b == 'x' && c > 0")
+
+	diff, err := DiffCaveats(oldCaveat, compile(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.StringType,
+		"c": types.IntType,
+	}, "a == 1  &&  b == \"x\" && c > 0"))
+	require.NoError(t, err)
+	require.False(t, diff.HasChanges())
+	require.True(t, diff.IsBackwardCompatible())
+
+	diff, err = DiffCaveats(oldCaveat, compile(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.StringType,
+		"c": types.IntType,
+		"d": types.BooleanType,
+	}, "a == 1 && b == 'x' && c > 0 && d"))
+	require.NoError(t, err)
+	require.True(t, diff.HasChanges())
+	require.True(t, diff.ExpressionChanged)
+	require.Equal(t, []string{"d"}, diff.AddedParameters)
+	require.True(t, diff.IsBackwardCompatible())
+
+	diff, err = DiffCaveats(oldCaveat, compile(map[string]types.VariableType{
+		"a": types.UIntType,
+		"b": types.StringType,
+	}, "a == 1 && b == 'x'"))
+	require.NoError(t, err)
+	require.True(t, diff.ExpressionChanged)
+	require.Empty(t, diff.AddedParameters)
+	require.Equal(t, []string{"c"}, diff.RemovedParameters)
+	require.Equal(t, []ParameterTypeChange{{Name: "a", OldType: "int", NewType: "uint"}}, diff.RetypedParameters)
+	require.False(t, diff.IsBackwardCompatible())
+
+	withDefaults := func(defaults map[string]any) *CompiledCaveat {
+		env := NewEnvironment()
+		require.NoError(t, env.AddVariable("a", types.IntType))
+		for name, value := range defaults {
+			require.NoError(t, env.AddVariableWithDefault(name, types.IntType, value))
+		}
+		for _, name := range []string{"b", "c"} {
+			if _, ok := defaults[name]; !ok {
+				require.NoError(t, env.AddVariable(name, types.IntType))
+			}
+		}
+
+		compiled, err := compileCaveat(env, "a + b + c > 0")
+		require.NoError(t, err)
+		return compiled
+	}
+
+	diff, err = DiffCaveats(withDefaults(map[string]any{"b": 1, "c": 2}), withDefaults(map[string]any{"b": 1, "c": 3}))
+	require.NoError(t, err)
+	require.True(t, diff.HasChanges())
+	require.False(t, diff.ExpressionChanged)
+	require.Equal(t, []string{"c"}, diff.ChangedDefaults)
+	require.True(t, diff.IsBackwardCompatible())
+
+	diff, err = DiffCaveats(withDefaults(map[string]any{"b": 1}), withDefaults(map[string]any{"c": 1}))
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, diff.ChangedDefaults)
+
+	diff, err = DiffCaveats(withDefaults(map[string]any{"b": 1}), withDefaults(map[string]any{"b": 1}))
+	require.NoError(t, err)
+	require.False(t, diff.HasChanges())
+
+	serialized, err := oldCaveat.Serialize()
+	require.NoError(t, err)
+	deserialized, err := DeserializeCaveat(serialized, nil)
+	require.NoError(t, err)
+	_, err = DiffCaveats(oldCaveat, deserialized)
+	require.Error(t, err)
+}
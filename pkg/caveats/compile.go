@@ -1,11 +1,22 @@
 package caveats
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/common"
+	"golang.org/x/exp/maps"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
 
+	"github.com/authzed/spicedb/pkg/caveats/types"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	impl "github.com/authzed/spicedb/pkg/proto/impl/v1"
 	"github.com/authzed/spicedb/pkg/util"
 )
@@ -22,6 +33,73 @@ type CompiledCaveat struct {
 
 	// name of the caveat
 	name string
+
+	// sensitivities holds the sensitivity classes declared for the parameters, if any.
+	sensitivities map[string]string
+
+	// impureFunctions holds the names of the impure functions declared in the environment.
+	impureFunctions map[string]struct{}
+
+	// parameterTypes holds the types of the parameters declared in the environment, if known.
+	parameterTypes map[string]*core.CaveatTypeReference
+
+	// defaults holds the default values declared for the parameters, if any.
+	defaults map[string]any
+}
+
+// CompileOption is an option for compiling a caveat.
+type CompileOption func(*compileConfig)
+
+type compileConfig struct {
+	pureEvaluation     bool
+	envOptions         []cel.EnvOption
+	maxExpressionDepth int
+}
+
+// DefaultMaxExpressionDepth is the default maximum depth of caveat expressions defined in schema.
+// See WithMaxExpressionDepth.
+const DefaultMaxExpressionDepth = 100
+
+// WithMaxExpressionDepth limits the depth of the compiled expression: compilation fails if the
+// parsed expression is nested more than maxDepth levels deep, which is checked before the
+// expression is type-checked. The depth of an expression without subexpressions, such as a
+// constant or an identifier, is one. Note that macros such as `all` are expanded by the parser
+// into comprehensions, which are a few levels deep. A limit of zero or less disables the check.
+func WithMaxExpressionDepth(maxDepth int) CompileOption {
+	return func(c *compileConfig) {
+		c.maxExpressionDepth = maxDepth
+	}
+}
+
+// WithPureEvaluation requires that the compiled caveat be deterministic: compilation fails
+// if the expression calls any function marked as impure in the environment.
+func WithPureEvaluation() CompileOption {
+	return func(c *compileConfig) {
+		c.pureEvaluation = true
+	}
+}
+
+// WithFunctionLibrary extends the environment of the compiled caveat with the given CEL
+// libraries, making the functions they declare available to the expression. The libraries are
+// retained by the compiled caveat and used for its evaluation.
+//
+// Note that libraries are not part of the serialized form of the caveat: a deserialized caveat
+// calling a function of a library cannot be evaluated.
+func WithFunctionLibrary(libraries ...cel.Library) CompileOption {
+	return func(c *compileConfig) {
+		for _, library := range libraries {
+			c.envOptions = append(c.envOptions, cel.Lib(library))
+		}
+	}
+}
+
+// WithEnvOptions extends the environment of the compiled caveat with the given CEL environment
+// options, such as functions declared with cel.Function. As with WithFunctionLibrary, the
+// options are used for evaluation of the compiled caveat but are not serialized.
+func WithEnvOptions(opts ...cel.EnvOption) CompileOption {
+	return func(c *compileConfig) {
+		c.envOptions = append(c.envOptions, opts...)
+	}
 }
 
 // Name represents a user-friendly reference to a caveat
@@ -34,23 +112,142 @@ func (cc CompiledCaveat) ExprString() (string, error) {
 	return cel.AstToString(cc.ast)
 }
 
-// Serialize serializes the compiled caveat into a byte string for storage.
+// Serialize serializes the compiled caveat into a byte string for storage, including the default
+// values of its parameters.
 func (cc CompiledCaveat) Serialize() ([]byte, error) {
 	cexpr, err := cel.AstToCheckedExpr(cc.ast)
 	if err != nil {
 		return nil, err
 	}
 
+	defaults, err := cc.defaultsStruct()
+	if err != nil {
+		return nil, err
+	}
+
 	caveat := &impl.DecodedCaveat{
 		KindOneof: &impl.DecodedCaveat_Cel{
 			Cel: cexpr,
 		},
-		Name: cc.name,
+		Name:     cc.name,
+		Defaults: defaults,
 	}
 
 	return caveat.MarshalVT()
 }
 
+// defaultsStruct returns the default values of the parameters of the caveat as a struct, in the
+// form of context values stored on relationships, or nil if none.
+func (cc CompiledCaveat) defaultsStruct() (*structpb.Struct, error) {
+	if len(cc.defaults) == 0 {
+		return nil, nil
+	}
+
+	defaults := make(map[string]any, len(cc.defaults))
+	for name, value := range cc.defaults {
+		converted, err := structContextValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert default value of parameter `%s`: %w", name, err)
+		}
+		defaults[name] = converted
+	}
+
+	defaultsStruct, err := structpb.NewStruct(defaults)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert default values: %w", err)
+	}
+	return defaultsStruct, nil
+}
+
+// fingerprintVersion is the version of the fingerprint format, included in each fingerprint.
+const fingerprintVersion = "v1"
+
+// Fingerprint returns a stable hash of the semantics of the caveat: its checked expression and
+// the declarations of its parameters, including their default values. Caveats differing only in
+// the order in which their parameters were declared, in their name, or in the formatting of their
+// source expression have the same fingerprint.
+//
+// The fingerprint is `v1:` followed by the hex-encoded SHA-256 hash of:
+//   - the checked expression, without its source information, serialized as protobuf with
+//     deterministic marshaling, prefixed by its length as a 64-bit big-endian integer;
+//   - followed by, for each parameter in order of name, its name and its type (e.g.
+//     `list<int>`), each followed by a zero byte;
+//   - followed by, if any parameter has a default value, a 0xff byte and, for each such
+//     parameter in order of name, its name followed by a zero byte and its default value, as
+//     stored by Serialize, serialized as a protobuf Value with deterministic marshaling and
+//     prefixed by its length as a 64-bit big-endian integer.
+//
+// Caveats without default values therefore keep the fingerprint they had before default values
+// were hashed.
+//
+// Returns an error if the declared parameters are unknown, which is the case for a caveat
+// deserialized without its parameter types.
+func (cc CompiledCaveat) Fingerprint() (string, error) {
+	if cc.parameterTypes == nil {
+		return "", fmt.Errorf("the parameter types of caveat `%s` are unknown", cc.name)
+	}
+
+	cexpr, err := cel.AstToCheckedExpr(cc.ast)
+	if err != nil {
+		return "", err
+	}
+
+	cexpr = proto.Clone(cexpr).(*exprpb.CheckedExpr)
+	cexpr.SourceInfo = nil
+
+	exprBytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(cexpr)
+	if err != nil {
+		return "", err
+	}
+
+	hasher := sha256.New()
+	if err := binary.Write(hasher, binary.BigEndian, uint64(len(exprBytes))); err != nil {
+		return "", err
+	}
+	hasher.Write(exprBytes)
+
+	paramNames := maps.Keys(cc.parameterTypes)
+	sort.Strings(paramNames)
+	for _, paramName := range paramNames {
+		varType, err := types.DecodeParameterType(cc.parameterTypes[paramName])
+		if err != nil {
+			return "", err
+		}
+
+		hasher.Write([]byte(paramName))
+		hasher.Write([]byte{0})
+		hasher.Write([]byte(varType.String()))
+		hasher.Write([]byte{0})
+	}
+
+	defaults, err := cc.defaultsStruct()
+	if err != nil {
+		return "", err
+	}
+
+	if defaults != nil {
+		hasher.Write([]byte{0xff})
+
+		defaultNames := maps.Keys(defaults.Fields)
+		sort.Strings(defaultNames)
+		for _, paramName := range defaultNames {
+			valueBytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(defaults.Fields[paramName])
+			if err != nil {
+				return "", err
+			}
+
+			hasher.Write([]byte(paramName))
+			hasher.Write([]byte{0})
+			if err := binary.Write(hasher, binary.BigEndian, uint64(len(valueBytes))); err != nil {
+				return "", err
+			}
+			hasher.Write(valueBytes)
+		}
+	}
+
+	return fingerprintVersion + ":" + hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
 // ReferencedParameters returns the names of the parameters referenced in the expression.
 func (cc CompiledCaveat) ReferencedParameters(parameters []string) *util.Set[string] {
 	referencedParams := util.NewSet[string]()
@@ -61,10 +258,103 @@ func (cc CompiledCaveat) ReferencedParameters(parameters []string) *util.Set[str
 	return referencedParams
 }
 
+// ReferencedParameterTypes returns the declared type of each parameter referenced in the
+// expression. Parameters declared but not referenced are not included. Returns an error if the
+// declared parameters are unknown, which is the case for a caveat deserialized without its
+// parameter types.
+func (cc CompiledCaveat) ReferencedParameterTypes() (map[string]*types.VariableType, error) {
+	if cc.parameterTypes == nil {
+		return nil, fmt.Errorf("the parameter types of caveat `%s` are unknown", cc.name)
+	}
+
+	referenced := cc.ReferencedParameters(maps.Keys(cc.parameterTypes))
+	parameterTypes := make(map[string]*types.VariableType, referenced.Len())
+	for _, paramName := range referenced.AsSlice() {
+		varType, err := types.DecodeParameterType(cc.parameterTypes[paramName])
+		if err != nil {
+			return nil, fmt.Errorf("parameter `%s` of caveat `%s`: %w", paramName, cc.name, err)
+		}
+		parameterTypes[paramName] = varType
+	}
+	return parameterTypes, nil
+}
+
+// Validate returns an error if the expression references any variable without a declared
+// parameter type, listing all such variables. Expressions compiled from source cannot reference
+// undeclared variables, but caveats deserialized with parameter types not matching their
+// expression can, in which case their evaluation would be partial regardless of the context
+// given. Returns an error if the declared parameters are unknown, which is the case for a caveat
+// deserialized without its parameter types.
+func (cc CompiledCaveat) Validate() error {
+	if cc.parameterTypes == nil {
+		return fmt.Errorf("the parameter types of caveat `%s` are unknown", cc.name)
+	}
+
+	identifiers := map[int64]string{}
+	variableIdentifiers(cc.ast.Expr(), map[string]int{}, identifiers)
+
+	undeclared := util.NewSet[string]()
+	for _, name := range identifiers {
+		if _, ok := cc.parameterTypes[name]; !ok {
+			undeclared.Add(name)
+		}
+	}
+
+	if undeclared.IsEmpty() {
+		return nil
+	}
+
+	names := undeclared.AsSlice()
+	sort.Strings(names)
+	return fmt.Errorf("caveat `%s` references undeclared parameter(s) `%s`", cc.name, strings.Join(names, "`, `"))
+}
+
+// SensitivityClasses returns the declared sensitivity class for each parameter referenced by
+// the caveat expression. Parameters declared without a sensitivity class are not included.
+// Note that sensitivity classes are not stored in the serialized form of the caveat.
+func (cc CompiledCaveat) SensitivityClasses() map[string]string {
+	if len(cc.sensitivities) == 0 {
+		return map[string]string{}
+	}
+
+	referenced := cc.ReferencedParameters(maps.Keys(cc.sensitivities))
+	classes := make(map[string]string, referenced.Len())
+	for _, paramName := range referenced.AsSlice() {
+		classes[paramName] = cc.sensitivities[paramName]
+	}
+	return classes
+}
+
+// IsDeterministic returns true if the caveat does not call any function marked as impure in
+// the environment under which it was compiled, and therefore always evaluates to the same
+// result for the same context.
+func (cc CompiledCaveat) IsDeterministic() bool {
+	return len(cc.referencedImpureFunctions()) == 0
+}
+
+// referencedImpureFunctions returns the sorted names of the impure functions called by the caveat.
+func (cc CompiledCaveat) referencedImpureFunctions() []string {
+	if len(cc.impureFunctions) == 0 {
+		return nil
+	}
+
+	referencedFuncs := util.NewSet[string]()
+	referencedFunctions(cc.ast.Expr(), referencedFuncs)
+
+	var impure []string
+	for _, funcName := range referencedFuncs.AsSlice() {
+		if _, ok := cc.impureFunctions[funcName]; ok {
+			impure = append(impure, funcName)
+		}
+	}
+	sort.Strings(impure)
+	return impure
+}
+
 // CompileCaveatWithName compiles a caveat string into a compiled caveat with a given name,
 // or returns the compilation errors.
-func CompileCaveatWithName(env *Environment, exprString, name string) (*CompiledCaveat, error) {
-	c, err := CompileCaveatWithSource(env, name, common.NewStringSource(exprString, name))
+func CompileCaveatWithName(env *Environment, exprString, name string, opts ...CompileOption) (*CompiledCaveat, error) {
+	c, err := CompileCaveatWithSource(env, name, common.NewStringSource(exprString, name), opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -73,39 +363,109 @@ func CompileCaveatWithName(env *Environment, exprString, name string) (*Compiled
 }
 
 // CompileCaveatWithSource compiles a caveat source into a compiled caveat, or returns the compilation errors.
-func CompileCaveatWithSource(env *Environment, name string, source common.Source) (*CompiledCaveat, error) {
+func CompileCaveatWithSource(env *Environment, name string, source common.Source, opts ...CompileOption) (*CompiledCaveat, error) {
+	config := &compileConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
 	celEnv, err := env.asCelEnvironment()
 	if err != nil {
 		return nil, err
 	}
 
-	ast, issues := celEnv.CompileSource(source)
+	if len(config.envOptions) > 0 {
+		celEnv, err = celEnv.Extend(config.envOptions...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ast, issues := celEnv.ParseSource(source)
+	if issues != nil && issues.Err() != nil {
+		return nil, newCompilationErrors(issues, source)
+	}
+
+	if config.maxExpressionDepth > 0 {
+		if depth := expressionDepth(ast.Expr()); depth > config.maxExpressionDepth {
+			return nil, CompilationErrors{fmt.Errorf("caveat expression exceeds the maximum depth of %d: found depth %d", config.maxExpressionDepth, depth), nil}
+		}
+	}
+
+	ast, issues = celEnv.Check(ast)
 	if issues != nil && issues.Err() != nil {
-		return nil, CompilationErrors{issues.Err(), issues}
+		return nil, newCompilationErrors(issues, source)
+	}
+
+	if err := validateBooleanOutput(ast); err != nil {
+		return nil, CompilationErrors{err, nil}
+	}
+
+	if err := validateRegularExpressions(ast.Expr()); err != nil {
+		return nil, CompilationErrors{err, nil}
+	}
+
+	compiled := &CompiledCaveat{
+		celEnv:          celEnv,
+		ast:             ast,
+		name:            name,
+		sensitivities:   env.sensitivitiesCopy(),
+		impureFunctions: maps.Clone(env.impureFunctions),
+		parameterTypes:  env.EncodedParametersTypes(),
+		defaults:        env.defaultsCopy(),
 	}
 
-	if ast.OutputType() != cel.BoolType {
-		return nil, CompilationErrors{fmt.Errorf("caveat expression must result in a boolean value: found `%s`", ast.OutputType().String()), nil}
+	if config.pureEvaluation {
+		if impure := compiled.referencedImpureFunctions(); len(impure) > 0 {
+			return nil, CompilationErrors{fmt.Errorf("caveat expression must be deterministic: found call(s) to impure function(s) `%s`", strings.Join(impure, "`, `")), nil}
+		}
 	}
 
-	compiled := &CompiledCaveat{celEnv, ast, anonymousCaveat}
-	compiled.name = name
 	return compiled, nil
 }
 
+// validateBooleanOutput ensures that the checked expression results in a boolean value, as
+// required by CaveatResult. Expressions whose type is only known at evaluation, such as `dyn`,
+// are rejected as well, since nothing guarantees they will produce a boolean.
+func validateBooleanOutput(ast *cel.Ast) error {
+	if outputType := ast.OutputType(); outputType != cel.BoolType {
+		return fmt.Errorf("caveat expression must result in a boolean value: found `%s`", outputType.String())
+	}
+	return nil
+}
+
 // compileCaveat compiles a caveat string into a compiled caveat, or returns the compilation errors.
-func compileCaveat(env *Environment, exprString string) (*CompiledCaveat, error) {
+func compileCaveat(env *Environment, exprString string, opts ...CompileOption) (*CompiledCaveat, error) {
 	s := common.NewStringSource(exprString, "caveat")
-	return CompileCaveatWithSource(env, "caveat", s)
+	return CompileCaveatWithSource(env, "caveat", s, opts...)
 }
 
 // DeserializeCaveat deserializes a byte-serialized caveat back into a CompiledCaveat.
-func DeserializeCaveat(serialized []byte) (*CompiledCaveat, error) {
+//
+// The serialized form holds the checked expression, the name of the caveat and the default values
+// of its parameters, but not the declarations of its parameters, which are stored separately
+// (e.g. in the CaveatDefinition). If the parameter types are given, the environment of the
+// caveat is restored with them declared, as by compilation, and the default values are converted
+// to them; otherwise, the parameters of the caveat are unknown and the default values are not
+// restored.
+func DeserializeCaveat(serialized []byte, parameterTypes map[string]*core.CaveatTypeReference) (*CompiledCaveat, error) {
 	if len(serialized) == 0 {
 		return nil, fmt.Errorf("given empty serialized")
 	}
 
-	celEnv, err := NewEnvironment().asCelEnvironment()
+	env := NewEnvironment()
+	for paramName, paramType := range parameterTypes {
+		varType, err := types.DecodeParameterType(paramType)
+		if err != nil {
+			return nil, fmt.Errorf("parameter `%s`: %w", paramName, err)
+		}
+
+		if err := env.AddVariable(paramName, *varType); err != nil {
+			return nil, err
+		}
+	}
+
+	celEnv, err := env.asCelEnvironment()
 	if err != nil {
 		return nil, err
 	}
@@ -117,5 +477,19 @@ func DeserializeCaveat(serialized []byte) (*CompiledCaveat, error) {
 	}
 
 	ast := cel.CheckedExprToAst(caveat.GetCel())
-	return &CompiledCaveat{celEnv, ast, caveat.Name}, nil
+	if err := validateBooleanOutput(ast); err != nil {
+		return nil, err
+	}
+
+	compiled := &CompiledCaveat{celEnv: celEnv, ast: ast, name: caveat.Name}
+	if parameterTypes != nil {
+		compiled.parameterTypes = maps.Clone(parameterTypes)
+
+		defaults, err := ConvertContextToParameters(caveat.GetDefaults().AsMap(), parameterTypes, ErrorForUnknownParameters)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert default values: %w", err)
+		}
+		compiled.defaults = defaults
+	}
+	return compiled, nil
 }
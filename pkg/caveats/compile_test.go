@@ -2,11 +2,17 @@ package caveats
 
 import (
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/cel-go/cel"
+	celtypes "github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
 	"github.com/stretchr/testify/require"
 
 	"github.com/authzed/spicedb/pkg/caveats/types"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
 func TestCompile(t *testing.T) {
@@ -113,6 +119,22 @@ func TestCompile(t *testing.T) {
 			"a + b",
 			[]string{"caveat expression must result in a boolean value: found `int`"},
 		},
+		{
+			"string expression",
+			MustEnvForVariables(map[string]types.VariableType{
+				"a": types.StringType,
+			}),
+			"a + \"suffix\"",
+			[]string{"caveat expression must result in a boolean value: found `string`"},
+		},
+		{
+			"dynamic expression",
+			MustEnvForVariables(map[string]types.VariableType{
+				"a": types.BooleanType,
+			}),
+			"dyn(a)",
+			[]string{"caveat expression must result in a boolean value: found `dyn`"},
+		},
 		{
 			"valid expression over a byte sequence",
 			MustEnvForVariables(map[string]types.VariableType{
@@ -208,8 +230,41 @@ func TestCompile(t *testing.T) {
 	}
 }
 
+func TestCompilationErrorsReportAllIssues(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+	})
+
+	_, err := compileCaveat(env, "a == 1 &&\n  missing > 2 &&\n  other < 3")
+	require.Error(t, err)
+
+	var compilationErrs CompilationErrors
+	require.True(t, errors.As(err, &compilationErrs))
+
+	issues := compilationErrs.Errors()
+	require.Len(t, issues, 2)
+
+	require.Contains(t, issues[0].Error(), "undeclared reference to 'missing'")
+	require.Equal(t, 1, issues[0].Location().LineNumber)
+	require.Equal(t, "  missing > 2 &&", issues[0].Snippet())
+
+	require.Contains(t, issues[1].Error(), "undeclared reference to 'other'")
+	require.Equal(t, 2, issues[1].Location().LineNumber)
+	require.Equal(t, "  other < 3", issues[1].Snippet())
+
+	// The position of the error is that of its first issue.
+	require.Equal(t, issues[0].Location().LineNumber, compilationErrs.LineNumber())
+	require.Equal(t, issues[0].Location().ColumnPosition, compilationErrs.ColumnPosition())
+
+	// Errors which are not attached to a position have no issues.
+	_, err = compileCaveat(env, "a + 1")
+	require.True(t, errors.As(err, &compilationErrs))
+	require.Empty(t, compilationErrs.Errors())
+	require.Equal(t, 0, compilationErrs.LineNumber())
+}
+
 func TestDeserializeEmpty(t *testing.T) {
-	_, err := DeserializeCaveat([]byte{})
+	_, err := DeserializeCaveat([]byte{}, nil)
 	require.NotNil(t, err)
 }
 
@@ -228,7 +283,7 @@ func TestSerialization(t *testing.T) {
 			serialized, err := compiled.Serialize()
 			require.NoError(t, err)
 
-			deserialized, err := DeserializeCaveat(serialized)
+			deserialized, err := DeserializeCaveat(serialized, nil)
 			require.NoError(t, err)
 
 			astExpr, err := deserialized.ExprString()
@@ -238,6 +293,20 @@ func TestSerialization(t *testing.T) {
 	}
 }
 
+func TestDeserializeNonBooleanCaveat(t *testing.T) {
+	celEnv, err := cel.NewEnv(cel.Variable("a", cel.IntType))
+	require.NoError(t, err)
+
+	ast, issues := celEnv.Compile("a + 1")
+	require.NoError(t, issues.Err())
+
+	serialized, err := CompiledCaveat{celEnv: celEnv, ast: ast, name: "notbool"}.Serialize()
+	require.NoError(t, err)
+
+	_, err = DeserializeCaveat(serialized, nil)
+	require.ErrorContains(t, err, "caveat expression must result in a boolean value: found `int`")
+}
+
 func TestSerializeName(t *testing.T) {
 	env := MustEnvForVariables(map[string]types.VariableType{
 		"a": types.IntType,
@@ -249,8 +318,377 @@ func TestSerializeName(t *testing.T) {
 	serialized, err := compiled.Serialize()
 	require.NoError(t, err)
 
-	deserialized, err := DeserializeCaveat(serialized)
+	deserialized, err := DeserializeCaveat(serialized, nil)
 	require.NoError(t, err)
 
 	require.Equal(t, "hi", deserialized.name)
 }
+
+func envWithImpureNow(t *testing.T) *Environment {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"expiration": types.TimestampType,
+		"count":      types.IntType,
+	})
+
+	err := env.AddFunction("now", ImpureFunction, cel.Overload("now", []*cel.Type{}, cel.TimestampType,
+		cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+			return celtypes.Timestamp{Time: time.Now().UTC()}
+		}),
+	))
+	require.NoError(t, err)
+	return env
+}
+
+func TestCompilePureEvaluation(t *testing.T) {
+	env := envWithImpureNow(t)
+
+	_, err := compileCaveat(env, "now() < expiration", WithPureEvaluation())
+	require.Error(t, err)
+	require.True(t, errors.As(err, &CompilationErrors{}))
+	require.Contains(t, err.Error(), "found call(s) to impure function(s) `now`")
+
+	// Without pure evaluation, the caveat compiles but is not deterministic.
+	compiled, err := compileCaveat(env, "now() < expiration")
+	require.NoError(t, err)
+	require.False(t, compiled.IsDeterministic())
+
+	result, err := EvaluateCaveat(compiled, map[string]any{"expiration": time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+
+	// Expressions not calling impure functions compile in pure mode.
+	compiled, err = compileCaveat(env, "count + 1 > 2", WithPureEvaluation())
+	require.NoError(t, err)
+	require.True(t, compiled.IsDeterministic())
+}
+
+func TestAddFunctionWithoutOverloads(t *testing.T) {
+	err := NewEnvironment().AddFunction("now", ImpureFunction)
+	require.ErrorContains(t, err, "requires at least one overload")
+}
+
+type sameDayLibrary struct{}
+
+func (sameDayLibrary) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("time.same_day",
+			cel.Overload("time_same_day_timestamp_timestamp", []*cel.Type{cel.TimestampType, cel.TimestampType}, cel.BoolType,
+				cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+					lhsTime := lhs.(celtypes.Timestamp).Time
+					rhsTime := rhs.(celtypes.Timestamp).Time
+					return celtypes.Bool(lhsTime.Truncate(24 * time.Hour).Equal(rhsTime.Truncate(24 * time.Hour)))
+				}),
+			),
+		),
+	}
+}
+
+func (sameDayLibrary) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
+
+func TestCompileWithFunctionLibrary(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"a": types.TimestampType,
+		"b": types.TimestampType,
+	})
+
+	// Without the library, the function is unknown.
+	_, err := compileCaveat(env, "time.same_day(a, b)")
+	require.Error(t, err)
+
+	compiled, err := compileCaveat(env, "time.same_day(a, b)", WithFunctionLibrary(sameDayLibrary{}))
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{
+		"a": time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+		"b": time.Date(2022, 1, 1, 20, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+
+	result, err = EvaluateCaveat(compiled, map[string]any{
+		"a": time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+		"b": time.Date(2022, 1, 2, 10, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.False(t, result.Value())
+
+	// An argument referencing a missing variable results in a partial result.
+	result, err = EvaluateCaveat(compiled, map[string]any{
+		"a": time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	missingVarNames, err := result.MissingVarNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, missingVarNames)
+}
+
+func TestCompileWithEnvOptions(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+	})
+
+	compiled, err := compileCaveat(env, "is_even(a)", WithEnvOptions(
+		cel.Function("is_even",
+			cel.Overload("is_even_int", []*cel.Type{cel.IntType}, cel.BoolType,
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					return celtypes.Bool(arg.(celtypes.Int)%2 == 0)
+				}),
+			),
+		),
+	))
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{"a": 4})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+}
+
+func TestSerializationRoundTripWithParameterTypes(t *testing.T) {
+	filter, err := types.NewBloomFilter(10, 0.01)
+	require.NoError(t, err)
+	filter.Add("tom")
+
+	tcs := []struct {
+		name       string
+		paramType  types.VariableType
+		exprString string
+		value      any
+	}{
+		{"any", types.AnyType, "p == p", 42},
+		{"bool", types.BooleanType, "p", true},
+		{"string", types.StringType, `p == "hi"`, "hi"},
+		{"int", types.IntType, "p == 42", 42},
+		{"uint", types.UIntType, "p == p", 42},
+		{"double", types.DoubleType, "p > 1.25", 1.5},
+		{"bytes", types.BytesType, "size(p) == 2", "aGk="},
+		{"duration", types.DurationType, `p == duration("1h")`, "1h"},
+		{"timestamp", types.TimestampType, `p == timestamp("2022-01-01T00:00:00Z")`, "2022-01-01T00:00:00Z"},
+		{"list", types.MustListType(types.IntType), "1 in p", []any{1, 2}},
+		{"map", types.MustMapType(types.StringType), `p.a == "b"`, map[string]any{"a": "b"}},
+		{"ipaddress", types.IPAddressType, `p.in_cidr("10.0.0.0/8")`, "10.1.2.3"},
+		{"bloomfilter", types.BloomFilterType, `maybe_contains(p, "tom")`, filter.Serialize()},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			env := MustEnvForVariables(map[string]types.VariableType{
+				"p":      tc.paramType,
+				"unused": types.IntType,
+			})
+			compiled, err := CompileCaveatWithName(env, tc.exprString, "somecaveat")
+			require.NoError(t, err)
+
+			serialized, err := compiled.Serialize()
+			require.NoError(t, err)
+
+			parameterTypes := env.EncodedParametersTypes()
+			deserialized, err := DeserializeCaveat(serialized, parameterTypes)
+			require.NoError(t, err)
+			require.Equal(t, "somecaveat", deserialized.Name())
+
+			exprString, err := deserialized.ExprString()
+			require.NoError(t, err)
+			require.Equal(t, tc.exprString, exprString)
+
+			referenced, err := deserialized.ReferencedParameterTypes()
+			require.NoError(t, err)
+			require.Len(t, referenced, 1)
+			require.Equal(t, tc.paramType.String(), referenced["p"].String())
+
+			typedParameters, err := ConvertContextToParameters(map[string]any{"p": tc.value}, parameterTypes, ErrorForUnknownParameters)
+			require.NoError(t, err)
+
+			result, err := EvaluateCaveat(deserialized, typedParameters)
+			require.NoError(t, err)
+			require.True(t, result.Value())
+
+			// The restored environment declares the parameters, allowing the caveat to be
+			// combined with others.
+			combined, err := CombineCaveats(CombineAnd, deserialized, compiled)
+			require.NoError(t, err)
+
+			result, err = EvaluateCaveat(combined, typedParameters)
+			require.NoError(t, err)
+			require.True(t, result.Value())
+		})
+	}
+}
+
+func TestSerializationRoundTripWithDefaults(t *testing.T) {
+	env := NewEnvironment()
+	require.NoError(t, env.AddVariable("used", types.IntType))
+	require.NoError(t, env.AddVariableWithDefault("threshold", types.IntType, 10))
+	require.NoError(t, env.AddVariableWithDefault("expires", types.TimestampType, "2030-01-01T00:00:00Z"))
+	require.NoError(t, env.AddVariableWithDefault("allowed", types.MustListType(types.StringType), []any{"tom"}))
+	require.NoError(t, env.AddVariable("now", types.TimestampType))
+	require.NoError(t, env.AddVariable("user", types.StringType))
+
+	compiled, err := CompileCaveatWithName(env, "used > threshold && now < expires && user in allowed", "somecaveat")
+	require.NoError(t, err)
+
+	serialized, err := compiled.Serialize()
+	require.NoError(t, err)
+
+	deserialized, err := DeserializeCaveat(serialized, env.EncodedParametersTypes())
+	require.NoError(t, err)
+	require.Equal(t, compiled.defaults, deserialized.defaults)
+
+	contextValues := map[string]any{
+		"used": int64(11),
+		"now":  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		"user": "tom",
+	}
+
+	result, err := EvaluateCaveat(deserialized, contextValues)
+	require.NoError(t, err)
+	require.False(t, result.IsPartial())
+	require.True(t, result.Value())
+
+	contextValues["threshold"] = int64(20)
+	result, err = EvaluateCaveat(deserialized, contextValues)
+	require.NoError(t, err)
+	require.False(t, result.Value())
+
+	// Without the parameter types, the defaults cannot be restored.
+	deserialized, err = DeserializeCaveat(serialized, nil)
+	require.NoError(t, err)
+	require.Nil(t, deserialized.defaults)
+}
+
+func TestDeserializeWithInvalidParameterType(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+	}), "a == 1")
+	require.NoError(t, err)
+
+	serialized, err := compiled.Serialize()
+	require.NoError(t, err)
+
+	_, err = DeserializeCaveat(serialized, map[string]*core.CaveatTypeReference{
+		"a": {TypeName: "unknowntype"},
+	})
+	require.ErrorContains(t, err, "unknown caveat parameter type `unknowntype`")
+}
+
+func TestFingerprint(t *testing.T) {
+	firstEnv := NewEnvironment()
+	require.NoError(t, firstEnv.AddVariable("a", types.IntType))
+	require.NoError(t, firstEnv.AddVariable("b", types.MustListType(types.StringType)))
+
+	secondEnv := NewEnvironment()
+	require.NoError(t, secondEnv.AddVariable("b", types.MustListType(types.StringType)))
+	require.NoError(t, secondEnv.AddVariable("a", types.IntType))
+
+	first, err := CompileCaveatWithName(firstEnv, "a > 10 && 'hi' in b", "first")
+	require.NoError(t, err)
+
+	second, err := CompileCaveatWithName(secondEnv, "a   >   10\n  &&   'hi' in b", "second")
+	require.NoError(t, err)
+
+	firstFingerprint, err := first.Fingerprint()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(firstFingerprint, "v1:"))
+
+	secondFingerprint, err := second.Fingerprint()
+	require.NoError(t, err)
+	require.Equal(t, firstFingerprint, secondFingerprint)
+
+	// A different expression has a different fingerprint.
+	differentExpr, err := CompileCaveatWithName(firstEnv, "a > 11 && 'hi' in b", "first")
+	require.NoError(t, err)
+
+	differentExprFingerprint, err := differentExpr.Fingerprint()
+	require.NoError(t, err)
+	require.NotEqual(t, firstFingerprint, differentExprFingerprint)
+
+	// A different parameter type has a different fingerprint, even if unreferenced.
+	differentParams, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.MustListType(types.StringType),
+		"c": types.BooleanType,
+	}), "a > 10 && 'hi' in b", "first")
+	require.NoError(t, err)
+
+	differentParamsFingerprint, err := differentParams.Fingerprint()
+	require.NoError(t, err)
+	require.NotEqual(t, firstFingerprint, differentParamsFingerprint)
+
+	// A different default value has a different fingerprint.
+	withDefaultEnv := NewEnvironment()
+	require.NoError(t, withDefaultEnv.AddVariableWithDefault("a", types.IntType, 5))
+	require.NoError(t, withDefaultEnv.AddVariable("b", types.MustListType(types.StringType)))
+
+	withDefault, err := CompileCaveatWithName(withDefaultEnv, "a > 10 && 'hi' in b", "first")
+	require.NoError(t, err)
+
+	withDefaultFingerprint, err := withDefault.Fingerprint()
+	require.NoError(t, err)
+	require.NotEqual(t, firstFingerprint, withDefaultFingerprint)
+
+	otherDefaultEnv := NewEnvironment()
+	require.NoError(t, otherDefaultEnv.AddVariableWithDefault("a", types.IntType, 6))
+	require.NoError(t, otherDefaultEnv.AddVariable("b", types.MustListType(types.StringType)))
+
+	otherDefault, err := CompileCaveatWithName(otherDefaultEnv, "a > 10 && 'hi' in b", "first")
+	require.NoError(t, err)
+
+	otherDefaultFingerprint, err := otherDefault.Fingerprint()
+	require.NoError(t, err)
+	require.NotEqual(t, withDefaultFingerprint, otherDefaultFingerprint)
+
+	serializedWithDefault, err := withDefault.Serialize()
+	require.NoError(t, err)
+
+	deserializedWithDefault, err := DeserializeCaveat(serializedWithDefault, withDefault.parameterTypes)
+	require.NoError(t, err)
+
+	deserializedWithDefaultFingerprint, err := deserializedWithDefault.Fingerprint()
+	require.NoError(t, err)
+	require.Equal(t, withDefaultFingerprint, deserializedWithDefaultFingerprint)
+
+	// Without the parameter types, the fingerprint cannot be computed.
+	serialized, err := first.Serialize()
+	require.NoError(t, err)
+
+	deserialized, err := DeserializeCaveat(serialized, nil)
+	require.NoError(t, err)
+
+	_, err = deserialized.Fingerprint()
+	require.Error(t, err)
+
+	deserialized, err = DeserializeCaveat(serialized, first.parameterTypes)
+	require.NoError(t, err)
+
+	deserializedFingerprint, err := deserialized.Fingerprint()
+	require.NoError(t, err)
+	require.Equal(t, firstFingerprint, deserializedFingerprint)
+}
+
+func TestCompileWithMaxExpressionDepth(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+	})
+
+	// The sum of 50 terms is 50 levels deep, plus one for the comparison.
+	deepExpr := strings.Repeat("a + ", 49) + "a == 50"
+
+	_, err := compileCaveat(env, deepExpr, WithMaxExpressionDepth(50))
+	require.ErrorContains(t, err, "caveat expression exceeds the maximum depth of 50: found depth 51")
+
+	var compilationErrs CompilationErrors
+	require.True(t, errors.As(err, &compilationErrs))
+
+	_, err = compileCaveat(env, deepExpr, WithMaxExpressionDepth(51))
+	require.NoError(t, err)
+
+	_, err = compileCaveat(env, deepExpr)
+	require.NoError(t, err)
+
+	// Chains of logical operators are balanced by the parser.
+	_, err = compileCaveat(env, strings.Repeat("a == 1 && ", 99)+"a == 1", WithMaxExpressionDepth(DefaultMaxExpressionDepth))
+	require.NoError(t, err)
+}
@@ -0,0 +1,122 @@
+package caveats
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/interpreter"
+	"golang.org/x/exp/maps"
+)
+
+// ContextProvider provides the context values of a caveat evaluation on demand, for values
+// which are expensive to compute and may not be read by the caveat.
+type ContextProvider interface {
+	// Resolve returns the value of the context parameter with the given name, and whether the
+	// value is available. An unavailable value is missing from the context, which results in a
+	// partial evaluation should the caveat depend on it.
+	Resolve(name string) (any, bool, error)
+}
+
+// ContextProviderFunc adapts a function into a ContextProvider.
+type ContextProviderFunc func(name string) (any, bool, error)
+
+// Resolve implements the ContextProvider interface method.
+func (f ContextProviderFunc) Resolve(name string) (any, bool, error) {
+	return f(name)
+}
+
+// EvaluateCaveatWithContextProvider evaluates the compiled caveat with context values resolved
+// on demand by the provider, and returns the result or an error, as EvaluateCaveatWithConfig.
+// Only the parameters read by the evaluation are resolved, e.g. `b` is not resolved for `a || b`
+// if `a` is true, and each is resolved at most once. The ContextValues of the result are the
+// values resolved.
+//
+// The first error returned by the provider fails the evaluation. Should a value be missing, the
+// evaluation is resumed with the parameter unknown, which results in a partial result should the
+// caveat depend on it. NullContextValue is not supported for values resolved by a provider.
+func EvaluateCaveatWithContextProvider(ctx context.Context, caveat *CompiledCaveat, provider ContextProvider, config *EvaluationConfig) (*CaveatResult, error) {
+	pa := &providerActivation{
+		caveat:   caveat,
+		provider: provider,
+		resolved: map[string]any{},
+		missing:  map[string]struct{}{},
+	}
+
+	pvars, err := cel.PartialVars(pa)
+	if err != nil {
+		return nil, translateEvaluationError(caveat.name, config, err)
+	}
+
+	activation := &evaluationActivation{pvars: pvars, provider: pa}
+	for {
+		missingCount := len(pa.missing)
+		result, err := evaluateCaveat(ctx, caveat, activation, config, nil)
+		if pa.err != nil {
+			return nil, pa.err
+		}
+
+		// A missing value fails the evaluation as an unknown attribute, unless the parameter is
+		// known to be missing, in which case it is unknown: the evaluation is therefore resumed
+		// until no further values are found missing.
+		if err != nil && len(pa.missing) > missingCount {
+			continue
+		}
+		return result, err
+	}
+}
+
+// providerActivation is an activation resolving the context values from a ContextProvider, and
+// caching the values resolved and those found missing.
+type providerActivation struct {
+	caveat   *CompiledCaveat
+	provider ContextProvider
+	resolved map[string]any
+	missing  map[string]struct{}
+	err      error
+}
+
+// ResolveName implements the interpreter.Activation interface method.
+func (pa *providerActivation) ResolveName(name string) (any, bool) {
+	if value, ok := pa.resolved[name]; ok {
+		return value, true
+	}
+
+	if _, ok := pa.missing[name]; ok || pa.err != nil {
+		return nil, false
+	}
+
+	value, ok, err := pa.provider.Resolve(name)
+	if err != nil {
+		pa.err = fmt.Errorf("could not resolve context parameter `%s`: %w", name, err)
+		return nil, false
+	}
+
+	if !ok {
+		pa.missing[name] = struct{}{}
+		return nil, false
+	}
+
+	value, _, err = coerceContextValue(pa.caveat, name, value)
+	if err != nil {
+		pa.err = err
+		return nil, false
+	}
+
+	pa.resolved[name] = value
+	return value, true
+}
+
+// Parent implements the interpreter.Activation interface method.
+func (pa *providerActivation) Parent() interpreter.Activation {
+	return nil
+}
+
+func (pa *providerActivation) isMissing(name string) bool {
+	_, ok := pa.missing[name]
+	return ok
+}
+
+func (pa *providerActivation) resolvedValues() map[string]any {
+	return maps.Clone(pa.resolved)
+}
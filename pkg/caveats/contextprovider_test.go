@@ -0,0 +1,113 @@
+package caveats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+type countingProvider struct {
+	values   map[string]any
+	resolved map[string]int
+}
+
+func (cp *countingProvider) Resolve(name string) (any, bool, error) {
+	cp.resolved[name]++
+	value, ok := cp.values[name]
+	return value, ok, nil
+}
+
+func TestEvaluateCaveatWithContextProvider(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a":       types.BooleanType,
+		"b":       types.IntType,
+		"c":       types.IntType,
+		"expires": types.TimestampType,
+	}), "(a || b > 10) && b < 100 && c == b && expires > timestamp('2020-01-01T00:00:00Z')")
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name             string
+		values           map[string]any
+		expectedValue    bool
+		expectedPartial  bool
+		expectedMissing  []string
+		expectedResolved map[string]int
+	}{
+		{
+			"all resolved once",
+			map[string]any{"a": false, "b": 42, "c": 42, "expires": "2030-01-01T00:00:00Z"},
+			true,
+			false,
+			nil,
+			map[string]int{"a": 1, "b": 1, "c": 1, "expires": 1},
+		},
+		{
+			"short-circuited",
+			map[string]any{"a": false, "b": 1, "c": 1, "expires": "2030-01-01T00:00:00Z"},
+			false,
+			false,
+			nil,
+			map[string]int{"a": 1, "b": 1},
+		},
+		{
+			"missing value",
+			map[string]any{"a": true, "b": 42, "expires": "2030-01-01T00:00:00Z"},
+			false,
+			true,
+			[]string{"c"},
+			map[string]int{"a": 1, "b": 1, "c": 1, "expires": 1},
+		},
+		{
+			"missing values",
+			map[string]any{"a": true},
+			false,
+			true,
+			[]string{"b", "c", "expires"},
+			map[string]int{"a": 1, "b": 1, "c": 1, "expires": 1},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := &countingProvider{values: tc.values, resolved: map[string]int{}}
+			result, err := EvaluateCaveatWithContextProvider(context.Background(), compiled, provider, nil)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedValue, result.Value())
+			require.Equal(t, tc.expectedPartial, result.IsPartial())
+			require.Equal(t, tc.expectedResolved, provider.resolved)
+
+			if tc.expectedPartial {
+				missingVarNames, err := result.MissingVarNames()
+				require.NoError(t, err)
+				require.Equal(t, tc.expectedMissing, missingVarNames)
+				return
+			}
+
+			// The context values are those resolved, with timestamps converted.
+			require.Len(t, result.ContextValues(), len(tc.expectedResolved))
+			if expires, ok := result.ContextValues()["expires"]; ok {
+				require.IsType(t, time.Time{}, expires)
+			}
+		})
+	}
+}
+
+func TestEvaluateCaveatWithContextProviderError(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+	}), "a == 1")
+	require.NoError(t, err)
+
+	lookupErr := errors.New("lookup service unavailable")
+	_, err = EvaluateCaveatWithContextProvider(context.Background(), compiled, ContextProviderFunc(func(name string) (any, bool, error) {
+		return nil, false, lookupErr
+	}), nil)
+	require.ErrorIs(t, err, lookupErr)
+	require.ErrorContains(t, err, "could not resolve context parameter `a`")
+}
@@ -0,0 +1,67 @@
+package caveats
+
+import (
+	"reflect"
+)
+
+// countContextValues counts the values of the context, each top-level value counting as one in
+// addition to the elements of the lists and the entries of the maps it holds, at any depth. Byte
+// sequences and other scalars count as a single value. Counting stops once the limit is
+// exceeded, such that oversized contexts are rejected without being fully traversed.
+func countContextValues(contextValues map[string]any, limit uint64) uint64 {
+	var count uint64
+	for _, value := range contextValues {
+		count = countValue(reflect.ValueOf(value), count, limit)
+		if count > limit {
+			break
+		}
+	}
+	return count
+}
+
+// countValue adds the count of the value and of its nested values to the running count, up to
+// the point the limit is exceeded.
+func countValue(value reflect.Value, count uint64, limit uint64) uint64 {
+	count++
+	if count > limit || !value.IsValid() {
+		return count
+	}
+
+	switch value.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if value.IsNil() {
+			return count
+		}
+		return countValue(value.Elem(), count-1, limit)
+
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return count
+		}
+
+		for i := 0; i < value.Len() && count <= limit; i++ {
+			count = countValue(value.Index(i), count, limit)
+		}
+
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() && count <= limit {
+			count = countValue(iter.Value(), count, limit)
+		}
+	}
+
+	return count
+}
+
+// validateContextSize ensures the context values do not exceed the limit on their count
+// configured for evaluation, if any.
+func validateContextSize(contextValues map[string]any, config *EvaluationConfig) error {
+	if config == nil || config.MaxContextValues == 0 {
+		return nil
+	}
+
+	if countContextValues(contextValues, config.MaxContextValues) > config.MaxContextValues {
+		return newContextSizeLimitError(config.MaxContextValues)
+	}
+	return nil
+}
@@ -4,20 +4,60 @@ import (
 	"fmt"
 
 	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"golang.org/x/exp/maps"
 
 	"github.com/authzed/spicedb/pkg/caveats/types"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
+const (
+	// SensitivityPublic is the sensitivity class for parameters holding public data.
+	SensitivityPublic = "public"
+
+	// SensitivityInternal is the sensitivity class for parameters holding internal data.
+	SensitivityInternal = "internal"
+
+	// SensitivityRestricted is the sensitivity class for parameters holding restricted data,
+	// which should not be sent to low-trust clients.
+	SensitivityRestricted = "restricted"
+)
+
+var sensitivityClasses = map[string]struct{}{
+	SensitivityPublic:     {},
+	SensitivityInternal:   {},
+	SensitivityRestricted: {},
+}
+
+// FunctionPurity indicates whether a function is a pure function of its arguments.
+type FunctionPurity int
+
+const (
+	// PureFunction indicates a function whose result depends only on its arguments.
+	PureFunction FunctionPurity = 0
+
+	// ImpureFunction indicates a function whose result can depend on state other than its
+	// arguments, such as the current time, randomness or an external service.
+	ImpureFunction FunctionPurity = 1
+)
+
 // Environment defines the evaluation environment for a caveat.
 type Environment struct {
-	variables map[string]types.VariableType
+	variables       map[string]types.VariableType
+	sensitivities   map[string]string
+	defaults        map[string]any
+	functions       []cel.EnvOption
+	impureFunctions map[string]struct{}
+	disabledMacros  map[string]struct{}
 }
 
 // NewEnvironment creates and returns a new environment for compiling a caveat.
 func NewEnvironment() *Environment {
 	return &Environment{
-		variables: map[string]types.VariableType{},
+		variables:       map[string]types.VariableType{},
+		sensitivities:   map[string]string{},
+		defaults:        map[string]any{},
+		impureFunctions: map[string]struct{}{},
 	}
 }
 
@@ -53,6 +93,70 @@ func (e *Environment) AddVariable(name string, varType types.VariableType) error
 	return nil
 }
 
+// AddVariableWithSensitivity adds a variable with the given type and sensitivity class
+// to the environment. The sensitivity must be one of SensitivityPublic, SensitivityInternal or
+// SensitivityRestricted.
+func (e *Environment) AddVariableWithSensitivity(name string, varType types.VariableType, sensitivity string) error {
+	if _, ok := sensitivityClasses[sensitivity]; !ok {
+		return fmt.Errorf("unknown sensitivity class `%s` for variable `%s`", sensitivity, name)
+	}
+
+	if err := e.AddVariable(name, varType); err != nil {
+		return err
+	}
+
+	e.sensitivities[name] = sensitivity
+	return nil
+}
+
+// AddVariableWithDefault adds a variable with the given type to the environment, along with the
+// value it takes when no value is given for it in the context values of an evaluation. Omitting
+// such a variable therefore never results in a partial evaluation. The default value is
+// converted to the type of the variable, as context values are by ConvertContextToParameters.
+func (e *Environment) AddVariableWithDefault(name string, varType types.VariableType, defaultValue any) error {
+	converted, err := varType.ConvertValue(defaultValue)
+	if err != nil {
+		return fmt.Errorf("invalid default value for variable `%s`: %w", name, err)
+	}
+
+	if err := e.AddVariable(name, varType); err != nil {
+		return err
+	}
+
+	e.defaults[name] = converted
+	return nil
+}
+
+// AddFunction adds a custom function with the given overloads to the environment. Impure
+// functions cannot be used by caveats compiled with WithPureEvaluation.
+func (e *Environment) AddFunction(name string, purity FunctionPurity, overloads ...cel.FunctionOpt) error {
+	if len(overloads) == 0 {
+		return fmt.Errorf("function `%s` requires at least one overload", name)
+	}
+
+	e.functions = append(e.functions, cel.Function(name, overloads...))
+	if purity == ImpureFunction {
+		e.impureFunctions[name] = struct{}{}
+	}
+	return nil
+}
+
+// sensitivitiesCopy returns a copy of the sensitivity classes declared in the environment.
+func (e *Environment) sensitivitiesCopy() map[string]string {
+	if len(e.sensitivities) == 0 {
+		return nil
+	}
+	return maps.Clone(e.sensitivities)
+}
+
+// defaultsCopy returns a copy of the default values declared in the environment.
+func (e *Environment) defaultsCopy() map[string]any {
+	if len(e.defaults) == 0 {
+		return nil
+	}
+	return maps.Clone(e.defaults)
+}
+
 // EncodedParametersTypes returns the map of encoded parameters for the environment.
 func (e *Environment) EncodedParametersTypes() map[string]*core.CaveatTypeReference {
 	return types.EncodeParameterTypes(e.variables)
@@ -60,7 +164,7 @@ func (e *Environment) EncodedParametersTypes() map[string]*core.CaveatTypeRefere
 
 // asCelEnvironment converts the exported Environment into an internal CEL environment.
 func (e *Environment) asCelEnvironment() (*cel.Env, error) {
-	opts := make([]cel.EnvOption, 0, len(e.variables)+len(types.CustomTypes)+2)
+	opts := make([]cel.EnvOption, 0, len(e.variables)+len(types.CustomTypes)+len(e.functions)+3)
 
 	// Add the custom type adapter and functions.
 	opts = append(opts, cel.CustomTypeAdapter(&types.CustomTypeAdapter{}))
@@ -69,10 +173,24 @@ func (e *Environment) asCelEnvironment() (*cel.Env, error) {
 	}
 	opts = append(opts, types.CustomMethodsOnTypes...)
 
+	// Add the strings extension library, whose functions are listed by stringExtensionFunctions.
+	opts = append(opts, ext.Strings())
+
 	// Set options.
 	// DefaultUTCTimeZone: ensure all timestamps are evaluated at UTC
 	opts = append(opts, cel.DefaultUTCTimeZone(true))
 
+	// EnableMacroCallTracking: record the calls of macros as written, such that expressions using
+	// macros, which are expanded into comprehensions, can be converted back into strings.
+	opts = append(opts, cel.EnableMacroCallTracking())
+
+	opts = append(opts, e.functions...)
+
+	// Replace any disabled macros, which must come after the standard macros.
+	if overrides := e.disabledMacroOverrides(); len(overrides) > 0 {
+		opts = append(opts, cel.Macros(overrides...))
+	}
+
 	for name, varType := range e.variables {
 		opts = append(opts, cel.Variable(name, varType.CelType()))
 	}
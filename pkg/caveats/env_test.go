@@ -1,6 +1,7 @@
 package caveats
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -16,3 +17,128 @@ func TestAddVariable(t *testing.T) {
 	err = env.AddVariable("foobar", types.IntType)
 	req.Error(err)
 }
+
+func TestSensitivityClasses(t *testing.T) {
+	req := require.New(t)
+	env := NewEnvironment()
+	req.NoError(env.AddVariableWithSensitivity("ssn", types.StringType, SensitivityRestricted))
+	req.NoError(env.AddVariableWithSensitivity("department", types.StringType, SensitivityInternal))
+	req.NoError(env.AddVariableWithSensitivity("region", types.StringType, SensitivityPublic))
+	req.NoError(env.AddVariable("untagged", types.StringType))
+
+	compiled, err := compileCaveat(env, "ssn == '123' && department == 'eng' && untagged == 'hi'")
+	req.NoError(err)
+
+	// Only referenced, tagged parameters are returned.
+	req.Equal(map[string]string{
+		"ssn":        SensitivityRestricted,
+		"department": SensitivityInternal,
+	}, compiled.SensitivityClasses())
+
+	// Sensitivity classes propagate to partially evaluated caveats.
+	result, err := EvaluateCaveat(compiled, map[string]any{
+		"department": "eng",
+	})
+	req.NoError(err)
+	req.True(result.IsPartial())
+
+	partial, err := result.PartialValue()
+	req.NoError(err)
+	req.Equal(map[string]string{
+		"ssn": SensitivityRestricted,
+	}, partial.SensitivityClasses())
+}
+
+func TestAddVariableWithUnknownSensitivity(t *testing.T) {
+	req := require.New(t)
+	env := NewEnvironment()
+	err := env.AddVariableWithSensitivity("ssn", types.StringType, "topsecret")
+	req.Error(err)
+	req.Contains(err.Error(), "unknown sensitivity class `topsecret`")
+
+	req.NoError(env.AddVariableWithSensitivity("ssn", types.StringType, SensitivityRestricted))
+	req.Error(env.AddVariableWithSensitivity("ssn", types.StringType, SensitivityRestricted))
+}
+
+func TestEnabledMacros(t *testing.T) {
+	tcs := []struct {
+		name           string
+		enabledMacros  []string
+		exprString     string
+		expectedError  string
+		expectedLine   int
+		expectedColumn int
+	}{
+		{
+			"all macros enabled by default",
+			nil,
+			"has(m.f) && nums.exists(n, n > 1) && nums.all(n, n > 0)",
+			"",
+			0,
+			0,
+		},
+		{
+			"enabled macro",
+			[]string{ExistsMacro},
+			"nums.exists(n, n > 1)",
+			"",
+			0,
+			0,
+		},
+		{
+			"disabled has macro",
+			[]string{ExistsMacro, AllMacro},
+			"nums.exists(n, n > 1) &&\n  has(m.f)",
+			"macro `has` is disabled",
+			1,
+			4,
+		},
+		{
+			"disabled receiver macro",
+			[]string{HasMacro},
+			"has(m.f) && nums.map(n, n * 2).size() > 1",
+			"macro `map` is disabled",
+			0,
+			19,
+		},
+		{
+			"all macros disabled",
+			[]string{},
+			"nums.filter(n, n > 1).size() > 1",
+			"macro `filter` is disabled",
+			0,
+			10,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			env := MustEnvForVariables(map[string]types.VariableType{
+				"m":    types.MustMapType(types.IntType),
+				"nums": types.MustListType(types.IntType),
+			})
+			if tc.enabledMacros != nil {
+				require.NoError(t, env.SetEnabledMacros(tc.enabledMacros...))
+			}
+
+			_, err := compileCaveat(env, tc.exprString)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedError)
+
+			var compilationErrs CompilationErrors
+			require.True(t, errors.As(err, &compilationErrs))
+			require.Equal(t, tc.expectedLine, compilationErrs.LineNumber())
+			require.Equal(t, tc.expectedColumn, compilationErrs.ColumnPosition())
+		})
+	}
+}
+
+func TestSetUnknownMacro(t *testing.T) {
+	err := NewEnvironment().SetEnabledMacros("unknown")
+	require.ErrorContains(t, err, "unknown macro `unknown`")
+}
@@ -1,9 +1,13 @@
 package caveats
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strconv"
 
 	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
 	"github.com/rs/zerolog"
 )
 
@@ -18,6 +22,11 @@ func (err ParameterConversionErr) MarshalZerologObject(e *zerolog.Event) {
 	e.Err(err.error).Str("parameterName", err.parameterName)
 }
 
+// ParameterName returns the name of the parameter which could not be converted.
+func (err ParameterConversionErr) ParameterName() string {
+	return err.parameterName
+}
+
 // DetailsMetadata returns the metadata for details for this error.
 func (err ParameterConversionErr) DetailsMetadata() map[string]string {
 	return map[string]string{
@@ -25,26 +34,124 @@ func (err ParameterConversionErr) DetailsMetadata() map[string]string {
 	}
 }
 
+// UnknownParameterErr is an error for a supplied parameter which is not declared by the caveat.
+type UnknownParameterErr struct {
+	error
+	parameterName string
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler
+func (err UnknownParameterErr) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Str("parameterName", err.parameterName)
+}
+
+// ParameterName returns the name of the parameter which is not declared.
+func (err UnknownParameterErr) ParameterName() string {
+	return err.parameterName
+}
+
+// DetailsMetadata returns the metadata for details for this error.
+func (err UnknownParameterErr) DetailsMetadata() map[string]string {
+	return map[string]string{
+		"parameter_name": err.parameterName,
+	}
+}
+
+// SourceLocation is the 0-indexed position of an issue in the source of a caveat expression.
+type SourceLocation struct {
+	LineNumber     int
+	ColumnPosition int
+}
+
+// CompilationError is a single issue found when compiling a caveat expression.
+type CompilationError struct {
+	error
+	location SourceLocation
+	snippet  string
+}
+
+// Location returns the position of the issue in the source of the expression.
+func (err CompilationError) Location() SourceLocation {
+	return err.location
+}
+
+// Snippet returns the line of the source containing the issue, or an empty string if the source
+// is not available. The snippet is the full line, so the column position of the issue can be
+// used to locate it within the snippet.
+func (err CompilationError) Snippet() string {
+	return err.snippet
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler
+func (err CompilationError) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Int("lineNumber", err.location.LineNumber).Int("columnPosition", err.location.ColumnPosition)
+}
+
+// DetailsMetadata returns the metadata for details for this error.
+func (err CompilationError) DetailsMetadata() map[string]string {
+	return map[string]string{
+		"line_number":     strconv.Itoa(err.location.LineNumber),
+		"column_position": strconv.Itoa(err.location.ColumnPosition),
+		"snippet":         err.snippet,
+	}
+}
+
 // CompilationErrors is a wrapping error for containing compilation errors for a Caveat.
+//
+// Errors found by the CEL parser and checker carry the position of each issue in the expression,
+// all of which are available via Errors. Other errors, such as the expression exceeding its
+// maximum depth, are not attached to a position and have no issues.
 type CompilationErrors struct {
 	error
 
-	issues *cel.Issues
+	issues []CompilationError
 }
 
-// LineNumber is the 0-indexed line number for compilation error.
+// newCompilationErrors returns the CompilationErrors for the issues found by CEL when compiling
+// the source.
+func newCompilationErrors(issues *cel.Issues, source common.Source) CompilationErrors {
+	compilationErrs := make([]CompilationError, 0, len(issues.Errors()))
+	for _, issue := range issues.Errors() {
+		snippet, _ := source.Snippet(issue.Location.Line())
+		compilationErrs = append(compilationErrs, CompilationError{
+			error: errors.New(issue.Message),
+			location: SourceLocation{
+				LineNumber:     issue.Location.Line() - 1,
+				ColumnPosition: issue.Location.Column() - 1,
+			},
+			snippet: snippet,
+		})
+	}
+
+	return CompilationErrors{issues.Err(), compilationErrs}
+}
+
+// Errors returns each of the issues found in the expression, in the order reported by CEL.
+func (err CompilationErrors) Errors() []CompilationError {
+	return err.issues
+}
+
+// LineNumber is the 0-indexed line number for the first compilation error, or 0 if the error is
+// not attached to a position.
 func (err CompilationErrors) LineNumber() int {
-	return err.issues.Errors()[0].Location.Line() - 1
+	if len(err.issues) == 0 {
+		return 0
+	}
+	return err.issues[0].location.LineNumber
 }
 
-// ColumnPositionis the 0-indexed column position for compilation error.
+// ColumnPosition is the 0-indexed column position for the first compilation error, or 0 if the
+// error is not attached to a position.
 func (err CompilationErrors) ColumnPosition() int {
-	return err.issues.Errors()[0].Location.Column() - 1
+	if len(err.issues) == 0 {
+		return 0
+	}
+	return err.issues[0].location.ColumnPosition
 }
 
 // MarshalZerologObject implements zerolog.LogObjectMarshaler
 func (err CompilationErrors) MarshalZerologObject(e *zerolog.Event) {
-	e.Err(err.error).Int("lineNumber", err.LineNumber()).Int("columnPosition", err.ColumnPosition())
+	e.Err(err.error).Int("lineNumber", err.LineNumber()).Int("columnPosition", err.ColumnPosition()).Int("issueCount", len(err.issues))
 }
 
 // DetailsMetadata returns the metadata for details for this error.
@@ -52,5 +159,276 @@ func (err CompilationErrors) DetailsMetadata() map[string]string {
 	return map[string]string{
 		"line_number":     strconv.Itoa(err.LineNumber()),
 		"column_position": strconv.Itoa(err.ColumnPosition()),
+		"issue_count":     strconv.Itoa(len(err.issues)),
+	}
+}
+
+// CaveatArithmeticError is an error returned when a caveat performs an invalid arithmetic
+// operation during evaluation, such as an integer division or modulus by zero, or an operation
+// whose result overflows the range of its type (e.g. the product of two large `int` values).
+//
+// Note that CEL does not guard divisions: caveat authors should ensure the divisor is non-zero
+// (e.g. `b != 0 && a / b > 2`) if the context can supply a zero value. Division of doubles by
+// zero follows IEEE 754 and produces an infinity or NaN rather than an error, and operations on
+// doubles never overflow.
+//
+// The error is returned in place of a result, the same for any given expression and context, so
+// callers may deterministically treat it either as a denial (failing closed) or as a hard error.
+// Note that, as with any error, it is absorbed by a logical operator whose other operand decides
+// its result, e.g. `false && a * b > 0` evaluates to false even if the product overflows.
+type CaveatArithmeticError struct {
+	error
+	caveatName string
+	operation  string
+	operands   []any
+	overflow   bool
+}
+
+// Operation returns the arithmetic operator that failed, e.g. `/` or `%`, or an empty string if
+// it could not be determined.
+func (err CaveatArithmeticError) Operation() string {
+	return err.operation
+}
+
+// IsOverflow returns whether the operation overflowed the range of its type, rather than being a
+// division or modulus by zero.
+func (err CaveatArithmeticError) IsOverflow() bool {
+	return err.overflow
+}
+
+// Operands returns the values of the operands of the failed operation, if they could be
+// determined.
+func (err CaveatArithmeticError) Operands() []any {
+	return err.operands
+}
+
+// Unwrap returns the underlying evaluation error.
+func (err CaveatArithmeticError) Unwrap() error {
+	return err.error
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler
+func (err CaveatArithmeticError) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Str("caveatName", err.caveatName).Str("operation", err.operation).Interface("operands", err.operands).Bool("overflow", err.overflow)
+}
+
+// DetailsMetadata returns the metadata for details for this error.
+func (err CaveatArithmeticError) DetailsMetadata() map[string]string {
+	return map[string]string{
+		"caveat_name": err.caveatName,
+		"operation":   err.operation,
+		"operands":    fmt.Sprintf("%v", err.operands),
+		"overflow":    strconv.FormatBool(err.overflow),
+	}
+}
+
+func newCaveatArithmeticError(err error, caveatName string, operation string, operands []any) CaveatArithmeticError {
+	switch len(operands) {
+	case 1:
+		err = fmt.Errorf("%w: `%s%v`", err, operation, operands[0])
+	case 2:
+		err = fmt.Errorf("%w: `%v %s %v`", err, operands[0], operation, operands[1])
+	}
+
+	return CaveatArithmeticError{
+		error:      err,
+		caveatName: caveatName,
+		operation:  operation,
+		operands:   operands,
+	}
+}
+
+func newCaveatOverflowError(err error, caveatName string, operation string, operands []any) CaveatArithmeticError {
+	arithmeticErr := newCaveatArithmeticError(err, caveatName, operation, operands)
+	arithmeticErr.overflow = true
+	return arithmeticErr
+}
+
+// CaveatIterationLimitError is an error returned when a caveat exceeds the maximum number of
+// comprehension iterations configured for its evaluation.
+type CaveatIterationLimitError struct {
+	error
+	caveatName    string
+	maxIterations uint64
+}
+
+// MaxIterations returns the iteration limit that was exceeded.
+func (err CaveatIterationLimitError) MaxIterations() uint64 {
+	return err.maxIterations
+}
+
+// Unwrap returns the underlying evaluation error.
+func (err CaveatIterationLimitError) Unwrap() error {
+	return err.error
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler
+func (err CaveatIterationLimitError) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Str("caveatName", err.caveatName).Uint64("maxIterations", err.maxIterations)
+}
+
+// DetailsMetadata returns the metadata for details for this error.
+func (err CaveatIterationLimitError) DetailsMetadata() map[string]string {
+	return map[string]string{
+		"caveat_name":    err.caveatName,
+		"max_iterations": strconv.FormatUint(err.maxIterations, 10),
+	}
+}
+
+func newCaveatIterationLimitError(err error, caveatName string, maxIterations uint64) CaveatIterationLimitError {
+	return CaveatIterationLimitError{
+		error:         fmt.Errorf("caveat exceeded the maximum of %d comprehension iterations: %w", maxIterations, err),
+		caveatName:    caveatName,
+		maxIterations: maxIterations,
+	}
+}
+
+// ContextSizeLimitError is an error returned when the context values given for the evaluation
+// of a caveat exceed the maximum configured for their count.
+type ContextSizeLimitError struct {
+	error
+	maxContextValues uint64
+}
+
+// MaxContextValues returns the limit on the count of context values that was exceeded.
+func (err ContextSizeLimitError) MaxContextValues() uint64 {
+	return err.maxContextValues
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler
+func (err ContextSizeLimitError) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Uint64("maxContextValues", err.maxContextValues)
+}
+
+// DetailsMetadata returns the metadata for details for this error.
+func (err ContextSizeLimitError) DetailsMetadata() map[string]string {
+	return map[string]string{
+		"max_context_values": strconv.FormatUint(err.maxContextValues, 10),
+	}
+}
+
+func newContextSizeLimitError(maxContextValues uint64) ContextSizeLimitError {
+	return ContextSizeLimitError{
+		error:            fmt.Errorf("context exceeds the maximum of %d values", maxContextValues),
+		maxContextValues: maxContextValues,
+	}
+}
+
+// EvaluationErrorCode is a stable code identifying the kind of failure of a caveat evaluation.
+type EvaluationErrorCode string
+
+// EvaluationErrorCodesVersion is the version of the set of evaluation error codes and of their
+// messages. It is incremented whenever a code or message is changed or removed, but not when
+// codes are added.
+const EvaluationErrorCodesVersion = 2
+
+const (
+	// EvaluationErrorTypeMismatch indicates that a value could not be converted to the type
+	// required by an operation.
+	EvaluationErrorTypeMismatch EvaluationErrorCode = "CAVEAT_TYPE_MISMATCH"
+
+	// EvaluationErrorNoSuchOverload indicates that no overload of a function or operator
+	// matches the types of the arguments given to it.
+	EvaluationErrorNoSuchOverload EvaluationErrorCode = "CAVEAT_NO_SUCH_OVERLOAD"
+
+	// EvaluationErrorCostLimitExceeded indicates that the evaluation exceeded its maximum cost.
+	EvaluationErrorCostLimitExceeded EvaluationErrorCode = "CAVEAT_COST_LIMIT_EXCEEDED"
+
+	// EvaluationErrorDivisionByZero indicates an integer division or modulus by zero.
+	EvaluationErrorDivisionByZero EvaluationErrorCode = "CAVEAT_DIVISION_BY_ZERO"
+
+	// EvaluationErrorArithmeticOverflow indicates an arithmetic operation whose result
+	// overflows the range of its type.
+	EvaluationErrorArithmeticOverflow EvaluationErrorCode = "CAVEAT_ARITHMETIC_OVERFLOW"
+
+	// EvaluationErrorIterationLimitExceeded indicates that the evaluation exceeded its maximum
+	// number of comprehension iterations.
+	EvaluationErrorIterationLimitExceeded EvaluationErrorCode = "CAVEAT_ITERATION_LIMIT_EXCEEDED"
+
+	// EvaluationErrorContextTooLarge indicates that the context values given for evaluation
+	// exceed their maximum count.
+	EvaluationErrorContextTooLarge EvaluationErrorCode = "CAVEAT_CONTEXT_TOO_LARGE"
+
+	// EvaluationErrorTimeout indicates that the evaluation exceeded its timeout or the deadline
+	// of its context.
+	EvaluationErrorTimeout EvaluationErrorCode = "CAVEAT_EVALUATION_TIMEOUT"
+
+	// EvaluationErrorCancelled indicates that the context of the evaluation was cancelled.
+	EvaluationErrorCancelled EvaluationErrorCode = "CAVEAT_EVALUATION_CANCELLED"
+
+	// EvaluationErrorUnknown indicates any other failure of the evaluation.
+	EvaluationErrorUnknown EvaluationErrorCode = "CAVEAT_EVALUATION_FAILED"
+)
+
+// CaveatEvaluationError is the error returned when a caveat fails to evaluate.
+//
+// Its code and message are part of the API contract and, unlike the messages of the underlying
+// CEL errors, do not change across versions of cel-go; see EvaluationErrorCodesVersion. The only
+// exception are errors with EvaluationErrorUnknown, whose message is the stable prefix
+// "caveat `<name>` could not be evaluated: " followed by the message of the underlying error.
+// The underlying error, including any CaveatArithmeticError or CaveatIterationLimitError, remains
+// available via errors.As.
+type CaveatEvaluationError struct {
+	error
+	caveatName string
+	code       EvaluationErrorCode
+	message    string
+}
+
+// Error returns the stable message for the error.
+func (err CaveatEvaluationError) Error() string {
+	return err.message
+}
+
+// CaveatName returns the name of the caveat which failed to evaluate.
+func (err CaveatEvaluationError) CaveatName() string {
+	return err.caveatName
+}
+
+// Code returns the stable code for the error.
+func (err CaveatEvaluationError) Code() EvaluationErrorCode {
+	return err.code
+}
+
+// Unwrap returns the underlying evaluation error.
+func (err CaveatEvaluationError) Unwrap() error {
+	return err.error
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler
+func (err CaveatEvaluationError) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Str("caveatName", err.caveatName).Str("code", string(err.code))
+}
+
+// DetailsMetadata returns the metadata for details for this error.
+func (err CaveatEvaluationError) DetailsMetadata() map[string]string {
+	return map[string]string{
+		"caveat_name":         err.caveatName,
+		"error_code":          string(err.code),
+		"error_codes_version": strconv.Itoa(EvaluationErrorCodesVersion),
+	}
+}
+
+// ErrEvaluationTimeout is the error underlying the CaveatEvaluationError returned when the
+// evaluation of a caveat exceeds its timeout or the deadline of its context.
+var ErrEvaluationTimeout = errors.New("caveat evaluation timed out")
+
+// newCaveatInterruptedError returns the error for an evaluation interrupted by its context
+// ending with the given error.
+func newCaveatInterruptedError(caveatName string, ctxErr error) CaveatEvaluationError {
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		return CaveatEvaluationError{
+			error:      ErrEvaluationTimeout,
+			caveatName: caveatName,
+			code:       EvaluationErrorTimeout,
+			message:    fmt.Sprintf("caveat `%s` exceeded its evaluation timeout", caveatName),
+		}
+	}
+
+	return CaveatEvaluationError{
+		error:      ctxErr,
+		caveatName: caveatName,
+		code:       EvaluationErrorCancelled,
+		message:    fmt.Sprintf("evaluation of caveat `%s` was cancelled", caveatName),
 	}
 }
@@ -0,0 +1,82 @@
+package caveats
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/interpreter"
+)
+
+// noSuchOverloadErrMessage, typeConversionErrMessage and unsupportedTypeErrMessage are the
+// prefixes of the messages of the corresponding CEL runtime errors, which are not otherwise
+// distinguishable.
+const (
+	noSuchOverloadErrMessage  = "no such overload"
+	typeConversionErrMessage  = "type conversion error"
+	unsupportedTypeErrMessage = "unsupported conversion to ref.Val"
+)
+
+// translateEvaluationError translates an error returned by the evaluation of a caveat into a
+// CaveatEvaluationError, with a stable code and message.
+func translateEvaluationError(caveatName string, config *EvaluationConfig, err error) CaveatEvaluationError {
+	var evaluationErr CaveatEvaluationError
+	if errors.As(err, &evaluationErr) && evaluationErr.caveatName == caveatName {
+		return evaluationErr
+	}
+
+	translated := CaveatEvaluationError{
+		error:      err,
+		caveatName: caveatName,
+		code:       EvaluationErrorUnknown,
+		message:    fmt.Sprintf("caveat `%s` could not be evaluated: %s", caveatName, err.Error()),
+	}
+
+	var iterationErr CaveatIterationLimitError
+	var contextSizeErr ContextSizeLimitError
+	var arithmeticErr CaveatArithmeticError
+	var cancelledErr interpreter.EvalCancelledError
+
+	message := strings.TrimSpace(err.Error())
+	switch {
+	case errors.As(err, &iterationErr):
+		translated.code = EvaluationErrorIterationLimitExceeded
+		translated.message = fmt.Sprintf("caveat `%s` exceeded the maximum of %d comprehension iterations", caveatName, iterationErr.MaxIterations())
+
+	case errors.As(err, &contextSizeErr):
+		translated.code = EvaluationErrorContextTooLarge
+		translated.message = fmt.Sprintf("caveat `%s` context exceeds the maximum of %d values", caveatName, contextSizeErr.MaxContextValues())
+
+	case errors.As(err, &arithmeticErr) && arithmeticErr.IsOverflow():
+		translated.code = EvaluationErrorArithmeticOverflow
+		translated.message = fmt.Sprintf("caveat `%s` could not be evaluated: arithmetic overflow", caveatName)
+
+	case errors.As(err, &arithmeticErr):
+		operation := "division"
+		if arithmeticErr.Operation() == "%" {
+			operation = "modulus"
+		}
+
+		translated.code = EvaluationErrorDivisionByZero
+		translated.message = fmt.Sprintf("caveat `%s` could not be evaluated: integer %s by zero", caveatName, operation)
+
+	case errors.As(err, &cancelledErr) && cancelledErr.Cause == interpreter.CostLimitExceeded:
+		var maxCost uint64
+		if config != nil {
+			maxCost = config.MaxCost
+		}
+
+		translated.code = EvaluationErrorCostLimitExceeded
+		translated.message = fmt.Sprintf("caveat `%s` exceeded the maximum evaluation cost of %d", caveatName, maxCost)
+
+	case strings.HasPrefix(message, noSuchOverloadErrMessage):
+		translated.code = EvaluationErrorNoSuchOverload
+		translated.message = fmt.Sprintf("caveat `%s` could not be evaluated: no overload of a function or operator accepts the types of its arguments", caveatName)
+
+	case strings.HasPrefix(message, typeConversionErrMessage), strings.HasPrefix(message, unsupportedTypeErrMessage):
+		translated.code = EvaluationErrorTypeMismatch
+		translated.message = fmt.Sprintf("caveat `%s` could not be evaluated: a value could not be converted to the required type", caveatName)
+	}
+
+	return translated
+}
@@ -0,0 +1,141 @@
+package caveats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	celtypes "github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/interpreter"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+func TestTranslateEvaluationError(t *testing.T) {
+	tcs := []struct {
+		name            string
+		err             error
+		expectedCode    EvaluationErrorCode
+		expectedMessage string
+	}{
+		{
+			"type mismatch",
+			celtypes.NewErr("type conversion error from 'int' to 'bool'").(*celtypes.Err),
+			EvaluationErrorTypeMismatch,
+			"caveat `somecaveat` could not be evaluated: a value could not be converted to the required type",
+		},
+		{
+			"unsupported conversion",
+			celtypes.NewErr("unsupported conversion to ref.Val: (foo)bar").(*celtypes.Err),
+			EvaluationErrorTypeMismatch,
+			"caveat `somecaveat` could not be evaluated: a value could not be converted to the required type",
+		},
+		{
+			"no such overload",
+			celtypes.NewErr("no such overload: string + int").(*celtypes.Err),
+			EvaluationErrorNoSuchOverload,
+			"caveat `somecaveat` could not be evaluated: no overload of a function or operator accepts the types of its arguments",
+		},
+		{
+			"cost limit exceeded",
+			interpreter.EvalCancelledError{Cause: interpreter.CostLimitExceeded, Message: "operation cancelled: actual cost limit exceeded"},
+			EvaluationErrorCostLimitExceeded,
+			"caveat `somecaveat` exceeded the maximum evaluation cost of 10",
+		},
+		{
+			"division by zero",
+			newCaveatArithmeticError(errors.New("division by zero"), "somecaveat", "/", nil),
+			EvaluationErrorDivisionByZero,
+			"caveat `somecaveat` could not be evaluated: integer division by zero",
+		},
+		{
+			"modulus by zero",
+			newCaveatArithmeticError(errors.New("modulus by zero"), "somecaveat", "%", nil),
+			EvaluationErrorDivisionByZero,
+			"caveat `somecaveat` could not be evaluated: integer modulus by zero",
+		},
+		{
+			"arithmetic overflow",
+			newCaveatOverflowError(errors.New("integer overflow"), "somecaveat", "*", nil),
+			EvaluationErrorArithmeticOverflow,
+			"caveat `somecaveat` could not be evaluated: arithmetic overflow",
+		},
+		{
+			"iteration limit exceeded",
+			newCaveatIterationLimitError(errors.New("operation interrupted"), "somecaveat", 100),
+			EvaluationErrorIterationLimitExceeded,
+			"caveat `somecaveat` exceeded the maximum of 100 comprehension iterations",
+		},
+		{
+			"unknown",
+			fmt.Errorf("invalid CIDR string: `invalidcidr`"),
+			EvaluationErrorUnknown,
+			"caveat `somecaveat` could not be evaluated: invalid CIDR string: `invalidcidr`",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			translated := translateEvaluationError("somecaveat", &EvaluationConfig{MaxCost: 10}, tc.err)
+			require.Equal(t, tc.expectedCode, translated.Code())
+			require.Equal(t, tc.expectedMessage, translated.Error())
+			require.Equal(t, string(tc.expectedCode), translated.DetailsMetadata()["error_code"])
+			require.Equal(t, tc.err, translated.Unwrap())
+		})
+	}
+}
+
+func TestEvaluationErrorsAreTranslated(t *testing.T) {
+	compiled, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"m": types.MustMapType(types.AnyType),
+	}), "m.value + 1 == 2", "somecaveat")
+	require.NoError(t, err)
+
+	_, err = EvaluateCaveat(compiled, map[string]any{
+		"m": map[string]any{"value": "one"},
+	})
+	require.Equal(t, "caveat `somecaveat` could not be evaluated: no overload of a function or operator accepts the types of its arguments", err.Error())
+
+	compiled, err = CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.IntType,
+	}), "a / b == 1", "somecaveat")
+	require.NoError(t, err)
+
+	_, err = EvaluateCaveat(compiled, map[string]any{
+		"a": int64(1),
+		"b": int64(0),
+	})
+	require.Equal(t, "caveat `somecaveat` could not be evaluated: integer division by zero", err.Error())
+
+	var evalErr CaveatEvaluationError
+	require.True(t, errors.As(err, &evalErr))
+	require.Equal(t, EvaluationErrorDivisionByZero, evalErr.Code())
+
+	var arithmeticErr CaveatArithmeticError
+	require.True(t, errors.As(err, &arithmeticErr))
+	require.Equal(t, "/", arithmeticErr.Operation())
+}
+
+func TestEvaluationErrorsExposeCaveatName(t *testing.T) {
+	compiled, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"user_ip": types.IPAddressType,
+	}), "user_ip.in_cidr('invalidcidr')", "somecaveat")
+	require.NoError(t, err)
+
+	_, err = EvaluateCaveatWithConfig(context.Background(), compiled, map[string]any{
+		"user_ip": types.MustParseIPAddress("10.0.0.1"),
+	}, nil)
+	require.Error(t, err)
+
+	var evalErr CaveatEvaluationError
+	require.True(t, errors.As(err, &evalErr))
+	require.Equal(t, "somecaveat", evalErr.CaveatName())
+	require.Equal(t, EvaluationErrorUnknown, evalErr.Code())
+	require.Equal(t, "caveat `somecaveat` could not be evaluated: invalid CIDR string: `invalidcidr`", err.Error())
+
+	// Translating an already translated error leaves it unchanged.
+	require.Equal(t, evalErr, translateEvaluationError("somecaveat", nil, evalErr))
+}
@@ -0,0 +1,57 @@
+package caveats
+
+import (
+	"strings"
+
+	"github.com/google/cel-go/checker"
+)
+
+// EstimateCost returns the estimated minimum and maximum cost of evaluating the caveat, as
+// counted against EvaluationConfig.MaxCost, for any values of its parameters.
+//
+// The cost of operations on strings, bytes, lists and maps grows with their size, which is
+// unbounded for parameters. The maximum cost of any comprehension (e.g. `all` or `map`) over a
+// list or map parameter, and of any function of the strings extension library on a string
+// parameter, is therefore reported as math.MaxUint64, while that of other operations on such
+// parameters is reported as the cost for the largest size representable. Use
+// EstimateCostWithMaxSizes to bound the sizes of the parameters.
+func (cc CompiledCaveat) EstimateCost() (uint64, uint64, error) {
+	return cc.EstimateCostWithMaxSizes(nil)
+}
+
+// EstimateCostWithMaxSizes returns the estimated minimum and maximum cost of evaluating the
+// caveat, as EstimateCost, with the size of the string, bytes, list and map parameters bounded by
+// the given maximum sizes, keyed by parameter name. The sizes of the items of a list parameter,
+// and of the keys and values of a map parameter, are bounded by the keys `<name>.@items`,
+// `<name>.@keys` and `<name>.@values` respectively.
+func (cc CompiledCaveat) EstimateCostWithMaxSizes(maxSizes map[string]uint64) (uint64, uint64, error) {
+	estimate, err := cc.celEnv.EstimateCost(cc.ast, maxSizesEstimator{maxSizes})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return estimate.Min, estimate.Max, nil
+}
+
+// maxSizesEstimator is a checker.CostEstimator bounding the sizes of parameters.
+type maxSizesEstimator struct {
+	maxSizes map[string]uint64
+}
+
+func (e maxSizesEstimator) EstimateSize(element checker.AstNode) *checker.SizeEstimate {
+	path := element.Path()
+	if len(path) == 0 {
+		return nil
+	}
+
+	maxSize, ok := e.maxSizes[strings.Join(path, ".")]
+	if !ok {
+		return nil
+	}
+
+	return &checker.SizeEstimate{Min: 0, Max: maxSize}
+}
+
+func (e maxSizesEstimator) EstimateCallCost(function, overloadID string, target *checker.AstNode, args []checker.AstNode) *checker.CallEstimate {
+	return e.estimateStringExtensionCallCost(function, target, args)
+}
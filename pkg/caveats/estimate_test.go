@@ -0,0 +1,90 @@
+package caveats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+var estimateEnv = MustEnvForVariables(map[string]types.VariableType{
+	"a": types.IntType,
+	"l": types.MustListType(types.IntType),
+	"s": types.StringType,
+})
+
+func TestEstimateCost(t *testing.T) {
+	tcs := []struct {
+		exprString  string
+		expectedMin uint64
+		expectedMax uint64
+	}{
+		{"a == 1", 2, 2},
+		{"a == 1 || a == 2", 2, 4},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.exprString, func(t *testing.T) {
+			compiled, err := compileCaveat(estimateEnv, tc.exprString)
+			require.NoError(t, err)
+
+			minCost, maxCost, err := compiled.EstimateCost()
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedMin, minCost)
+			require.Equal(t, tc.expectedMax, maxCost)
+		})
+	}
+}
+
+func TestEstimateCostWithMaxSizes(t *testing.T) {
+	tcs := []struct {
+		exprString    string
+		sizeDependent bool
+	}{
+		{"l.all(i, i > a)", true},
+		{"l.size() > 2 && s.contains('hi')", true},
+
+		// The cost of startsWith only depends on the size of its argument, and that of size is
+		// constant, so the maximum sizes of the parameters do not apply.
+		{"l.size() > 2 && s.startsWith('hi')", false},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.exprString, func(t *testing.T) {
+			compiled, err := compileCaveat(estimateEnv, tc.exprString)
+			require.NoError(t, err)
+
+			unboundedMin, unboundedMax, err := compiled.EstimateCost()
+			require.NoError(t, err)
+
+			smallMin, smallMax, err := compiled.EstimateCostWithMaxSizes(map[string]uint64{"l": 10, "s": 10})
+			require.NoError(t, err)
+			require.Equal(t, unboundedMin, smallMin)
+			require.Less(t, smallMax, uint64(math.MaxUint64))
+
+			_, largeMax, err := compiled.EstimateCostWithMaxSizes(map[string]uint64{"l": 1000, "s": 1000})
+			require.NoError(t, err)
+
+			if !tc.sizeDependent {
+				require.Equal(t, smallMax, largeMax)
+				require.Equal(t, largeMax, unboundedMax)
+				return
+			}
+
+			require.Less(t, smallMax, largeMax)
+			require.Less(t, largeMax, unboundedMax)
+		})
+	}
+}
+
+func TestEstimateCostOfUnboundedComprehension(t *testing.T) {
+	compiled, err := compileCaveat(estimateEnv, "l.all(i, i > a)")
+	require.NoError(t, err)
+
+	_, maxCost, err := compiled.EstimateCost()
+	require.NoError(t, err)
+	require.Equal(t, uint64(math.MaxUint64), maxCost)
+}
@@ -1,22 +1,55 @@
 package caveats
 
 import (
+	"context"
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/cel-go/cel"
+	celtypes "github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
 	"github.com/google/cel-go/interpreter"
+	"golang.org/x/exp/maps"
 	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
-)
 
-var noSuchAttributeErrMessage = regexp.MustCompile(`^no such attribute: id: (.+), names: \[(.+)\]$`)
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
 
 // EvaluationConfig is configuration given to an EvaluateCaveatWithConfig call.
 type EvaluationConfig struct {
 	// MaxCost is the max cost of the caveat to be executed.
 	MaxCost uint64
+
+	// MaxComprehensionIterations is the max number of iterations, summed across all
+	// comprehensions (e.g. `all`, `exists`, `map` and `filter`), performed by the caveat.
+	// Unlike MaxCost, the limit is independent of the cost of each operation.
+	MaxComprehensionIterations uint64
+
+	// Timeout is the maximum duration of the evaluation. Once exceeded, the evaluation is
+	// interrupted and fails with ErrEvaluationTimeout.
+	//
+	// Evaluation is interrupted between iterations of comprehensions (e.g. `all` and `map`),
+	// which are the only constructs whose running time is not bounded by the size of the
+	// expression. The same applies to the cancellation of the context given for evaluation.
+	Timeout time.Duration
+
+	// MaxContextValues is the maximum number of context values given for evaluation, counting
+	// each top-level value as well as the elements of the lists and the entries of the maps it
+	// holds, at any depth. Evaluation with more context values fails with a
+	// ContextSizeLimitError before any is converted for CEL, complementing MaxCost by rejecting
+	// obviously oversized inputs upfront. Default values of parameters are counted when applied.
+	// Values resolved on demand by a ContextProvider are not counted.
+	MaxContextValues uint64
+
+	// MissingVariableBehavior defines the result of a caveat depending on parameters missing from
+	// the context values. Defaults to PartialEvaluation.
+	MissingVariableBehavior MissingVariableBehavior
+
+	// ThreeValuedLogic enables evaluation of parameters given as NullContextValue as null,
+	// per Kleene logic. See NullContextValue for details.
+	ThreeValuedLogic bool
 }
 
 // CaveatResult holds the result of evaluating a caveat.
@@ -27,11 +60,12 @@ type CaveatResult struct {
 	contextValues   map[string]any
 	missingVarNames []string
 	isPartial       bool
+	isNull          bool
 }
 
 // Value returns the computed value for the result.
 func (cr CaveatResult) Value() bool {
-	if cr.isPartial {
+	if cr.isPartial || cr.isNull {
 		return false
 	}
 
@@ -43,17 +77,97 @@ func (cr CaveatResult) IsPartial() bool {
 	return cr.isPartial
 }
 
+// IsNull returns true if the caveat evaluated to null under three-valued logic, because its
+// result depends on a parameter given as NullContextValue.
+func (cr CaveatResult) IsNull() bool {
+	return cr.isNull
+}
+
 // PartialValue returns the partially evaluated caveat. Only applies if IsPartial is true.
+//
+// The partial caveat is the expression pruned of the clauses already resolved, and can itself be
+// evaluated, e.g. with EvaluateCaveat, to resume evaluation once more context values are
+// available, without evaluating those clauses again. It retains the context values of this
+// result, which are used for the parameters not given when evaluating it, such that only the
+// newly available values need be given; values given take precedence. Evaluating a chain of
+// partial caveats thus yields the same result as evaluating the original caveat with all the
+// values at once.
 func (cr CaveatResult) PartialValue() (*CompiledCaveat, error) {
 	if !cr.isPartial {
 		return nil, fmt.Errorf("result is fully evaluated")
 	}
 
-	expr := interpreter.PruneAst(cr.parentCaveat.ast.Expr(), cr.details.State())
-	return &CompiledCaveat{cr.parentCaveat.celEnv, cel.ParsedExprToAst(&exprpb.ParsedExpr{Expr: expr}), cr.parentCaveat.name}, nil
+	// The values of the parameters are retained as defaults, as pruning only inlines those of
+	// the expressions evaluated and only for values representable as literals.
+	defaults := cr.parentCaveat.defaults
+	if len(cr.contextValues) > 0 {
+		defaults = make(map[string]any, len(cr.parentCaveat.defaults)+len(cr.contextValues))
+		maps.Copy(defaults, cr.parentCaveat.defaults)
+		maps.Copy(defaults, cr.contextValues)
+	}
+
+	expr := interpreter.PruneAst(cr.parentCaveat.ast.Expr(), resolvedValuesState{cr.details.State()})
+	return &CompiledCaveat{
+		celEnv:          cr.parentCaveat.celEnv,
+		ast:             cel.ParsedExprToAst(&exprpb.ParsedExpr{Expr: expr, SourceInfo: cr.parentCaveat.ast.SourceInfo()}),
+		name:            cr.parentCaveat.name,
+		sensitivities:   cr.parentCaveat.sensitivities,
+		impureFunctions: cr.parentCaveat.impureFunctions,
+		parameterTypes:  cr.parentCaveat.parameterTypes,
+		defaults:        defaults,
+	}, nil
+}
+
+// resolvedValuesState hides the unknown values of an evaluation state from pruning, such that
+// the clauses of logical operators resolved alongside a missing variable are kept as literals,
+// e.g. `a == 2 && true`, rather than dropped. Unknown values are still reported as found, as the
+// pruner allocates the IDs of the literals it creates among those not found.
+type resolvedValuesState struct {
+	interpreter.EvalState
+}
+
+// Value implements the interpreter.EvalState interface method.
+func (s resolvedValuesState) Value(id int64) (ref.Val, bool) {
+	val, found := s.EvalState.Value(id)
+	if found && celtypes.IsUnknown(val) {
+		return nil, true
+	}
+	return val, found
 }
 
-// ContextValues returns the context values used when computing this result.
+// OperationCount returns the number of distinct AST nodes evaluated when computing this result.
+// Unlike cost, operations are not weighted, which helps identify caveats performing many cheap
+// operations. Nodes skipped by short-circuiting are not counted, and nodes evaluated multiple
+// times within a comprehension are counted once.
+//
+// The count is derived from the tracked evaluation state, and therefore relies on evaluation
+// being performed with cel.OptTrackState, which is always enabled by EvaluateCaveatWithConfig.
+func (cr CaveatResult) OperationCount() uint64 {
+	if cr.details == nil {
+		return 0
+	}
+
+	return uint64(len(cr.details.State().IDs()))
+}
+
+// Cost returns the actual cost of the evaluation which produced this result, as counted against
+// EvaluationConfig.MaxCost, and whether the cost is available. The cost is only unavailable if
+// the evaluation details were not captured.
+func (cr CaveatResult) Cost() (uint64, bool) {
+	if cr.details == nil {
+		return 0, false
+	}
+
+	cost := cr.details.ActualCost()
+	if cost == nil {
+		return 0, false
+	}
+
+	return *cost, true
+}
+
+// ContextValues returns the context values used when computing this result, including the
+// default values of the parameters for which no value was given.
 func (cr CaveatResult) ContextValues() map[string]any {
 	return cr.contextValues
 }
@@ -63,7 +177,8 @@ func (cr CaveatResult) ExpressionString() (string, error) {
 	return cr.parentCaveat.ExprString()
 }
 
-// MissingVarNames returns the name(s) of the missing variables.
+// MissingVarNames returns the name(s) of the missing variables on which the partial result
+// depends, deduplicated and sorted.
 func (cr CaveatResult) MissingVarNames() ([]string, error) {
 	if !cr.isPartial {
 		return nil, fmt.Errorf("result is fully evaluated")
@@ -72,36 +187,393 @@ func (cr CaveatResult) MissingVarNames() ([]string, error) {
 	return cr.missingVarNames, nil
 }
 
+// UsedVariableNames returns the names of the variables read during the evaluation which
+// produced this result, deduplicated and sorted. Variables whose subexpressions were skipped by
+// short-circuiting, e.g. `b` in `a || b` if `a` is true, are not included, nor are missing
+// variables.
+//
+// The variables are derived from the tracked evaluation state, and therefore rely on evaluation
+// being performed with cel.OptTrackState, which is always enabled by EvaluateCaveatWithConfig.
+func (cr CaveatResult) UsedVariableNames() []string {
+	if cr.details == nil {
+		return nil
+	}
+
+	identifiers := map[int64]string{}
+	variableIdentifiers(cr.parentCaveat.ast.Expr(), map[string]int{}, identifiers)
+
+	state := cr.details.State()
+	used := map[string]struct{}{}
+	for id, name := range identifiers {
+		val, ok := state.Value(id)
+		if !ok || val == nil || celtypes.IsUnknown(val) {
+			continue
+		}
+		used[name] = struct{}{}
+	}
+
+	names := maps.Keys(used)
+	sort.Strings(names)
+	return names
+}
+
 // EvaluateCaveat evaluates the compiled caveat with the specified values, and returns
 // the result or an error.
 func EvaluateCaveat(caveat *CompiledCaveat, contextValues map[string]any) (*CaveatResult, error) {
-	return EvaluateCaveatWithConfig(caveat, contextValues, nil)
+	return EvaluateCaveatWithConfig(context.Background(), caveat, contextValues, nil)
 }
 
 // EvaluateCaveatWithConfig evaluates the compiled caveat with the specified values, and returns
-// the result or an error.
-func EvaluateCaveatWithConfig(caveat *CompiledCaveat, contextValues map[string]any, config *EvaluationConfig) (*CaveatResult, error) {
-	env := caveat.celEnv
-	celopts := make([]cel.ProgramOption, 0, 3)
+// the result or an error. Errors raised by the evaluation itself are returned as a
+// CaveatEvaluationError, with a stable code and message. Arithmetic failures, such as integer
+// overflow or division by zero, additionally wrap a CaveatArithmeticError describing the
+// operation; see its documentation for how they may be handled.
+//
+// If the parameter types of the caveat are known, values given for its timestamp and duration
+// parameters in their string (or, for durations, numeric) forms, and for its message parameters
+// in their JSON forms, are converted as by ConvertContextToParameters, failing with a
+// ParameterConversionErr if malformed.
+//
+// Parameters declared with a default value, for which no value is given, take their default.
+//
+// The evaluation is interrupted should the context be cancelled, or its deadline or the
+// configured Timeout be exceeded, in which case no partial result is returned.
+func EvaluateCaveatWithConfig(ctx context.Context, caveat *CompiledCaveat, contextValues map[string]any, config *EvaluationConfig) (*CaveatResult, error) {
+	contextValues, err := coerceContextValues(caveat, withDefaultValues(caveat, contextValues))
+	if err != nil {
+		return nil, err
+	}
+
+	activation, err := newEvaluationActivation(contextValues, config)
+	if err != nil {
+		return nil, translateEvaluationError(caveat.name, config, err)
+	}
+
+	return evaluateCaveat(ctx, caveat, activation, config, nil)
+}
+
+// EvaluateCaveatWithContextLayers evaluates the compiled caveat with the values of the context
+// layers given, as EvaluateCaveatWithConfig. The layers are merged in order, with the value
+// given for a parameter by a layer overriding those given by the layers preceding it, e.g.
+// static defaults followed by tenant defaults followed by per-request values.
+//
+// The merge is shallow: a map value given for a parameter replaces any value given by an earlier
+// layer, rather than being merged into it. The merged values are those returned by the
+// ContextValues of the result. The layers themselves are not modified.
+func EvaluateCaveatWithContextLayers(ctx context.Context, caveat *CompiledCaveat, layers []map[string]any, config *EvaluationConfig) (*CaveatResult, error) {
+	return EvaluateCaveatWithConfig(ctx, caveat, mergeContextLayers(layers), config)
+}
+
+// mergeContextLayers merges the context layers into a new map, with later layers taking
+// precedence over earlier ones.
+func mergeContextLayers(layers []map[string]any) map[string]any {
+	size := 0
+	for _, layer := range layers {
+		size += len(layer)
+	}
+
+	merged := make(map[string]any, size)
+	for _, layer := range layers {
+		maps.Copy(merged, layer)
+	}
+	return merged
+}
+
+// EvaluateCaveatExpression compiles the expression with the given parameter types and evaluates
+// it with the specified values, for tooling evaluating one-off expressions. Compilation errors
+// are returned as CompilationErrors, which carry the position of the error in the expression,
+// while errors raised by the evaluation are returned as for EvaluateCaveatWithConfig.
+//
+// The expression is compiled on every call: caveats evaluated repeatedly should be compiled once
+// and evaluated with EvaluateCaveatWithConfig.
+func EvaluateCaveatExpression(exprString string, parameterTypes map[string]types.VariableType, contextValues map[string]any, config *EvaluationConfig) (*CaveatResult, error) {
+	env, err := EnvForVariables(parameterTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := compileCaveat(env, exprString)
+	if err != nil {
+		return nil, err
+	}
+
+	return EvaluateCaveatWithConfig(context.Background(), compiled, contextValues, config)
+}
+
+// withDefaultValues returns the context values along with the default values of the parameters
+// of the caveat for which no value is given. The context values are returned as-is if no default
+// applies.
+func withDefaultValues(caveat *CompiledCaveat, contextValues map[string]any) map[string]any {
+	if !needsDefaultValues(caveat, contextValues) {
+		return contextValues
+	}
+
+	withDefaults := make(map[string]any, len(contextValues)+len(caveat.defaults))
+	maps.Copy(withDefaults, caveat.defaults)
+	maps.Copy(withDefaults, contextValues)
+	return withDefaults
+}
+
+// needsDefaultValues returns whether a default value of the caveat applies to the context values.
+func needsDefaultValues(caveat *CompiledCaveat, contextValues map[string]any) bool {
+	for name := range caveat.defaults {
+		if _, ok := contextValues[name]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// coercedTypeNames are the names of the parameter types whose values are converted by
+// coerceContextValues.
+var coercedTypeNames = map[string]struct{}{
+	types.DurationType.String():  {},
+	types.TimestampType.String(): {},
+}
+
+// coerceContextValues converts the values given for the timestamp, duration and message
+// parameters of the caveat, if its parameter types are known, into the types expected by CEL.
+func coerceContextValues(caveat *CompiledCaveat, contextValues map[string]any) (map[string]any, error) {
+	coerced := contextValues
+	cloned := false
+	for paramName := range caveat.parameterTypes {
+		value, ok := contextValues[paramName]
+		if !ok {
+			continue
+		}
+
+		converted, isConverted, err := coerceContextValue(caveat, paramName, value)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isConverted {
+			continue
+		}
+
+		if !cloned {
+			coerced = maps.Clone(contextValues)
+			cloned = true
+		}
+		coerced[paramName] = converted
+	}
+	return coerced, nil
+}
+
+// coerceContextValue converts the value given for the parameter of the caveat, as
+// coerceContextValues, returning whether it was converted.
+func coerceContextValue(caveat *CompiledCaveat, paramName string, value any) (any, bool, error) {
+	paramType, ok := caveat.parameterTypes[paramName]
+	if !ok {
+		return value, false, nil
+	}
+
+	if _, ok := coercedTypeNames[paramType.TypeName]; !ok && !types.IsMessageType(paramType.TypeName) {
+		return value, false, nil
+	}
+
+	switch value.(type) {
+	case time.Time, time.Duration, nullContextValue:
+		return value, false, nil
+	}
+
+	varType, err := types.DecodeParameterType(paramType)
+	if err != nil {
+		return nil, false, err
+	}
+
+	converted, err := varType.ConvertValue(value)
+	if err != nil {
+		return nil, false, ParameterConversionErr{fmt.Errorf("could not convert context parameter `%s`: %w", paramName, err), paramName}
+	}
+	return converted, true, nil
+}
+
+// evaluationActivation holds the context values for evaluation, in the form given to CEL, such
+// that they can be shared by the evaluations of multiple caveats.
+type evaluationActivation struct {
+	contextValues map[string]any
+	pvars         interpreter.PartialActivation
+	nullPatterns  []*interpreter.AttributePattern
+
+	// provider, if set, resolves the context values on demand, in place of contextValues.
+	provider *providerActivation
+}
+
+// values returns the context values used by the evaluation.
+func (a *evaluationActivation) values() map[string]any {
+	if a.provider != nil {
+		return a.provider.resolvedValues()
+	}
+	return a.contextValues
+}
+
+func newEvaluationActivation(contextValues map[string]any, config *EvaluationConfig) (*evaluationActivation, error) {
+	if err := validateContextSize(contextValues, config); err != nil {
+		return nil, err
+	}
+
+	activationValues := contextValues
+	var nullPatterns []*interpreter.AttributePattern
+	if config != nil && config.ThreeValuedLogic {
+		activationValues, nullPatterns = withoutNullContextValues(contextValues)
+	}
+
+	pvars, err := cel.PartialVars(activationValues, nullPatterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &evaluationActivation{
+		contextValues: contextValues,
+		pvars:         pvars,
+		nullPatterns:  nullPatterns,
+	}, nil
+}
+
+// missingVariablesActivation extends the shared activation with attribute patterns marking the
+// variables of a caveat missing from the context values as unknown. Evaluating a missing variable
+// then results in an unknown value, identifying the variable, rather than an error.
+type missingVariablesActivation struct {
+	interpreter.PartialActivation
+	patterns []*interpreter.AttributePattern
+}
+
+// UnknownAttributePatterns implements the interpreter.PartialActivation interface method.
+func (a *missingVariablesActivation) UnknownAttributePatterns() []*interpreter.AttributePattern {
+	return a.patterns
+}
+
+// missingVariables returns the identifiers of the variables of the caveat, by expression ID, which
+// are missing from the context values of the activation. For context values resolved on demand,
+// the variables missing are those which the provider has already failed to resolve.
+//
+// Only top-level variables can be missing: fields selected from a variable given in the context
+// values, e.g. `user.email` for a map `user`, are resolved concretely, such that presence tests
+// of absent fields (`has(user.email)`) evaluate to false rather than to a partial result.
+func missingVariables(caveat *CompiledCaveat, activation *evaluationActivation) map[int64]string {
+	identifiers := map[int64]string{}
+	variableIdentifiers(caveat.ast.Expr(), map[string]int{}, identifiers)
+
+	for id, name := range identifiers {
+		if activation.provider != nil {
+			if !activation.provider.isMissing(name) {
+				delete(identifiers, id)
+			}
+			continue
+		}
+
+		if hasContextValue(activation.contextValues, name) {
+			delete(identifiers, id)
+		}
+	}
+	return identifiers
+}
+
+// hasContextValue returns whether the context values contain the variable with the given name,
+// or a variable qualified by it, as found in an expression which has not been type-checked (e.g.
+// `foo` for `foo.a`).
+func hasContextValue(contextValues map[string]any, name string) bool {
+	if _, ok := contextValues[name]; ok {
+		return true
+	}
+
+	prefix := name + "."
+	for key := range contextValues {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// programOptions are the options varying between the programs built to evaluate a caveat.
+type programOptions struct {
+	// maxCost is the cost limit of the evaluation, or zero for no limit.
+	maxCost uint64
+
+	// interruptible indicates whether the evaluation checks for interrupts.
+	interruptible bool
+}
+
+func newProgram(caveat *CompiledCaveat, options programOptions) (cel.Program, error) {
+	celopts := make([]cel.ProgramOption, 0, 5)
 
 	// TODO(jschorr): Turn off if we know we have all the context values necessary?
 	// Option: enables partial evaluation and state tracking for partial evaluation.
 	celopts = append(celopts, cel.EvalOptions(cel.OptTrackState))
 	celopts = append(celopts, cel.EvalOptions(cel.OptPartialEval))
 
+	// Option: tracks the actual cost of the evaluation, as reported by CaveatResult.Cost, with
+	// the calls of the strings extension functions costed by the size of their operands.
+	celopts = append(celopts, cel.CostTracking(stringExtensionCostEstimator{}))
+
 	// Option: Cost limit on the evaluation.
-	if config != nil && config.MaxCost > 0 {
-		celopts = append(celopts, cel.CostLimit(config.MaxCost))
+	if options.maxCost > 0 {
+		celopts = append(celopts, cel.CostLimit(options.maxCost))
 	}
 
-	prg, err := env.Program(caveat.ast, celopts...)
-	if err != nil {
-		return nil, err
+	// Option: Checks for an interrupt after each iteration of a comprehension.
+	if options.interruptible {
+		celopts = append(celopts, cel.InterruptCheckFrequency(1))
 	}
 
-	pvars, err := cel.PartialVars(contextValues)
+	return caveat.celEnv.Program(caveat.ast, celopts...)
+}
+
+// evaluateCaveat evaluates the caveat with the activation. Programs are taken from the cache
+// given, if any, or otherwise built for the evaluation.
+func evaluateCaveat(ctx context.Context, caveat *CompiledCaveat, activation *evaluationActivation, config *EvaluationConfig, programs *programCache) (*CaveatResult, error) {
+	nullPatterns := activation.nullPatterns
+
+	if config != nil && config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, newCaveatInterruptedError(caveat.name, err)
+	}
+
+	// The iteration limit on comprehensions and cancellation are enforced by checking for an
+	// interrupt after each iteration.
+	hasIterationLimit := config != nil && config.MaxComprehensionIterations > 0
+	options := programOptions{interruptible: hasIterationLimit || ctx.Done() != nil}
+	if config != nil {
+		options.maxCost = config.MaxCost
+	}
+
+	var prg cel.Program
+	var err error
+	if programs != nil {
+		prg, err = programs.program(caveat, options)
+	} else {
+		prg, err = newProgram(caveat, options)
+	}
 	if err != nil {
-		return nil, err
+		return nil, translateEvaluationError(caveat.name, config, err)
+	}
+
+	missing := missingVariables(caveat, activation)
+
+	var pvars interpreter.PartialActivation = activation.pvars
+	if len(missing) > 0 {
+		patterns := make([]*interpreter.AttributePattern, 0, len(nullPatterns)+len(missing))
+		patterns = append(patterns, nullPatterns...)
+		for _, name := range missingVarNames(missing) {
+			patterns = append(patterns, cel.AttributePattern(name))
+		}
+		pvars = &missingVariablesActivation{PartialActivation: pvars, patterns: patterns}
+	}
+
+	var interruptible *interruptibleActivation
+	if options.interruptible {
+		interruptible = &interruptibleActivation{PartialActivation: pvars, done: ctx.Done()}
+		if hasIterationLimit {
+			interruptible.maxIterations = config.MaxComprehensionIterations
+		}
+		pvars = interruptible
 	}
 
 	val, details, err := prg.Eval(pvars)
@@ -110,32 +582,57 @@ func EvaluateCaveatWithConfig(caveat *CompiledCaveat, contextValues map[string]a
 		// *  `val`, `details`, `nil` - Successful evaluation of a non-error result.
 		// *  `val`, `details`, `err` - Successful evaluation to an error result.
 		// *  `nil`, `details`, `err` - Unsuccessful evaluation.
-		// TODO(jschorr): Change to a better way to detect partial eval if/when CEL adds properly
-		// wrapped errors.
-		if val != nil && strings.Contains(err.Error(), "no such attribute") {
-			found := noSuchAttributeErrMessage.FindStringSubmatch(err.Error())
-			if found != nil {
+		if interruptible != nil && interruptible.exceeded() {
+			err = newCaveatIterationLimitError(err, caveat.name, interruptible.maxIterations)
+		} else if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, newCaveatInterruptedError(caveat.name, ctxErr)
+		} else if arithmeticErr, ok := asArithmeticError(caveat, details, err); ok {
+			err = arithmeticErr
+		}
+
+		return nil, translateEvaluationError(caveat.name, config, err)
+	}
+
+	contextValues := activation.values()
+
+	// An unknown result is partial if any variable is missing. As the unknown result only
+	// identifies the variables of the branches evaluated, e.g. one side of a logical operator, all
+	// the missing variables are reported.
+	isUnknown := celtypes.IsUnknown(val)
+	if isUnknown {
+		dependencies := missingVarNames(missing)
+
+		if len(dependencies) > 0 {
+			if config == nil || config.MissingVariableBehavior == PartialEvaluation {
 				return &CaveatResult{
 					val:             val,
 					details:         details,
 					parentCaveat:    caveat,
 					contextValues:   contextValues,
-					missingVarNames: strings.Split(found[2], " "),
+					missingVarNames: dependencies,
 					isPartial:       true,
 				}, nil
 			}
 
-			return &CaveatResult{
-				val:             val,
-				details:         details,
-				parentCaveat:    caveat,
-				contextValues:   contextValues,
-				missingVarNames: nil,
-				isPartial:       true,
-			}, nil
+			val, err = evaluateWithoutMissingVariables(caveat, details, pvars, missing, config.MissingVariableBehavior)
+			if err != nil {
+				return nil, translateEvaluationError(caveat.name, config, err)
+			}
+			isUnknown = celtypes.IsUnknown(val)
 		}
+	}
 
-		return nil, err
+	// Under three-valued logic, a result depending on a null parameter is unknown.
+	if len(nullPatterns) > 0 && isUnknown {
+		return &CaveatResult{
+			val:             val,
+			details:         details,
+			parentCaveat:    caveat,
+			contextValues:   contextValues,
+			missingVarNames: nil,
+			isPartial:       false,
+			isNull:          true,
+		}, nil
 	}
 
 	return &CaveatResult{
@@ -147,3 +644,18 @@ func EvaluateCaveatWithConfig(caveat *CompiledCaveat, contextValues map[string]a
 		isPartial:       false,
 	}, nil
 }
+
+// missingVarNames returns the deduplicated and sorted names of the missing variables.
+func missingVarNames(missing map[int64]string) []string {
+	found := map[string]struct{}{}
+	for _, name := range missing {
+		found[name] = struct{}{}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,6 +1,10 @@
 package caveats
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -8,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/authzed/spicedb/pkg/caveats/types"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
 var noMissingVars []string
@@ -135,7 +140,21 @@ func TestEvaluateCaveat(t *testing.T) {
 			"",
 			false,
 			"a == 2 || b == 6",
-			[]string{"a"}, // second part of OR expression is not evaluated
+			[]string{"a", "b"},
+		},
+		{
+			"multiple missing variables are sorted and deduplicated",
+			MustEnvForVariables(map[string]types.VariableType{
+				"a": types.IntType,
+				"b": types.IntType,
+				"c": types.IntType,
+			}),
+			"c == 3 && b == 2 && a == 1 && c < 10",
+			map[string]any{},
+			"",
+			false,
+			"c == 3 && b == 2 && a == 1 && c < 10",
+			[]string{"a", "b", "c"},
 		},
 		{
 			"missing variable for left side of and boolean expression",
@@ -338,6 +357,132 @@ func TestPartialEvaluation(t *testing.T) {
 	require.False(t, fullResult.IsPartial())
 }
 
+func TestMissingVarNamesExcludeComprehensionVariables(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"l": types.MustListType(types.IntType),
+		"x": types.IntType,
+	}), "l.all(i, i < x) && type(x) == int")
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{
+		"l": []int{1, 2},
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	missingVarNames, err := result.MissingVarNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"x"}, missingVarNames)
+
+	result, err = EvaluateCaveat(compiled, map[string]any{})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	missingVarNames, err = result.MissingVarNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"l", "x"}, missingVarNames)
+}
+
+func TestEvalPresenceOfNestedFields(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"context": types.MustMapType(types.AnyType),
+		"user":    types.MustMapType(types.StringType),
+	}), "has(context.user.email) || has(user.email)")
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name             string
+		contextValues    map[string]any
+		expectedValue    bool
+		expectedPartial  bool
+		expectedMissing  []string
+		expectedErrorMsg string
+	}{
+		{
+			"present children",
+			map[string]any{
+				"context": map[string]any{"user": map[string]any{"email": "a@example.com"}},
+				"user":    map[string]any{"email": "a@example.com"},
+			},
+			true,
+			false,
+			nil,
+			"",
+		},
+		{
+			"present parents, absent children",
+			map[string]any{
+				"context": map[string]any{"user": map[string]any{"name": "a"}},
+				"user":    map[string]any{"name": "a"},
+			},
+			false,
+			false,
+			nil,
+			"",
+		},
+		{
+			"present parent, absent child of the other",
+			map[string]any{
+				"context": map[string]any{"user": map[string]any{}},
+				"user":    map[string]any{"email": "a@example.com"},
+			},
+			true,
+			false,
+			nil,
+			"",
+		},
+		{
+			"absent parent",
+			map[string]any{
+				"context": map[string]any{"user": map[string]any{}},
+			},
+			false,
+			true,
+			[]string{"user"},
+			"",
+		},
+		{
+			"absent parents",
+			map[string]any{},
+			false,
+			true,
+			[]string{"context", "user"},
+			"",
+		},
+		{
+			"absent intermediate field",
+			map[string]any{
+				"context": map[string]any{},
+				"user":    map[string]any{},
+			},
+			false,
+			false,
+			nil,
+			"no such key: user",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := EvaluateCaveat(compiled, tc.contextValues)
+			if tc.expectedErrorMsg != "" {
+				require.ErrorContains(t, err, tc.expectedErrorMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedValue, result.Value())
+			require.Equal(t, tc.expectedPartial, result.IsPartial())
+
+			if tc.expectedPartial {
+				missingVarNames, err := result.MissingVarNames()
+				require.NoError(t, err)
+				require.Equal(t, tc.expectedMissing, missingVarNames)
+			}
+		})
+	}
+}
+
 func TestEvalWithMaxCost(t *testing.T) {
 	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
 		"a": types.IntType,
@@ -345,14 +490,198 @@ func TestEvalWithMaxCost(t *testing.T) {
 	}), "a + b > 47")
 	require.NoError(t, err)
 
-	_, err = EvaluateCaveatWithConfig(compiled, map[string]any{
+	_, err = EvaluateCaveatWithConfig(context.Background(), compiled, map[string]any{
 		"a": 42,
 		"b": 4,
 	}, &EvaluationConfig{
 		MaxCost: 1,
 	})
 	require.Error(t, err)
-	require.Equal(t, "operation cancelled: actual cost limit exceeded", err.Error())
+	require.Equal(t, "caveat `caveat` exceeded the maximum evaluation cost of 1", err.Error())
+
+	var evalErr CaveatEvaluationError
+	require.True(t, errors.As(err, &evalErr))
+	require.Equal(t, EvaluationErrorCostLimitExceeded, evalErr.Code())
+}
+
+func TestEvalCost(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.IntType,
+	}), "a + b > 47")
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{
+		"a": 42,
+		"b": 4,
+	})
+	require.NoError(t, err)
+
+	cost, ok := result.Cost()
+	require.True(t, ok)
+	require.Greater(t, cost, uint64(0))
+
+	// The reported cost is exactly that counted against the cost limit.
+	_, err = EvaluateCaveatWithConfig(context.Background(), compiled, map[string]any{
+		"a": 42,
+		"b": 4,
+	}, &EvaluationConfig{MaxCost: cost})
+	require.NoError(t, err)
+
+	_, err = EvaluateCaveatWithConfig(context.Background(), compiled, map[string]any{
+		"a": 42,
+		"b": 4,
+	}, &EvaluationConfig{MaxCost: cost - 1})
+	require.Error(t, err)
+
+	// Partial results report the cost of the partial evaluation.
+	result, err = EvaluateCaveat(compiled, map[string]any{
+		"a": 42,
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	_, ok = result.Cost()
+	require.True(t, ok)
+
+	// Without evaluation details, the cost is unavailable.
+	cost, ok = CaveatResult{}.Cost()
+	require.False(t, ok)
+	require.Equal(t, uint64(0), cost)
+}
+
+func TestEvalWithMaxComprehensionIterations(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"nums": types.MustListType(types.IntType),
+	}), "nums.all(n, n > 0) && nums.map(n, n * 2).size() > 0")
+	require.NoError(t, err)
+
+	nums := make([]any, 0, 1000)
+	for i := 1; i <= 1000; i++ {
+		nums = append(nums, i)
+	}
+
+	_, err = EvaluateCaveatWithConfig(context.Background(), compiled, map[string]any{
+		"nums": nums,
+	}, &EvaluationConfig{
+		MaxComprehensionIterations: 1500,
+	})
+	require.Error(t, err)
+
+	var iterationErr CaveatIterationLimitError
+	require.True(t, errors.As(err, &iterationErr))
+	require.Equal(t, uint64(1500), iterationErr.MaxIterations())
+	require.Equal(t, "caveat `caveat` exceeded the maximum of 1500 comprehension iterations", err.Error())
+
+	// Both comprehensions iterate over the full list, for a total of 2000 iterations.
+	result, err := EvaluateCaveatWithConfig(context.Background(), compiled, map[string]any{
+		"nums": nums,
+	}, &EvaluationConfig{
+		MaxComprehensionIterations: 2000,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+
+	// Without a limit, the iterations are unbounded.
+	result, err = EvaluateCaveat(compiled, map[string]any{
+		"nums": nums,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+}
+
+func TestEvalWithMaxContextValues(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"allowed": types.MustMapType(types.MustListType(types.StringType)),
+		"user":    types.StringType,
+	}), "allowed.exists(k, user in allowed[k])")
+	require.NoError(t, err)
+
+	// 2 top-level values, 2 map entries and 3 list elements.
+	contextValues := map[string]any{
+		"allowed": map[string]any{
+			"first":  []any{"tom", "sarah"},
+			"second": []any{"fred"},
+		},
+		"user": "fred",
+	}
+	require.Equal(t, uint64(7), countContextValues(contextValues, 100))
+
+	result, err := EvaluateCaveatWithConfig(context.Background(), compiled, contextValues, &EvaluationConfig{MaxContextValues: 7})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+
+	_, err = EvaluateCaveatWithConfig(context.Background(), compiled, contextValues, &EvaluationConfig{MaxContextValues: 6})
+	require.Error(t, err)
+	require.Equal(t, "caveat `caveat` context exceeds the maximum of 6 values", err.Error())
+
+	var sizeErr ContextSizeLimitError
+	require.True(t, errors.As(err, &sizeErr))
+	require.Equal(t, uint64(6), sizeErr.MaxContextValues())
+
+	var evalErr CaveatEvaluationError
+	require.True(t, errors.As(err, &evalErr))
+	require.Equal(t, EvaluationErrorContextTooLarge, evalErr.Code())
+
+	// Counting stops once the limit is exceeded.
+	huge := make([]any, 1_000_000)
+	require.Equal(t, uint64(3), countContextValues(map[string]any{"huge": huge}, 2))
+
+	// Byte sequences count as a single value.
+	require.Equal(t, uint64(1), countContextValues(map[string]any{"bytes": []byte("somebytes")}, 100))
+}
+
+func TestEvalWithTimeout(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"nums": types.MustListType(types.IntType),
+	}), "nums.all(x, nums.all(y, x + y > 0))")
+	require.NoError(t, err)
+
+	nums := make([]any, 0, 2000)
+	for i := 1; i <= 2000; i++ {
+		nums = append(nums, i)
+	}
+
+	_, err = EvaluateCaveatWithConfig(context.Background(), compiled, map[string]any{
+		"nums": nums,
+	}, &EvaluationConfig{
+		Timeout: time.Millisecond,
+	})
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrEvaluationTimeout)
+	require.Equal(t, "caveat `caveat` exceeded its evaluation timeout", err.Error())
+
+	var evalErr CaveatEvaluationError
+	require.True(t, errors.As(err, &evalErr))
+	require.Equal(t, EvaluationErrorTimeout, evalErr.Code())
+
+	// The deadline of the context is respected as well.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	_, err = EvaluateCaveatWithConfig(ctx, compiled, map[string]any{
+		"nums": nums,
+	}, nil)
+	require.ErrorIs(t, err, ErrEvaluationTimeout)
+
+	// Cancellation is distinguished from timeouts.
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	_, err = EvaluateCaveatWithConfig(ctx, compiled, map[string]any{
+		"nums": nums,
+	}, nil)
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotErrorIs(t, err, ErrEvaluationTimeout)
+
+	// A generous timeout does not interfere with the evaluation.
+	result, err := EvaluateCaveatWithConfig(context.Background(), compiled, map[string]any{
+		"nums": []any{1, 2, 3},
+	}, &EvaluationConfig{
+		Timeout: time.Minute,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Value())
 }
 
 func TestEvalWithNesting(t *testing.T) {
@@ -370,3 +699,748 @@ func TestEvalWithNesting(t *testing.T) {
 	require.False(t, result.Value())
 	require.False(t, result.IsPartial())
 }
+
+func TestEvalDivisionByZero(t *testing.T) {
+	tcs := []struct {
+		name              string
+		varType           types.VariableType
+		exprString        string
+		context           map[string]any
+		expectedOperation string
+		expectedOperands  []any
+	}{
+		{
+			"integer division by zero",
+			types.IntType,
+			"a / b == 1",
+			map[string]any{"a": int64(42), "b": int64(0)},
+			"/",
+			[]any{int64(42), int64(0)},
+		},
+		{
+			"integer modulus by zero",
+			types.IntType,
+			"a % b == 1",
+			map[string]any{"a": int64(42), "b": int64(0)},
+			"%",
+			[]any{int64(42), int64(0)},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			compiled, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+				"a": tc.varType,
+				"b": tc.varType,
+			}), tc.exprString, "somecaveat")
+			require.NoError(t, err)
+
+			_, err = EvaluateCaveat(compiled, tc.context)
+			require.Error(t, err)
+
+			var arithmeticErr CaveatArithmeticError
+			require.True(t, errors.As(err, &arithmeticErr))
+			require.Equal(t, tc.expectedOperation, arithmeticErr.Operation())
+			require.Equal(t, tc.expectedOperands, arithmeticErr.Operands())
+			require.Equal(t, "somecaveat", arithmeticErr.DetailsMetadata()["caveat_name"])
+		})
+	}
+}
+
+func TestEvalIntegerOverflow(t *testing.T) {
+	tcs := []struct {
+		name              string
+		exprString        string
+		context           map[string]any
+		expectedOperation string
+		expectedOperands  []any
+	}{
+		{
+			"addition above max",
+			"a + b > 0",
+			map[string]any{"a": int64(math.MaxInt64), "b": int64(1)},
+			"+",
+			[]any{int64(math.MaxInt64), int64(1)},
+		},
+		{
+			"subtraction below min",
+			"a - b < 0",
+			map[string]any{"a": int64(math.MinInt64), "b": int64(1)},
+			"-",
+			[]any{int64(math.MinInt64), int64(1)},
+		},
+		{
+			"multiplication",
+			"a * b > 0",
+			map[string]any{"a": int64(math.MaxInt64 / 2), "b": int64(3)},
+			"*",
+			[]any{int64(math.MaxInt64 / 2), int64(3)},
+		},
+		{
+			"division of min by minus one",
+			"a / b > 0",
+			map[string]any{"a": int64(math.MinInt64), "b": int64(-1)},
+			"/",
+			[]any{int64(math.MinInt64), int64(-1)},
+		},
+		{
+			"negation of min",
+			"-a > 0",
+			map[string]any{"a": int64(math.MinInt64)},
+			"-",
+			[]any{int64(math.MinInt64)},
+		},
+		{
+			"nested operation",
+			"(a * b) + 1 > 0",
+			map[string]any{"a": int64(math.MaxInt64), "b": int64(2)},
+			"*",
+			[]any{int64(math.MaxInt64), int64(2)},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			compiled, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+				"a": types.IntType,
+				"b": types.IntType,
+			}), tc.exprString, "somecaveat")
+			require.NoError(t, err)
+
+			_, err = EvaluateCaveat(compiled, tc.context)
+			require.Error(t, err)
+
+			var arithmeticErr CaveatArithmeticError
+			require.True(t, errors.As(err, &arithmeticErr))
+			require.True(t, arithmeticErr.IsOverflow())
+			require.Equal(t, tc.expectedOperation, arithmeticErr.Operation())
+			require.Equal(t, tc.expectedOperands, arithmeticErr.Operands())
+
+			var evalErr CaveatEvaluationError
+			require.True(t, errors.As(err, &evalErr))
+			require.Equal(t, EvaluationErrorArithmeticOverflow, evalErr.Code())
+		})
+	}
+
+	// Operations reaching the boundaries without overflowing succeed.
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.IntType,
+	}), "a + b == 9223372036854775807 && -a - b == -9223372036854775807")
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{"a": int64(math.MaxInt64 - 1), "b": int64(1)})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+}
+
+func TestEvalDoubleDivisionByZero(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.DoubleType,
+		"b": types.DoubleType,
+	}), "a / b > 1000.0")
+	require.NoError(t, err)
+
+	// Double division follows IEEE 754, so no error is raised.
+	result, err := EvaluateCaveat(compiled, map[string]any{
+		"a": 42.0,
+		"b": 0.0,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+}
+
+func TestEvalGuardedDivision(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.IntType,
+	}), "b != 0 && a / b == 2")
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{
+		"a": int64(4),
+		"b": int64(0),
+	})
+	require.NoError(t, err)
+	require.False(t, result.Value())
+}
+
+func TestResumePartial(t *testing.T) {
+	compiled, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"a":       types.IntType,
+		"b":       types.IntType,
+		"expires": types.TimestampType,
+		"now":     types.TimestampType,
+		"user_ip": types.IPAddressType,
+	}), "a + b > 47 && now < expires && user_ip.in_cidr('10.0.0.0/8')", "somecaveat")
+	require.NoError(t, err)
+
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	result, err := EvaluateCaveat(compiled, map[string]any{
+		"a":       int64(42),
+		"expires": expires,
+		"user_ip": types.MustParseIPAddress("10.1.2.3"),
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	serialized, err := result.MarshalPartial()
+	require.NoError(t, err)
+
+	// Resume from only the serialized bytes, as would be done in another process.
+	resumed, err := ResumePartial(serialized, map[string]any{
+		"b":   int64(6),
+		"now": time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.False(t, resumed.IsPartial())
+	require.True(t, resumed.Value())
+
+	// Ensure the result matches that of a single full evaluation.
+	full, err := EvaluateCaveat(compiled, map[string]any{
+		"a":       int64(42),
+		"b":       int64(6),
+		"expires": expires,
+		"now":     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		"user_ip": types.MustParseIPAddress("10.1.2.3"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, full.Value(), resumed.Value())
+
+	resumed, err = ResumePartial(serialized, map[string]any{
+		"b":   int64(2),
+		"now": time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.False(t, resumed.IsPartial())
+	require.False(t, resumed.Value())
+
+	// Resuming with insufficient context remains partial.
+	resumed, err = ResumePartial(serialized, map[string]any{
+		"b": int64(6),
+	})
+	require.NoError(t, err)
+	require.True(t, resumed.IsPartial())
+
+	// Resume against the caveat from which the partial result was produced.
+	resumed, err = ResumePartialWithConfig(context.Background(), compiled, serialized, map[string]any{
+		"b":   int64(6),
+		"now": time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, nil)
+	require.NoError(t, err)
+	require.False(t, resumed.IsPartial())
+	require.True(t, resumed.Value())
+
+	other, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+	}), "a > 1", "othercaveat")
+	require.NoError(t, err)
+
+	_, err = ResumePartialWithConfig(context.Background(), other, serialized, map[string]any{"b": int64(6)}, nil)
+	require.ErrorContains(t, err, "cannot resume a partial result of caveat `somecaveat` against caveat `othercaveat`")
+}
+
+func TestMarshalPartialWithRestrictedParameter(t *testing.T) {
+	env := NewEnvironment()
+	require.NoError(t, env.AddVariableWithSensitivity("ssn", types.StringType, SensitivityRestricted))
+	require.NoError(t, env.AddVariableWithSensitivity("department", types.StringType, SensitivityInternal))
+
+	compiled, err := CompileCaveatWithName(env, "ssn == '123' && department == 'eng'", "somecaveat")
+	require.NoError(t, err)
+
+	// A restricted value cannot be serialized.
+	result, err := EvaluateCaveat(compiled, map[string]any{
+		"ssn": "123",
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	_, err = result.MarshalPartial()
+	require.ErrorContains(t, err, "cannot serialize a partial result holding a value for restricted parameter `ssn`")
+
+	// A restricted parameter yet to be supplied is not written out, so the result can be serialized.
+	result, err = EvaluateCaveat(compiled, map[string]any{
+		"department": "eng",
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	serialized, err := result.MarshalPartial()
+	require.NoError(t, err)
+
+	resumed, err := ResumePartial(serialized, map[string]any{
+		"ssn": "123",
+	})
+	require.NoError(t, err)
+	require.False(t, resumed.IsPartial())
+	require.True(t, resumed.Value())
+}
+
+func TestResumePartialWithFunctionLibrary(t *testing.T) {
+	compiled, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"a":       types.TimestampType,
+		"b":       types.TimestampType,
+		"enabled": types.BooleanType,
+	}), "enabled && time.same_day(a, b)", "somecaveat", WithFunctionLibrary(sameDayLibrary{}))
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{
+		"enabled": true,
+		"a":       time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	serialized, err := result.MarshalPartial()
+	require.NoError(t, err)
+
+	moreContext := map[string]any{"b": time.Date(2022, 1, 1, 20, 0, 0, 0, time.UTC)}
+
+	// The library is not serialized, so the partial result must be resumed against the caveat.
+	_, err = ResumePartial(serialized, moreContext)
+	require.Error(t, err)
+
+	resumed, err := ResumePartialWithConfig(context.Background(), compiled, serialized, moreContext, nil)
+	require.NoError(t, err)
+	require.False(t, resumed.IsPartial())
+	require.True(t, resumed.Value())
+
+	other, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"enabled": types.BooleanType,
+	}), "enabled", "othercaveat")
+	require.NoError(t, err)
+
+	_, err = ResumePartialWithConfig(context.Background(), other, serialized, moreContext, nil)
+	require.ErrorContains(t, err, "cannot resume a partial result of caveat `somecaveat` against caveat `othercaveat`")
+}
+
+func TestResumePartialWithStringExtensions(t *testing.T) {
+	compiled, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"domain":  types.StringType,
+		"enabled": types.BooleanType,
+	}), "enabled && domain.lowerAscii() == 'example.com'", "somecaveat")
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{"enabled": true})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	serialized, err := result.MarshalPartial()
+	require.NoError(t, err)
+
+	resumed, err := ResumePartial(serialized, map[string]any{"domain": "Example.COM"})
+	require.NoError(t, err)
+	require.False(t, resumed.IsPartial())
+	require.True(t, resumed.Value())
+
+	resumed, err = ResumePartialWithConfig(context.Background(), compiled, serialized, map[string]any{"domain": "example.org"}, nil)
+	require.NoError(t, err)
+	require.False(t, resumed.IsPartial())
+	require.False(t, resumed.Value())
+}
+
+func TestResumePartialWithMessageValue(t *testing.T) {
+	compiled, err := CompileCaveatWithName(MustEnvForVariables(map[string]types.VariableType{
+		"rel":     relationTupleType,
+		"enabled": types.BooleanType,
+	}), "enabled && rel.subject.object_id == 'tom'", "somecaveat")
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{
+		"rel": &core.RelationTuple{
+			ResourceAndRelation: &core.ObjectAndRelation{Namespace: "document", ObjectId: "readme", Relation: "viewer"},
+			Subject:             &core.ObjectAndRelation{Namespace: "user", ObjectId: "tom", Relation: "..."},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	serialized, err := result.MarshalPartial()
+	require.NoError(t, err)
+
+	resumed, err := ResumePartial(serialized, map[string]any{"enabled": true})
+	require.NoError(t, err)
+	require.False(t, resumed.IsPartial())
+	require.True(t, resumed.Value())
+
+	resumed, err = ResumePartialWithConfig(context.Background(), compiled, serialized, map[string]any{"enabled": false}, nil)
+	require.NoError(t, err)
+	require.False(t, resumed.IsPartial())
+	require.False(t, resumed.Value())
+}
+
+func TestChainedPartialEvaluation(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a":       types.IntType,
+		"allowed": types.MustListType(types.StringType),
+		"region":  types.StringType,
+		"expires": types.TimestampType,
+		"now":     types.TimestampType,
+	}), "a > 1 && allowed.exists(r, r == region) && now < expires")
+	require.NoError(t, err)
+
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	stages := []map[string]any{
+		{"a": int64(2), "allowed": []any{"us", "eu"}},
+		{"expires": expires},
+		{"region": "eu"},
+	}
+
+	for _, now := range []time.Time{
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2035, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		all := map[string]any{"now": now}
+		for _, stage := range stages {
+			for name, value := range stage {
+				all[name] = value
+			}
+		}
+
+		full, err := EvaluateCaveat(compiled, all)
+		require.NoError(t, err)
+		require.False(t, full.IsPartial())
+
+		current := compiled
+		for _, stage := range stages {
+			result, err := EvaluateCaveat(current, stage)
+			require.NoError(t, err)
+			require.True(t, result.IsPartial())
+
+			current, err = result.PartialValue()
+			require.NoError(t, err)
+		}
+
+		// The clause resolved by the first stage is pruned from the partial caveat.
+		exprString, err := current.ExprString()
+		require.NoError(t, err)
+		require.NotContains(t, exprString, "a > 1")
+
+		result, err := EvaluateCaveat(current, map[string]any{"now": now})
+		require.NoError(t, err)
+		require.False(t, result.IsPartial())
+		require.Equal(t, full.Value(), result.Value())
+	}
+}
+
+func TestMarshalPartialOfFullResult(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+	}), "a == 1")
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{"a": int64(1)})
+	require.NoError(t, err)
+
+	_, err = result.MarshalPartial()
+	require.Error(t, err)
+}
+
+func TestPartialValueAsExpression(t *testing.T) {
+	parameterTypes := map[string]types.VariableType{
+		"a":       types.IntType,
+		"b":       types.IntType,
+		"expires": types.TimestampType,
+		"now":     types.TimestampType,
+		"user_ip": types.IPAddressType,
+	}
+	compiled, err := CompileCaveatWithName(MustEnvForVariables(parameterTypes), "a + b > 47 && now < expires && user_ip.in_cidr('10.0.0.0/8')", "somecaveat")
+	require.NoError(t, err)
+
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	result, err := EvaluateCaveat(compiled, map[string]any{
+		"a":       int64(42),
+		"expires": expires,
+		"user_ip": types.MustParseIPAddress("10.1.2.3"),
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	expr, err := result.PartialValueAsExpression()
+	require.NoError(t, err)
+
+	caveat := expr.GetCaveat()
+	require.NotNil(t, caveat)
+	require.Equal(t, "somecaveat", caveat.CaveatName)
+	require.Equal(t, map[string]any{
+		"a":       float64(42),
+		"expires": "2030-01-01T00:00:00Z",
+		"user_ip": "10.1.2.3",
+	}, caveat.Context.AsMap())
+
+	// Evaluating the named caveat with the retained context and the missing values yields the
+	// same result as a full evaluation.
+	contextValues := caveat.Context.AsMap()
+	contextValues["b"] = int64(6)
+	contextValues["now"] = "2025-01-01T00:00:00Z"
+
+	encodedTypes := MustEnvForVariables(parameterTypes).EncodedParametersTypes()
+	converted, err := ConvertContextToParameters(contextValues, encodedTypes, ErrorForUnknownParameters)
+	require.NoError(t, err)
+
+	resumed, err := EvaluateCaveat(compiled, converted)
+	require.NoError(t, err)
+	require.False(t, resumed.IsPartial())
+	require.True(t, resumed.Value())
+
+	// A fully evaluated result has no partial value.
+	_, err = resumed.PartialValueAsExpression()
+	require.Error(t, err)
+}
+
+func TestOperationCount(t *testing.T) {
+	tcs := []struct {
+		name          string
+		exprString    string
+		context       map[string]any
+		expectedCount uint64
+	}{
+		{
+			"arithmetic and comparison",
+			"a + 1 > 2",
+			map[string]any{"a": 5},
+			5,
+		},
+		{
+			"short-circuited and",
+			"a > 10 && a < 20",
+			map[string]any{"a": 5},
+			4,
+		},
+		{
+			"fully evaluated and",
+			"a > 1 && a < 20",
+			map[string]any{"a": 5},
+			7,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+				"a": types.IntType,
+			}), tc.exprString)
+			require.NoError(t, err)
+
+			result, err := EvaluateCaveat(compiled, tc.context)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCount, result.OperationCount())
+		})
+	}
+}
+
+func TestUsedVariableNames(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.BooleanType,
+		"b": types.IntType,
+		"c": types.IntType,
+		"l": types.MustListType(types.IntType),
+		"m": types.MustMapType(types.StringType),
+	}), "(a || b > c) && l.all(i, i > 0) && has(m.key)")
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name          string
+		contextValues map[string]any
+		expectedUsed  []string
+	}{
+		{
+			"short-circuited",
+			map[string]any{"a": true, "b": 1, "c": 2, "l": []int{1}, "m": map[string]any{}},
+			[]string{"a", "l", "m"},
+		},
+		{
+			"all read",
+			map[string]any{"a": false, "b": 3, "c": 2, "l": []int{1}, "m": map[string]any{}},
+			[]string{"a", "b", "c", "l", "m"},
+		},
+		{
+			"short-circuited by the first clause",
+			map[string]any{"a": false, "b": 1, "c": 2, "l": []int{1}, "m": map[string]any{}},
+			[]string{"a", "b", "c"},
+		},
+		{
+			"missing variable",
+			map[string]any{"a": true, "l": []int{1}},
+			[]string{"a", "l"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := EvaluateCaveat(compiled, tc.contextValues)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedUsed, result.UsedVariableNames())
+		})
+	}
+}
+
+func TestEvalThreeValuedLogic(t *testing.T) {
+	tcs := []struct {
+		exprString     string
+		context        map[string]any
+		expectedNull   bool
+		expectedResult bool
+	}{
+		{"a && b", map[string]any{"a": NullContextValue, "b": false}, false, false},
+		{"a && b", map[string]any{"a": NullContextValue, "b": true}, true, false},
+		{"a && b", map[string]any{"a": NullContextValue, "b": NullContextValue}, true, false},
+		{"a || b", map[string]any{"a": NullContextValue, "b": true}, false, true},
+		{"a || b", map[string]any{"a": NullContextValue, "b": false}, true, false},
+		{"a || b", map[string]any{"a": NullContextValue, "b": NullContextValue}, true, false},
+		{"!a", map[string]any{"a": NullContextValue}, true, false},
+		{"!a || b", map[string]any{"a": NullContextValue, "b": true}, false, true},
+		{"a && b", map[string]any{"a": true, "b": true}, false, true},
+		{"count > 5 || b", map[string]any{"count": NullContextValue, "b": false}, true, false},
+		{"count > 5 && b", map[string]any{"count": NullContextValue, "b": false}, false, false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("%s %v", tc.exprString, tc.context), func(t *testing.T) {
+			compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+				"a":     types.BooleanType,
+				"b":     types.BooleanType,
+				"count": types.IntType,
+			}), tc.exprString)
+			require.NoError(t, err)
+
+			result, err := EvaluateCaveatWithConfig(context.Background(), compiled, tc.context, &EvaluationConfig{
+				ThreeValuedLogic: true,
+			})
+			require.NoError(t, err)
+			require.False(t, result.IsPartial())
+			require.Equal(t, tc.expectedNull, result.IsNull())
+			require.Equal(t, tc.expectedResult, result.Value())
+		})
+	}
+}
+
+func TestEvaluateCaveatExpression(t *testing.T) {
+	parameterTypes := map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.IntType,
+	}
+
+	result, err := EvaluateCaveatExpression("a + b > 47", parameterTypes, map[string]any{"a": 42, "b": 6}, nil)
+	require.NoError(t, err)
+	require.True(t, result.Value())
+
+	result, err = EvaluateCaveatExpression("a + b > 47", parameterTypes, map[string]any{"a": 42}, nil)
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	// Compilation errors are reported with their position.
+	_, err = EvaluateCaveatExpression("a +", parameterTypes, map[string]any{}, nil)
+	var compilationErr CompilationErrors
+	require.True(t, errors.As(err, &compilationErr))
+	require.Equal(t, 0, compilationErr.LineNumber())
+
+	// Evaluation errors are distinct from compilation errors.
+	_, err = EvaluateCaveatExpression("a / b == 1", parameterTypes, map[string]any{"a": 1, "b": 0}, nil)
+	require.False(t, errors.As(err, &CompilationErrors{}))
+	var evalErr CaveatEvaluationError
+	require.True(t, errors.As(err, &evalErr))
+	require.Equal(t, EvaluationErrorDivisionByZero, evalErr.Code())
+}
+
+func TestEvalCoercesTimestampsAndDurations(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"expires": types.TimestampType,
+		"ttl":     types.DurationType,
+	}), "expires > timestamp('2022-01-01T00:00:00Z') && ttl < duration('2h')")
+	require.NoError(t, err)
+
+	for _, contextValues := range []map[string]any{
+		{"expires": "2023-01-01T00:00:00Z", "ttl": "1h30m"},
+		{"expires": "2023-01-01T00:00:00.5Z", "ttl": 5400.0},
+		{"expires": time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), "ttl": 90 * time.Minute},
+	} {
+		result, err := EvaluateCaveat(compiled, contextValues)
+		require.NoError(t, err)
+		require.True(t, result.Value())
+	}
+
+	_, err = EvaluateCaveat(compiled, map[string]any{"expires": "2023-01-01", "ttl": "1h"})
+	var conversionErr ParameterConversionErr
+	require.ErrorAs(t, err, &conversionErr)
+	require.Equal(t, "expires", conversionErr.ParameterName())
+}
+
+func TestEvaluateCaveatWithContextLayers(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"limit":  types.IntType,
+		"used":   types.IntType,
+		"config": types.MustMapType(types.IntType),
+	}), "used < limit && config.minimum <= used")
+	require.NoError(t, err)
+
+	staticDefaults := map[string]any{"limit": 10, "config": map[string]any{"minimum": 1, "other": 2}}
+	tenantDefaults := map[string]any{"limit": 5}
+	requestValues := map[string]any{"used": 7, "config": map[string]any{"minimum": 3}}
+
+	result, err := EvaluateCaveatWithContextLayers(context.Background(), compiled, []map[string]any{staticDefaults, tenantDefaults, requestValues}, nil)
+	require.NoError(t, err)
+	require.False(t, result.Value())
+
+	// Later layers override earlier ones, with maps replaced rather than merged.
+	require.Equal(t, map[string]any{
+		"limit":  5,
+		"used":   7,
+		"config": map[string]any{"minimum": 3},
+	}, result.ContextValues())
+
+	// The layers are not modified.
+	require.Equal(t, map[string]any{"limit": 5}, tenantDefaults)
+
+	result, err = EvaluateCaveatWithContextLayers(context.Background(), compiled, []map[string]any{tenantDefaults, staticDefaults, requestValues}, nil)
+	require.NoError(t, err)
+	require.True(t, result.Value())
+
+	// A parameter missing from all layers results in a partial result.
+	result, err = EvaluateCaveatWithContextLayers(context.Background(), compiled, []map[string]any{staticDefaults, tenantDefaults}, nil)
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	missingVarNames, err := result.MissingVarNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"used"}, missingVarNames)
+}
+
+func TestEvalDefaultValues(t *testing.T) {
+	env := NewEnvironment()
+	require.NoError(t, env.AddVariable("used", types.IntType))
+	require.NoError(t, env.AddVariableWithDefault("threshold", types.IntType, 0))
+	require.NoError(t, env.AddVariableWithDefault("enabled", types.BooleanType, false))
+	require.Error(t, env.AddVariableWithDefault("invalid", types.IntType, "zero"))
+	require.Error(t, env.AddVariableWithDefault("used", types.IntType, 1))
+
+	compiled, err := compileCaveat(env, "enabled || used > threshold")
+	require.NoError(t, err)
+
+	// Parameters with defaults do not result in a partial result when omitted.
+	result, err := EvaluateCaveat(compiled, map[string]any{"used": int64(1)})
+	require.NoError(t, err)
+	require.False(t, result.IsPartial())
+	require.True(t, result.Value())
+	require.Equal(t, map[string]any{"used": int64(1), "threshold": int64(0), "enabled": false}, result.ContextValues())
+
+	// Values given override the defaults.
+	result, err = EvaluateCaveat(compiled, map[string]any{"used": int64(1), "threshold": int64(5)})
+	require.NoError(t, err)
+	require.False(t, result.Value())
+
+	// Parameters without defaults still result in a partial result when omitted.
+	result, err = EvaluateCaveat(compiled, map[string]any{"threshold": int64(5)})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	missingVarNames, err := result.MissingVarNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"used"}, missingVarNames)
+
+	// Defaults also apply to batch evaluation.
+	results, err := EvaluateEachCaveat(context.Background(), []*CompiledCaveat{compiled}, map[string]any{"used": int64(1)}, nil)
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	require.True(t, results[0].Result.Value())
+}
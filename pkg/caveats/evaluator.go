@@ -0,0 +1,68 @@
+package caveats
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+)
+
+// Evaluator evaluates a compiled caveat repeatedly, reusing the CEL programs built for its
+// evaluation rather than building a program on each call to EvaluateCaveatWithConfig. A program
+// is built for each distinct set of program options, which depend on the MaxCost of the
+// evaluation configuration and on whether the evaluation is interruptible.
+//
+// An Evaluator is safe for concurrent use.
+type Evaluator struct {
+	caveat   *CompiledCaveat
+	programs *programCache
+}
+
+// NewEvaluator returns an evaluator for the compiled caveat.
+func NewEvaluator(caveat *CompiledCaveat) *Evaluator {
+	return &Evaluator{
+		caveat:   caveat,
+		programs: &programCache{programs: map[programOptions]cel.Program{}},
+	}
+}
+
+// Evaluate evaluates the caveat with the specified values, and returns the result or an error,
+// as EvaluateCaveatWithConfig.
+func (e *Evaluator) Evaluate(ctx context.Context, contextValues map[string]any, config *EvaluationConfig) (*CaveatResult, error) {
+	contextValues, err := coerceContextValues(e.caveat, contextValues)
+	if err != nil {
+		return nil, err
+	}
+
+	activation, err := newEvaluationActivation(contextValues, config)
+	if err != nil {
+		return nil, translateEvaluationError(e.caveat.name, config, err)
+	}
+
+	return evaluateCaveat(ctx, e.caveat, activation, config, e.programs)
+}
+
+// programCache holds the programs built for a caveat, by their options.
+type programCache struct {
+	lock     sync.RWMutex
+	programs map[programOptions]cel.Program
+}
+
+func (pc *programCache) program(caveat *CompiledCaveat, options programOptions) (cel.Program, error) {
+	pc.lock.RLock()
+	prg, ok := pc.programs[options]
+	pc.lock.RUnlock()
+	if ok {
+		return prg, nil
+	}
+
+	prg, err := newProgram(caveat, options)
+	if err != nil {
+		return nil, err
+	}
+
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+	pc.programs[options] = prg
+	return prg, nil
+}
@@ -0,0 +1,76 @@
+package caveats
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+func TestEvaluator(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"l": types.MustListType(types.IntType),
+		"a": types.IntType,
+	}), "l.all(i, i < a)")
+	require.NoError(t, err)
+
+	evaluator := NewEvaluator(compiled)
+	contextValues := map[string]any{"l": []int{1, 2, 3}, "a": 10}
+
+	for i := 0; i < 2; i++ {
+		result, err := evaluator.Evaluate(context.Background(), contextValues, nil)
+		require.NoError(t, err)
+		require.True(t, result.Value())
+	}
+	require.Len(t, evaluator.programs.programs, 1)
+
+	result, err := evaluator.Evaluate(context.Background(), map[string]any{"l": []int{1, 2, 3}}, nil)
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	// A different cost limit requires a distinct program.
+	_, err = evaluator.Evaluate(context.Background(), contextValues, &EvaluationConfig{MaxCost: 1})
+	require.Error(t, err)
+	require.Len(t, evaluator.programs.programs, 2)
+
+	result, err = evaluator.Evaluate(context.Background(), contextValues, &EvaluationConfig{MaxCost: 1000})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+	require.Len(t, evaluator.programs.programs, 3)
+}
+
+func benchmarkCaveat(b *testing.B) *CompiledCaveat {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"l": types.MustListType(types.IntType),
+		"a": types.IntType,
+		"b": types.StringType,
+	}), "l.all(i, i < a) && b.startsWith('some') && a > 5")
+	require.NoError(b, err)
+	return compiled
+}
+
+var benchmarkContext = map[string]any{"l": []int{1, 2, 3}, "a": 10, "b": "somevalue"}
+
+func BenchmarkEvaluateCaveat(b *testing.B) {
+	compiled := benchmarkCaveat(b)
+	config := &EvaluationConfig{MaxCost: 1000}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := EvaluateCaveatWithConfig(context.Background(), compiled, benchmarkContext, config)
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkEvaluator(b *testing.B) {
+	evaluator := NewEvaluator(benchmarkCaveat(b))
+	config := &EvaluationConfig{MaxCost: 1000}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := evaluator.Evaluate(context.Background(), benchmarkContext, config)
+		require.NoError(b, err)
+	}
+}
@@ -0,0 +1,152 @@
+package caveats
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/operators"
+	celtypes "github.com/google/cel-go/common/types"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// SubexpressionValue is the value of a subexpression of an evaluated caveat.
+type SubexpressionValue int
+
+const (
+	// SubexpressionNotEvaluated indicates that the subexpression was skipped, as the result of
+	// the expression was decided without it.
+	SubexpressionNotEvaluated SubexpressionValue = iota
+
+	// SubexpressionTrue indicates that the subexpression evaluated to true.
+	SubexpressionTrue
+
+	// SubexpressionFalse indicates that the subexpression evaluated to false.
+	SubexpressionFalse
+
+	// SubexpressionUnknown indicates that the subexpression could not be decided, as it depends
+	// on a missing (or, under three-valued logic, null) parameter.
+	SubexpressionUnknown
+
+	// SubexpressionError indicates that the subexpression evaluated to an error, which was
+	// absorbed by a boolean operator, e.g. `false && 1 / 0 == 1`.
+	SubexpressionError
+)
+
+func (sv SubexpressionValue) String() string {
+	switch sv {
+	case SubexpressionNotEvaluated:
+		return "not evaluated"
+	case SubexpressionTrue:
+		return "true"
+	case SubexpressionFalse:
+		return "false"
+	case SubexpressionUnknown:
+		return "unknown"
+	case SubexpressionError:
+		return "error"
+	default:
+		return fmt.Sprintf("SubexpressionValue(%d)", int(sv))
+	}
+}
+
+// SubexpressionResult is the result of a clause of an evaluated caveat.
+type SubexpressionResult struct {
+	// ExpressionString is the human-readable form of the subexpression.
+	ExpressionString string
+
+	// Value is the value to which the subexpression evaluated.
+	Value SubexpressionValue
+
+	// Depth is the depth of the subexpression in the tree of boolean operators, starting at
+	// zero for the expression as a whole.
+	Depth int
+}
+
+// Explain returns the result of each clause of the evaluated expression: the expression as a
+// whole, followed by each operand of its `&&`, `||` and `!` operators, recursively and in the
+// order in which they appear. Operands of a chain of the same operator, e.g. `a && b && c`, are
+// reported as operands of a single clause.
+//
+// The values are those recorded by the evaluation, which is performed with cel.OptTrackState.
+func (cr CaveatResult) Explain() ([]SubexpressionResult, error) {
+	if cr.details == nil {
+		return nil, fmt.Errorf("evaluation state is unavailable")
+	}
+
+	var results []SubexpressionResult
+	err := cr.explain(cr.parentCaveat.ast.Expr(), 0, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (cr CaveatResult) explain(expr *exprpb.Expr, depth int, results *[]SubexpressionResult) error {
+	exprString, err := cel.AstToString(cel.ParsedExprToAst(&exprpb.ParsedExpr{
+		Expr:       expr,
+		SourceInfo: cr.parentCaveat.ast.SourceInfo(),
+	}))
+	if err != nil {
+		return err
+	}
+
+	*results = append(*results, SubexpressionResult{
+		ExpressionString: exprString,
+		Value:            cr.subexpressionValue(expr.Id),
+		Depth:            depth,
+	})
+
+	call := expr.GetCallExpr()
+	if call == nil {
+		return nil
+	}
+
+	switch call.Function {
+	case operators.LogicalAnd, operators.LogicalOr:
+		for _, operand := range chainOperands(call.Function, expr) {
+			if err := cr.explain(operand, depth+1, results); err != nil {
+				return err
+			}
+		}
+
+	case operators.LogicalNot:
+		return cr.explain(call.Args[0], depth+1, results)
+	}
+
+	return nil
+}
+
+func (cr CaveatResult) subexpressionValue(id int64) SubexpressionValue {
+	val, ok := cr.details.State().Value(id)
+	if !ok || val == nil {
+		return SubexpressionNotEvaluated
+	}
+
+	switch {
+	case celtypes.IsUnknown(val):
+		return SubexpressionUnknown
+	case celtypes.IsError(val):
+		return SubexpressionError
+	case val == celtypes.True:
+		return SubexpressionTrue
+	case val == celtypes.False:
+		return SubexpressionFalse
+	default:
+		return SubexpressionError
+	}
+}
+
+// chainOperands returns the operands of the chain of calls to the given operator rooted at the
+// expression, e.g. `a`, `b` and `c` for `a && b && c`.
+func chainOperands(function string, expr *exprpb.Expr) []*exprpb.Expr {
+	call := expr.GetCallExpr()
+	if call == nil || call.Function != function {
+		return []*exprpb.Expr{expr}
+	}
+
+	var operands []*exprpb.Expr
+	for _, arg := range call.Args {
+		operands = append(operands, chainOperands(function, arg)...)
+	}
+	return operands
+}
@@ -0,0 +1,76 @@
+package caveats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+func TestExplain(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.IntType,
+		"c": types.IntType,
+		"d": types.BooleanType,
+	}), "a > 1 && (b == 2 || c == 3) && !d")
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name     string
+		context  map[string]any
+		expected []SubexpressionResult
+	}{
+		{
+			"all true",
+			map[string]any{"a": 2, "b": 2, "c": 3, "d": false},
+			[]SubexpressionResult{
+				{"a > 1 && (b == 2 || c == 3) && !d", SubexpressionTrue, 0},
+				{"a > 1", SubexpressionTrue, 1},
+				{"b == 2 || c == 3", SubexpressionTrue, 1},
+				{"b == 2", SubexpressionTrue, 2},
+				{"c == 3", SubexpressionNotEvaluated, 2},
+				{"!d", SubexpressionTrue, 1},
+				{"d", SubexpressionFalse, 2},
+			},
+		},
+		{
+			"failing clause",
+			map[string]any{"a": 2, "b": 1, "c": 1, "d": false},
+			[]SubexpressionResult{
+				{"a > 1 && (b == 2 || c == 3) && !d", SubexpressionFalse, 0},
+				{"a > 1", SubexpressionTrue, 1},
+				{"b == 2 || c == 3", SubexpressionFalse, 1},
+				{"b == 2", SubexpressionFalse, 2},
+				{"c == 3", SubexpressionFalse, 2},
+				{"!d", SubexpressionNotEvaluated, 1},
+				{"d", SubexpressionNotEvaluated, 2},
+			},
+		},
+		{
+			"partial",
+			map[string]any{"a": 2, "b": 1, "d": false},
+			[]SubexpressionResult{
+				{"a > 1 && (b == 2 || c == 3) && !d", SubexpressionUnknown, 0},
+				{"a > 1", SubexpressionTrue, 1},
+				{"b == 2 || c == 3", SubexpressionUnknown, 1},
+				{"b == 2", SubexpressionFalse, 2},
+				{"c == 3", SubexpressionUnknown, 2},
+				{"!d", SubexpressionTrue, 1},
+				{"d", SubexpressionFalse, 2},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := EvaluateCaveat(compiled, tc.context)
+			require.NoError(t, err)
+
+			explained, err := result.Explain()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, explained)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package caveats
+
+import (
+	"github.com/google/cel-go/interpreter"
+)
+
+// interruptedVarName is the hidden variable checked by CEL after each iteration of an
+// interruptable comprehension.
+const interruptedVarName = "#interrupted"
+
+// interruptibleActivation wraps the activation used for evaluation, interrupting evaluation once
+// the limit on the iterations performed across all comprehensions is exceeded, or once the done
+// channel of the evaluation's context is closed.
+//
+// CEL checks the interrupted variable after each iteration of a comprehension when the program is
+// built with cel.InterruptCheckFrequency, which makes resolution of the variable a reliable
+// iteration counter. Note that cel.Program.ContextEval is not used for cancellation, as its
+// activation hides the unknown attribute patterns required for partial evaluation.
+type interruptibleActivation struct {
+	interpreter.PartialActivation
+
+	// maxIterations is the maximum number of iterations, or zero for no limit.
+	maxIterations uint64
+	iterations    uint64
+
+	// done is the done channel of the evaluation's context, if any.
+	done <-chan struct{}
+}
+
+// ResolveName implements the interpreter.Activation interface method.
+func (a *interruptibleActivation) ResolveName(name string) (any, bool) {
+	if name != interruptedVarName {
+		return a.PartialActivation.ResolveName(name)
+	}
+
+	a.iterations++
+	if a.exceeded() {
+		return true, true
+	}
+
+	select {
+	case <-a.done:
+		return true, true
+	default:
+		return false, true
+	}
+}
+
+// exceeded returns whether the iteration limit, if any, has been exceeded.
+func (a *interruptibleActivation) exceeded() bool {
+	return a.maxIterations > 0 && a.iterations > a.maxIterations
+}
@@ -0,0 +1,90 @@
+package caveats
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"golang.org/x/exp/maps"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Names of the macros which can be enabled or disabled in an Environment. All macros are enabled
+// by default.
+const (
+	// HasMacro is the `has(m.f)` macro, testing for the presence of a field.
+	HasMacro = "has"
+
+	// AllMacro is the `range.all(var, predicate)` macro.
+	AllMacro = "all"
+
+	// ExistsMacro is the `range.exists(var, predicate)` macro.
+	ExistsMacro = "exists"
+
+	// ExistsOneMacro is the `range.exists_one(var, predicate)` macro.
+	ExistsOneMacro = "exists_one"
+
+	// MapMacro is the `range.map(var, function)` and `range.map(var, predicate, function)` macro.
+	MapMacro = "map"
+
+	// FilterMacro is the `range.filter(var, predicate)` macro.
+	FilterMacro = "filter"
+)
+
+var knownMacros = map[string]struct{}{
+	HasMacro:       {},
+	AllMacro:       {},
+	ExistsMacro:    {},
+	ExistsOneMacro: {},
+	MapMacro:       {},
+	FilterMacro:    {},
+}
+
+// SetEnabledMacros sets the macros which can be used by caveats compiled in the environment.
+// Caveats using any other macro fail to compile, with an error positioned at the macro call.
+func (e *Environment) SetEnabledMacros(macroNames ...string) error {
+	enabled := make(map[string]struct{}, len(macroNames))
+	for _, name := range macroNames {
+		if _, ok := knownMacros[name]; !ok {
+			return fmt.Errorf("unknown macro `%s`", name)
+		}
+		enabled[name] = struct{}{}
+	}
+
+	disabled := maps.Clone(knownMacros)
+	for name := range enabled {
+		delete(disabled, name)
+	}
+
+	e.disabledMacros = disabled
+	return nil
+}
+
+// disabledMacroOverrides returns macros replacing each disabled standard macro with one which
+// fails to expand. The parser reports the expansion failure at the position of the macro call.
+func (e *Environment) disabledMacroOverrides() []cel.Macro {
+	if len(e.disabledMacros) == 0 {
+		return nil
+	}
+
+	overrides := make([]cel.Macro, 0, len(e.disabledMacros))
+	for _, macro := range cel.StandardMacros {
+		if _, ok := e.disabledMacros[macro.Function()]; !ok {
+			continue
+		}
+
+		expander := disabledMacroExpander(macro.Function())
+		if macro.IsReceiverStyle() {
+			overrides = append(overrides, cel.NewReceiverMacro(macro.Function(), macro.ArgCount(), expander))
+		} else {
+			overrides = append(overrides, cel.NewGlobalMacro(macro.Function(), macro.ArgCount(), expander))
+		}
+	}
+	return overrides
+}
+
+func disabledMacroExpander(macroName string) cel.MacroExpander {
+	return func(_ cel.MacroExprHelper, _ *exprpb.Expr, _ []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+		return nil, &common.Error{Message: fmt.Sprintf("macro `%s` is disabled", macroName)}
+	}
+}
@@ -0,0 +1,56 @@
+package caveats
+
+// DefaultMaskedValue is the value used in place of a masked context parameter.
+const DefaultMaskedValue = "***"
+
+// MaskFunc returns the value to display in place of the value of the given context parameter.
+// Returning the value unchanged leaves the parameter unmasked.
+type MaskFunc func(parameterName string, value any) any
+
+// ContextMask defines how context values are masked when rendered for logging, auditing or
+// debugging output. Masking never affects the values used for evaluation of a caveat.
+type ContextMask struct {
+	maskFunc MaskFunc
+}
+
+// NewContextMask returns a mask which replaces the values of the given top-level parameters
+// with DefaultMaskedValue.
+func NewContextMask(parameterNames ...string) *ContextMask {
+	masked := make(map[string]struct{}, len(parameterNames))
+	for _, name := range parameterNames {
+		masked[name] = struct{}{}
+	}
+
+	return NewContextMaskWithFunc(func(parameterName string, value any) any {
+		if _, ok := masked[parameterName]; ok {
+			return DefaultMaskedValue
+		}
+		return value
+	})
+}
+
+// NewContextMaskWithFunc returns a mask which applies the given function to the value of each
+// top-level parameter.
+func NewContextMaskWithFunc(maskFunc MaskFunc) *ContextMask {
+	return &ContextMask{maskFunc}
+}
+
+// Apply returns a copy of the context values with the mask applied. A nil mask returns the
+// context values unchanged.
+func (m *ContextMask) Apply(contextValues map[string]any) map[string]any {
+	if m == nil || contextValues == nil {
+		return contextValues
+	}
+
+	masked := make(map[string]any, len(contextValues))
+	for name, value := range contextValues {
+		masked[name] = m.maskFunc(name, value)
+	}
+	return masked
+}
+
+// MaskedContextValues returns the context values used when computing this result, with the
+// given mask applied. The returned map is suitable for logs and audit output.
+func (cr CaveatResult) MaskedContextValues(mask *ContextMask) map[string]any {
+	return mask.Apply(cr.contextValues)
+}
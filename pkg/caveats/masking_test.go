@@ -0,0 +1,59 @@
+package caveats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+func TestMaskedContextValues(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"ssn":      types.StringType,
+		"username": types.StringType,
+	}), "ssn == '123-45-6789' && username == 'sarah'")
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{
+		"ssn":      "123-45-6789",
+		"username": "sarah",
+	})
+	require.NoError(t, err)
+
+	// The real values are used for evaluation.
+	require.True(t, result.Value())
+
+	require.Equal(t, map[string]any{
+		"ssn":      DefaultMaskedValue,
+		"username": "sarah",
+	}, result.MaskedContextValues(NewContextMask("ssn")))
+
+	// Masking returns a copy and does not change the context values of the result.
+	require.Equal(t, "123-45-6789", result.ContextValues()["ssn"])
+}
+
+func TestMaskedContextValuesWithFunc(t *testing.T) {
+	mask := NewContextMaskWithFunc(func(parameterName string, value any) any {
+		str, ok := value.(string)
+		if !ok || parameterName != "ssn" {
+			return value
+		}
+		return strings.Repeat("*", len(str)-4) + str[len(str)-4:]
+	})
+
+	require.Equal(t, map[string]any{
+		"ssn":   "*******6789",
+		"count": 42,
+	}, mask.Apply(map[string]any{
+		"ssn":   "123-45-6789",
+		"count": 42,
+	}))
+}
+
+func TestNilContextMask(t *testing.T) {
+	var mask *ContextMask
+	values := map[string]any{"ssn": "123-45-6789"}
+	require.Equal(t, values, mask.Apply(values))
+}
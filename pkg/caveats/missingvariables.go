@@ -0,0 +1,159 @@
+package caveats
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// MissingVariableBehavior defines the result of a caveat depending on parameters missing from
+// the context values.
+type MissingVariableBehavior int
+
+const (
+	// PartialEvaluation returns a partial result for a caveat depending on missing parameters,
+	// which can be resumed once the parameters are supplied.
+	PartialEvaluation MissingVariableBehavior = iota
+
+	// FailClosed evaluates each clause of the caveat depending on a missing parameter as false.
+	FailClosed
+
+	// FailOpen evaluates each clause of the caveat depending on a missing parameter as true.
+	FailOpen
+)
+
+// evaluateWithoutMissingVariables evaluates the residual of a partial evaluation of the caveat,
+// with each clause referencing a missing variable replaced by the value defined by the behavior.
+//
+// A clause is an operand of the `&&` and `||` operators of the expression, or the expression as a
+// whole if it has neither: `!(a == 1)` is a single clause, and so is false under FailClosed
+// should `a` be missing.
+func evaluateWithoutMissingVariables(
+	caveat *CompiledCaveat,
+	details *cel.EvalDetails,
+	activation interpreter.PartialActivation,
+	missing map[int64]string,
+	behavior MissingVariableBehavior,
+) (ref.Val, error) {
+	missingNames := make(map[string]struct{}, len(missing))
+	for _, name := range missing {
+		missingNames[name] = struct{}{}
+	}
+
+	residual := interpreter.PruneAst(caveat.ast.Expr(), details.State())
+	replaced := replaceMissingClauses(residual, missingNames, behavior == FailOpen)
+
+	prg, err := caveat.celEnv.Program(
+		cel.ParsedExprToAst(&exprpb.ParsedExpr{Expr: replaced}),
+		cel.EvalOptions(cel.OptPartialEval),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	val, _, err := prg.Eval(activation)
+	return val, err
+}
+
+// replaceMissingClauses returns the expression with each clause referencing any of the missing
+// variables replaced by the given value.
+func replaceMissingClauses(expr *exprpb.Expr, missingNames map[string]struct{}, value bool) *exprpb.Expr {
+	if call := expr.GetCallExpr(); call != nil && (call.Function == operators.LogicalAnd || call.Function == operators.LogicalOr) {
+		args := make([]*exprpb.Expr, 0, len(call.Args))
+		for _, arg := range call.Args {
+			args = append(args, replaceMissingClauses(arg, missingNames, value))
+		}
+
+		return &exprpb.Expr{
+			Id: expr.Id,
+			ExprKind: &exprpb.Expr_CallExpr{
+				CallExpr: &exprpb.Expr_Call{
+					Function: call.Function,
+					Args:     args,
+				},
+			},
+		}
+	}
+
+	identifiers := map[int64]string{}
+	variableIdentifiers(expr, map[string]int{}, identifiers)
+	for _, name := range identifiers {
+		if _, ok := missingNames[name]; ok {
+			return &exprpb.Expr{
+				Id: expr.Id,
+				ExprKind: &exprpb.Expr_ConstExpr{
+					ConstExpr: &exprpb.Constant{
+						ConstantKind: &exprpb.Constant_BoolValue{BoolValue: value},
+					},
+				},
+			}
+		}
+	}
+
+	return expr
+}
+
+// MissingVariableExpressions returns, for each missing variable on which the partial result
+// depends, the text of the clauses of the caveat referencing it, such that clients can be told
+// which checks require the variable. Clauses are as for FailClosed: the operands of the `&&` and
+// `||` operators of the expression. Should a variable be referenced by several clauses, their
+// texts are joined with `; `, in the order in which they appear in the expression.
+func (cr CaveatResult) MissingVariableExpressions() (map[string]string, error) {
+	if !cr.isPartial {
+		return nil, fmt.Errorf("result is fully evaluated")
+	}
+
+	missingNames := make(map[string]struct{}, len(cr.missingVarNames))
+	for _, name := range cr.missingVarNames {
+		missingNames[name] = struct{}{}
+	}
+
+	clauseTexts := make(map[string][]string, len(cr.missingVarNames))
+	for _, clause := range logicalClauses(cr.parentCaveat.ast.Expr(), nil) {
+		identifiers := map[int64]string{}
+		variableIdentifiers(clause, map[string]int{}, identifiers)
+
+		referenced := map[string]struct{}{}
+		for _, name := range identifiers {
+			if _, ok := missingNames[name]; ok {
+				referenced[name] = struct{}{}
+			}
+		}
+		if len(referenced) == 0 {
+			continue
+		}
+
+		text, err := cel.AstToString(cel.ParsedExprToAst(&exprpb.ParsedExpr{Expr: clause, SourceInfo: cr.parentCaveat.ast.SourceInfo()}))
+		if err != nil {
+			return nil, err
+		}
+
+		for name := range referenced {
+			clauseTexts[name] = append(clauseTexts[name], text)
+		}
+	}
+
+	expressions := make(map[string]string, len(clauseTexts))
+	for name, texts := range clauseTexts {
+		expressions[name] = strings.Join(texts, "; ")
+	}
+	return expressions, nil
+}
+
+// logicalClauses appends the clauses of the expression to those given, in order: the operands of
+// its `&&` and `||` operators, or the expression itself if it has neither.
+func logicalClauses(expr *exprpb.Expr, clauses []*exprpb.Expr) []*exprpb.Expr {
+	if call := expr.GetCallExpr(); call != nil && (call.Function == operators.LogicalAnd || call.Function == operators.LogicalOr) {
+		for _, arg := range call.Args {
+			clauses = logicalClauses(arg, clauses)
+		}
+		return clauses
+	}
+
+	return append(clauses, expr)
+}
@@ -0,0 +1,84 @@
+package caveats
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+func TestMissingVariableBehavior(t *testing.T) {
+	tcs := []struct {
+		exprString     string
+		context        map[string]any
+		behavior       MissingVariableBehavior
+		expectedResult bool
+	}{
+		{"a == 1 || b == 2", map[string]any{"b": 3}, FailClosed, false},
+		{"a == 1 || b == 2", map[string]any{"b": 2}, FailClosed, true},
+		{"a == 1 || b == 2", map[string]any{"b": 3}, FailOpen, true},
+		{"a == 1 && b == 2", map[string]any{"b": 2}, FailClosed, false},
+		{"a == 1 && b == 2", map[string]any{"b": 2}, FailOpen, true},
+		{"a == 1 && b == 2", map[string]any{"b": 3}, FailOpen, false},
+		{"!(a == 1) && b == 2", map[string]any{"b": 2}, FailClosed, false},
+		{"!(a == 1) && b == 2", map[string]any{"b": 2}, FailOpen, true},
+		{"a + b > 10 || b == 2", map[string]any{"b": 2}, FailClosed, true},
+		{"a + b > 10 || b == 3", map[string]any{"b": 2}, FailClosed, false},
+		{"a == 1 || b == 2", map[string]any{}, FailOpen, true},
+		{"a == 1 || b == 2", map[string]any{}, FailClosed, false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("%s %v %d", tc.exprString, tc.context, tc.behavior), func(t *testing.T) {
+			compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+				"a": types.IntType,
+				"b": types.IntType,
+			}), tc.exprString)
+			require.NoError(t, err)
+
+			result, err := EvaluateCaveatWithConfig(context.Background(), compiled, tc.context, &EvaluationConfig{
+				MissingVariableBehavior: tc.behavior,
+			})
+			require.NoError(t, err)
+			require.False(t, result.IsPartial())
+			require.Equal(t, tc.expectedResult, result.Value())
+
+			// Partial evaluation remains the default.
+			result, err = EvaluateCaveat(compiled, tc.context)
+			require.NoError(t, err)
+			if !result.IsPartial() {
+				require.Equal(t, tc.expectedResult, result.Value())
+			}
+		})
+	}
+}
+
+func TestMissingVariableExpressions(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a":       types.IntType,
+		"b":       types.IntType,
+		"request": types.MustMapType(types.StringType),
+	}), "a == 1 && (request.ip == '127.0.0.1' || b > 2) && request.region != 'eu'")
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{"a": int64(1)})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	expressions, err := result.MissingVariableExpressions()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"b":       "b > 2",
+		"request": `request.ip == "127.0.0.1"; request.region != "eu"`,
+	}, expressions)
+
+	result, err = EvaluateCaveat(compiled, map[string]any{"a": int64(2)})
+	require.NoError(t, err)
+	require.False(t, result.IsPartial())
+
+	_, err = result.MissingVariableExpressions()
+	require.Error(t, err)
+}
@@ -0,0 +1,165 @@
+package caveats
+
+import (
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+	celtypes "github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// foldableFunctions are the functions whose calls are folded into constants by
+// NormalizedExpressionString when all their arguments are constants. They are all pure.
+var foldableFunctions = map[string]struct{}{
+	operators.Add:               {},
+	operators.Subtract:          {},
+	operators.Multiply:          {},
+	operators.Divide:            {},
+	operators.Modulo:            {},
+	operators.Negate:            {},
+	operators.Equals:            {},
+	operators.NotEquals:         {},
+	operators.Less:              {},
+	operators.LessEquals:        {},
+	operators.Greater:           {},
+	operators.GreaterEquals:     {},
+	operators.LogicalAnd:        {},
+	operators.LogicalOr:         {},
+	operators.LogicalNot:        {},
+	operators.Conditional:       {},
+	overloads.Size:              {},
+	overloads.TypeConvertInt:    {},
+	overloads.TypeConvertUint:   {},
+	overloads.TypeConvertDouble: {},
+	overloads.TypeConvertString: {},
+}
+
+// NormalizedExpressionString returns the string form of the caveat with its constant
+// sub-expressions folded, e.g. `1 + 2 > x` as `3 > x`, for display. The string is the same for
+// expressions differing only in formatting or in the way their constants are written. Calls of
+// operators and standard conversion functions whose arguments are all constants are folded, unless
+// their evaluation fails, e.g. `1 / 0`, in which case they are kept as written. Calls of macros,
+// e.g. `items.all(i, i > 1 + 1)`, are displayed as written, without folding their arguments.
+//
+// The normalized expression is equivalent to the caveat, but is meant for display only: the
+// caveat itself is left unchanged.
+func (cc CompiledCaveat) NormalizedExpressionString() (string, error) {
+	folded := proto.Clone(cc.ast.Expr()).(*exprpb.Expr)
+	if err := cc.foldConstants(folded); err != nil {
+		return "", err
+	}
+
+	return cel.AstToString(cel.ParsedExprToAst(&exprpb.ParsedExpr{Expr: folded, SourceInfo: cc.ast.SourceInfo()}))
+}
+
+// foldConstants replaces, in place, each call of a foldable function in the expression whose
+// arguments are all constants, once folded, by the constant it evaluates to.
+func (cc CompiledCaveat) foldConstants(expr *exprpb.Expr) error {
+	if expr == nil {
+		return nil
+	}
+
+	switch t := expr.ExprKind.(type) {
+	case *exprpb.Expr_ConstExpr, *exprpb.Expr_IdentExpr:
+		return nil
+
+	case *exprpb.Expr_SelectExpr:
+		return cc.foldConstants(t.SelectExpr.Operand)
+
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range t.ListExpr.Elements {
+			if err := cc.foldConstants(elem); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range t.StructExpr.Entries {
+			if err := cc.foldConstants(entry.GetMapKey()); err != nil {
+				return err
+			}
+			if err := cc.foldConstants(entry.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *exprpb.Expr_ComprehensionExpr:
+		comprehension := t.ComprehensionExpr
+		for _, child := range []*exprpb.Expr{
+			comprehension.IterRange,
+			comprehension.AccuInit,
+			comprehension.LoopCondition,
+			comprehension.LoopStep,
+			comprehension.Result,
+		} {
+			if err := cc.foldConstants(child); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *exprpb.Expr_CallExpr:
+		call := t.CallExpr
+		if err := cc.foldConstants(call.Target); err != nil {
+			return err
+		}
+
+		allConstant := call.Target == nil
+		for _, arg := range call.Args {
+			if err := cc.foldConstants(arg); err != nil {
+				return err
+			}
+			allConstant = allConstant && arg.GetConstExpr() != nil
+		}
+
+		if _, ok := foldableFunctions[call.Function]; !ok || !allConstant {
+			return nil
+		}
+
+		prg, err := cc.celEnv.Program(cel.ParsedExprToAst(&exprpb.ParsedExpr{Expr: expr}))
+		if err != nil {
+			return err
+		}
+
+		val, _, err := prg.Eval(map[string]any{})
+		if err != nil {
+			// Calls failing to evaluate are kept as written, to fail when evaluating the caveat.
+			return nil
+		}
+
+		if constant, ok := constantOf(val); ok {
+			expr.ExprKind = &exprpb.Expr_ConstExpr{ConstExpr: constant}
+		}
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+// constantOf returns the constant expression of the value, if it is of a primitive type.
+func constantOf(val ref.Val) (*exprpb.Constant, bool) {
+	switch v := val.(type) {
+	case celtypes.Bool:
+		return &exprpb.Constant{ConstantKind: &exprpb.Constant_BoolValue{BoolValue: bool(v)}}, true
+	case celtypes.Int:
+		return &exprpb.Constant{ConstantKind: &exprpb.Constant_Int64Value{Int64Value: int64(v)}}, true
+	case celtypes.Uint:
+		return &exprpb.Constant{ConstantKind: &exprpb.Constant_Uint64Value{Uint64Value: uint64(v)}}, true
+	case celtypes.Double:
+		return &exprpb.Constant{ConstantKind: &exprpb.Constant_DoubleValue{DoubleValue: float64(v)}}, true
+	case celtypes.String:
+		return &exprpb.Constant{ConstantKind: &exprpb.Constant_StringValue{StringValue: string(v)}}, true
+	case celtypes.Bytes:
+		return &exprpb.Constant{ConstantKind: &exprpb.Constant_BytesValue{BytesValue: []byte(v)}}, true
+	case celtypes.Null:
+		return &exprpb.Constant{ConstantKind: &exprpb.Constant_NullValue{NullValue: structpb.NullValue_NULL_VALUE}}, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,54 @@
+package caveats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+func TestNormalizedExpressionString(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"x":     types.IntType,
+		"name":  types.StringType,
+		"items": types.MustListType(types.IntType),
+	})
+
+	tcs := []struct {
+		exprString string
+		expected   string
+	}{
+		{"1 + 2 > x", "3 > x"},
+		{"1+2   >x", "3 > x"},
+		{"(3) > x", "3 > x"},
+		{"x < 2 * 3 && name == 'a' + 'b'", `x < 6 && name == "ab"`},
+		{"x > -(1 + 1)", "x > -2"},
+		{"x > size('abc')", "x > 3"},
+		{"x > int('4')", "x > 4"},
+		{"1 / 0 == x", "1 / 0 == x"},
+		{"x + 1 > 2", "x + 1 > 2"},
+		{"items.all(i, i > 1 + 1)", "items.all(i, i > 1 + 1)"},
+		{"(1 < 2) || x > 1", "true || x > 1"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.exprString, func(t *testing.T) {
+			compiled, err := compileCaveat(env, tc.exprString)
+			require.NoError(t, err)
+
+			original, err := compiled.ExprString()
+			require.NoError(t, err)
+
+			normalized, err := compiled.NormalizedExpressionString()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, normalized)
+
+			// The caveat itself is unchanged.
+			exprString, err := compiled.ExprString()
+			require.NoError(t, err)
+			require.Equal(t, original, exprString)
+		})
+	}
+}
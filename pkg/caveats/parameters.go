@@ -2,6 +2,7 @@ package caveats
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/authzed/spicedb/pkg/caveats/types"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -60,3 +61,44 @@ func ConvertContextToParameters(
 	}
 	return converted, nil
 }
+
+// ValidateContext validates the context values against the parameters declared by the caveat,
+// before evaluation, returning an UnknownParameterErr for a value given for an undeclared
+// parameter, or a ParameterConversionErr for a value which cannot be converted to the type of its
+// parameter, as it would be for evaluation. Parameters are validated in name order, and only the
+// first invalid one is reported. Null values, as used by three-valued evaluation, are valid for
+// parameters of any type.
+func (cc CompiledCaveat) ValidateContext(contextValues map[string]any) error {
+	if cc.parameterTypes == nil {
+		return fmt.Errorf("cannot validate context of caveat `%s`: its parameters are unknown", cc.name)
+	}
+
+	paramNames := make([]string, 0, len(contextValues))
+	for paramName := range contextValues {
+		paramNames = append(paramNames, paramName)
+	}
+	sort.Strings(paramNames)
+
+	for _, paramName := range paramNames {
+		paramType, ok := cc.parameterTypes[paramName]
+		if !ok {
+			return UnknownParameterErr{fmt.Errorf("unknown parameter `%s` for caveat `%s`", paramName, cc.name), paramName}
+		}
+
+		value := contextValues[paramName]
+		if _, ok := value.(nullContextValue); ok {
+			continue
+		}
+
+		varType, err := types.DecodeParameterType(paramType)
+		if err != nil {
+			return fmt.Errorf("parameter `%s`: %w", paramName, err)
+		}
+
+		if _, err := varType.ConvertValue(value); err != nil {
+			return ParameterConversionErr{fmt.Errorf("could not convert context parameter `%s`: %w", paramName, err), paramName}
+		}
+	}
+
+	return nil
+}
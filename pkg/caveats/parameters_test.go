@@ -0,0 +1,55 @@
+package caveats
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+func TestValidateContext(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"count":   types.IntType,
+		"expires": types.TimestampType,
+		"names":   types.MustListType(types.StringType),
+	})
+
+	compiled, err := CompileCaveatWithName(env, "count > 1 && expires > timestamp('2020-01-01T00:00:00Z') && 'a' in names", "somecaveat")
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name          string
+		contextValues map[string]any
+		expectedError string
+		expectedParam string
+	}{
+		{"empty", nil, "", ""},
+		{"valid", map[string]any{"count": int64(2), "expires": "2023-01-01T00:00:00Z", "names": []any{"a"}}, "", ""},
+		{"coerced", map[string]any{"count": float64(2), "expires": time.Now()}, "", ""},
+		{"null", map[string]any{"count": NullContextValue}, "", ""},
+		{"unknown", map[string]any{"count": int64(2), "other": true}, "unknown parameter `other` for caveat `somecaveat`", "other"},
+		{"mismatch", map[string]any{"count": "two"}, "could not convert context parameter `count`", "count"},
+		{"invalid timestamp", map[string]any{"expires": "tomorrow"}, "could not convert context parameter `expires`", "expires"},
+		{"invalid list item", map[string]any{"names": []any{1}}, "could not convert context parameter `names`", "names"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := compiled.ValidateContext(tc.contextValues)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, tc.expectedError)
+
+			var paramErr interface{ ParameterName() string }
+			require.True(t, errors.As(err, &paramErr))
+			require.Equal(t, tc.expectedParam, paramErr.ParameterName())
+		})
+	}
+}
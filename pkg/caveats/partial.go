@@ -0,0 +1,459 @@
+package caveats
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"golang.org/x/exp/maps"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// partialResultVersion is the version of the serialized form of a partial result.
+const partialResultVersion = 1
+
+// serializedPartialResult is the serialized form of a partially evaluated caveat.
+type serializedPartialResult struct {
+	Version         int                        `json:"version"`
+	Name            string                     `json:"name"`
+	Expression      []byte                     `json:"expression"`
+	ParameterTypes  map[string][]byte          `json:"parameters,omitempty"`
+	ContextValues   map[string]serializedValue `json:"context,omitempty"`
+	MissingVarNames []string                   `json:"missing,omitempty"`
+}
+
+// serializedValue is a context value serialized with its kind, so that it can be restored
+// into the same Go type.
+type serializedValue struct {
+	Kind  string                     `json:"kind"`
+	Type  string                     `json:"type,omitempty"`
+	Value string                     `json:"value,omitempty"`
+	List  []serializedValue          `json:"list,omitempty"`
+	Map   map[string]serializedValue `json:"map,omitempty"`
+}
+
+const (
+	serializedNull        = "null"
+	serializedBool        = "bool"
+	serializedInt         = "int"
+	serializedUint        = "uint"
+	serializedDouble      = "double"
+	serializedString      = "string"
+	serializedBytes       = "bytes"
+	serializedTimestamp   = "timestamp"
+	serializedDuration    = "duration"
+	serializedIPAddress   = "ipaddress"
+	serializedBloomFilter = "bloomfilter"
+	serializedMessage     = "message"
+	serializedList        = "list"
+	serializedMap         = "map"
+)
+
+// MarshalPartial serializes a partial result into bytes, such that evaluation can be resumed
+// via ResumePartial, including in another process. The serialized form contains the pruned
+// expression, the parameter types of the caveat, the context values already supplied and the
+// names of the missing variables. The function libraries and CEL options the caveat was compiled
+// with are not serialized: see ResumePartialWithConfig.
+//
+// A partial result holding a value for a parameter declared with SensitivityRestricted cannot be
+// serialized, as the value would be written out both in the context and, once folded into the
+// pruned expression, in the expression itself.
+func (cr CaveatResult) MarshalPartial() ([]byte, error) {
+	if !cr.isPartial {
+		return nil, fmt.Errorf("result is fully evaluated")
+	}
+
+	partialValue, err := cr.PartialValue()
+	if err != nil {
+		return nil, err
+	}
+
+	contextNames := maps.Keys(cr.contextValues)
+	sort.Strings(contextNames)
+	for _, name := range contextNames {
+		if partialValue.sensitivities[name] == SensitivityRestricted {
+			return nil, fmt.Errorf("cannot serialize a partial result holding a value for restricted parameter `%s`", name)
+		}
+	}
+
+	expr, err := proto.Marshal(&exprpb.ParsedExpr{Expr: partialValue.ast.Expr()})
+	if err != nil {
+		return nil, err
+	}
+
+	contextValues := make(map[string]serializedValue, len(cr.contextValues))
+	for name, value := range cr.contextValues {
+		serialized, err := serializeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("could not serialize context value `%s`: %w", name, err)
+		}
+		contextValues[name] = serialized
+	}
+
+	var parameterTypes map[string][]byte
+	if partialValue.parameterTypes != nil {
+		parameterTypes = make(map[string][]byte, len(partialValue.parameterTypes))
+		for name, paramType := range partialValue.parameterTypes {
+			encoded, err := paramType.MarshalVT()
+			if err != nil {
+				return nil, fmt.Errorf("could not serialize type of parameter `%s`: %w", name, err)
+			}
+			parameterTypes[name] = encoded
+		}
+	}
+
+	return json.Marshal(serializedPartialResult{
+		Version:         partialResultVersion,
+		Name:            partialValue.name,
+		Expression:      expr,
+		ParameterTypes:  parameterTypes,
+		ContextValues:   contextValues,
+		MissingVarNames: cr.missingVarNames,
+	})
+}
+
+// PartialValueAsExpression returns the partial result as a CaveatExpression, for transmission
+// to another service or storage as a pending caveat. The expression references the caveat by its
+// name and retains the context values already supplied, such that evaluating the caveat of that
+// name with the retained context and the missing values yields the same result as evaluating the
+// partial value.
+//
+// As the CaveatExpression carries no expression, the caveat must be resolvable by name wherever
+// the expression is evaluated, e.g. from the schema. The context values are converted into their
+// Struct forms, which are accepted by ConvertContextToParameters: timestamps are formatted per
+// RFC 3339, durations, IP addresses and bloom filters as strings, bytes in base64 and messages in
+// their JSON form. Note that integers are stored as doubles by Struct, and lose precision beyond
+// 2^53.
+func (cr CaveatResult) PartialValueAsExpression() (*core.CaveatExpression, error) {
+	if !cr.isPartial {
+		return nil, fmt.Errorf("result is fully evaluated")
+	}
+
+	contextValues := make(map[string]any, len(cr.contextValues))
+	for name, value := range cr.contextValues {
+		converted, err := structContextValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert context value `%s`: %w", name, err)
+		}
+		contextValues[name] = converted
+	}
+
+	contextStruct, err := structpb.NewStruct(contextValues)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert context values: %w", err)
+	}
+
+	return &core.CaveatExpression{
+		OperationOrCaveat: &core.CaveatExpression_Caveat{
+			Caveat: &core.ContextualizedCaveat{
+				CaveatName: cr.parentCaveat.name,
+				Context:    contextStruct,
+			},
+		},
+	}, nil
+}
+
+// structContextValue converts a context value into a value accepted by structpb.NewValue.
+func structContextValue(value any) (any, error) {
+	switch v := value.(type) {
+	case nil, bool, int, int32, int64, uint, uint32, uint64, float32, float64, string, []byte:
+		return v, nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return v.String(), nil
+	case types.IPAddress:
+		return v.String(), nil
+	case types.BloomFilter:
+		return v.Serialize(), nil
+	case proto.Message:
+		encoded, err := protojson.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		var decoded map[string]any
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			return nil, err
+		}
+		return decoded, nil
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Slice, reflect.Array:
+		list := make([]any, 0, reflected.Len())
+		for i := 0; i < reflected.Len(); i++ {
+			converted, err := structContextValue(reflected.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, converted)
+		}
+		return list, nil
+
+	case reflect.Map:
+		if reflected.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", reflected.Type().Key())
+		}
+
+		entries := make(map[string]any, reflected.Len())
+		iter := reflected.MapRange()
+		for iter.Next() {
+			converted, err := structContextValue(iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+			entries[iter.Key().String()] = converted
+		}
+		return entries, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// ResumePartial resumes evaluation of a partial result serialized by MarshalPartial, with the
+// additional context values given. Additional context values take precedence over those
+// supplied when the partial result was produced. As no caveat is given to resume against, the
+// expression can only call the functions available to all caveats: see ResumePartialWithConfig.
+func ResumePartial(data []byte, moreContext map[string]any) (*CaveatResult, error) {
+	return ResumePartialWithConfig(context.Background(), nil, data, moreContext, nil)
+}
+
+// ResumePartialWithConfig resumes evaluation of a partial result serialized by MarshalPartial,
+// with the additional context values and evaluation configuration given.
+//
+// If the caveat from which the partial result was produced is given, the expression is evaluated
+// in its environment, including the function libraries and CEL options it was compiled with,
+// which are not serialized. Otherwise, the environment is restored from the parameter types held
+// by the partial result, and the expression can only call the functions available to all caveats.
+func ResumePartialWithConfig(ctx context.Context, caveat *CompiledCaveat, data []byte, moreContext map[string]any, config *EvaluationConfig) (*CaveatResult, error) {
+	serialized := serializedPartialResult{}
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return nil, fmt.Errorf("could not decode partial result: %w", err)
+	}
+
+	if serialized.Version != partialResultVersion {
+		return nil, fmt.Errorf("unsupported partial result version %d", serialized.Version)
+	}
+
+	parsed := &exprpb.ParsedExpr{}
+	if err := proto.Unmarshal(serialized.Expression, parsed); err != nil {
+		return nil, fmt.Errorf("could not decode partial result expression: %w", err)
+	}
+
+	resumed := &CompiledCaveat{ast: cel.ParsedExprToAst(parsed), name: serialized.Name}
+	if caveat != nil {
+		if caveat.name != serialized.Name {
+			return nil, fmt.Errorf("cannot resume a partial result of caveat `%s` against caveat `%s`", serialized.Name, caveat.name)
+		}
+
+		resumed.celEnv = caveat.celEnv
+		resumed.sensitivities = caveat.sensitivities
+		resumed.impureFunctions = caveat.impureFunctions
+		resumed.parameterTypes = caveat.parameterTypes
+		resumed.defaults = caveat.defaults
+	} else {
+		env := NewEnvironment()
+		if serialized.ParameterTypes != nil {
+			resumed.parameterTypes = make(map[string]*core.CaveatTypeReference, len(serialized.ParameterTypes))
+			for name, encoded := range serialized.ParameterTypes {
+				paramType := &core.CaveatTypeReference{}
+				if err := paramType.UnmarshalVT(encoded); err != nil {
+					return nil, fmt.Errorf("could not decode type of parameter `%s`: %w", name, err)
+				}
+
+				varType, err := types.DecodeParameterType(paramType)
+				if err != nil {
+					return nil, fmt.Errorf("parameter `%s`: %w", name, err)
+				}
+
+				if err := env.AddVariable(name, *varType); err != nil {
+					return nil, err
+				}
+				resumed.parameterTypes[name] = paramType
+			}
+		}
+
+		celEnv, err := env.asCelEnvironment()
+		if err != nil {
+			return nil, err
+		}
+		resumed.celEnv = celEnv
+	}
+
+	contextValues := make(map[string]any, len(serialized.ContextValues)+len(moreContext))
+	for name, value := range serialized.ContextValues {
+		deserialized, err := deserializeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode context value `%s`: %w", name, err)
+		}
+		contextValues[name] = deserialized
+	}
+
+	for name, value := range moreContext {
+		contextValues[name] = value
+	}
+
+	return EvaluateCaveatWithConfig(ctx, resumed, contextValues, config)
+}
+
+func serializeValue(value any) (serializedValue, error) {
+	switch v := value.(type) {
+	case nil:
+		return serializedValue{Kind: serializedNull}, nil
+	case bool:
+		return serializedValue{Kind: serializedBool, Value: strconv.FormatBool(v)}, nil
+	case int:
+		return serializedValue{Kind: serializedInt, Value: strconv.FormatInt(int64(v), 10)}, nil
+	case int32:
+		return serializedValue{Kind: serializedInt, Value: strconv.FormatInt(int64(v), 10)}, nil
+	case int64:
+		return serializedValue{Kind: serializedInt, Value: strconv.FormatInt(v, 10)}, nil
+	case uint:
+		return serializedValue{Kind: serializedUint, Value: strconv.FormatUint(uint64(v), 10)}, nil
+	case uint32:
+		return serializedValue{Kind: serializedUint, Value: strconv.FormatUint(uint64(v), 10)}, nil
+	case uint64:
+		return serializedValue{Kind: serializedUint, Value: strconv.FormatUint(v, 10)}, nil
+	case float32:
+		return serializedValue{Kind: serializedDouble, Value: strconv.FormatFloat(float64(v), 'g', -1, 64)}, nil
+	case float64:
+		return serializedValue{Kind: serializedDouble, Value: strconv.FormatFloat(v, 'g', -1, 64)}, nil
+	case string:
+		return serializedValue{Kind: serializedString, Value: v}, nil
+	case []byte:
+		return serializedValue{Kind: serializedBytes, Value: base64.StdEncoding.EncodeToString(v)}, nil
+	case time.Time:
+		return serializedValue{Kind: serializedTimestamp, Value: v.Format(time.RFC3339Nano)}, nil
+	case time.Duration:
+		return serializedValue{Kind: serializedDuration, Value: v.String()}, nil
+	case types.IPAddress:
+		return serializedValue{Kind: serializedIPAddress, Value: v.String()}, nil
+	case types.BloomFilter:
+		return serializedValue{Kind: serializedBloomFilter, Value: v.Serialize()}, nil
+	case proto.Message:
+		encoded, err := protojson.Marshal(v)
+		if err != nil {
+			return serializedValue{}, err
+		}
+		return serializedValue{Kind: serializedMessage, Type: string(v.ProtoReflect().Descriptor().FullName()), Value: string(encoded)}, nil
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Slice, reflect.Array:
+		list := make([]serializedValue, 0, reflected.Len())
+		for i := 0; i < reflected.Len(); i++ {
+			serialized, err := serializeValue(reflected.Index(i).Interface())
+			if err != nil {
+				return serializedValue{}, err
+			}
+			list = append(list, serialized)
+		}
+		return serializedValue{Kind: serializedList, List: list}, nil
+
+	case reflect.Map:
+		if reflected.Type().Key().Kind() != reflect.String {
+			return serializedValue{}, fmt.Errorf("unsupported map key type %s", reflected.Type().Key())
+		}
+
+		entries := make(map[string]serializedValue, reflected.Len())
+		iter := reflected.MapRange()
+		for iter.Next() {
+			serialized, err := serializeValue(iter.Value().Interface())
+			if err != nil {
+				return serializedValue{}, err
+			}
+			entries[iter.Key().String()] = serialized
+		}
+		return serializedValue{Kind: serializedMap, Map: entries}, nil
+
+	default:
+		return serializedValue{}, fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+func deserializeValue(value serializedValue) (any, error) {
+	switch value.Kind {
+	case serializedNull:
+		return nil, nil
+	case serializedBool:
+		return strconv.ParseBool(value.Value)
+	case serializedInt:
+		return strconv.ParseInt(value.Value, 10, 64)
+	case serializedUint:
+		return strconv.ParseUint(value.Value, 10, 64)
+	case serializedDouble:
+		return strconv.ParseFloat(value.Value, 64)
+	case serializedString:
+		return value.Value, nil
+	case serializedBytes:
+		return base64.StdEncoding.DecodeString(value.Value)
+	case serializedTimestamp:
+		return time.Parse(time.RFC3339Nano, value.Value)
+	case serializedDuration:
+		return time.ParseDuration(value.Value)
+	case serializedIPAddress:
+		return types.ParseIPAddress(value.Value)
+	case serializedBloomFilter:
+		return types.ParseBloomFilter(value.Value)
+	case serializedMessage:
+		// Messages whose type is not linked into the process are restored in their JSON form,
+		// which is converted into the message by the type of the parameter holding it, if known.
+		messageType, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(value.Type))
+		if err != nil {
+			var decoded map[string]any
+			if err := json.Unmarshal([]byte(value.Value), &decoded); err != nil {
+				return nil, err
+			}
+			return decoded, nil
+		}
+
+		message := messageType.New().Interface()
+		if err := protojson.Unmarshal([]byte(value.Value), message); err != nil {
+			return nil, err
+		}
+		return message, nil
+
+	case serializedList:
+		list := make([]any, 0, len(value.List))
+		for _, item := range value.List {
+			deserialized, err := deserializeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, deserialized)
+		}
+		return list, nil
+
+	case serializedMap:
+		entries := make(map[string]any, len(value.Map))
+		for key, item := range value.Map {
+			deserialized, err := deserializeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			entries[key] = deserialized
+		}
+		return entries, nil
+
+	default:
+		return nil, fmt.Errorf("unknown value kind `%s`", value.Kind)
+	}
+}
@@ -0,0 +1,92 @@
+package caveats
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/cel-go/common/overloads"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// The `matches` function of caveat expressions is implemented by CEL with the regexp package,
+// which uses RE2 syntax and semantics: matching runs in time linear in the size of the input,
+// such that no pattern can cause catastrophic backtracking on a context value, e.g.
+// `path.matches('(a+)+$')`. In exchange, features requiring backtracking, such as backreferences
+// (`\1`) and lookaround assertions (`(?=...)`, `(?!...)`, `(?<=...)`), are unsupported, and
+// counted repetitions are limited to 1000 (`a{1001}` is rejected).
+//
+// Patterns given as string literals are compiled when the caveat is compiled, so that patterns
+// using unsupported features are rejected on schema write rather than failing the evaluations
+// of the caveat. Patterns given by context values are compiled on evaluation.
+
+// validateRegularExpressions returns an error for the first pattern given as a string literal
+// to a call of `matches` in the expression which is not a valid RE2 pattern.
+func validateRegularExpressions(expr *exprpb.Expr) error {
+	if expr == nil {
+		return nil
+	}
+
+	switch t := expr.ExprKind.(type) {
+	case *exprpb.Expr_ConstExpr, *exprpb.Expr_IdentExpr:
+		return nil
+
+	case *exprpb.Expr_SelectExpr:
+		return validateRegularExpressions(t.SelectExpr.Operand)
+
+	case *exprpb.Expr_CallExpr:
+		call := t.CallExpr
+		if call.Function == overloads.Matches && len(call.Args) > 0 {
+			if pattern, ok := call.Args[len(call.Args)-1].GetConstExpr().GetConstantKind().(*exprpb.Constant_StringValue); ok {
+				if _, err := regexp.Compile(pattern.StringValue); err != nil {
+					return fmt.Errorf("invalid regular expression `%s` given to `matches`, which supports RE2 syntax only: %w", pattern.StringValue, err)
+				}
+			}
+		}
+
+		if err := validateRegularExpressions(call.Target); err != nil {
+			return err
+		}
+		for _, arg := range call.Args {
+			if err := validateRegularExpressions(arg); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range t.ListExpr.Elements {
+			if err := validateRegularExpressions(elem); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range t.StructExpr.Entries {
+			if err := validateRegularExpressions(entry.GetMapKey()); err != nil {
+				return err
+			}
+			if err := validateRegularExpressions(entry.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *exprpb.Expr_ComprehensionExpr:
+		for _, subexpr := range []*exprpb.Expr{
+			t.ComprehensionExpr.IterRange,
+			t.ComprehensionExpr.AccuInit,
+			t.ComprehensionExpr.LoopCondition,
+			t.ComprehensionExpr.LoopStep,
+			t.ComprehensionExpr.Result,
+		} {
+			if err := validateRegularExpressions(subexpr); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		panic(fmt.Sprintf("unknown CEL expression kind: %T", t))
+	}
+}
@@ -0,0 +1,56 @@
+package caveats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+func TestCompileValidatesRegularExpressions(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"path":    types.StringType,
+		"pattern": types.StringType,
+	})
+
+	tcs := []struct {
+		name          string
+		exprString    string
+		expectedError string
+	}{
+		{"valid member call", "path.matches('^/docs/[a-z]+$')", ""},
+		{"valid call within expression", "path.size() > 0 && path.matches('^/docs/[a-z]+$')", ""},
+		{"nested quantifiers", "path.matches('(a+)+$')", ""},
+		{"pattern from context", "path.matches(pattern)", ""},
+		{"backreference", "path.matches('(a)\\\\1')", "invalid regular expression `(a)\\1` given to `matches`, which supports RE2 syntax only"},
+		{"lookahead", "path.matches('a(?=b)')", "invalid regular expression `a(?=b)` given to `matches`"},
+		{"within comprehension", "['a', 'b'].exists(x, x.matches('a(?!b)'))", "invalid regular expression `a(?!b)` given to `matches`"},
+		{"repetition too large", "path.matches('a{1001}')", "invalid regular expression `a{1001}` given to `matches`"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := compileCaveat(env, tc.exprString)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestMatchesIsLinearTime(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"path": types.StringType,
+	}), "path.matches('^(a+)+$')")
+	require.NoError(t, err)
+
+	// A backtracking engine would not complete on this input.
+	result, err := EvaluateCaveat(compiled, map[string]any{"path": strings.Repeat("a", 100000) + "!"})
+	require.NoError(t, err)
+	require.False(t, result.Value())
+}
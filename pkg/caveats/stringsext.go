@@ -0,0 +1,142 @@
+package caveats
+
+import (
+	"math"
+
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/common"
+	celtypes "github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// stringExtensionFunctions are the functions of the CEL strings extension library, available
+// to caveats:
+//
+//	<string>.charAt(<int>) -> <string>
+//	<string>.indexOf(<string>) -> <int>
+//	<string>.indexOf(<string>, <int>) -> <int>
+//	<string>.lastIndexOf(<string>) -> <int>
+//	<string>.lastIndexOf(<string>, <int>) -> <int>
+//	<string>.lowerAscii() -> <string>
+//	<string>.upperAscii() -> <string>
+//	<string>.replace(<string>, <string>) -> <string>
+//	<string>.replace(<string>, <string>, <int>) -> <string>
+//	<string>.split(<string>) -> <list<string>>
+//	<string>.split(<string>, <int>) -> <list<string>>
+//	<string>.substring(<int>) -> <string>
+//	<string>.substring(<int>, <int>) -> <string>
+//	<string>.trim() -> <string>
+//	<list<string>>.join() -> <string>
+//	<list<string>>.join(<string>) -> <string>
+//
+// See the documentation of cel-go's ext.Strings for their semantics. `strings.format` is not
+// provided by the version of cel-go in use.
+//
+// Unlike the other functions on strings, they are not costed by CEL itself: their cost, both
+// estimated and actual, is that of traversing their string operands and result, as for the
+// standard functions on strings.
+var stringExtensionFunctions = map[string]struct{}{
+	"charAt":      {},
+	"indexOf":     {},
+	"lastIndexOf": {},
+	"lowerAscii":  {},
+	"upperAscii":  {},
+	"replace":     {},
+	"split":       {},
+	"substring":   {},
+	"trim":        {},
+	"join":        {},
+}
+
+// stringTraversalCost returns the cost of traversing strings of the total size given.
+func stringTraversalCost(size uint64) uint64 {
+	cost := math.Ceil(float64(size) * common.StringTraversalCostFactor)
+	if cost >= math.MaxUint64 {
+		return math.MaxUint64
+	}
+	return uint64(cost)
+}
+
+// addSizes adds the sizes, saturating at math.MaxUint64.
+func addSizes(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
+
+// estimateStringExtensionCallCost estimates the cost of a call of a function of the strings
+// extension library, from the maximum sizes of its operands, or returns nil for other functions.
+func (e maxSizesEstimator) estimateStringExtensionCallCost(function string, target *checker.AstNode, args []checker.AstNode) *checker.CallEstimate {
+	if _, ok := stringExtensionFunctions[function]; !ok {
+		return nil
+	}
+
+	operands := args
+	if target != nil {
+		operands = append([]checker.AstNode{*target}, args...)
+	}
+
+	var maxSize uint64
+	for _, operand := range operands {
+		maxSize = addSizes(maxSize, e.maxSize(operand))
+	}
+
+	// The cost of traversing an unbounded operand is unbounded, as for comprehensions.
+	if maxSize == math.MaxUint64 {
+		return &checker.CallEstimate{
+			CostEstimate: checker.CostEstimate{Min: 1, Max: math.MaxUint64},
+		}
+	}
+
+	// The result of replace can be larger than its operands, and is only accounted for by the
+	// actual cost.
+	return &checker.CallEstimate{
+		CostEstimate: checker.CostEstimate{Min: 1, Max: addSizes(1, stringTraversalCost(maxSize))},
+	}
+}
+
+// maxSize returns the maximum size of the node, or math.MaxUint64 if it is unbounded. Nodes of
+// scalar types have a size of 1.
+func (e maxSizesEstimator) maxSize(node checker.AstNode) uint64 {
+	if size := node.ComputedSize(); size != nil {
+		return size.Max
+	}
+	if size := e.EstimateSize(node); size != nil {
+		return size.Max
+	}
+
+	nodeType := node.Type()
+	switch {
+	case nodeType.GetListType() != nil, nodeType.GetMapType() != nil:
+		return math.MaxUint64
+	case nodeType.GetPrimitive() == exprpb.Type_STRING, nodeType.GetPrimitive() == exprpb.Type_BYTES:
+		return math.MaxUint64
+	default:
+		return 1
+	}
+}
+
+// stringExtensionCostEstimator is an interpreter.ActualCostEstimator computing the actual cost of
+// the calls of the functions of the strings extension library.
+type stringExtensionCostEstimator struct{}
+
+func (stringExtensionCostEstimator) CallCost(function, overloadID string, args []ref.Val, result ref.Val) *uint64 {
+	if _, ok := stringExtensionFunctions[function]; !ok {
+		return nil
+	}
+
+	var size uint64
+	for _, arg := range append(args, result) {
+		if sizer, ok := arg.(traits.Sizer); ok {
+			if argSize, ok := sizer.Size().(celtypes.Int); ok && argSize > 0 {
+				size = addSizes(size, uint64(argSize))
+			}
+		}
+	}
+
+	cost := addSizes(1, stringTraversalCost(size))
+	return &cost
+}
@@ -0,0 +1,99 @@
+package caveats
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+)
+
+func TestStringExtensionFunctions(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"path":   types.StringType,
+		"domain": types.StringType,
+	})
+
+	tcs := []struct {
+		exprString string
+		expected   bool
+	}{
+		{"path.split('/')[1] == 'docs'", true},
+		{"path.replace('/', '.') == '.docs.readme'", true},
+		{"path.split('/').join('-') == '-docs-readme'", true},
+		{"path.upperAscii().lowerAscii() == path", true},
+		{"path.substring(1, 5) == 'docs'", true},
+		{"path.indexOf('readme') == 6", true},
+		{"path.lastIndexOf('/') == 5", true},
+		{"path.charAt(1) == 'd'", true},
+		{"'  docs '.trim() == 'docs'", true},
+		{"path.split('/').size() == 2", false},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.exprString, func(t *testing.T) {
+			compiled, err := compileCaveat(env, tc.exprString)
+			require.NoError(t, err)
+
+			result, err := EvaluateCaveat(compiled, map[string]any{"path": "/docs/readme"})
+			require.NoError(t, err)
+			require.False(t, result.IsPartial())
+			require.Equal(t, tc.expected, result.Value())
+		})
+	}
+
+	// The functions are partially evaluated like any other.
+	compiled, err := compileCaveat(env, "path.split('/')[1] == 'docs' && domain.lowerAscii() == 'example.com'")
+	require.NoError(t, err)
+
+	result, err := EvaluateCaveat(compiled, map[string]any{"path": "/docs/readme"})
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	partial, err := result.PartialValue()
+	require.NoError(t, err)
+
+	result, err = EvaluateCaveat(partial, map[string]any{"domain": "EXAMPLE.com"})
+	require.NoError(t, err)
+	require.True(t, result.Value())
+}
+
+func TestStringExtensionFunctionsCost(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"s": types.StringType,
+	})
+
+	compiled, err := compileCaveat(env, "s.replace('a', 'b').size() > 0")
+	require.NoError(t, err)
+
+	// The estimated cost grows with the size of the string.
+	_, unboundedMax, err := compiled.EstimateCost()
+	require.NoError(t, err)
+	require.Equal(t, uint64(math.MaxUint64), unboundedMax)
+
+	_, smallMax, err := compiled.EstimateCostWithMaxSizes(map[string]uint64{"s": 10})
+	require.NoError(t, err)
+
+	_, largeMax, err := compiled.EstimateCostWithMaxSizes(map[string]uint64{"s": 10000})
+	require.NoError(t, err)
+	require.Greater(t, largeMax, smallMax)
+
+	// So does the actual cost, which is limited by MaxCost.
+	short, err := EvaluateCaveat(compiled, map[string]any{"s": "abc"})
+	require.NoError(t, err)
+	shortCost, ok := short.Cost()
+	require.True(t, ok)
+
+	long, err := EvaluateCaveat(compiled, map[string]any{"s": strings.Repeat("abc", 1000)})
+	require.NoError(t, err)
+	longCost, ok := long.Cost()
+	require.True(t, ok)
+	require.Greater(t, longCost, shortCost+100)
+
+	_, err = EvaluateCaveatWithConfig(context.Background(), compiled, map[string]any{"s": strings.Repeat("abc", 1000)}, &EvaluationConfig{MaxCost: shortCost + 100})
+	require.Error(t, err)
+}
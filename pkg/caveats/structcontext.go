@@ -0,0 +1,228 @@
+package caveats
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// EvaluateCaveatWithStruct evaluates the compiled caveat with context values read from the fields
+// of the given Go struct (or pointer to a struct), and returns the result or an error. See
+// ContextFromStruct for how fields are read. The values are then coerced to the declared types of
+// the caveat's parameters, as done by ConvertContextToParameters; fields not matching a parameter
+// are ignored.
+//
+// The parameter types are only known for caveats compiled in this process: for a deserialized
+// caveat, use ContextFromStruct and ConvertContextToParameters with the parameter types of the
+// caveat definition instead.
+func EvaluateCaveatWithStruct(caveat *CompiledCaveat, value any) (*CaveatResult, error) {
+	if caveat.parameterTypes == nil {
+		return nil, fmt.Errorf("parameter types of caveat `%s` are unknown", caveat.name)
+	}
+
+	contextMap, err := ContextFromStruct(value)
+	if err != nil {
+		return nil, err
+	}
+
+	contextValues, err := ConvertContextToParameters(contextMap, caveat.parameterTypes, SkipUnknownParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return EvaluateCaveat(caveat, contextValues)
+}
+
+// ContextFromStruct builds a context map from the exported fields of the given Go struct (or
+// pointer to a struct), in the form expected by ConvertContextToParameters.
+//
+// Each field is named by its `cel` struct tag, falling back to its `json` struct tag and then its
+// Go name; fields tagged with `-` are skipped. The fields of embedded structs are promoted into
+// the enclosing struct, as with encoding/json. Nil pointers and interfaces are omitted, which
+// allows optional fields to be left for partial evaluation. Nested structs become maps, while
+// timestamps, durations and byte slices are encoded as strings, as they would be in JSON.
+func ContextFromStruct(value any) (map[string]any, error) {
+	reflected := reflect.ValueOf(value)
+	for reflected.Kind() == reflect.Pointer {
+		if reflected.IsNil() {
+			return nil, fmt.Errorf("context struct is nil")
+		}
+		reflected = reflected.Elem()
+	}
+
+	if reflected.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("context must be a struct, found %T", value)
+	}
+
+	contextMap := map[string]any{}
+	if err := addStructFields(reflected, contextMap); err != nil {
+		return nil, err
+	}
+	return contextMap, nil
+}
+
+func addStructFields(structValue reflect.Value, contextMap map[string]any) error {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, skip := contextFieldName(field)
+		if skip {
+			continue
+		}
+
+		fieldValue := structValue.Field(i)
+
+		// Promote the fields of untagged embedded structs.
+		if field.Anonymous && name == "" {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct && embedded.Type() != timeType {
+				if err := addStructFields(embedded, contextMap); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		converted, present, err := contextValueOf(fieldValue)
+		if err != nil {
+			return fmt.Errorf("could not read context field `%s`: %w", field.Name, err)
+		}
+		if present {
+			contextMap[name] = converted
+		}
+	}
+	return nil
+}
+
+// contextFieldName returns the name of the context value for the struct field from its tags, if
+// any, and whether the field should be skipped.
+func contextFieldName(field reflect.StructField) (string, bool) {
+	for _, tagName := range []string{"cel", "json"} {
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return "", true
+		}
+		if name != "" {
+			return name, false
+		}
+	}
+	return "", false
+}
+
+// contextValueOf converts the value into its context form, returning false if the value is absent.
+func contextValueOf(value reflect.Value) (any, bool, error) {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil, false, nil
+		}
+		return contextValueOf(value.Elem())
+	}
+
+	switch value.Type() {
+	case timeType:
+		if !value.CanInterface() {
+			return nil, false, fmt.Errorf("cannot read unexported timestamp")
+		}
+		return value.Interface().(time.Time).Format(time.RFC3339Nano), true, nil
+	case durationType:
+		return time.Duration(value.Int()).String(), true, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		return value.Bool(), true, nil
+
+	case reflect.String:
+		return value.String(), true, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), true, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint(), true, nil
+
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true, nil
+
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(b), value)
+			return base64.StdEncoding.EncodeToString(b), true, nil
+		}
+
+		list := make([]any, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			item, present, err := contextValueOf(value.Index(i))
+			if err != nil {
+				return nil, false, err
+			}
+			if !present {
+				item = nil
+			}
+			list = append(list, item)
+		}
+		return list, true, nil
+
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return nil, false, fmt.Errorf("unsupported map key type %s", value.Type().Key())
+		}
+
+		entries := make(map[string]any, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			item, present, err := contextValueOf(iter.Value())
+			if err != nil {
+				return nil, false, err
+			}
+			if present {
+				entries[iter.Key().String()] = item
+			}
+		}
+		return entries, true, nil
+
+	case reflect.Struct:
+		if value.Type().Implements(stringerType) && value.CanInterface() {
+			return value.Interface().(fmt.Stringer).String(), true, nil
+		}
+
+		entries := map[string]any{}
+		if err := addStructFields(value, entries); err != nil {
+			return nil, false, err
+		}
+		return entries, true, nil
+
+	default:
+		return nil, false, fmt.Errorf("unsupported type %s", value.Type())
+	}
+}
@@ -0,0 +1,182 @@
+package caveats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/caveats/types"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+type baseTestContext struct {
+	Region string `json:"region"`
+}
+
+type testRequestContext struct {
+	baseTestContext
+
+	UserID  string            `cel:"user_id" json:"userId"`
+	Count   int32             `json:"count"`
+	Limit   *int64            `json:"limit,omitempty"`
+	Expires time.Time         `json:"expires"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels"`
+	Ignored string            `json:"-"`
+	private string
+}
+
+func TestContextFromStruct(t *testing.T) {
+	limit := int64(10)
+	contextMap, err := ContextFromStruct(&testRequestContext{
+		baseTestContext: baseTestContext{Region: "us"},
+		UserID:          "tom",
+		Count:           3,
+		Limit:           &limit,
+		Expires:         time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		Tags:            []string{"admin"},
+		Labels:          map[string]string{"team": "core"},
+		Ignored:         "ignored",
+		private:         "private",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"region":  "us",
+		"user_id": "tom",
+		"count":   int64(3),
+		"limit":   int64(10),
+		"expires": "2030-01-01T00:00:00Z",
+		"tags":    []any{"admin"},
+		"labels":  map[string]any{"team": "core"},
+	}, contextMap)
+
+	_, err = ContextFromStruct(42)
+	require.ErrorContains(t, err, "context must be a struct")
+}
+
+func TestEvaluateCaveatWithStruct(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"region":  types.StringType,
+		"user_id": types.StringType,
+		"count":   types.IntType,
+		"limit":   types.IntType,
+		"expires": types.TimestampType,
+		"tags":    types.MustListType(types.StringType),
+	}), "region == 'us' && user_id == 'tom' && count < limit && expires > timestamp('2020-01-01T00:00:00Z') && 'admin' in tags")
+	require.NoError(t, err)
+
+	requestContext := &testRequestContext{
+		baseTestContext: baseTestContext{Region: "us"},
+		UserID:          "tom",
+		Count:           3,
+		Expires:         time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		Tags:            []string{"admin"},
+	}
+
+	// The nil optional field is absent, resulting in a partial result.
+	result, err := EvaluateCaveatWithStruct(compiled, requestContext)
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	missingVarNames, err := result.MissingVarNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"limit"}, missingVarNames)
+
+	limit := int64(10)
+	requestContext.Limit = &limit
+
+	result, err = EvaluateCaveatWithStruct(compiled, requestContext)
+	require.NoError(t, err)
+	require.False(t, result.IsPartial())
+	require.True(t, result.Value())
+
+	// Fields are coerced to the declared types of the parameters.
+	require.Equal(t, int64(3), result.ContextValues()["count"])
+	require.True(t, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).Equal(result.ContextValues()["expires"].(time.Time)))
+
+	limit = 2
+	result, err = EvaluateCaveatWithStruct(compiled, requestContext)
+	require.NoError(t, err)
+	require.False(t, result.Value())
+}
+
+func TestEvaluateCaveatWithStructDeserialized(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"region": types.StringType,
+	}), "region == 'us'")
+	require.NoError(t, err)
+
+	serialized, err := compiled.Serialize()
+	require.NoError(t, err)
+
+	deserialized, err := DeserializeCaveat(serialized, nil)
+	require.NoError(t, err)
+
+	_, err = EvaluateCaveatWithStruct(deserialized, baseTestContext{Region: "us"})
+	require.ErrorContains(t, err, "parameter types of caveat")
+}
+
+var relationTupleType = types.MustRegisterMessageType(&core.RelationTuple{})
+
+func TestEvaluateMessageParameter(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"rel": relationTupleType,
+	})
+
+	compiled, err := compileCaveat(env, "rel.resource_and_relation.namespace == 'document' && rel.subject.object_id == 'tom'")
+	require.NoError(t, err)
+
+	contextValues := map[string]any{
+		"rel": map[string]any{
+			"resourceAndRelation": map[string]any{"namespace": "document", "objectId": "readme", "relation": "viewer"},
+			"subject":             map[string]any{"namespace": "user", "objectId": "tom", "relation": "..."},
+		},
+	}
+
+	result, err := EvaluateCaveat(compiled, contextValues)
+	require.NoError(t, err)
+	require.True(t, result.Value())
+
+	// Absent nested fields read as their defaults rather than making the result partial.
+	result, err = EvaluateCaveat(compiled, map[string]any{
+		"rel": map[string]any{"resourceAndRelation": map[string]any{"namespace": "document"}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsPartial())
+	require.False(t, result.Value())
+
+	compiled, err = compileCaveat(env, "has(rel.subject) && rel.subject.object_id == 'tom'")
+	require.NoError(t, err)
+
+	result, err = EvaluateCaveat(compiled, map[string]any{"rel": map[string]any{}})
+	require.NoError(t, err)
+	require.False(t, result.IsPartial())
+	require.False(t, result.Value())
+
+	// Unknown fields are rejected.
+	_, err = EvaluateCaveat(compiled, map[string]any{"rel": map[string]any{"unknown": 1}})
+	require.Error(t, err)
+
+	// Message values round-trip through the partial expression form of a result.
+	compiled, err = compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"rel":     relationTupleType,
+		"enabled": types.BooleanType,
+	}), "rel.subject.object_id == 'tom' && enabled")
+	require.NoError(t, err)
+
+	result, err = EvaluateCaveat(compiled, contextValues)
+	require.NoError(t, err)
+	require.True(t, result.IsPartial())
+
+	expression, err := result.PartialValueAsExpression()
+	require.NoError(t, err)
+
+	roundTripped, err := ConvertContextToParameters(expression.GetCaveat().Context.AsMap(), compiled.parameterTypes, ErrorForUnknownParameters)
+	require.NoError(t, err)
+	roundTripped["enabled"] = true
+
+	result, err = EvaluateCaveat(compiled, roundTripped)
+	require.NoError(t, err)
+	require.True(t, result.Value())
+}
@@ -54,3 +54,181 @@ func referencedParameters(definedParameters *util.Set[string], expr *exprpb.Expr
 		panic(fmt.Sprintf("unknown CEL expression kind: %T", t))
 	}
 }
+
+// referencedFunctions traverses the expression given and finds the names of all functions called
+// in the expression.
+func referencedFunctions(expr *exprpb.Expr, referencedFuncs *util.Set[string]) {
+	if expr == nil {
+		return
+	}
+
+	switch t := expr.ExprKind.(type) {
+	case *exprpb.Expr_ConstExpr, *exprpb.Expr_IdentExpr:
+		// nothing to do
+
+	case *exprpb.Expr_SelectExpr:
+		referencedFunctions(t.SelectExpr.Operand, referencedFuncs)
+
+	case *exprpb.Expr_CallExpr:
+		referencedFuncs.Add(t.CallExpr.Function)
+		referencedFunctions(t.CallExpr.Target, referencedFuncs)
+		for _, arg := range t.CallExpr.Args {
+			referencedFunctions(arg, referencedFuncs)
+		}
+
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range t.ListExpr.Elements {
+			referencedFunctions(elem, referencedFuncs)
+		}
+
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range t.StructExpr.Entries {
+			referencedFunctions(entry.Value, referencedFuncs)
+		}
+
+	case *exprpb.Expr_ComprehensionExpr:
+		referencedFunctions(t.ComprehensionExpr.AccuInit, referencedFuncs)
+		referencedFunctions(t.ComprehensionExpr.IterRange, referencedFuncs)
+		referencedFunctions(t.ComprehensionExpr.LoopCondition, referencedFuncs)
+		referencedFunctions(t.ComprehensionExpr.LoopStep, referencedFuncs)
+		referencedFunctions(t.ComprehensionExpr.Result, referencedFuncs)
+
+	default:
+		panic(fmt.Sprintf("unknown CEL expression kind: %T", t))
+	}
+}
+
+// expressionDepth returns the depth of the expression given: one for an expression without
+// subexpressions, such as a constant or an identifier, plus the greatest depth of its
+// subexpressions otherwise.
+func expressionDepth(expr *exprpb.Expr) int {
+	if expr == nil {
+		return 0
+	}
+
+	maxDepth := 0
+	visit := func(subexprs ...*exprpb.Expr) {
+		for _, subexpr := range subexprs {
+			if depth := expressionDepth(subexpr); depth > maxDepth {
+				maxDepth = depth
+			}
+		}
+	}
+
+	switch t := expr.ExprKind.(type) {
+	case *exprpb.Expr_ConstExpr, *exprpb.Expr_IdentExpr:
+		// nothing to do
+
+	case *exprpb.Expr_SelectExpr:
+		visit(t.SelectExpr.Operand)
+
+	case *exprpb.Expr_CallExpr:
+		visit(t.CallExpr.Target)
+		visit(t.CallExpr.Args...)
+
+	case *exprpb.Expr_ListExpr:
+		visit(t.ListExpr.Elements...)
+
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range t.StructExpr.Entries {
+			visit(entry.GetMapKey(), entry.Value)
+		}
+
+	case *exprpb.Expr_ComprehensionExpr:
+		visit(
+			t.ComprehensionExpr.AccuInit,
+			t.ComprehensionExpr.IterRange,
+			t.ComprehensionExpr.LoopCondition,
+			t.ComprehensionExpr.LoopStep,
+			t.ComprehensionExpr.Result,
+		)
+
+	default:
+		panic(fmt.Sprintf("unknown CEL expression kind: %T", t))
+	}
+
+	return maxDepth + 1
+}
+
+// builtinTypeIdentifiers are the identifiers of the standard CEL types, which can appear as
+// values in an expression (e.g. `type(a) == int`) but are not variables.
+var builtinTypeIdentifiers = map[string]struct{}{
+	"bool":      {},
+	"bytes":     {},
+	"double":    {},
+	"duration":  {},
+	"dyn":       {},
+	"int":       {},
+	"list":      {},
+	"map":       {},
+	"null_type": {},
+	"string":    {},
+	"timestamp": {},
+	"type":      {},
+	"uint":      {},
+}
+
+// variableIdentifiers traverses the checked expression given and finds all identifiers which
+// refer to variables, keyed by the ID of the identifier's expression. Unlike referencedParameters,
+// it does not require the declared parameters: identifiers bound by an enclosing comprehension
+// and those of the standard types are skipped, which leaves the variables. Qualified variables
+// (e.g. `foo.a`) are found as a single identifier, as the checker resolves them as such.
+func variableIdentifiers(expr *exprpb.Expr, bound map[string]int, identifiers map[int64]string) {
+	if expr == nil {
+		return
+	}
+
+	switch t := expr.ExprKind.(type) {
+	case *exprpb.Expr_ConstExpr:
+		// nothing to do
+
+	case *exprpb.Expr_IdentExpr:
+		if bound[t.IdentExpr.Name] > 0 {
+			return
+		}
+
+		if _, ok := builtinTypeIdentifiers[t.IdentExpr.Name]; ok {
+			return
+		}
+
+		identifiers[expr.Id] = t.IdentExpr.Name
+
+	case *exprpb.Expr_SelectExpr:
+		variableIdentifiers(t.SelectExpr.Operand, bound, identifiers)
+
+	case *exprpb.Expr_CallExpr:
+		variableIdentifiers(t.CallExpr.Target, bound, identifiers)
+		for _, arg := range t.CallExpr.Args {
+			variableIdentifiers(arg, bound, identifiers)
+		}
+
+	case *exprpb.Expr_ListExpr:
+		for _, elem := range t.ListExpr.Elements {
+			variableIdentifiers(elem, bound, identifiers)
+		}
+
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range t.StructExpr.Entries {
+			variableIdentifiers(entry.Value, bound, identifiers)
+		}
+
+	case *exprpb.Expr_ComprehensionExpr:
+		comprehension := t.ComprehensionExpr
+		variableIdentifiers(comprehension.IterRange, bound, identifiers)
+		variableIdentifiers(comprehension.AccuInit, bound, identifiers)
+
+		// The accumulator is bound in the loop and result, the iteration variable only in the loop.
+		bound[comprehension.AccuVar]++
+		variableIdentifiers(comprehension.Result, bound, identifiers)
+
+		bound[comprehension.IterVar]++
+		variableIdentifiers(comprehension.LoopCondition, bound, identifiers)
+		variableIdentifiers(comprehension.LoopStep, bound, identifiers)
+
+		bound[comprehension.IterVar]--
+		bound[comprehension.AccuVar]--
+
+	default:
+		panic(fmt.Sprintf("unknown CEL expression kind: %T", t))
+	}
+}
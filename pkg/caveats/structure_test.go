@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/authzed/spicedb/pkg/caveats/types"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
 func TestReferencedParameters(t *testing.T) {
@@ -101,3 +102,52 @@ func TestReferencedParameters(t *testing.T) {
 		})
 	}
 }
+
+func TestReferencedParameterTypes(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a":     types.IntType,
+		"b":     types.MustListType(types.StringType),
+		"c":     types.IPAddressType,
+		"other": types.BooleanType,
+	}), "a > 1 && 'hi' in b && c.in_cidr('10.0.0.0/8')")
+	require.NoError(t, err)
+
+	parameterTypes, err := compiled.ReferencedParameterTypes()
+	require.NoError(t, err)
+	require.Len(t, parameterTypes, 3)
+	require.Equal(t, types.IntType.String(), parameterTypes["a"].String())
+	require.Equal(t, types.MustListType(types.StringType).String(), parameterTypes["b"].String())
+	require.Equal(t, types.IPAddressType.String(), parameterTypes["c"].String())
+
+	serialized, err := compiled.Serialize()
+	require.NoError(t, err)
+
+	deserialized, err := DeserializeCaveat(serialized, nil)
+	require.NoError(t, err)
+
+	_, err = deserialized.ReferencedParameterTypes()
+	require.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"a": types.IntType,
+		"b": types.MustListType(types.StringType),
+		"c": types.IntType,
+	}), "a > 1 && b.all(x, x != 'hi') && c < 10 && type(c) == int")
+	require.NoError(t, err)
+	require.NoError(t, compiled.Validate())
+
+	serialized, err := compiled.Serialize()
+	require.NoError(t, err)
+
+	deserialized, err := DeserializeCaveat(serialized, nil)
+	require.NoError(t, err)
+	require.Error(t, deserialized.Validate())
+
+	deserialized, err = DeserializeCaveat(serialized, map[string]*core.CaveatTypeReference{
+		"b": types.EncodeParameterType(types.MustListType(types.StringType)),
+	})
+	require.NoError(t, err)
+	require.EqualError(t, deserialized.Validate(), "caveat `caveat` references undeclared parameter(s) `a`, `c`")
+}
@@ -0,0 +1,42 @@
+package caveats
+
+import (
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/interpreter"
+)
+
+// nullContextValue is the type of NullContextValue.
+type nullContextValue struct{}
+
+// NullContextValue is a designated context value indicating that a parameter is intentionally
+// null, rather than missing. It can only be used when evaluating with ThreeValuedLogic enabled
+// in the EvaluationConfig.
+//
+// Null parameters propagate through boolean operators per Kleene logic: `false && null` is
+// false, `true || null` is true, and all other operations on null (including `!null` and
+// comparisons) result in null. If the caveat's result depends on a null parameter, the result
+// is null, as reported by CaveatResult.IsNull, and the caller decides whether to allow or deny.
+var NullContextValue = nullContextValue{}
+
+// withoutNullContextValues returns the context values with those designated as null removed,
+// along with the attribute patterns marking those parameters as unknown for evaluation.
+func withoutNullContextValues(contextValues map[string]any) (map[string]any, []*interpreter.AttributePattern) {
+	var patterns []*interpreter.AttributePattern
+	for name, value := range contextValues {
+		if _, ok := value.(nullContextValue); ok {
+			patterns = append(patterns, cel.AttributePattern(name))
+		}
+	}
+
+	if len(patterns) == 0 {
+		return contextValues, nil
+	}
+
+	filtered := make(map[string]any, len(contextValues)-len(patterns))
+	for name, value := range contextValues {
+		if _, ok := value.(nullContextValue); !ok {
+			filtered[name] = value
+		}
+	}
+	return filtered, patterns
+}
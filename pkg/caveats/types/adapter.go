@@ -3,6 +3,7 @@ package types
 import (
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
+	"google.golang.org/protobuf/proto"
 )
 
 // CustomTypeAdapter implements a CEL type adapter for handling the custom defined types.
@@ -14,5 +15,9 @@ func (CustomTypeAdapter) NativeToValue(value interface{}) ref.Val {
 		return converted
 	}
 
+	if message, ok := value.(proto.Message); ok {
+		return messageRegistry.NativeToValue(message)
+	}
+
 	return types.DefaultTypeAdapter.NativeToValue(value)
 }
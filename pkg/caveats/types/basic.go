@@ -2,7 +2,9 @@ package types
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
 	"time"
 
@@ -25,20 +27,56 @@ func convertNumericType[T int64 | uint64 | float64](value any) (any, error) {
 		return directValue, nil
 	}
 
-	floatValue, ok := value.(float64)
-	bigFloat := big.NewFloat(floatValue)
-	if !ok {
-		stringValue, ok := value.(string)
-		if !ok {
-			return nil, fmt.Errorf("a %T value is required, but found %T `%v`", *new(T), value, value)
+	var bigFloat *big.Float
+	switch v := value.(type) {
+	case float64:
+		if math.IsNaN(v) {
+			if _, isDouble := any(*new(T)).(float64); isDouble {
+				return v, nil
+			}
+			return nil, fmt.Errorf("a %T value is required, but found NaN", *new(T))
 		}
+		bigFloat = big.NewFloat(v)
+
+	case float32:
+		return convertNumericType[T](float64(v))
+
+	case int:
+		bigFloat = new(big.Float).SetInt64(int64(v))
+
+	case int32:
+		bigFloat = new(big.Float).SetInt64(int64(v))
+
+	case int64:
+		bigFloat = new(big.Float).SetInt64(v)
+
+	case uint:
+		bigFloat = new(big.Float).SetUint64(uint64(v))
+
+	case uint32:
+		bigFloat = new(big.Float).SetUint64(uint64(v))
+
+	case uint64:
+		bigFloat = new(big.Float).SetUint64(v)
 
-		f, _, err := big.ParseFloat(stringValue, 10, 64, 0)
+	case json.Number:
+		f, _, err := big.ParseFloat(v.String(), 10, 64, 0)
+		if err != nil {
+			return nil, fmt.Errorf("a %T value is required, but found invalid JSON number `%v`", *new(T), value)
+		}
+
+		bigFloat = f
+
+	case string:
+		f, _, err := big.ParseFloat(v, 10, 64, 0)
 		if err != nil {
 			return nil, fmt.Errorf("a %T value is required, but found invalid string value `%v`", *new(T), value)
 		}
 
 		bigFloat = f
+
+	default:
+		return nil, fmt.Errorf("a %T value is required, but found %T `%v`", *new(T), value, value)
 	}
 
 	// Convert the float to the int or uint if necessary.
@@ -49,7 +87,10 @@ func convertNumericType[T int64 | uint64 | float64](value any) (any, error) {
 			return nil, fmt.Errorf("a int value is required, but found numeric value `%s`", bigFloat.String())
 		}
 
-		numericValue, _ := bigFloat.Int64()
+		numericValue, accuracy := bigFloat.Int64()
+		if accuracy != big.Exact {
+			return nil, fmt.Errorf("a int value is required, but found out of range numeric value `%s`", bigFloat.String())
+		}
 		return numericValue, nil
 
 	case uint64:
@@ -57,11 +98,15 @@ func convertNumericType[T int64 | uint64 | float64](value any) (any, error) {
 			return nil, fmt.Errorf("a uint value is required, but found numeric value `%s`", bigFloat.String())
 		}
 
-		numericValue, _ := bigFloat.Int64()
-		if numericValue < 0 {
+		if bigFloat.Sign() < 0 {
 			return nil, fmt.Errorf("a uint value is required, but found int64 value `%s`", bigFloat.String())
 		}
-		return uint64(numericValue), nil
+
+		numericValue, accuracy := bigFloat.Uint64()
+		if accuracy != big.Exact {
+			return nil, fmt.Errorf("a uint value is required, but found out of range numeric value `%s`", bigFloat.String())
+		}
+		return numericValue, nil
 
 	case float64:
 		numericValue, _ := bigFloat.Float64()
@@ -72,6 +117,20 @@ func convertNumericType[T int64 | uint64 | float64](value any) (any, error) {
 	}
 }
 
+// convertDurationSeconds converts a numeric number of seconds into a duration.
+func convertDurationSeconds(value any) (any, error) {
+	seconds, err := convertNumericType[float64](value)
+	if err != nil {
+		return nil, err
+	}
+
+	nanoseconds := seconds.(float64) * float64(time.Second)
+	if math.IsNaN(nanoseconds) || nanoseconds > math.MaxInt64 || nanoseconds < math.MinInt64 {
+		return nil, fmt.Errorf("duration of `%v` seconds is out of range", value)
+	}
+	return time.Duration(nanoseconds), nil
+}
+
 var (
 	AnyType     = registerBasicType("any", cel.AnyType, func(value any) (any, error) { return value, nil })
 	BooleanType = registerBasicType("bool", cel.BoolType, requireType[bool])
@@ -80,7 +139,17 @@ var (
 	UIntType    = registerBasicType("uint", cel.IntType, convertNumericType[uint64])
 	DoubleType  = registerBasicType("double", cel.DoubleType, convertNumericType[float64])
 
+	// RelationshipCountType is the type of the parameters holding the number of relationships of
+	// a relation of the resource, populated when checking. It is an int within expressions.
+	RelationshipCountType = registerBasicType("relationship_count", cel.IntType, convertNumericType[int64])
+
+	// BytesType is the type of byte strings, given as a []byte or as a string in standard or
+	// URL-safe padded base64 encoding, as found in JSON.
 	BytesType = registerBasicType("bytes", cel.BytesType, func(value any) (any, error) {
+		if vle, ok := value.([]byte); ok {
+			return vle, nil
+		}
+
 		vle, ok := value.(string)
 		if !ok {
 			return nil, fmt.Errorf("bytes requires a base64 unicode string, found: %T `%v`", value, value)
@@ -88,27 +157,48 @@ var (
 
 		decoded, err := base64.StdEncoding.DecodeString(vle)
 		if err != nil {
-			return nil, fmt.Errorf("bytes requires a base64 encoded string: %w", err)
+			urlDecoded, urlErr := base64.URLEncoding.DecodeString(vle)
+			if urlErr != nil {
+				return nil, fmt.Errorf("bytes requires a base64 encoded string: %w", err)
+			}
+			return urlDecoded, nil
 		}
 
 		return decoded, nil
 	})
 
+	// DurationType is the type of durations, given as a Go duration string (e.g. `1h30m`) or as a
+	// number of seconds.
 	DurationType = registerBasicType("duration", cel.DurationType, func(value any) (any, error) {
-		vle, ok := value.(string)
-		if !ok {
-			return nil, fmt.Errorf("durations requires a duration string, found: %T", value)
-		}
+		switch vle := value.(type) {
+		case time.Duration:
+			return vle, nil
 
-		d, err := time.ParseDuration(vle)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse duration string `%s`: %w", vle, err)
-		}
+		case int:
+			return convertDurationSeconds(int64(vle))
 
-		return d, nil
+		case float64, int64, uint64:
+			return convertDurationSeconds(value)
+
+		case string:
+			d, err := time.ParseDuration(vle)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse duration string `%s`: %w", vle, err)
+			}
+			return d, nil
+
+		default:
+			return nil, fmt.Errorf("durations requires a duration string, found: %T", value)
+		}
 	})
 
+	// TimestampType is the type of timestamps, given as an RFC 3339 formatted string, with or
+	// without fractional seconds.
 	TimestampType = registerBasicType("timestamp", cel.TimestampType, func(value any) (any, error) {
+		if vle, ok := value.(time.Time); ok {
+			return vle, nil
+		}
+
 		vle, ok := value.(string)
 		if !ok {
 			return nil, fmt.Errorf("timestamps requires a RFC 3339 formatted timestamp string, found: %T `%v`", value, value)
@@ -0,0 +1,217 @@
+package types
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+const (
+	// bloomFilterVersion is the version of the serialized form of a bloom filter.
+	bloomFilterVersion = 1
+
+	// bloomFilterHeaderSize is the size of the serialized header: the version, the number of
+	// hash functions and the number of bits.
+	bloomFilterHeaderSize = 1 + 1 + 4
+
+	// maxBloomFilterHashes is the maximum number of hash functions in a bloom filter.
+	maxBloomFilterHashes = 32
+)
+
+var bloomFilterCelType = types.NewTypeValue("BloomFilter", traits.ReceiverType)
+
+// BloomFilter defines a custom type for a bloom filter over string ids, for membership checks
+// against sets too large to be passed as a list.
+//
+// A bloom filter has no false negatives: if an id was added to the filter, MaybeContains always
+// returns true. It may however have false positives: MaybeContains can return true for an id that
+// was never added, with a probability bounded by the false positive rate the filter was built
+// with, for up to the expected number of items. Caveats using a bloom filter should therefore only
+// be used where granting access to a small fraction of non-members is acceptable.
+type BloomFilter struct {
+	numHashes uint8
+	numBits   uint32
+	bits      []byte
+}
+
+// NewBloomFilter creates an empty bloom filter sized to hold the expected number of items with the
+// given false positive rate.
+func NewBloomFilter(expectedItems uint, falsePositiveRate float64) (*BloomFilter, error) {
+	if expectedItems == 0 {
+		return nil, fmt.Errorf("bloom filter requires at least one expected item")
+	}
+
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, fmt.Errorf("bloom filter false positive rate must be between 0 and 1, found %v", falsePositiveRate)
+	}
+
+	numBits := math.Ceil(-float64(expectedItems) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	if numBits > math.MaxUint32 {
+		return nil, fmt.Errorf("bloom filter for %d items with false positive rate %v is too large", expectedItems, falsePositiveRate)
+	}
+
+	numHashes := math.Round(numBits / float64(expectedItems) * math.Ln2)
+	numHashes = math.Max(1, math.Min(maxBloomFilterHashes, numHashes))
+
+	return &BloomFilter{
+		numHashes: uint8(numHashes),
+		numBits:   uint32(numBits),
+		bits:      make([]byte, (uint64(numBits)+7)/8),
+	}, nil
+}
+
+// Add adds the id to the bloom filter.
+func (bf *BloomFilter) Add(id string) {
+	h1, h2 := bloomFilterHashes(id)
+	for i := uint64(0); i < uint64(bf.numHashes); i++ {
+		bit := (h1 + i*h2) % uint64(bf.numBits)
+		bf.bits[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// MaybeContains returns false if the id is definitely not in the bloom filter, and true if the id
+// is probably in the bloom filter.
+func (bf BloomFilter) MaybeContains(id string) bool {
+	h1, h2 := bloomFilterHashes(id)
+	for i := uint64(0); i < uint64(bf.numHashes); i++ {
+		bit := (h1 + i*h2) % uint64(bf.numBits)
+		if bf.bits[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Serialize returns the compact serialized form of the bloom filter, as a base64 string suitable
+// for use as a caveat context value.
+func (bf BloomFilter) Serialize() string {
+	serialized := make([]byte, bloomFilterHeaderSize, bloomFilterHeaderSize+len(bf.bits))
+	serialized[0] = bloomFilterVersion
+	serialized[1] = bf.numHashes
+	binary.BigEndian.PutUint32(serialized[2:], bf.numBits)
+	serialized = append(serialized, bf.bits...)
+	return base64.StdEncoding.EncodeToString(serialized)
+}
+
+// ParseBloomFilter parses the serialized form of a bloom filter, as returned by Serialize.
+func ParseBloomFilter(serialized string) (BloomFilter, error) {
+	decoded, err := base64.StdEncoding.DecodeString(serialized)
+	if err != nil {
+		return BloomFilter{}, fmt.Errorf("bloom filter requires a base64 encoded string: %w", err)
+	}
+
+	if len(decoded) < bloomFilterHeaderSize {
+		return BloomFilter{}, fmt.Errorf("bloom filter is truncated")
+	}
+
+	if decoded[0] != bloomFilterVersion {
+		return BloomFilter{}, fmt.Errorf("unsupported bloom filter version %d", decoded[0])
+	}
+
+	numHashes := decoded[1]
+	if numHashes == 0 || numHashes > maxBloomFilterHashes {
+		return BloomFilter{}, fmt.Errorf("invalid bloom filter hash count %d", numHashes)
+	}
+
+	numBits := binary.BigEndian.Uint32(decoded[2:])
+	if numBits == 0 {
+		return BloomFilter{}, fmt.Errorf("bloom filter must have at least one bit")
+	}
+
+	bits := decoded[bloomFilterHeaderSize:]
+	if uint64(len(bits)) != (uint64(numBits)+7)/8 {
+		return BloomFilter{}, fmt.Errorf("bloom filter of %d bits requires %d bytes, found %d", numBits, (uint64(numBits)+7)/8, len(bits))
+	}
+
+	return BloomFilter{numHashes: numHashes, numBits: numBits, bits: bits}, nil
+}
+
+// bloomFilterHashes returns the two hashes of the id from which the bit indexes are derived, per
+// Kirsch and Mitzenmacher.
+func bloomFilterHashes(id string) (uint64, uint64) {
+	hasher := fnv.New128a()
+	_, _ = hasher.Write([]byte(id))
+	sum := hasher.Sum(nil)
+
+	// Ensure the second hash is odd, so the bit indexes do not repeat when the number of bits is even.
+	return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:]) | 1
+}
+
+func (bf BloomFilter) ConvertToNative(typeDesc reflect.Type) (interface{}, error) {
+	switch typeDesc {
+	case reflect.TypeOf(""):
+		return bf.Serialize(), nil
+	}
+	return nil, fmt.Errorf("type conversion error from 'BloomFilter' to '%v'", typeDesc)
+}
+
+func (bf BloomFilter) ConvertToType(typeVal ref.Type) ref.Val {
+	switch typeVal {
+	case types.StringType:
+		return types.String(bf.Serialize())
+	case types.TypeType:
+		return bloomFilterCelType
+	}
+	return types.NewErr("type conversion error from '%s' to '%s'", bloomFilterCelType, typeVal)
+}
+
+func (bf BloomFilter) Equal(other ref.Val) ref.Val {
+	o2, ok := other.(BloomFilter)
+	if !ok {
+		return types.ValOrErr(other, "no such overload")
+	}
+	return types.Bool(bf.numHashes == o2.numHashes && bf.numBits == o2.numBits && bytes.Equal(bf.bits, o2.bits))
+}
+
+func (bf BloomFilter) Type() ref.Type {
+	return bloomFilterCelType
+}
+
+func (bf BloomFilter) Value() interface{} {
+	return bf
+}
+
+var BloomFilterType = registerCustomType(
+	"bloomfilter",
+	cel.ObjectType("BloomFilter"),
+	func(value any) (any, error) {
+		filter, ok := value.(BloomFilter)
+		if ok {
+			return filter, nil
+		}
+
+		vle, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("bloomfilter requires a serialized bloom filter string, found: %T `%v`", value, value)
+		}
+
+		filter, err := ParseBloomFilter(vle)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse bloom filter: %w", err)
+		}
+
+		return filter, nil
+	},
+	cel.Function("maybe_contains",
+		cel.Overload("maybe_contains_bloomfilter_string",
+			[]*cel.Type{cel.ObjectType("BloomFilter"), cel.StringType},
+			cel.BoolType,
+			cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+				id, ok := rhs.Value().(string)
+				if !ok {
+					return types.NewErr("expected id string")
+				}
+
+				return types.Bool(lhs.(BloomFilter).MaybeContains(id))
+			}),
+		),
+	))
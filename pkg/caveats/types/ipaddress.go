@@ -51,12 +51,14 @@ func (ipa IPAddress) ConvertToType(typeVal ref.Type) ref.Val {
 	return types.NewErr("type conversion error from '%s' to '%s'", ipaddressCelType, typeVal)
 }
 
+// Equal returns whether the IP addresses are equal. An IPv4-mapped IPv6 address (e.g.
+// `::ffff:10.0.0.1`) is equal to the IPv4 address it maps.
 func (ipa IPAddress) Equal(other ref.Val) ref.Val {
 	o2, ok := other.(IPAddress)
 	if !ok {
 		return types.ValOrErr(other, "no such overload")
 	}
-	return types.Bool(ipa == o2)
+	return types.Bool(ipa.ip.Unmap() == o2.ip.Unmap())
 }
 
 func (ipa IPAddress) Type() ref.Type {
@@ -67,6 +69,11 @@ func (ipa IPAddress) Value() interface{} {
 	return ipa
 }
 
+// String returns the string form of the IP address.
+func (ipa IPAddress) String() string {
+	return ipa.ip.String()
+}
+
 var IPAddressType = registerCustomType(
 	"ipaddress",
 	cel.ObjectType("IPAddress"),
@@ -103,7 +110,13 @@ var IPAddressType = registerCustomType(
 					return types.NewErr("invalid CIDR string: `%s`", cidr)
 				}
 
-				return types.Bool(network.Contains(lhs.(IPAddress).ip))
+				// An IPv4-mapped IPv6 address is in the IPv4 networks containing the address it maps.
+				ip := lhs.(IPAddress).ip
+				if network.Addr().Is4() {
+					ip = ip.Unmap()
+				}
+
+				return types.Bool(network.Contains(ip))
 			}),
 		),
 	))
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// messageRegistry holds the protobuf message types registered by RegisterMessageType, to adapt
+// their values for CEL.
+var messageRegistry = func() ref.TypeRegistry {
+	registry, err := types.NewRegistry()
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}()
+
+// messageTypeNames holds the names of the message types registered by RegisterMessageType.
+var messageTypeNames = map[string]struct{}{}
+
+// IsMessageType returns whether the type name is that of a message type registered by
+// RegisterMessageType.
+func IsMessageType(typeName string) bool {
+	_, ok := messageTypeNames[typeName]
+	return ok
+}
+
+// RegisterMessageType registers the type of the protobuf message as a parameter type, named by
+// the full name of the message (e.g. `acme.v1.Request`), for parameters holding structured
+// objects. The fields of such parameters are typed as declared by the message, including nested
+// and repeated messages (e.g. `request.resource.tags[0].key`).
+//
+// Values are given either as a message of the type, or as their JSON form, as a string or as a
+// map as decoded by encoding/json, which is converted per the protobuf JSON mapping: unknown
+// fields are rejected. Absent fields, at any depth, read as their default values, as per
+// protobuf semantics, rather than making the evaluation partial; use `has()` to test for their
+// presence.
+//
+// Types must be registered during initialization, before compiling or decoding caveats using
+// them, as registration is not safe for concurrent use. Caveats whose parameters use a message
+// type can only be decoded in processes which registered it.
+func RegisterMessageType(message proto.Message) (VariableType, error) {
+	fullName := string(message.ProtoReflect().Descriptor().FullName())
+	if _, ok := definitions[fullName]; ok {
+		return VariableType{}, fmt.Errorf("type `%s` is already registered", fullName)
+	}
+
+	if err := messageRegistry.RegisterMessage(message); err != nil {
+		return VariableType{}, fmt.Errorf("could not register message type `%s`: %w", fullName, err)
+	}
+
+	converter := func(value any) (any, error) {
+		var encoded []byte
+		switch vle := value.(type) {
+		case proto.Message:
+			if found := string(vle.ProtoReflect().Descriptor().FullName()); found != fullName {
+				return nil, fmt.Errorf("%s requires a message of its type, found: %s", fullName, found)
+			}
+			return vle, nil
+
+		case string:
+			encoded = []byte(vle)
+
+		case map[string]any:
+			marshaled, err := json.Marshal(vle)
+			if err != nil {
+				return nil, fmt.Errorf("could not encode %s value: %w", fullName, err)
+			}
+			encoded = marshaled
+
+		default:
+			return nil, fmt.Errorf("%s requires a message, found: %T `%v`", fullName, value, value)
+		}
+
+		decoded := message.ProtoReflect().New().Interface()
+		if err := protojson.Unmarshal(encoded, decoded); err != nil {
+			return nil, fmt.Errorf("could not decode %s value: %w", fullName, err)
+		}
+		return decoded, nil
+	}
+
+	messageTypeNames[fullName] = struct{}{}
+	return registerCustomType(fullName, cel.ObjectType(fullName), converter, cel.Types(message)), nil
+}
+
+// MustRegisterMessageType registers the type of the protobuf message as a parameter type, as
+// RegisterMessageType, or panics.
+func MustRegisterMessageType(message proto.Message) VariableType {
+	varType, err := RegisterMessageType(message)
+	if err != nil {
+		panic(err)
+	}
+	return varType
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+var caveatDefinitionType = MustRegisterMessageType(&core.CaveatDefinition{})
+
+func TestRegisterMessageType(t *testing.T) {
+	require.Equal(t, "core.v1.CaveatDefinition", caveatDefinitionType.String())
+	require.True(t, IsMessageType("core.v1.CaveatDefinition"))
+	require.False(t, IsMessageType("int"))
+
+	_, err := RegisterMessageType(&core.CaveatDefinition{})
+	require.ErrorContains(t, err, "already registered")
+
+	// The type round-trips through its encoded form.
+	decoded, err := DecodeParameterType(EncodeParameterType(caveatDefinitionType))
+	require.NoError(t, err)
+	require.Equal(t, caveatDefinitionType.String(), decoded.String())
+
+	expected := &core.CaveatDefinition{
+		Name: "somecaveat",
+		ParameterTypes: map[string]*core.CaveatTypeReference{
+			"tags": {
+				TypeName:   "list",
+				ChildTypes: []*core.CaveatTypeReference{{TypeName: "string"}},
+			},
+		},
+	}
+
+	for _, value := range []any{
+		expected,
+		map[string]any{
+			"name": "somecaveat",
+			"parameterTypes": map[string]any{
+				"tags": map[string]any{
+					"typeName":   "list",
+					"childTypes": []any{map[string]any{"typeName": "string"}},
+				},
+			},
+		},
+		`{"name": "somecaveat", "parameter_types": {"tags": {"type_name": "list", "child_types": [{"type_name": "string"}]}}}`,
+	} {
+		converted, err := decoded.ConvertValue(value)
+		require.NoError(t, err)
+		require.True(t, proto.Equal(expected, converted.(proto.Message)), "mismatch for %v", value)
+	}
+
+	_, err = decoded.ConvertValue(map[string]any{"unknown": true})
+	require.Error(t, err)
+
+	_, err = decoded.ConvertValue(&core.RelationTuple{})
+	require.ErrorContains(t, err, "requires a message of its type")
+
+	_, err = decoded.ConvertValue(42)
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -101,6 +102,62 @@ func TestConversion(t *testing.T) {
 			expectedValue: int64(42),
 			expectedErr:   "for int: a int value is required, but found numeric value `42.1`",
 		},
+		{
+			name:          "JSON number to int",
+			vtype:         IntType,
+			inputValue:    json.Number("42"),
+			expectedValue: int64(42),
+			expectedErr:   "",
+		},
+		{
+			name:          "JSON number with exponent to uint",
+			vtype:         UIntType,
+			inputValue:    json.Number("1e3"),
+			expectedValue: uint64(1000),
+			expectedErr:   "",
+		},
+		{
+			name:          "JSON number to double",
+			vtype:         DoubleType,
+			inputValue:    json.Number("42.5"),
+			expectedValue: 42.5,
+			expectedErr:   "",
+		},
+		{
+			name:          "fractional JSON number to int",
+			vtype:         IntType,
+			inputValue:    json.Number("42.5"),
+			expectedValue: nil,
+			expectedErr:   "for int: a int value is required, but found numeric value `42.5`",
+		},
+		{
+			name:          "invalid JSON number to int",
+			vtype:         IntType,
+			inputValue:    json.Number("forty-two"),
+			expectedValue: nil,
+			expectedErr:   "for int: a int64 value is required, but found invalid JSON number `forty-two`",
+		},
+		{
+			name:          "out of range float to int",
+			vtype:         IntType,
+			inputValue:    1e19,
+			expectedValue: nil,
+			expectedErr:   "for int: a int value is required, but found out of range numeric value `1e+19`",
+		},
+		{
+			name:          "Go int to int",
+			vtype:         IntType,
+			inputValue:    42,
+			expectedValue: int64(42),
+			expectedErr:   "",
+		},
+		{
+			name:          "Go uint32 to uint",
+			vtype:         UIntType,
+			inputValue:    uint32(42),
+			expectedValue: uint64(42),
+			expectedErr:   "",
+		},
 		{
 			name:          "negative float to int",
 			vtype:         IntType,
@@ -195,6 +252,27 @@ func TestConversion(t *testing.T) {
 			expectedValue: []byte{1, 2, 3, 42},
 			expectedErr:   "",
 		},
+		{
+			name:          "valid URL-safe bytes",
+			vtype:         BytesType,
+			inputValue:    "-_8=",
+			expectedValue: []byte{0xfb, 0xff},
+			expectedErr:   "",
+		},
+		{
+			name:          "valid standard bytes",
+			vtype:         BytesType,
+			inputValue:    "+/8=",
+			expectedValue: []byte{0xfb, 0xff},
+			expectedErr:   "",
+		},
+		{
+			name:          "raw bytes",
+			vtype:         BytesType,
+			inputValue:    []byte{1, 2, 3},
+			expectedValue: []byte{1, 2, 3},
+			expectedErr:   "",
+		},
 		{
 			name:          "invalid type for bytes",
 			vtype:         BytesType,
@@ -223,6 +301,55 @@ func TestConversion(t *testing.T) {
 			expectedValue: nil,
 			expectedErr:   "for duration: could not parse duration string `10hm`: time: unknown unit \"hm\" in duration \"10hm\"",
 		},
+		{
+			name:          "duration in seconds",
+			vtype:         DurationType,
+			inputValue:    90.5,
+			expectedValue: 90*time.Second + 500*time.Millisecond,
+			expectedErr:   "",
+		},
+		{
+			name:          "duration in integer seconds",
+			vtype:         DurationType,
+			inputValue:    int64(60),
+			expectedValue: time.Minute,
+			expectedErr:   "",
+		},
+		{
+			name:          "duration value",
+			vtype:         DurationType,
+			inputValue:    time.Hour,
+			expectedValue: time.Hour,
+			expectedErr:   "",
+		},
+		{
+			name:          "out of range duration",
+			vtype:         DurationType,
+			inputValue:    1e20,
+			expectedValue: nil,
+			expectedErr:   "for duration: duration of `1e+20` seconds is out of range",
+		},
+		{
+			name:          "invalid duration value",
+			vtype:         DurationType,
+			inputValue:    true,
+			expectedValue: nil,
+			expectedErr:   "for duration: durations requires a duration string, found: bool",
+		},
+		{
+			name:          "timestamp with fractional seconds",
+			vtype:         TimestampType,
+			inputValue:    "2023-01-22T01:13:00.123456789Z",
+			expectedValue: time.Date(2023, time.January, 22, 1, 13, 0, 123456789, time.UTC),
+			expectedErr:   "",
+		},
+		{
+			name:          "timestamp value",
+			vtype:         TimestampType,
+			inputValue:    time.Date(2023, time.January, 22, 1, 13, 0, 0, time.UTC),
+			expectedValue: time.Date(2023, time.January, 22, 1, 13, 0, 0, time.UTC),
+			expectedErr:   "",
+		},
 		{
 			name:          "valid timestamp",
 			vtype:         TimestampType,
@@ -279,6 +406,20 @@ func TestConversion(t *testing.T) {
 			expectedValue: []any{MustParseIPAddress("1.2.3.4"), MustParseIPAddress("4.5.6.7")},
 			expectedErr:   "",
 		},
+		{
+			name:          "invalid bloomfilter",
+			vtype:         BloomFilterType,
+			inputValue:    "AQEAAAAI",
+			expectedValue: nil,
+			expectedErr:   "for bloomfilter: could not parse bloom filter: bloom filter of 8 bits requires 1 bytes, found 0",
+		},
+		{
+			name:          "invalid bloomfilter type",
+			vtype:         BloomFilterType,
+			inputValue:    42.0,
+			expectedValue: nil,
+			expectedErr:   "for bloomfilter: bloomfilter requires a serialized bloom filter string, found: float64 `42`",
+		},
 	}
 
 	for _, tc := range tcs {
@@ -1,6 +1,7 @@
 package caveats
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -29,6 +30,58 @@ func TestIPAddress(t *testing.T) {
 	require.False(t, result.Value())
 }
 
+func TestIPAddressIPv6AndEquality(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"user_ip":    types.IPAddressType,
+		"allowed_ip": types.IPAddressType,
+	})
+	parameterTypes := env.EncodedParametersTypes()
+
+	tcs := []struct {
+		exprString string
+		context    map[string]any
+		expected   bool
+	}{
+		{"user_ip.in_cidr('2001:db8::/32')", map[string]any{"user_ip": "2001:db8::1"}, true},
+		{"user_ip.in_cidr('2001:db8::/32')", map[string]any{"user_ip": "2001:db9::1"}, false},
+		{"user_ip.in_cidr('2001:db8::/32')", map[string]any{"user_ip": "10.0.0.1"}, false},
+		{"user_ip.in_cidr('10.0.0.0/8')", map[string]any{"user_ip": "::ffff:10.0.0.1"}, true},
+		{"user_ip == allowed_ip", map[string]any{"user_ip": "10.0.0.1", "allowed_ip": "10.0.0.1"}, true},
+		{"user_ip == allowed_ip", map[string]any{"user_ip": "10.0.0.1", "allowed_ip": "10.0.0.2"}, false},
+		{"user_ip == allowed_ip", map[string]any{"user_ip": "2001:db8::1", "allowed_ip": "2001:0db8:0000::1"}, true},
+		{"user_ip == allowed_ip", map[string]any{"user_ip": "::ffff:10.0.0.1", "allowed_ip": "10.0.0.1"}, true},
+		{"user_ip != allowed_ip", map[string]any{"user_ip": "10.0.0.1", "allowed_ip": "2001:db8::1"}, true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("%s %v", tc.exprString, tc.context), func(t *testing.T) {
+			compiled, err := compileCaveat(env, tc.exprString)
+			require.NoError(t, err)
+
+			typedParameters, err := ConvertContextToParameters(tc.context, parameterTypes, ErrorForUnknownParameters)
+			require.NoError(t, err)
+
+			result, err := EvaluateCaveat(compiled, typedParameters)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result.Value())
+		})
+	}
+}
+
+func TestIPAddressMalformedContextValue(t *testing.T) {
+	parameterTypes := MustEnvForVariables(map[string]types.VariableType{
+		"user_ip": types.IPAddressType,
+	}).EncodedParametersTypes()
+
+	_, err := ConvertContextToParameters(map[string]any{"user_ip": "10.0.0"}, parameterTypes, ErrorForUnknownParameters)
+	require.Error(t, err)
+
+	var conversionErr ParameterConversionErr
+	require.ErrorAs(t, err, &conversionErr)
+	require.Equal(t, "user_ip", conversionErr.ParameterName())
+	require.Contains(t, err.Error(), "could not parse ip address string `10.0.0`")
+}
+
 func TestIPAddressInvalidCIDR(t *testing.T) {
 	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
 		"user_ip": types.IPAddressType,
@@ -40,5 +93,86 @@ func TestIPAddressInvalidCIDR(t *testing.T) {
 		"user_ip": parsed,
 	})
 	require.Error(t, err)
-	require.Equal(t, "invalid CIDR string: `invalidcidr`", err.Error())
+	require.Equal(t, "caveat `caveat` could not be evaluated: invalid CIDR string: `invalidcidr`", err.Error())
+}
+
+func TestBloomFilter(t *testing.T) {
+	compiled, err := compileCaveat(MustEnvForVariables(map[string]types.VariableType{
+		"allowed": types.BloomFilterType,
+		"user_id": types.StringType,
+	}), "maybe_contains(allowed, user_id)")
+	require.NoError(t, err)
+
+	filter, err := types.NewBloomFilter(1000, 0.000001)
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		filter.Add(fmt.Sprintf("member-%d", i))
+	}
+
+	// The filter is passed in the context in its serialized form.
+	allowed, err := types.BloomFilterType.ConvertValue(filter.Serialize())
+	require.NoError(t, err)
+
+	for _, member := range []string{"member-0", "member-500", "member-999"} {
+		result, err := EvaluateCaveat(compiled, map[string]any{
+			"allowed": allowed,
+			"user_id": member,
+		})
+		require.NoError(t, err)
+		require.True(t, result.Value(), "expected %s to be a member", member)
+	}
+
+	for _, nonMember := range []string{"member-1000", "tom", "sarah", ""} {
+		result, err := EvaluateCaveat(compiled, map[string]any{
+			"allowed": allowed,
+			"user_id": nonMember,
+		})
+		require.NoError(t, err)
+		require.False(t, result.Value(), "expected %s to not be a member", nonMember)
+	}
+}
+
+func TestBloomFilterRoundTrip(t *testing.T) {
+	filter, err := types.NewBloomFilter(10, 0.01)
+	require.NoError(t, err)
+	filter.Add("tom")
+
+	parsed, err := types.ParseBloomFilter(filter.Serialize())
+	require.NoError(t, err)
+	require.True(t, parsed.MaybeContains("tom"))
+	require.Equal(t, filter.Serialize(), parsed.Serialize())
+
+	_, err = types.NewBloomFilter(0, 0.01)
+	require.ErrorContains(t, err, "at least one expected item")
+
+	_, err = types.NewBloomFilter(10, 1)
+	require.ErrorContains(t, err, "false positive rate must be between 0 and 1")
+
+	_, err = types.ParseBloomFilter("not base64!")
+	require.ErrorContains(t, err, "base64 encoded string")
+}
+
+func TestBytesContextValues(t *testing.T) {
+	env := MustEnvForVariables(map[string]types.VariableType{
+		"token": types.BytesType,
+	})
+	parameterTypes := env.EncodedParametersTypes()
+
+	compiled, err := compileCaveat(env, "token == b'\\xfb\\xff'")
+	require.NoError(t, err)
+
+	for _, value := range []any{"+/8=", "-_8=", []byte{0xfb, 0xff}} {
+		typedParameters, err := ConvertContextToParameters(map[string]any{"token": value}, parameterTypes, ErrorForUnknownParameters)
+		require.NoError(t, err)
+
+		result, err := EvaluateCaveat(compiled, typedParameters)
+		require.NoError(t, err)
+		require.True(t, result.Value())
+	}
+
+	_, err = ConvertContextToParameters(map[string]any{"token": "not base64!"}, parameterTypes, ErrorForUnknownParameters)
+	var conversionErr ParameterConversionErr
+	require.ErrorAs(t, err, &conversionErr)
+	require.Equal(t, "token", conversionErr.ParameterName())
+	require.Contains(t, err.Error(), "bytes requires a base64 encoded string")
 }
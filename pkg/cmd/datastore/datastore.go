@@ -68,15 +68,20 @@ type Config struct {
 	RequestHedgingQuantile         float64
 
 	// CRDB
-	FollowerReadDelay time.Duration
-	MaxRetries        int
-	OverlapKey        string
-	OverlapStrategy   string
+	FollowerReadDelay   time.Duration
+	EnableFollowerReads bool
+	MaxRetries          int
+	OverlapKey          string
+	OverlapStrategy     string
 
 	// Postgres
-	HealthCheckPeriod  time.Duration
-	GCInterval         time.Duration
-	GCMaxOperationTime time.Duration
+	HealthCheckPeriod   time.Duration
+	GCInterval          time.Duration
+	GCMaxOperationTime  time.Duration
+	GCBatchSize         uint64
+	PrewarmConns        int
+	PrewarmTimeout      time.Duration
+	AllowPrewarmFailure bool
 
 	// Spanner
 	SpannerCredentialsFile string
@@ -87,6 +92,7 @@ type Config struct {
 
 	// Internal
 	WatchBufferLength uint16
+	OperationObserver datastore.OperationObserver
 
 	// Migrations
 	MigrationPhase string
@@ -118,6 +124,10 @@ func RegisterDatastoreFlagsWithPrefix(flagSet *pflag.FlagSet, prefix string, opt
 	flagSet.DurationVar(&opts.GCWindow, flagName("datastore-gc-window"), defaults.GCWindow, "amount of time before revisions are garbage collected")
 	flagSet.DurationVar(&opts.GCInterval, flagName("datastore-gc-interval"), defaults.GCInterval, "amount of time between passes of garbage collection (postgres driver only)")
 	flagSet.DurationVar(&opts.GCMaxOperationTime, flagName("datastore-gc-max-operation-time"), defaults.GCMaxOperationTime, "maximum amount of time a garbage collection pass can operate before timing out (postgres driver only)")
+	flagSet.Uint64Var(&opts.GCBatchSize, flagName("datastore-gc-batch-size"), defaults.GCBatchSize, "maximum number of rows deleted by each statement of a garbage collection pass (postgres and mysql drivers only)")
+	flagSet.IntVar(&opts.PrewarmConns, flagName("datastore-conn-pool-prewarm-count"), defaults.PrewarmConns, "number of connections to open and validate on startup, failing startup if they cannot be established unless pre-warm failures are allowed (postgres driver only)")
+	flagSet.DurationVar(&opts.PrewarmTimeout, flagName("datastore-conn-pool-prewarm-timeout"), defaults.PrewarmTimeout, "maximum amount of time to open and validate the pre-warmed connections on startup (postgres driver only)")
+	flagSet.BoolVar(&opts.AllowPrewarmFailure, flagName("datastore-conn-pool-prewarm-allow-failure"), defaults.AllowPrewarmFailure, "start with a warning, rather than fail, if the pre-warmed connections cannot all be established (postgres driver only)")
 	flagSet.DurationVar(&opts.RevisionQuantization, flagName("datastore-revision-quantization-interval"), defaults.RevisionQuantization, "boundary interval to which to round the quantized revision")
 	flagSet.BoolVar(&opts.ReadOnly, flagName("datastore-readonly"), defaults.ReadOnly, "set the service to read-only mode")
 	flagSet.StringSliceVar(&opts.BootstrapFiles, flagName("datastore-bootstrap-files"), defaults.BootstrapFiles, "bootstrap data yaml files to load")
@@ -130,6 +140,7 @@ func RegisterDatastoreFlagsWithPrefix(flagSet *pflag.FlagSet, prefix string, opt
 	flagSet.BoolVar(&opts.EnableDatastoreMetrics, flagName("datastore-prometheus-metrics"), defaults.EnableDatastoreMetrics, "set to false to disabled prometheus metrics from the datastore")
 	// See crdb doc for info about follower reads and how it is configured: https://www.cockroachlabs.com/docs/stable/follower-reads.html
 	flagSet.DurationVar(&opts.FollowerReadDelay, flagName("datastore-follower-read-delay-duration"), 4_800*time.Millisecond, "amount of time to subtract from non-sync revision timestamps to ensure they are sufficiently in the past to enable follower reads (cockroach driver only)")
+	flagSet.BoolVar(&opts.EnableFollowerReads, flagName("datastore-follower-reads-enabled"), false, "base non-sync revisions on the cluster's follower read timestamp, so that reads at them are served by the nearest replica (cockroach driver only)")
 	flagSet.Uint16Var(&opts.SplitQueryCount, flagName("datastore-query-userset-batch-size"), 1024, "number of usersets after which a relationship query will be split into multiple queries")
 	flagSet.IntVar(&opts.MaxRetries, flagName("datastore-max-tx-retries"), 10, "number of times a retriable transaction should be retried")
 	flagSet.StringVar(&opts.OverlapStrategy, flagName("datastore-tx-overlap-strategy"), "static", `strategy to generate transaction overlap keys ("prefix", "static", "insecure") (cockroach driver only)`)
@@ -172,6 +183,8 @@ func DefaultDatastoreConfig() *Config {
 		HealthCheckPeriod:              30 * time.Second,
 		GCInterval:                     3 * time.Minute,
 		GCMaxOperationTime:             1 * time.Minute,
+		GCBatchSize:                    1000,
+		PrewarmTimeout:                 10 * time.Second,
 		WatchBufferLength:              1024,
 		EnableDatastoreMetrics:         true,
 		DisableStats:                   false,
@@ -275,12 +288,14 @@ func newCRDBDatastore(opts Config) (datastore.Datastore, error) {
 		crdb.MinOpenConns(opts.MinOpenConns),
 		crdb.SplitAtUsersetCount(opts.SplitQueryCount),
 		crdb.FollowerReadDelay(opts.FollowerReadDelay),
+		crdb.EnableFollowerReads(opts.EnableFollowerReads),
 		crdb.MaxRetries(uint8(opts.MaxRetries)),
 		crdb.OverlapKey(opts.OverlapKey),
 		crdb.OverlapStrategy(opts.OverlapStrategy),
 		crdb.WatchBufferLength(opts.WatchBufferLength),
 		crdb.DisableStats(opts.DisableStats),
 		crdb.WithEnablePrometheusStats(opts.EnableDatastoreMetrics),
+		crdb.WithOperationObserver(opts.OperationObserver),
 	)
 }
 
@@ -297,11 +312,16 @@ func newPostgresDatastore(opts Config) (datastore.Datastore, error) {
 		postgres.HealthCheckPeriod(opts.HealthCheckPeriod),
 		postgres.GCInterval(opts.GCInterval),
 		postgres.GCMaxOperationTime(opts.GCMaxOperationTime),
+		postgres.GCBatchSize(opts.GCBatchSize),
+		postgres.PrewarmConns(opts.PrewarmConns),
+		postgres.PrewarmTimeout(opts.PrewarmTimeout),
+		postgres.AllowPrewarmFailure(opts.AllowPrewarmFailure),
 		postgres.EnableTracing(),
 		postgres.WatchBufferLength(opts.WatchBufferLength),
 		postgres.WithEnablePrometheusStats(opts.EnableDatastoreMetrics),
 		postgres.MaxRetries(uint8(opts.MaxRetries)),
 		postgres.MigrationPhase(opts.MigrationPhase),
+		postgres.WithOperationObserver(opts.OperationObserver),
 	}
 	return postgres.NewPostgresDatastore(opts.URI, pgOpts...)
 }
@@ -326,6 +346,7 @@ func newMySQLDatastore(opts Config) (datastore.Datastore, error) {
 		mysql.GCInterval(opts.GCInterval),
 		mysql.GCEnabled(!opts.ReadOnly),
 		mysql.GCMaxOperationTime(opts.GCMaxOperationTime),
+		mysql.GCBatchSize(opts.GCBatchSize),
 		mysql.ConnMaxIdleTime(opts.MaxIdleTime),
 		mysql.ConnMaxLifetime(opts.MaxLifetime),
 		mysql.MaxOpenConns(opts.MaxOpenConns),
@@ -1,7 +1,10 @@
 // Code generated by github.com/ecordell/optgen. DO NOT EDIT.
 package datastore
 
-import "time"
+import (
+	datastore "github.com/authzed/spicedb/pkg/datastore"
+	"time"
+)
 
 type ConfigOption func(c *Config)
 
@@ -38,16 +41,22 @@ func (c *Config) ToOption() ConfigOption {
 		to.RequestHedgingMaxRequests = c.RequestHedgingMaxRequests
 		to.RequestHedgingQuantile = c.RequestHedgingQuantile
 		to.FollowerReadDelay = c.FollowerReadDelay
+		to.EnableFollowerReads = c.EnableFollowerReads
 		to.MaxRetries = c.MaxRetries
 		to.OverlapKey = c.OverlapKey
 		to.OverlapStrategy = c.OverlapStrategy
 		to.HealthCheckPeriod = c.HealthCheckPeriod
 		to.GCInterval = c.GCInterval
 		to.GCMaxOperationTime = c.GCMaxOperationTime
+		to.GCBatchSize = c.GCBatchSize
+		to.PrewarmConns = c.PrewarmConns
+		to.PrewarmTimeout = c.PrewarmTimeout
+		to.AllowPrewarmFailure = c.AllowPrewarmFailure
 		to.SpannerCredentialsFile = c.SpannerCredentialsFile
 		to.SpannerEmulatorHost = c.SpannerEmulatorHost
 		to.TablePrefix = c.TablePrefix
 		to.WatchBufferLength = c.WatchBufferLength
+		to.OperationObserver = c.OperationObserver
 		to.MigrationPhase = c.MigrationPhase
 	}
 }
@@ -214,6 +223,13 @@ func WithFollowerReadDelay(followerReadDelay time.Duration) ConfigOption {
 	}
 }
 
+// WithEnableFollowerReads returns an option that can set EnableFollowerReads on a Config
+func WithEnableFollowerReads(enableFollowerReads bool) ConfigOption {
+	return func(c *Config) {
+		c.EnableFollowerReads = enableFollowerReads
+	}
+}
+
 // WithMaxRetries returns an option that can set MaxRetries on a Config
 func WithMaxRetries(maxRetries int) ConfigOption {
 	return func(c *Config) {
@@ -256,6 +272,34 @@ func WithGCMaxOperationTime(gCMaxOperationTime time.Duration) ConfigOption {
 	}
 }
 
+// WithGCBatchSize returns an option that can set GCBatchSize on a Config
+func WithGCBatchSize(gCBatchSize uint64) ConfigOption {
+	return func(c *Config) {
+		c.GCBatchSize = gCBatchSize
+	}
+}
+
+// WithPrewarmConns returns an option that can set PrewarmConns on a Config
+func WithPrewarmConns(prewarmConns int) ConfigOption {
+	return func(c *Config) {
+		c.PrewarmConns = prewarmConns
+	}
+}
+
+// WithPrewarmTimeout returns an option that can set PrewarmTimeout on a Config
+func WithPrewarmTimeout(prewarmTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.PrewarmTimeout = prewarmTimeout
+	}
+}
+
+// WithAllowPrewarmFailure returns an option that can set AllowPrewarmFailure on a Config
+func WithAllowPrewarmFailure(allowPrewarmFailure bool) ConfigOption {
+	return func(c *Config) {
+		c.AllowPrewarmFailure = allowPrewarmFailure
+	}
+}
+
 // WithSpannerCredentialsFile returns an option that can set SpannerCredentialsFile on a Config
 func WithSpannerCredentialsFile(spannerCredentialsFile string) ConfigOption {
 	return func(c *Config) {
@@ -284,6 +328,13 @@ func WithWatchBufferLength(watchBufferLength uint16) ConfigOption {
 	}
 }
 
+// WithOperationObserver returns an option that can set OperationObserver on a Config
+func WithOperationObserver(operationObserver datastore.OperationObserver) ConfigOption {
+	return func(c *Config) {
+		c.OperationObserver = operationObserver
+	}
+}
+
 // WithMigrationPhase returns an option that can set MigrationPhase on a Config
 func WithMigrationPhase(migrationPhase string) ConfigOption {
 	return func(c *Config) {
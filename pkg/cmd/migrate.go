@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/fatih/color"
@@ -27,6 +28,8 @@ func RegisterMigrateFlags(cmd *cobra.Command) {
 	cmd.Flags().String("datastore-mysql-table-prefix", "", "prefix to add to the name of all mysql database tables")
 	cmd.Flags().Uint64("migration-backfill-batch-size", 1000, "number of items to migrate per iteration of a datastore backfill")
 	cmd.Flags().Duration("migration-timeout", 1*time.Hour, "defines a timeout for the execution of the migration, set to 1 hour by default")
+	cmd.Flags().Bool("migration-dry-run", false, "print the statements the migrations would execute instead of running them (postgres only)")
+	cmd.Flags().Bool("migration-rebaseline-checksums", false, "accept changes to already applied migrations by replacing their stored checksums before migrating (postgres only)")
 }
 
 func NewMigrateCommand(programName string) *cobra.Command {
@@ -45,6 +48,8 @@ func migrateRun(cmd *cobra.Command, args []string) error {
 	dbURL := cobrautil.MustGetStringExpanded(cmd, "datastore-conn-uri")
 	timeout := cobrautil.MustGetDuration(cmd, "migration-timeout")
 	migrationBatachSize := cobrautil.MustGetUint64(cmd, "migration-backfill-batch-size")
+	dryRun := cobrautil.MustGetBool(cmd, "migration-dry-run")
+	rebaselineChecksums := cobrautil.MustGetBool(cmd, "migration-rebaseline-checksums")
 
 	if datastoreEngine == "cockroachdb" {
 		log.Ctx(cmd.Context()).Info().Msg("migrating cockroachdb datastore")
@@ -54,7 +59,7 @@ func migrateRun(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("unable to create migration driver for %s: %w", datastoreEngine, err)
 		}
-		return runMigration(cmd.Context(), migrationDriver, crdbmigrations.CRDBMigrations, args[0], timeout, migrationBatachSize)
+		return runMigration(cmd.Context(), migrationDriver, crdbmigrations.CRDBMigrations, args[0], timeout, migrationBatachSize, dryRun, rebaselineChecksums)
 	} else if datastoreEngine == "postgres" {
 		log.Ctx(cmd.Context()).Info().Msg("migrating postgres datastore")
 
@@ -63,7 +68,7 @@ func migrateRun(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("unable to create migration driver for %s: %w", datastoreEngine, err)
 		}
-		return runMigration(cmd.Context(), migrationDriver, migrations.DatabaseMigrations, args[0], timeout, migrationBatachSize)
+		return runMigration(cmd.Context(), migrationDriver, migrations.DatabaseMigrations, args[0], timeout, migrationBatachSize, dryRun, rebaselineChecksums)
 	} else if datastoreEngine == "spanner" {
 		log.Ctx(cmd.Context()).Info().Msg("migrating spanner datastore")
 
@@ -77,7 +82,7 @@ func migrateRun(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("unable to create migration driver for %s: %w", datastoreEngine, err)
 		}
-		return runMigration(cmd.Context(), migrationDriver, spannermigrations.SpannerMigrations, args[0], timeout, migrationBatachSize)
+		return runMigration(cmd.Context(), migrationDriver, spannermigrations.SpannerMigrations, args[0], timeout, migrationBatachSize, dryRun, rebaselineChecksums)
 	} else if datastoreEngine == "mysql" {
 		log.Ctx(cmd.Context()).Info().Msg("migrating mysql datastore")
 
@@ -91,7 +96,7 @@ func migrateRun(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("unable to create migration driver for %s: %w", datastoreEngine, err)
 		}
-		return runMigration(cmd.Context(), migrationDriver, mysqlmigrations.Manager, args[0], timeout, migrationBatachSize)
+		return runMigration(cmd.Context(), migrationDriver, mysqlmigrations.Manager, args[0], timeout, migrationBatachSize, dryRun, rebaselineChecksums)
 	}
 
 	return fmt.Errorf("cannot migrate datastore engine type: %s", datastoreEngine)
@@ -104,12 +109,36 @@ func runMigration[D migrate.Driver[C, T], C any, T any](
 	targetRevision string,
 	timeout time.Duration,
 	backfillBatchSize uint64,
+	dryRun bool,
+	rebaselineChecksums bool,
 ) error {
 	log.Ctx(ctx).Info().Str("targetRevision", targetRevision).Msg("running migrations")
-	ctxWithBatch := context.WithValue(ctx, migrate.BackfillBatchSize, backfillBatchSize)
-	ctx, cancel := context.WithTimeout(ctxWithBatch, timeout)
+	ctxWithProgress := context.WithValue(ctx, migrate.BackfillProgress, migrate.BackfillProgressFunc(
+		func(migrationName string, rowsProcessed, rowsTotalEstimate uint64) {
+			log.Ctx(ctx).Info().
+				Str("migration", migrationName).
+				Uint64("processed", rowsProcessed).
+				Uint64("totalEstimate", rowsTotalEstimate).
+				Msg("backfill progress")
+		},
+	))
+	ctx, cancel := context.WithTimeout(ctxWithProgress, timeout)
 	defer cancel()
-	if err := manager.Run(ctx, driver, targetRevision, migrate.LiveRun); err != nil {
+	if rebaselineChecksums && !dryRun {
+		if err := manager.RebaselineChecksums(ctx, driver); err != nil {
+			return fmt.Errorf("unable to re-baseline migration checksums: %w", err)
+		}
+	}
+
+	if dryRun {
+		steps, err := manager.DryRun(ctx, driver, targetRevision)
+		if err != nil {
+			return fmt.Errorf("unable to dry-run migrations to `%s` revision: %w", targetRevision, err)
+		}
+		if err := migrate.WriteDryRunSteps(os.Stdout, steps); err != nil {
+			return err
+		}
+	} else if err := manager.Run(ctx, driver, targetRevision, migrate.LiveRun, migrate.WithBackfillBatchSize(backfillBatchSize)); err != nil {
 		return fmt.Errorf("unable to migrate to `%s` revision: %w", targetRevision, err)
 	}
 
@@ -119,6 +119,7 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	cmd.Flags().DurationVar(&config.TelemetryInterval, "telemetry-interval", telemetry.DefaultInterval, "approximate period between telemetry reports, minimum 1 minute")
 
 	cmd.Flags().BoolVar(&config.ExperimentalCaveatsEnabled, "experiment-enable-caveats", false, "if true, experimental support for caveats is enabled; note that these are not fully implemented and may break")
+	cmd.Flags().StringSliceVar(&config.DebugMaskedCaveatParameters, "debug-masked-caveat-parameters", nil, "names of the caveat parameters whose values are masked in debug information")
 	return nil
 }
 
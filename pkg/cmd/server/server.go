@@ -35,6 +35,7 @@ import (
 	v1svc "github.com/authzed/spicedb/internal/services/v1"
 	"github.com/authzed/spicedb/internal/telemetry"
 	"github.com/authzed/spicedb/pkg/balancer"
+	"github.com/authzed/spicedb/pkg/caveats"
 	datastorecfg "github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/util"
 	"github.com/authzed/spicedb/pkg/datastore"
@@ -87,6 +88,10 @@ type Config struct {
 	MaximumPreconditionCount   uint16
 	ExperimentalCaveatsEnabled bool
 
+	// DebugMaskedCaveatParameters are the names of the caveat parameters whose values are masked
+	// in debug information.
+	DebugMaskedCaveatParameters []string
+
 	// Additional Services
 	DashboardAPI util.HTTPServerConfig
 	MetricsAPI   util.HTTPServerConfig
@@ -307,6 +312,9 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		MaxUpdatesPerWrite:    c.MaximumUpdatesPerWrite,
 		MaximumAPIDepth:       c.DispatchMaxDepth,
 	}
+	if len(c.DebugMaskedCaveatParameters) > 0 {
+		permSysConfig.DebugContextMask = caveats.NewContextMask(c.DebugMaskedCaveatParameters...)
+	}
 
 	caveatsOption := services.CaveatsDisabled
 	if c.ExperimentalCaveatsEnabled {
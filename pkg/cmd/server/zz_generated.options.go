@@ -56,6 +56,7 @@ func (c *Config) ToOption() ConfigOption {
 		to.MaximumUpdatesPerWrite = c.MaximumUpdatesPerWrite
 		to.MaximumPreconditionCount = c.MaximumPreconditionCount
 		to.ExperimentalCaveatsEnabled = c.ExperimentalCaveatsEnabled
+		to.DebugMaskedCaveatParameters = c.DebugMaskedCaveatParameters
 		to.DashboardAPI = c.DashboardAPI
 		to.MetricsAPI = c.MetricsAPI
 		to.MiddlewareModification = c.MiddlewareModification
@@ -300,6 +301,20 @@ func WithExperimentalCaveatsEnabled(experimentalCaveatsEnabled bool) ConfigOptio
 	}
 }
 
+// WithDebugMaskedCaveatParameters returns an option that can append DebugMaskedCaveatParameterss to Config.DebugMaskedCaveatParameters
+func WithDebugMaskedCaveatParameters(debugMaskedCaveatParameters string) ConfigOption {
+	return func(c *Config) {
+		c.DebugMaskedCaveatParameters = append(c.DebugMaskedCaveatParameters, debugMaskedCaveatParameters)
+	}
+}
+
+// SetDebugMaskedCaveatParameters returns an option that can set DebugMaskedCaveatParameters on a Config
+func SetDebugMaskedCaveatParameters(debugMaskedCaveatParameters []string) ConfigOption {
+	return func(c *Config) {
+		c.DebugMaskedCaveatParameters = debugMaskedCaveatParameters
+	}
+}
+
 // WithDashboardAPI returns an option that can set DashboardAPI on a Config
 func WithDashboardAPI(dashboardAPI util.HTTPServerConfig) ConfigOption {
 	return func(c *Config) {
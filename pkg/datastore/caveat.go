@@ -12,7 +12,9 @@ type CaveatReader interface {
 	ReadCaveatByName(ctx context.Context, name string) (*core.CaveatDefinition, Revision, error)
 
 	// ListCaveats returns all caveats stored in the system. If caveatNames are provided
-	// the result will be filtered to the provided caveat names
+	// the result will be filtered to the provided caveat names. As with all reads, the caveats
+	// are listed as of the revision of the reader, so the caveats at a specific revision are
+	// listed with the reader returned by Datastore.SnapshotReader for that revision.
 	ListCaveats(ctx context.Context, caveatNamesForFiltering ...string) ([]*core.CaveatDefinition, error)
 }
 
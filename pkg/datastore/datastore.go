@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -47,6 +48,48 @@ const FilterMaximumIDCount uint16 = 100
 type RevisionChanges struct {
 	Revision Revision
 	Changes  []*core.RelationTupleUpdate
+
+	// CaveatChanges are the changes made to caveat definitions in the transaction, sorted by
+	// caveat name. They are only emitted to watches which opted into them with
+	// options.WithIncludeCaveats.
+	CaveatChanges []CaveatChange
+}
+
+// CaveatChangeOperation is the kind of change made to a caveat definition.
+type CaveatChangeOperation int
+
+const (
+	// CaveatAdded indicates a caveat definition that did not exist before the transaction.
+	CaveatAdded CaveatChangeOperation = iota
+
+	// CaveatUpdated indicates an existing caveat definition replaced by the transaction.
+	CaveatUpdated
+
+	// CaveatDeleted indicates a caveat definition deleted by the transaction.
+	CaveatDeleted
+)
+
+// String returns the name of the operation.
+func (op CaveatChangeOperation) String() string {
+	switch op {
+	case CaveatAdded:
+		return "added"
+	case CaveatUpdated:
+		return "updated"
+	case CaveatDeleted:
+		return "deleted"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(op))
+	}
+}
+
+// CaveatChange is a change made to a caveat definition.
+type CaveatChange struct {
+	Operation CaveatChangeOperation
+	Name      string
+
+	// Definition is the new definition of the caveat, or nil if the caveat was deleted.
+	Definition *core.CaveatDefinition
 }
 
 // RelationshipsFilter is a filter for relationships.
@@ -57,6 +100,9 @@ type RelationshipsFilter struct {
 	// OptionalResourceIds are the IDs of the resources to find. If nil empty, any resource ID will be allowed.
 	OptionalResourceIds []string
 
+	// OptionalResourceIDPrefix is the prefix of the IDs of the resources to find. If empty, any resource ID will be allowed.
+	OptionalResourceIDPrefix string
+
 	// OptionalResourceRelation is the relation of the resource to find. If empty, any relation is allowed.
 	OptionalResourceRelation string
 
@@ -240,6 +286,22 @@ type ReadWriteTransaction interface {
 
 	// DeleteNamespaces deletes namespaces including associated relationships.
 	DeleteNamespaces(ctx context.Context, nsNames ...string) error
+
+	// BulkLoad writes all the relationships of the iterator in large batches, and returns the
+	// number of relationships written. It is intended for the initial import of relationships
+	// into an empty datastore, or one containing none of the relationships loaded: the
+	// relationships are written as CREATEs, so should one already exist, the load fails with an
+	// error and, as for any error, the entire transaction is rolled back. All the relationships
+	// loaded are written at the revision of the transaction.
+	BulkLoad(ctx context.Context, iter RelationshipIterator) (uint64, error)
+
+	// BulkDeleteRelationships deletes the relationships matching the filter in batches, and
+	// returns the number of relationships deleted. If limit is non-zero, at most limit
+	// relationships are deleted, so that very large sets of relationships can be deleted across
+	// several transactions by calling it until it deletes fewer than limit. The relationships
+	// are deleted at the revision of the transaction, as with DELETE mutations written with
+	// WriteRelationships.
+	BulkDeleteRelationships(ctx context.Context, filter RelationshipsFilter, limit uint64) (uint64, error)
 }
 
 // TxUserFunc is a type for the function that users supply when they invoke a read-write transaction.
@@ -271,10 +333,11 @@ type Datastore interface {
 	// used by the specific datastore implementation.
 	RevisionFromString(serialized string) (Revision, error)
 
-	// Watch notifies the caller about all changes to tuples.
+	// Watch notifies the caller about all changes to tuples, and to caveat definitions if
+	// requested with options.WithIncludeCaveats.
 	//
 	// All events following afterRevision will be sent to the caller.
-	Watch(ctx context.Context, afterRevision Revision) (<-chan *RevisionChanges, <-chan error)
+	Watch(ctx context.Context, afterRevision Revision, opts ...options.WatchOptionsOption) (<-chan *RevisionChanges, <-chan error)
 
 	// IsReady returns whether the datastore is ready to accept data. Datastores that require
 	// database schema creation will return false until the migrations have been run to create
@@ -285,7 +348,9 @@ type Datastore interface {
 	// datastore can support.
 	Features(ctx context.Context) (*Features, error)
 
-	// Statistics returns relevant values about the data contained in this cluster.
+	// Statistics returns relevant values about the data contained in this cluster. The counts
+	// are estimates computed from the statistics of the backing database, and are not exact.
+	// The number of object definitions is the length of the ObjectTypeStatistics returned.
 	Statistics(ctx context.Context) (Stats, error)
 
 	// Close closes the data store.
@@ -331,6 +396,16 @@ type Stats struct {
 	// table statistics.
 	EstimatedRelationshipCount uint64
 
+	// EstimatedObjectCount is a best-guess estimate of the number of unique objects with
+	// relationships in the datastore. Like EstimatedRelationshipCount, it is read from the
+	// statistics maintained by the backing database, and may therefore be stale or approximate,
+	// e.g. by including objects whose relationships have been deleted but not yet collected.
+	EstimatedObjectCount uint64
+
+	// HasEstimatedObjectCount is false if the datastore cannot estimate the number of unique
+	// objects without a full scan, in which case EstimatedObjectCount is zero.
+	HasEstimatedObjectCount bool
+
 	// ObjectTypeStatistics returns a slice element for each object type (namespace)
 	// stored in the datastore.
 	ObjectTypeStatistics []ObjectTypeStat
@@ -390,3 +465,42 @@ func (nilRevision) MarshalBinary() ([]byte, error) {
 // revision type in the future a bit easier if necessary. Implementations
 // should use any time they want to signal an empty/error revision.
 var NoRevision Revision = nilRevision{}
+
+// EngineRevision is a revision along with the ID of the engine of the datastore which returned it,
+// as registered in Engines. Several engines share the same revision type, so the revision alone
+// does not tell which engine it belongs to.
+type EngineRevision struct {
+	Engine   string
+	Revision Revision
+}
+
+// CompareRevisions returns -1 if the left hand side revision is provably less than the right hand
+// side, 1 if it is provably greater, and 0 if they are equal. NoRevision is less than all the other
+// revisions.
+//
+// An instance of ErrIncomparableRevisions is returned for revisions which were not returned by
+// the same datastore engine, or which cannot be ordered, such as the revisions of concurrent
+// transactions in Postgres.
+func CompareRevisions(lhsEngineRevision, rhsEngineRevision EngineRevision) (int, error) {
+	lhs, rhs := lhsEngineRevision.Revision, rhsEngineRevision.Revision
+	switch {
+	case lhs == NoRevision && rhs == NoRevision:
+		return 0, nil
+	case lhs == NoRevision:
+		return -1, nil
+	case rhs == NoRevision:
+		return 1, nil
+	case lhsEngineRevision.Engine != rhsEngineRevision.Engine:
+		return 0, NewIncomparableRevisionsErr(lhs, rhs, fmt.Sprintf("revisions of engines `%s` and `%s`", lhsEngineRevision.Engine, rhsEngineRevision.Engine))
+	case reflect.TypeOf(lhs) != reflect.TypeOf(rhs):
+		return 0, NewIncomparableRevisionsErr(lhs, rhs, fmt.Sprintf("revisions of types %T and %T", lhs, rhs))
+	case lhs.Equal(rhs):
+		return 0, nil
+	case lhs.LessThan(rhs):
+		return -1, nil
+	case lhs.GreaterThan(rhs):
+		return 1, nil
+	default:
+		return 0, NewIncomparableRevisionsErr(lhs, rhs, "revisions of concurrent transactions")
+	}
+}
@@ -1,6 +1,7 @@
 package datastore
 
 import (
+	"fmt"
 	"testing"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
@@ -103,3 +104,80 @@ func TestRelationshipsFilterFromPublicFilter(t *testing.T) {
 		})
 	}
 }
+
+type intRevision int
+
+func (ir intRevision) Equal(rhs Revision) bool {
+	rhsInt, ok := rhs.(intRevision)
+	return ok && ir == rhsInt
+}
+
+func (ir intRevision) GreaterThan(rhs Revision) bool {
+	rhsInt, ok := rhs.(intRevision)
+	return ok && ir > rhsInt
+}
+
+func (ir intRevision) LessThan(rhs Revision) bool {
+	rhsInt, ok := rhs.(intRevision)
+	return ok && ir < rhsInt
+}
+
+func (ir intRevision) String() string {
+	return fmt.Sprintf("%d", int(ir))
+}
+
+func (ir intRevision) MarshalBinary() ([]byte, error) {
+	return []byte(ir.String()), nil
+}
+
+type stringRevision string
+
+func (sr stringRevision) Equal(rhs Revision) bool       { return sr == rhs }
+func (sr stringRevision) GreaterThan(rhs Revision) bool { return false }
+func (sr stringRevision) LessThan(rhs Revision) bool    { return false }
+func (sr stringRevision) String() string                { return string(sr) }
+func (sr stringRevision) MarshalBinary() ([]byte, error) {
+	return []byte(sr), nil
+}
+
+func TestCompareRevisions(t *testing.T) {
+	tests := []struct {
+		name     string
+		lhs      Revision
+		rhs      Revision
+		expected int
+	}{
+		{"equal", intRevision(1), intRevision(1), 0},
+		{"less", intRevision(1), intRevision(2), -1},
+		{"greater", intRevision(2), intRevision(1), 1},
+		{"no revisions", NoRevision, NoRevision, 0},
+		{"no revision less", NoRevision, intRevision(1), -1},
+		{"no revision greater", intRevision(1), NoRevision, 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := CompareRevisions(EngineRevision{"test", tc.lhs}, EngineRevision{"test", tc.rhs})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+
+	t.Run("different engines", func(t *testing.T) {
+		_, err := CompareRevisions(EngineRevision{"test", intRevision(1)}, EngineRevision{"other", intRevision(1)})
+		require.ErrorAs(t, err, &ErrIncomparableRevisions{})
+		require.ErrorContains(t, err, "revisions of engines `test` and `other`")
+	})
+
+	t.Run("different types", func(t *testing.T) {
+		_, err := CompareRevisions(EngineRevision{"test", intRevision(1)}, EngineRevision{"test", stringRevision("1")})
+		require.ErrorAs(t, err, &ErrIncomparableRevisions{})
+		require.ErrorContains(t, err, "revisions of types")
+	})
+
+	t.Run("unordered", func(t *testing.T) {
+		_, err := CompareRevisions(EngineRevision{"test", stringRevision("a")}, EngineRevision{"test", stringRevision("b")})
+		require.ErrorAs(t, err, &ErrIncomparableRevisions{})
+		require.ErrorContains(t, err, "concurrent")
+	})
+}
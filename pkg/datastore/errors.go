@@ -73,6 +73,11 @@ func (err ErrInvalidRevision) Reason() InvalidRevisionReason {
 	return err.reason
 }
 
+// Is returns whether the target is ErrRevisionUnavailable, which all invalid revisions are.
+func (err ErrInvalidRevision) Is(target error) bool {
+	return target == ErrRevisionUnavailable
+}
+
 // MarshalZerologObject implements zerolog object marshalling.
 func (err ErrInvalidRevision) MarshalZerologObject(e *zerolog.Event) {
 	switch err.reason {
@@ -141,6 +146,32 @@ func NewInvalidRevisionErr(revision Revision, reason InvalidRevisionReason) erro
 	}
 }
 
+// ErrIncomparableRevisions is returned when comparing revisions which cannot be ordered.
+type ErrIncomparableRevisions struct {
+	error
+	lhs Revision
+	rhs Revision
+}
+
+// Revisions returns the revisions which could not be compared.
+func (err ErrIncomparableRevisions) Revisions() (Revision, Revision) {
+	return err.lhs, err.rhs
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err ErrIncomparableRevisions) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Stringer("lhs", err.lhs).Stringer("rhs", err.rhs)
+}
+
+// NewIncomparableRevisionsErr constructs a new incomparable revisions error.
+func NewIncomparableRevisionsErr(lhs, rhs Revision, reason string) error {
+	return ErrIncomparableRevisions{
+		error: fmt.Errorf("cannot compare %s: `%s` and `%s`", reason, lhs, rhs),
+		lhs:   lhs,
+		rhs:   rhs,
+	}
+}
+
 // ErrCaveatNameNotFound is the error returned when a caveat is not found by its name
 type ErrCaveatNameNotFound struct {
 	error
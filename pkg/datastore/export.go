@@ -0,0 +1,233 @@
+package datastore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// ExportVersion is the version of the format written by Export.
+const ExportVersion = 1
+
+// exportPageSize is the number of relationships read per query by Export.
+const exportPageSize = 1000
+
+// exportHeader is the first line of an export.
+type exportHeader struct {
+	Version  int    `json:"version"`
+	Revision string `json:"revision"`
+}
+
+// exportRecord is a single line of an export following its header, holding exactly one of a
+// namespace definition, a caveat definition or a relationship. Definitions are kept as their
+// serialized protobuf messages.
+type exportRecord struct {
+	Namespace    []byte                `json:"namespace,omitempty"`
+	Caveat       []byte                `json:"caveat,omitempty"`
+	Relationship *exportedRelationship `json:"relationship,omitempty"`
+}
+
+// ExportProgress is the progress of an export, as the number of definitions and relationships
+// written so far.
+type ExportProgress struct {
+	Namespaces    uint64
+	Caveats       uint64
+	Relationships uint64
+}
+
+// ExportProgressHandler is called with the progress of an export as it is written.
+type ExportProgressHandler func(progress ExportProgress)
+
+// exportedRelationship is a single relationship in an export. The caveat context of a caveated
+// relationship is encoded as a JSON object whose values keep the types in which they are stored.
+type exportedRelationship struct {
+	ResourceType    string          `json:"resource_type"`
+	ResourceID      string          `json:"resource_id"`
+	Relation        string          `json:"relation"`
+	SubjectType     string          `json:"subject_type"`
+	SubjectID       string          `json:"subject_id"`
+	SubjectRelation string          `json:"subject_relation"`
+	CaveatName      string          `json:"caveat_name,omitempty"`
+	CaveatContext   json.RawMessage `json:"caveat_context,omitempty"`
+}
+
+func exportRelationship(tpl *core.RelationTuple) (exportedRelationship, error) {
+	exported := exportedRelationship{
+		ResourceType:    tpl.ResourceAndRelation.Namespace,
+		ResourceID:      tpl.ResourceAndRelation.ObjectId,
+		Relation:        tpl.ResourceAndRelation.Relation,
+		SubjectType:     tpl.Subject.Namespace,
+		SubjectID:       tpl.Subject.ObjectId,
+		SubjectRelation: tpl.Subject.Relation,
+	}
+
+	if tpl.Caveat != nil && tpl.Caveat.CaveatName != "" {
+		exported.CaveatName = tpl.Caveat.CaveatName
+		if tpl.Caveat.Context != nil && len(tpl.Caveat.Context.Fields) > 0 {
+			contextJSON, err := tpl.Caveat.Context.MarshalJSON()
+			if err != nil {
+				return exported, fmt.Errorf("could not encode caveat context: %w", err)
+			}
+			exported.CaveatContext = contextJSON
+		}
+	}
+
+	return exported, nil
+}
+
+func importRelationship(exported exportedRelationship) (*core.RelationTuple, error) {
+	tpl := &core.RelationTuple{
+		ResourceAndRelation: &core.ObjectAndRelation{
+			Namespace: exported.ResourceType,
+			ObjectId:  exported.ResourceID,
+			Relation:  exported.Relation,
+		},
+		Subject: &core.ObjectAndRelation{
+			Namespace: exported.SubjectType,
+			ObjectId:  exported.SubjectID,
+			Relation:  exported.SubjectRelation,
+		},
+	}
+
+	if exported.CaveatName != "" {
+		caveatContext := &structpb.Struct{}
+		if len(exported.CaveatContext) > 0 {
+			if err := caveatContext.UnmarshalJSON(exported.CaveatContext); err != nil {
+				return nil, fmt.Errorf("could not decode caveat context: %w", err)
+			}
+		}
+
+		tpl.Caveat = &core.ContextualizedCaveat{
+			CaveatName: exported.CaveatName,
+			Context:    caveatContext,
+		}
+	}
+
+	return tpl, tpl.Validate()
+}
+
+// Export writes all the namespace definitions, caveat definitions and relationships of the
+// datastore at the revision to the writer, in a versioned format restorable with Import, and
+// returns the final progress of the export. The progress handler, if not nil, is called after
+// the definitions and after each page of relationships are written.
+//
+// The export is in JSON Lines format: a header line holding the format version and the revision
+// exported, followed by one line per namespace, then one per caveat, then one per relationship.
+// Everything is read at the revision, so the export is internally consistent, and relationships
+// are read page by page, so the export is streamed without holding all the relationships in
+// memory. The export fails with ErrRevisionUnavailable if the revision is, or falls, out of the
+// garbage collection window of the datastore. Relationships whose resource type is not defined
+// are not exported.
+func Export(ctx context.Context, ds Datastore, revision Revision, w io.Writer, progress ExportProgressHandler) (ExportProgress, error) {
+	var exported ExportProgress
+	if progress == nil {
+		progress = func(ExportProgress) {}
+	}
+
+	reader, err := CheckedSnapshotReader(ctx, ds, revision)
+	if err != nil {
+		return exported, err
+	}
+
+	nsDefs, err := reader.ListNamespaces(ctx)
+	if err != nil {
+		return exported, err
+	}
+
+	caveatDefs, err := reader.ListCaveats(ctx)
+	if err != nil {
+		return exported, err
+	}
+
+	encoder := json.NewEncoder(w)
+	err = encoder.Encode(exportHeader{
+		Version:  ExportVersion,
+		Revision: revision.String(),
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	for _, nsDef := range nsDefs {
+		serialized, err := nsDef.MarshalVT()
+		if err != nil {
+			return exported, fmt.Errorf("could not encode namespace `%s`: %w", nsDef.Name, err)
+		}
+		if err := encoder.Encode(exportRecord{Namespace: serialized}); err != nil {
+			return exported, err
+		}
+		exported.Namespaces++
+	}
+
+	for _, caveatDef := range caveatDefs {
+		serialized, err := caveatDef.MarshalVT()
+		if err != nil {
+			return exported, fmt.Errorf("could not encode caveat `%s`: %w", caveatDef.Name, err)
+		}
+		if err := encoder.Encode(exportRecord{Caveat: serialized}); err != nil {
+			return exported, err
+		}
+		exported.Caveats++
+	}
+	progress(exported)
+
+	for _, nsDef := range nsDefs {
+		var after *core.RelationTuple
+		for {
+			page, err := readExportPage(ctx, reader, nsDef.Name, after)
+			if err != nil {
+				return exported, err
+			}
+
+			for _, tpl := range page {
+				relationship, err := exportRelationship(tpl)
+				if err != nil {
+					return exported, err
+				}
+				if err := encoder.Encode(exportRecord{Relationship: &relationship}); err != nil {
+					return exported, err
+				}
+				exported.Relationships++
+			}
+
+			if len(page) > 0 {
+				progress(exported)
+			}
+			if len(page) < exportPageSize {
+				break
+			}
+			after = page[len(page)-1]
+		}
+	}
+
+	return exported, nil
+}
+
+// readExportPage reads the page of relationships of the resource type following the relationship,
+// or the first page if nil.
+func readExportPage(ctx context.Context, reader Reader, resourceType string, after *core.RelationTuple) ([]*core.RelationTuple, error) {
+	limit := uint64(exportPageSize)
+	iter, err := reader.QueryRelationships(
+		ctx,
+		RelationshipsFilter{ResourceType: resourceType},
+		options.WithSort(options.ByResource),
+		options.WithAfter(after),
+		options.WithLimit(&limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	page := make([]*core.RelationTuple, 0, exportPageSize)
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		page = append(page, tpl)
+	}
+	return page, iter.Err()
+}
@@ -0,0 +1,381 @@
+package datastore
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/util"
+)
+
+// ErrImportConflict is matched, with errors.Is, by the errors returned when an import with the
+// ConflictFail strategy finds a definition or relationship which already exists.
+var ErrImportConflict = errors.New("import conflicts with existing data")
+
+// relationshipImportBatchSize is the number of relationships written per transaction by Import.
+const relationshipImportBatchSize = 1000
+
+// ConflictStrategy is how Import handles the definitions and relationships it imports which
+// already exist in the datastore.
+type ConflictStrategy int
+
+const (
+	// ConflictFail fails the import at the first existing definition or relationship.
+	ConflictFail ConflictStrategy = iota
+
+	// ConflictSkip leaves existing definitions and relationships untouched.
+	ConflictSkip
+
+	// ConflictOverwrite replaces existing definitions and relationships with the imported ones,
+	// including the caveats of relationships.
+	ConflictOverwrite
+)
+
+func (cs ConflictStrategy) String() string {
+	switch cs {
+	case ConflictFail:
+		return "fail"
+	case ConflictSkip:
+		return "skip"
+	case ConflictOverwrite:
+		return "overwrite"
+	default:
+		return fmt.Sprintf("unknown conflict strategy %d", int(cs))
+	}
+}
+
+// ImportCounts are the numbers of items of a kind handled by an import.
+type ImportCounts struct {
+	// Imported is the number of items which did not exist, and were written.
+	Imported uint64
+
+	// Skipped is the number of items which already existed, and were left untouched.
+	Skipped uint64
+
+	// Overwritten is the number of items which already existed, and were replaced.
+	Overwritten uint64
+}
+
+// ImportResult is the outcome of an import, by kind of item.
+type ImportResult struct {
+	Namespaces    ImportCounts
+	Caveats       ImportCounts
+	Relationships ImportCounts
+}
+
+// Import reads an export written by Export and writes its contents to the datastore, which may
+// already hold data, handling the definitions and relationships which already exist according to
+// the strategy. It returns the counts of items handled, including when failing part way.
+//
+// All the namespace and caveat definitions are written first, in a single transaction, so that
+// relationships are only written once the definitions they reference exist. Relationships are
+// then written in batches of bounded size, each in its own transaction, so a failed import leaves
+// the batches written before the failure in place: it can be resumed by importing again with the
+// ConflictSkip strategy. A relationship appearing more than once in a batch is only written, and
+// counted, once, with the caveat of its last occurrence. Note that relationships are not validated
+// against the schema.
+func Import(ctx context.Context, ds Datastore, r io.Reader, strategy ConflictStrategy) (ImportResult, error) {
+	var result ImportResult
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if scanner.Err() != nil {
+			return result, scanner.Err()
+		}
+		return result, errors.New("missing export header")
+	}
+
+	var header exportHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return result, fmt.Errorf("could not decode export header: %w", err)
+	}
+	if header.Version != ExportVersion {
+		return result, fmt.Errorf("unsupported export version %d", header.Version)
+	}
+
+	importer := &importer{ds: ds, strategy: strategy, result: &result}
+
+	var nsDefs []*core.NamespaceDefinition
+	var caveatDefs []*core.CaveatDefinition
+	definitionsWritten := false
+
+	batch := make([]*core.RelationTuple, 0, relationshipImportBatchSize)
+	line := 1
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record exportRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return result, fmt.Errorf("could not decode record on line %d: %w", line, err)
+		}
+
+		switch {
+		case record.Namespace != nil:
+			if definitionsWritten {
+				return result, fmt.Errorf("namespace on line %d follows relationships", line)
+			}
+
+			nsDef := &core.NamespaceDefinition{}
+			if err := nsDef.UnmarshalVT(record.Namespace); err != nil {
+				return result, fmt.Errorf("could not decode namespace on line %d: %w", line, err)
+			}
+			nsDefs = append(nsDefs, nsDef)
+
+		case record.Caveat != nil:
+			if definitionsWritten {
+				return result, fmt.Errorf("caveat on line %d follows relationships", line)
+			}
+
+			caveatDef := &core.CaveatDefinition{}
+			if err := caveatDef.UnmarshalVT(record.Caveat); err != nil {
+				return result, fmt.Errorf("could not decode caveat on line %d: %w", line, err)
+			}
+			caveatDefs = append(caveatDefs, caveatDef)
+
+		case record.Relationship != nil:
+			if !definitionsWritten {
+				if err := importer.writeDefinitions(ctx, nsDefs, caveatDefs); err != nil {
+					return result, err
+				}
+				definitionsWritten = true
+			}
+
+			tpl, err := importRelationship(*record.Relationship)
+			if err != nil {
+				return result, fmt.Errorf("invalid relationship on line %d: %w", line, err)
+			}
+
+			batch = append(batch, tpl)
+			if len(batch) >= relationshipImportBatchSize {
+				if err := importer.writeRelationships(ctx, batch); err != nil {
+					return result, err
+				}
+				batch = make([]*core.RelationTuple, 0, relationshipImportBatchSize)
+			}
+
+		default:
+			return result, fmt.Errorf("empty record on line %d", line)
+		}
+	}
+
+	if scanner.Err() != nil {
+		return result, scanner.Err()
+	}
+
+	if !definitionsWritten {
+		if err := importer.writeDefinitions(ctx, nsDefs, caveatDefs); err != nil {
+			return result, err
+		}
+	}
+
+	return result, importer.writeRelationships(ctx, batch)
+}
+
+type importer struct {
+	ds       Datastore
+	strategy ConflictStrategy
+	result   *ImportResult
+}
+
+// resolve returns whether an item should be written given whether it already exists, and
+// counts it.
+func (i *importer) resolve(exists bool, counts *ImportCounts, description string) (bool, error) {
+	switch {
+	case !exists:
+		counts.Imported++
+		return true, nil
+	case i.strategy == ConflictSkip:
+		counts.Skipped++
+		return false, nil
+	case i.strategy == ConflictOverwrite:
+		counts.Overwritten++
+		return true, nil
+	default:
+		return false, fmt.Errorf("%w: %s already exists", ErrImportConflict, description)
+	}
+}
+
+func (i *importer) writeDefinitions(ctx context.Context, nsDefs []*core.NamespaceDefinition, caveatDefs []*core.CaveatDefinition) error {
+	var nsCounts, caveatCounts ImportCounts
+	_, err := i.ds.ReadWriteTx(ctx, func(rwt ReadWriteTransaction) error {
+		nsCounts, caveatCounts = ImportCounts{}, ImportCounts{}
+
+		toWriteNamespaces := make([]*core.NamespaceDefinition, 0, len(nsDefs))
+		for _, nsDef := range nsDefs {
+			_, _, err := rwt.ReadNamespace(ctx, nsDef.Name)
+			exists := err == nil
+			if err != nil && !errors.As(err, &ErrNamespaceNotFound{}) {
+				return err
+			}
+
+			write, err := i.resolve(exists, &nsCounts, fmt.Sprintf("namespace `%s`", nsDef.Name))
+			if err != nil {
+				return err
+			}
+			if write {
+				toWriteNamespaces = append(toWriteNamespaces, nsDef)
+			}
+		}
+
+		toWriteCaveats := make([]*core.CaveatDefinition, 0, len(caveatDefs))
+		for _, caveatDef := range caveatDefs {
+			_, _, err := rwt.ReadCaveatByName(ctx, caveatDef.Name)
+			exists := err == nil
+			if err != nil && !errors.As(err, &ErrCaveatNameNotFound{}) {
+				return err
+			}
+
+			write, err := i.resolve(exists, &caveatCounts, fmt.Sprintf("caveat `%s`", caveatDef.Name))
+			if err != nil {
+				return err
+			}
+			if write {
+				toWriteCaveats = append(toWriteCaveats, caveatDef)
+			}
+		}
+
+		if len(toWriteNamespaces) > 0 {
+			if err := rwt.WriteNamespaces(ctx, toWriteNamespaces...); err != nil {
+				return err
+			}
+		}
+		if len(toWriteCaveats) > 0 {
+			return rwt.WriteCaveats(ctx, toWriteCaveats)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	i.result.Namespaces = nsCounts
+	i.result.Caveats = caveatCounts
+	return nil
+}
+
+func (i *importer) writeRelationships(ctx context.Context, batch []*core.RelationTuple) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	batch = lastOccurrences(batch)
+
+	var counts ImportCounts
+	_, err := i.ds.ReadWriteTx(ctx, func(rwt ReadWriteTransaction) error {
+		counts = ImportCounts{}
+
+		existing, err := existingRelationships(ctx, rwt, batch)
+		if err != nil {
+			return err
+		}
+
+		mutations := make([]*core.RelationTupleUpdate, 0, len(batch))
+		for _, tpl := range batch {
+			key := tuple.StringWithoutCaveat(tpl)
+			_, exists := existing[key]
+
+			write, err := i.resolve(exists, &counts, fmt.Sprintf("relationship `%s`", key))
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case !write:
+			case exists:
+				mutations = append(mutations, tuple.Touch(tpl))
+			default:
+				mutations = append(mutations, tuple.Create(tpl))
+			}
+		}
+
+		if len(mutations) == 0 {
+			return nil
+		}
+		return rwt.WriteRelationships(ctx, mutations)
+	})
+	if err != nil {
+		return err
+	}
+
+	i.result.Relationships.Imported += counts.Imported
+	i.result.Relationships.Skipped += counts.Skipped
+	i.result.Relationships.Overwritten += counts.Overwritten
+	return nil
+}
+
+// lastOccurrences returns the relationships of the batch with each relationship appearing only
+// once, as its last occurrence, since two mutations of the same relationship fail a write.
+func lastOccurrences(batch []*core.RelationTuple) []*core.RelationTuple {
+	indexes := make(map[string]int, len(batch))
+	unique := make([]*core.RelationTuple, 0, len(batch))
+	for _, tpl := range batch {
+		key := tuple.StringWithoutCaveat(tpl)
+		if index, ok := indexes[key]; ok {
+			unique[index] = tpl
+			continue
+		}
+
+		indexes[key] = len(unique)
+		unique = append(unique, tpl)
+	}
+	return unique
+}
+
+// existingRelationships returns the keys of the relationships of the batch which exist, read by
+// resource type and resource IDs.
+func existingRelationships(ctx context.Context, reader Reader, batch []*core.RelationTuple) (map[string]struct{}, error) {
+	wanted := make(map[string]struct{}, len(batch))
+	resourceIDs := make(map[string]*util.Set[string])
+	for _, tpl := range batch {
+		wanted[tuple.StringWithoutCaveat(tpl)] = struct{}{}
+
+		resourceType := tpl.ResourceAndRelation.Namespace
+		if _, ok := resourceIDs[resourceType]; !ok {
+			resourceIDs[resourceType] = util.NewSet[string]()
+		}
+		resourceIDs[resourceType].Add(tpl.ResourceAndRelation.ObjectId)
+	}
+
+	existing := make(map[string]struct{})
+	for resourceType, idSet := range resourceIDs {
+		ids := idSet.AsSlice()
+		for start := 0; start < len(ids); start += int(FilterMaximumIDCount) {
+			end := start + int(FilterMaximumIDCount)
+			if end > len(ids) {
+				end = len(ids)
+			}
+
+			iter, err := reader.QueryRelationships(ctx, RelationshipsFilter{
+				ResourceType:        resourceType,
+				OptionalResourceIds: ids[start:end],
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+				key := tuple.StringWithoutCaveat(tpl)
+				if _, ok := wanted[key]; ok {
+					existing[key] = struct{}{}
+				}
+			}
+			err = iter.Err()
+			iter.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return existing, nil
+}
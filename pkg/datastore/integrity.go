@@ -0,0 +1,182 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// IntegrityViolationReason is the reason for which a relationship fails an integrity check.
+type IntegrityViolationReason int
+
+const (
+	// UndefinedResourceType is reported for relationships whose resource type is not defined.
+	UndefinedResourceType IntegrityViolationReason = iota
+
+	// UndefinedRelation is reported for relationships whose relation is not defined on their
+	// resource type.
+	UndefinedRelation
+
+	// UndefinedSubjectType is reported for relationships whose subject type is not defined.
+	UndefinedSubjectType
+
+	// UndefinedSubjectRelation is reported for relationships whose subject relation is not
+	// defined on their subject type.
+	UndefinedSubjectRelation
+
+	// UndefinedCaveat is reported for relationships whose caveat is not defined.
+	UndefinedCaveat
+)
+
+func (r IntegrityViolationReason) String() string {
+	switch r {
+	case UndefinedResourceType:
+		return "undefined resource type"
+	case UndefinedRelation:
+		return "undefined relation"
+	case UndefinedSubjectType:
+		return "undefined subject type"
+	case UndefinedSubjectRelation:
+		return "undefined subject relation"
+	case UndefinedCaveat:
+		return "undefined caveat"
+	default:
+		return fmt.Sprintf("unknown integrity violation reason %d", int(r))
+	}
+}
+
+// IntegrityViolation is a relationship referencing a definition missing from the schema.
+type IntegrityViolation struct {
+	Relationship *core.RelationTuple
+	Reason       IntegrityViolationReason
+}
+
+// IntegrityViolationHandler is called with each integrity violation found. Returning an error
+// stops the check, which returns the error.
+type IntegrityViolationHandler func(violation IntegrityViolation) error
+
+// CheckIntegrity scans the relationships readable by the reader and calls the handler for each
+// one referencing a resource type, relation, subject type, subject relation or caveat which is not
+// defined, returning the number of relationships checked. A relationship referencing more than
+// one undefined definition is reported once, for the first of them in that order.
+//
+// Violations are streamed to the handler as they are found, so the check can be run over datasets
+// of any size. Relationships are read by resource type, then by subject type for those whose
+// resource type is undefined, so relationships whose resource and subject types are both
+// undefined cannot be found.
+func CheckIntegrity(ctx context.Context, reader Reader, handler IntegrityViolationHandler) (uint64, error) {
+	nsDefs, err := reader.ListNamespaces(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	relations := make(map[string]map[string]struct{}, len(nsDefs))
+	for _, nsDef := range nsDefs {
+		defined := make(map[string]struct{}, len(nsDef.Relation))
+		for _, relation := range nsDef.Relation {
+			defined[relation.Name] = struct{}{}
+		}
+		relations[nsDef.Name] = defined
+	}
+
+	caveatDefs, err := reader.ListCaveats(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	caveats := make(map[string]struct{}, len(caveatDefs))
+	for _, caveatDef := range caveatDefs {
+		caveats[caveatDef.Name] = struct{}{}
+	}
+
+	checker := integrityChecker{relations, caveats, handler}
+
+	var count uint64
+	for _, nsDef := range nsDefs {
+		iter, err := reader.QueryRelationships(ctx, RelationshipsFilter{ResourceType: nsDef.Name})
+		if err != nil {
+			return count, err
+		}
+
+		checked, err := checker.checkAll(iter, false)
+		count += checked
+		if err != nil {
+			return count, err
+		}
+	}
+
+	for _, nsDef := range nsDefs {
+		iter, err := reader.ReverseQueryRelationships(ctx, SubjectsFilter{SubjectType: nsDef.Name})
+		if err != nil {
+			return count, err
+		}
+
+		checked, err := checker.checkAll(iter, true)
+		count += checked
+		if err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+type integrityChecker struct {
+	relations map[string]map[string]struct{}
+	caveats   map[string]struct{}
+	handler   IntegrityViolationHandler
+}
+
+// checkAll checks the relationships of the iterator, and closes it. If onlyUndefinedResources is
+// set, relationships whose resource type is defined are skipped, as they are checked when reading
+// by resource type.
+func (ic integrityChecker) checkAll(iter RelationshipIterator, onlyUndefinedResources bool) (uint64, error) {
+	defer iter.Close()
+
+	var count uint64
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		if _, ok := ic.relations[tpl.ResourceAndRelation.Namespace]; ok && onlyUndefinedResources {
+			continue
+		}
+
+		count++
+		if reason, ok := ic.check(tpl); !ok {
+			if err := ic.handler(IntegrityViolation{Relationship: tpl, Reason: reason}); err != nil {
+				return count, err
+			}
+		}
+	}
+
+	return count, iter.Err()
+}
+
+func (ic integrityChecker) check(tpl *core.RelationTuple) (IntegrityViolationReason, bool) {
+	resourceRelations, ok := ic.relations[tpl.ResourceAndRelation.Namespace]
+	if !ok {
+		return UndefinedResourceType, false
+	}
+
+	if _, ok := resourceRelations[tpl.ResourceAndRelation.Relation]; !ok {
+		return UndefinedRelation, false
+	}
+
+	subjectRelations, ok := ic.relations[tpl.Subject.Namespace]
+	if !ok {
+		return UndefinedSubjectType, false
+	}
+
+	if tpl.Subject.Relation != Ellipsis {
+		if _, ok := subjectRelations[tpl.Subject.Relation]; !ok {
+			return UndefinedSubjectRelation, false
+		}
+	}
+
+	if tpl.Caveat != nil && tpl.Caveat.CaveatName != "" {
+		if _, ok := ic.caveats[tpl.Caveat.CaveatName]; !ok {
+			return UndefinedCaveat, false
+		}
+	}
+
+	return 0, true
+}
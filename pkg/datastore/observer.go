@@ -0,0 +1,42 @@
+package datastore
+
+import (
+	"context"
+	"time"
+)
+
+// OperationEvent describes a datastore operation which has completed.
+type OperationEvent struct {
+	// Backend is the name of the datastore engine, e.g. `postgres`.
+	Backend string
+
+	// Operation is the name of the datastore method, e.g. `QueryRelationships`.
+	Operation string
+
+	// Duration is the time taken by the operation. For relationship queries, it is the time
+	// taken to return the iterator.
+	Duration time.Duration
+
+	// Err is the error returned by the operation, if any.
+	Err error
+
+	// ResultSize approximates the size of the result: the number of relationships, namespaces or
+	// caveats read, or written or deleted by name. It is zero for operations whose size is unknown,
+	// such as DeleteRelationships.
+	ResultSize int
+}
+
+// OperationObserver observes the operations made against a datastore, e.g. to record their
+// latency and errors. It is called synchronously once each operation completes, and must
+// therefore be cheap and safe for concurrent use.
+type OperationObserver interface {
+	ObserveOperation(ctx context.Context, event OperationEvent)
+}
+
+// OperationObserverFunc adapts a function into an OperationObserver.
+type OperationObserverFunc func(ctx context.Context, event OperationEvent)
+
+// ObserveOperation implements the OperationObserver interface method.
+func (f OperationObserverFunc) ObserveOperation(ctx context.Context, event OperationEvent) {
+	f(ctx, event)
+}
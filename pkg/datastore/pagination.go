@@ -0,0 +1,150 @@
+package datastore
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/authzed/spicedb/internal/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// cursorVersion is the version of the encoding of cursors.
+const cursorVersion = 1
+
+// ErrInvalidCursor is matched, with errors.Is, by the errors returned for cursors which cannot be
+// decoded, or which were returned by a datastore of another engine.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is an opaque token marking the position at which a paginated read of relationships
+// resumes. The empty cursor starts a read from its first page.
+type Cursor string
+
+// encodedCursor is the content of a cursor, before it is encoded as base64 JSON.
+type encodedCursor struct {
+	Version  int    `json:"v"`
+	Engine   string `json:"e"`
+	Revision string `json:"r"`
+	After    string `json:"a"`
+}
+
+// QueryRelationshipsPage reads a page of at most pageSize relationships matching the filter, and
+// returns the cursor from which to read the next page, which is empty once all the relationships
+// have been read. The engine is the ID of the engine of the datastore, as registered in Engines.
+//
+// The first page is read at the specified revision. The cursor records that revision along with
+// the last relationship returned, so that all the following pages are read at the same revision
+// and together return each matching relationship exactly once, regardless of the writes made in
+// between. Reading a page fails with ErrRevisionUnavailable once the revision falls out of the
+// garbage collection window of the datastore.
+//
+// Relationships are returned sorted by resource type, resource ID, relation, subject type,
+// subject ID and subject relation, in that order, with the values compared as by the backing
+// database. As the order may thus differ between datastore engines, cursors are only accepted by
+// datastores of the engine which returned them, and fail with ErrInvalidCursor otherwise. A cursor
+// must only be used with the filter of the read which returned it.
+func QueryRelationshipsPage(
+	ctx context.Context,
+	ds Datastore,
+	engine string,
+	revision Revision,
+	filter RelationshipsFilter,
+	pageSize uint64,
+	cursor Cursor,
+) ([]*core.RelationTuple, Cursor, error) {
+	if pageSize == 0 {
+		return nil, "", fmt.Errorf("page size must be greater than zero")
+	}
+
+	var after *core.RelationTuple
+	if cursor != "" {
+		decoded, err := decodeCursor(cursor, engine)
+		if err != nil {
+			return nil, "", err
+		}
+
+		revision, err = ds.RevisionFromString(decoded.Revision)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+		}
+
+		after = tuple.Parse(decoded.After)
+		if after == nil {
+			return nil, "", fmt.Errorf("%w: malformed relationship", ErrInvalidCursor)
+		}
+	}
+
+	reader, err := CheckedSnapshotReader(ctx, ds, revision)
+	if err != nil {
+		return nil, "", err
+	}
+
+	iter, err := reader.QueryRelationships(
+		ctx,
+		filter,
+		options.WithSort(options.ByResource),
+		options.WithAfter(after),
+		options.WithLimit(&pageSize),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer iter.Close()
+
+	page := make([]*core.RelationTuple, 0, pageSize)
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		page = append(page, tpl)
+	}
+	if iter.Err() != nil {
+		return nil, "", iter.Err()
+	}
+
+	if uint64(len(page)) < pageSize {
+		return page, "", nil
+	}
+
+	next, err := encodeCursor(encodedCursor{
+		Version:  cursorVersion,
+		Engine:   engine,
+		Revision: revision.String(),
+		After:    tuple.StringWithoutCaveat(page[len(page)-1]),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return page, next, nil
+}
+
+func encodeCursor(decoded encodedCursor) (Cursor, error) {
+	serialized, err := json.Marshal(decoded)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode cursor: %w", err)
+	}
+	return Cursor(base64.RawURLEncoding.EncodeToString(serialized)), nil
+}
+
+func decodeCursor(cursor Cursor, engine string) (encodedCursor, error) {
+	var decoded encodedCursor
+
+	serialized, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err != nil {
+		return decoded, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+
+	if err := json.Unmarshal(serialized, &decoded); err != nil {
+		return decoded, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+
+	if decoded.Version != cursorVersion {
+		return decoded, fmt.Errorf("%w: unsupported version %d", ErrInvalidCursor, decoded.Version)
+	}
+
+	if decoded.Engine != engine {
+		return decoded, fmt.Errorf("%w: returned by a %s datastore, not %s", ErrInvalidCursor, decoded.Engine, engine)
+	}
+
+	return decoded, nil
+}
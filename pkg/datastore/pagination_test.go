@@ -0,0 +1,33 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorEncoding(t *testing.T) {
+	encoded, err := encodeCursor(encodedCursor{
+		Version:  cursorVersion,
+		Engine:   "memory",
+		Revision: "1234",
+		After:    "document:foo#viewer@user:tom",
+	})
+	require.NoError(t, err)
+
+	decoded, err := decodeCursor(encoded, "memory")
+	require.NoError(t, err)
+	require.Equal(t, "1234", decoded.Revision)
+	require.Equal(t, "document:foo#viewer@user:tom", decoded.After)
+
+	_, err = decodeCursor(encoded, "postgres")
+	require.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = decodeCursor("not a cursor", "memory")
+	require.ErrorIs(t, err, ErrInvalidCursor)
+
+	unsupported, err := encodeCursor(encodedCursor{Version: cursorVersion + 1, Engine: "memory"})
+	require.NoError(t, err)
+	_, err = decodeCursor(unsupported, "memory")
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -21,9 +21,8 @@ func (d Decimal) Equal(rhs datastore.Revision) bool {
 		return false
 	}
 
-	rhsD := rhs.(Decimal)
-
-	return d.Decimal.Equal(rhsD.Decimal)
+	rhsD, ok := rhs.(Decimal)
+	return ok && d.Decimal.Equal(rhsD.Decimal)
 }
 
 func (d Decimal) GreaterThan(rhs datastore.Revision) bool {
@@ -31,9 +30,8 @@ func (d Decimal) GreaterThan(rhs datastore.Revision) bool {
 		rhs = Decimal{decimal.Zero}
 	}
 
-	rhsD := rhs.(Decimal)
-
-	return d.Decimal.GreaterThan(rhsD.Decimal)
+	rhsD, ok := rhs.(Decimal)
+	return ok && d.Decimal.GreaterThan(rhsD.Decimal)
 }
 
 func (d Decimal) LessThan(rhs datastore.Revision) bool {
@@ -41,9 +39,8 @@ func (d Decimal) LessThan(rhs datastore.Revision) bool {
 		rhs = Decimal{decimal.Zero}
 	}
 
-	rhsD := rhs.(Decimal)
-
-	return d.Decimal.LessThan(rhsD.Decimal)
+	rhsD, ok := rhs.(Decimal)
+	return ok && d.Decimal.LessThan(rhsD.Decimal)
 }
 
 var _ datastore.Revision = Decimal{}
@@ -0,0 +1,28 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRevisionUnavailable is matched, with errors.Is, by the errors returned for revisions which
+// cannot be read from the datastore, because they are outside of its garbage collection window or
+// are not yet known to it.
+var ErrRevisionUnavailable = errors.New("revision is unavailable")
+
+// CheckedSnapshotReader returns a reader bound to the revision for all of its reads, as
+// SnapshotReader, after checking that the revision can be read from the datastore. If it cannot,
+// the returned error matches ErrRevisionUnavailable and is an ErrInvalidRevision holding the
+// reason.
+//
+// It is meant for operations performing several reads which must all be consistent at the same
+// revision, such as point-in-time exports. Note that the revision is only checked when the reader
+// is created: reads still fail should the revision fall out of the garbage collection window
+// while the reader is in use.
+func CheckedSnapshotReader(ctx context.Context, ds Datastore, revision Revision) (Reader, error) {
+	if err := ds.CheckRevision(ctx, revision); err != nil {
+		return nil, err
+	}
+
+	return ds.SnapshotReader(revision), nil
+}
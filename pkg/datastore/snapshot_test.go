@@ -0,0 +1,45 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type checkingDatastore struct {
+	Datastore
+	checkErr error
+}
+
+func (cd checkingDatastore) CheckRevision(_ context.Context, _ Revision) error {
+	return cd.checkErr
+}
+
+func (cd checkingDatastore) SnapshotReader(_ Revision) Reader {
+	return nil
+}
+
+func TestCheckedSnapshotReader(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := CheckedSnapshotReader(ctx, checkingDatastore{}, NoRevision)
+	require.NoError(t, err)
+
+	_, err = CheckedSnapshotReader(ctx, checkingDatastore{checkErr: NewInvalidRevisionErr(NoRevision, RevisionStale)}, NoRevision)
+	require.ErrorIs(t, err, ErrRevisionUnavailable)
+
+	var invalidErr ErrInvalidRevision
+	require.ErrorAs(t, err, &invalidErr)
+	require.Equal(t, RevisionStale, invalidErr.Reason())
+
+	_, err = CheckedSnapshotReader(ctx, checkingDatastore{checkErr: NewInvalidRevisionErr(NoRevision, CouldNotDetermineRevision)}, NoRevision)
+	require.ErrorIs(t, err, ErrRevisionUnavailable)
+
+	// Other failures to check the revision are not reported as unavailable revisions.
+	failure := errors.New("connection failed")
+	_, err = CheckedSnapshotReader(ctx, checkingDatastore{checkErr: failure}, NoRevision)
+	require.ErrorIs(t, err, failure)
+	require.False(t, errors.Is(err, ErrRevisionUnavailable))
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/options"
 	"github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/pkg/caveats"
 	caveattypes "github.com/authzed/spicedb/pkg/caveats/types"
@@ -308,6 +310,105 @@ func CaveatedRelationshipWatchTest(t *testing.T, tester DatastoreTester) {
 	expectTupleChange(t, ds, thirdRevBeforeWrite, tupleWithNilContext)
 }
 
+func CaveatWatchTest(t *testing.T, tester DatastoreTester) {
+	req := require.New(t)
+	ds, err := tester.New(0*time.Second, veryLargeGCWindow, 16)
+	req.NoError(err)
+
+	skipIfNotCaveatStorer(t, ds)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	startRevision := setupDatastore(ds, req)
+
+	changes, errchan := ds.Watch(ctx, startRevision, options.WithIncludeCaveats(true))
+	if len(errchan) > 0 {
+		err := <-errchan
+		if errors.As(err, &datastore.ErrWatchDisabled{}) {
+			t.Skipf("watching caveats is not supported: %s", err)
+		}
+		req.NoError(err)
+	}
+	relationshipChanges, relationshipErrchan := ds.Watch(ctx, startRevision)
+	req.Zero(len(relationshipErrchan))
+
+	first := createCoreCaveat(t)
+	second := createCoreCaveat(t)
+	updatedFirst := createCoreCaveat(t)
+	updatedFirst.Name = first.Name
+
+	tpl := makeTestTuple("caveatwatch", "tom")
+	addedRevision, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		if err := rwt.WriteCaveats(ctx, []*core.CaveatDefinition{first}); err != nil {
+			return err
+		}
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{tuple.Create(tpl)})
+	})
+	req.NoError(err)
+
+	updatedRevision, err := writeCaveats(ctx, ds, updatedFirst, second)
+	req.NoError(err)
+
+	deletedRevision, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.DeleteCaveats(ctx, []string{first.Name})
+	})
+	req.NoError(err)
+
+	expected := []struct {
+		revision      datastore.Revision
+		caveatChanges []datastore.CaveatChange
+	}{
+		{addedRevision, []datastore.CaveatChange{
+			{Operation: datastore.CaveatAdded, Name: first.Name, Definition: first},
+		}},
+		{updatedRevision, sortedCaveatChanges(
+			datastore.CaveatChange{Operation: datastore.CaveatUpdated, Name: first.Name, Definition: updatedFirst},
+			datastore.CaveatChange{Operation: datastore.CaveatAdded, Name: second.Name, Definition: second},
+		)},
+		{deletedRevision, []datastore.CaveatChange{
+			{Operation: datastore.CaveatDeleted, Name: first.Name},
+		}},
+	}
+
+	for i, expectedChange := range expected {
+		changeWait := time.NewTimer(waitForChangesTimeout)
+		select {
+		case change, ok := <-changes:
+			req.True(ok)
+			req.True(expectedChange.revision.Equal(change.Revision), "unexpected revision %s, expected %s", change.Revision, expectedChange.revision)
+			req.Empty(cmp.Diff(expectedChange.caveatChanges, change.CaveatChanges, protocmp.Transform()))
+
+			// The relationship and caveat changes of a revision are emitted together.
+			if i == 0 {
+				req.Len(change.Changes, 1)
+				req.Empty(cmp.Diff(tpl, change.Changes[0].Tuple, protocmp.Transform()))
+			}
+		case err := <-errchan:
+			req.NoError(err)
+		case <-changeWait.C:
+			req.Fail("timed out waiting for caveat changes via Watch API")
+		}
+	}
+
+	// Watches which did not opt into caveat changes do not receive them.
+	changeWait := time.NewTimer(waitForChangesTimeout)
+	select {
+	case change, ok := <-relationshipChanges:
+		req.True(ok)
+		req.Empty(change.CaveatChanges)
+	case <-changeWait.C:
+		req.Fail("timed out waiting for relationship changes via Watch API")
+	}
+}
+
+func sortedCaveatChanges(changes ...datastore.CaveatChange) []datastore.CaveatChange {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
 func expectTupleChange(t *testing.T, ds datastore.Datastore, revBeforeWrite datastore.Revision, expectedTuple *core.RelationTuple) {
 	t.Helper()
 
@@ -0,0 +1,234 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const contendedResourceID = "contended_resource"
+
+// ContentionStats holds the statistics collected by RunConcurrentWriters.
+type ContentionStats struct {
+	// Writes is the number of logical writes that were requested across all writers.
+	Writes uint64
+
+	// Attempts is the number of times a transaction function was invoked by the datastore.
+	Attempts uint64
+
+	// Retries is the number of times a transaction function was re-invoked by the datastore
+	// after a conflict. This is Attempts less the number of logical writes.
+	Retries uint64
+
+	// RelationshipsRead is the number of relationships read by all transaction attempts.
+	RelationshipsRead uint64
+
+	// Committed is the number of logical writes whose transaction committed successfully.
+	Committed uint64
+
+	// Failed is the number of logical writes whose transaction returned an error.
+	Failed uint64
+
+	// LostWrites is the number of committed writes whose relationship could not be found
+	// after all writers completed.
+	LostWrites uint64
+}
+
+// RunConcurrentWriters spawns the given number of writers, each of which performs writesPerWriter
+// read-modify-write transactions against the same resource. Every transaction reads the
+// relationships of the contended resource before adding a new relationship to it, which forces
+// conflicts under serializable isolation. Once all writers complete, the relationships committed
+// are read back at head to detect lost writes.
+//
+// The datastore must have had the test namespaces written to it (see setupDatastore).
+func RunConcurrentWriters(ctx context.Context, ds datastore.Datastore, writers, writesPerWriter int) (ContentionStats, error) {
+	var stats ContentionStats
+	var committedLock sync.Mutex
+	committed := make([]*core.RelationTuple, 0, writers*writesPerWriter)
+
+	g, gctx := errgroup.WithContext(ctx)
+	for writerIndex := 0; writerIndex < writers; writerIndex++ {
+		writerIndex := writerIndex
+		g.Go(func() error {
+			for writeIndex := 0; writeIndex < writesPerWriter; writeIndex++ {
+				tpl := makeTestTuple(contendedResourceID, fmt.Sprintf("writer%d_write%d", writerIndex, writeIndex))
+				atomic.AddUint64(&stats.Writes, 1)
+
+				_, err := ds.ReadWriteTx(gctx, func(rwt datastore.ReadWriteTransaction) error {
+					atomic.AddUint64(&stats.Attempts, 1)
+
+					iter, err := rwt.QueryRelationships(gctx, datastore.RelationshipsFilter{
+						ResourceType:        testResourceNamespace,
+						OptionalResourceIds: []string{contendedResourceID},
+					})
+					if err != nil {
+						return err
+					}
+					for found := iter.Next(); found != nil; found = iter.Next() {
+						atomic.AddUint64(&stats.RelationshipsRead, 1)
+					}
+					iter.Close()
+					if iter.Err() != nil {
+						return iter.Err()
+					}
+
+					return rwt.WriteRelationships(gctx, []*core.RelationTupleUpdate{tuple.Create(tpl)})
+				})
+				if err != nil {
+					atomic.AddUint64(&stats.Failed, 1)
+					continue
+				}
+
+				atomic.AddUint64(&stats.Committed, 1)
+				committedLock.Lock()
+				committed = append(committed, tpl)
+				committedLock.Unlock()
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return stats, err
+	}
+
+	stats.Retries = stats.Attempts - stats.Writes
+
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return stats, err
+	}
+
+	iter, err := ds.SnapshotReader(headRevision).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType:        testResourceNamespace,
+		OptionalResourceIds: []string{contendedResourceID},
+	})
+	if err != nil {
+		return stats, err
+	}
+	defer iter.Close()
+
+	found := make(map[string]struct{}, len(committed))
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		found[tuple.StringWithoutCaveat(tpl)] = struct{}{}
+	}
+	if iter.Err() != nil {
+		return stats, iter.Err()
+	}
+
+	for _, tpl := range committed {
+		if _, ok := found[tuple.StringWithoutCaveat(tpl)]; !ok {
+			stats.LostWrites++
+		}
+	}
+
+	return stats, nil
+}
+
+// ConcurrentWriterContentionTest runs conflicting writers against the datastore and ensures
+// that its retry behavior converges without any lost writes.
+//
+// The datastore must retry the transactions failing with a serialization failure until they
+// commit, such as through the retrying proxy, since every such failure fails the test.
+func ConcurrentWriterContentionTest(t *testing.T, ds datastore.Datastore) {
+	require := require.New(t)
+
+	setupDatastore(ds, require)
+
+	const writers = 4
+	const writesPerWriter = 5
+
+	stats, err := RunConcurrentWriters(context.Background(), ds, writers, writesPerWriter)
+	require.NoError(err)
+	require.Equal(uint64(writers*writesPerWriter), stats.Writes)
+	require.Equal(stats.Writes, stats.Committed+stats.Failed)
+	require.Zero(stats.Failed, "writes failed to converge: %+v", stats)
+	require.Zero(stats.LostWrites, "writes were lost: %+v", stats)
+	require.GreaterOrEqual(stats.Attempts, stats.Writes)
+}
+
+// SerializationConflictTest runs two transactions which both read the relationships of the same
+// resource before either adds a relationship to it, so that they conflict under serializable
+// isolation, and ensures that both are committed.
+//
+// The datastore must run read-write transactions concurrently, and must retry a transaction
+// failing with a serialization failure by running its function again, so that at least one retry
+// is observed.
+func SerializationConflictTest(t *testing.T, ds datastore.Datastore) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	setupDatastore(ds, require)
+
+	var attempts uint64
+	var read sync.WaitGroup
+	read.Add(2)
+
+	g, gctx := errgroup.WithContext(ctx)
+	for writerIndex := 0; writerIndex < 2; writerIndex++ {
+		written := makeTestTuple(contendedResourceID, fmt.Sprintf("writer%d", writerIndex))
+		g.Go(func() error {
+			// Signal the read even if the writer fails before reaching it, so that the other
+			// writer is not left waiting.
+			var signalRead sync.Once
+			defer signalRead.Do(read.Done)
+
+			firstAttempt := true
+			_, err := ds.ReadWriteTx(gctx, func(rwt datastore.ReadWriteTransaction) error {
+				atomic.AddUint64(&attempts, 1)
+
+				iter, err := rwt.QueryRelationships(gctx, datastore.RelationshipsFilter{
+					ResourceType:        testResourceNamespace,
+					OptionalResourceIds: []string{contendedResourceID},
+				})
+				if err != nil {
+					return err
+				}
+				for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+				}
+				iter.Close()
+				if iter.Err() != nil {
+					return iter.Err()
+				}
+
+				// Both transactions read before either writes on their first attempt.
+				if firstAttempt {
+					firstAttempt = false
+					signalRead.Do(read.Done)
+					read.Wait()
+				}
+
+				return rwt.WriteRelationships(gctx, []*core.RelationTupleUpdate{tuple.Create(written)})
+			})
+			return err
+		})
+	}
+	require.NoError(g.Wait())
+	require.GreaterOrEqual(attempts, uint64(3), "no serialization failure was retried")
+
+	headRevision, err := ds.HeadRevision(ctx)
+	require.NoError(err)
+
+	iter, err := ds.SnapshotReader(headRevision).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType:        testResourceNamespace,
+		OptionalResourceIds: []string{contendedResourceID},
+	})
+	require.NoError(err)
+	defer iter.Close()
+
+	found := 0
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		found++
+	}
+	require.NoError(iter.Err())
+	require.Equal(2, found)
+}
@@ -46,6 +46,9 @@ func All(t *testing.T, tester DatastoreTester) {
 	t.Run("TestWriteDeleteWrite", func(t *testing.T) { WriteDeleteWriteTest(t, tester) })
 	t.Run("TestCreateAlreadyExisting", func(t *testing.T) { CreateAlreadyExistingTest(t, tester) })
 	t.Run("TestTouchAlreadyExisting", func(t *testing.T) { TouchAlreadyExistingTest(t, tester) })
+	t.Run("TestBulkLoad", func(t *testing.T) { BulkLoadTest(t, tester) })
+	t.Run("TestBulkDeleteRelationships", func(t *testing.T) { BulkDeleteRelationshipsTest(t, tester) })
+	t.Run("TestQueryRelationshipsPage", func(t *testing.T) { QueryRelationshipsPageTest(t, tester) })
 	t.Run("TestUsersets", func(t *testing.T) { UsersetsTest(t, tester) })
 	t.Run("TestMultipleReadsInRWT", func(t *testing.T) { MultipleReadsInRWTTest(t, tester) })
 	t.Run("TestConcurrentWriteSerialization", func(t *testing.T) { ConcurrentWriteSerializationTest(t, tester) })
@@ -63,6 +66,11 @@ func All(t *testing.T, tester DatastoreTester) {
 	t.Run("TestCaveatedRelationshipFilter", func(t *testing.T) { CaveatedRelationshipFilterTest(t, tester) })
 	t.Run("TestCaveatSnapshotReads", func(t *testing.T) { CaveatSnapshotReadsTest(t, tester) })
 	t.Run("TestCaveatedRelationshipWatch", func(t *testing.T) { CaveatedRelationshipWatchTest(t, tester) })
+	t.Run("TestCaveatWatch", func(t *testing.T) { CaveatWatchTest(t, tester) })
+	t.Run("TestRelationshipExportRoundTrip", func(t *testing.T) { RelationshipExportRoundTripTest(t, tester) })
+	t.Run("TestDatastoreExport", func(t *testing.T) { DatastoreExportTest(t, tester) })
+	t.Run("TestDatastoreImport", func(t *testing.T) { DatastoreImportTest(t, tester) })
+	t.Run("TestIntegrityCheck", func(t *testing.T) { IntegrityCheckTest(t, tester) })
 }
 
 var testResourceNS = namespace.Namespace(
@@ -0,0 +1,265 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// RelationshipExportRoundTripTest tests that relationships exported from a datastore are
+// imported into an empty datastore faithfully, including their caveats and caveat contexts.
+func RelationshipExportRoundTripTest(t *testing.T, tester DatastoreTester) {
+	req := require.New(t)
+	ctx := context.Background()
+
+	sourceDS, err := tester.New(0*time.Second, veryLargeGCWindow, 1)
+	req.NoError(err)
+	defer sourceDS.Close()
+	skipIfNotCaveatStorer(t, sourceDS)
+
+	setupDatastore(sourceDS, req)
+	coreCaveat := createCoreCaveat(t)
+	_, err = writeCaveats(ctx, sourceDS, coreCaveat)
+	req.NoError(err)
+
+	caveatContext, err := structpb.NewStruct(map[string]any{
+		"string": "hello",
+		"int":    42,
+		"double": 3.5,
+		"bool":   true,
+		"null":   nil,
+		"list":   []any{1, "two", false},
+		"nested": map[string]any{
+			"key":   "value",
+			"inner": map[string]any{"negative": -1},
+		},
+	})
+	req.NoError(err)
+
+	caveated := makeTestTuple("caveated", "tom")
+	caveated.Caveat = &core.ContextualizedCaveat{
+		CaveatName: coreCaveat.Name,
+		Context:    caveatContext,
+	}
+
+	userset := makeTestTuple("userset", "")
+	userset.Subject = &core.ObjectAndRelation{
+		Namespace: testGroupNamespace,
+		ObjectId:  "eng",
+		Relation:  testMemberRelation,
+	}
+
+	rev, err := common.WriteTuples(ctx, sourceDS, core.RelationTupleUpdate_CREATE,
+		makeTestTuple("first", "tom"),
+		makeTestTuple("second", "sarah"),
+		caveated,
+		userset,
+	)
+	req.NoError(err)
+
+	buf := &bytes.Buffer{}
+	exported, err := datastore.Export(ctx, sourceDS, rev, buf, nil)
+	req.NoError(err)
+	req.Equal(datastore.ExportProgress{Namespaces: 3, Caveats: 1, Relationships: 4}, exported)
+	req.Len(strings.Split(strings.TrimSpace(buf.String()), "\n"), 1+3+1+4)
+
+	targetDS, err := tester.New(0*time.Second, veryLargeGCWindow, 1)
+	req.NoError(err)
+	defer targetDS.Close()
+
+	result, err := datastore.Import(ctx, targetDS, bytes.NewReader(buf.Bytes()), datastore.ConflictFail)
+	req.NoError(err)
+	req.Equal(datastore.ImportCounts{Imported: 4}, result.Relationships)
+
+	targetRev, err := targetDS.HeadRevision(ctx)
+	req.NoError(err)
+
+	expected := readAllRelationships(req, sourceDS.SnapshotReader(rev))
+	found := readAllRelationships(req, targetDS.SnapshotReader(targetRev))
+	req.Len(found, 4)
+	req.Empty(cmp.Diff(expected, found, protocmp.Transform()))
+
+	// Exports of another version are rejected.
+	_, err = datastore.Import(ctx, targetDS, strings.NewReader(`{"version":42}`), datastore.ConflictSkip)
+	req.ErrorContains(err, "unsupported export version 42")
+}
+
+// DatastoreExportTest tests exporting all the definitions and relationships of a datastore at a
+// revision, across multiple pages of relationships.
+func DatastoreExportTest(t *testing.T, tester DatastoreTester) {
+	req := require.New(t)
+	ctx := context.Background()
+
+	ds, err := tester.New(0*time.Second, veryLargeGCWindow, 1)
+	req.NoError(err)
+	defer ds.Close()
+	skipIfNotCaveatStorer(t, ds)
+
+	setupDatastore(ds, req)
+	_, err = writeCaveats(ctx, ds, createCoreCaveat(t))
+	req.NoError(err)
+
+	const relCount = 2500
+	tuples := make([]*core.RelationTuple, 0, relCount)
+	for i := 0; i < relCount; i++ {
+		tuples = append(tuples, makeTestTuple(fmt.Sprintf("resource%d", i), "user"))
+	}
+	rev, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.BulkLoad(ctx, datastore.NewSliceRelationshipIterator(tuples))
+		return err
+	})
+	req.NoError(err)
+
+	// Relationships written after the revision are not exported.
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, makeTestTuple("later", "user"))
+	req.NoError(err)
+
+	var reported []datastore.ExportProgress
+	buf := &bytes.Buffer{}
+	exported, err := datastore.Export(ctx, ds, rev, buf, func(progress datastore.ExportProgress) {
+		reported = append(reported, progress)
+	})
+	req.NoError(err)
+	req.Equal(datastore.ExportProgress{Namespaces: 3, Caveats: 1, Relationships: relCount}, exported)
+	req.Equal(exported, reported[len(reported)-1])
+	req.Equal(datastore.ExportProgress{Namespaces: 3, Caveats: 1}, reported[0])
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	req.Len(lines, 1+3+1+relCount)
+	req.Contains(lines[0], fmt.Sprintf(`"version":%d`, datastore.ExportVersion))
+	req.Contains(lines[0], rev.String())
+}
+
+// DatastoreImportTest tests importing a datastore export into empty and non-empty datastores,
+// with each conflict strategy.
+func DatastoreImportTest(t *testing.T, tester DatastoreTester) {
+	req := require.New(t)
+	ctx := context.Background()
+
+	sourceDS, err := tester.New(0*time.Second, veryLargeGCWindow, 1)
+	req.NoError(err)
+	defer sourceDS.Close()
+	skipIfNotCaveatStorer(t, sourceDS)
+
+	setupDatastore(sourceDS, req)
+	coreCaveat := createCoreCaveat(t)
+	_, err = writeCaveats(ctx, sourceDS, coreCaveat)
+	req.NoError(err)
+
+	caveatContext, err := structpb.NewStruct(map[string]any{"key": "value"})
+	req.NoError(err)
+
+	caveated := makeTestTuple("caveated", "tom")
+	caveated.Caveat = &core.ContextualizedCaveat{
+		CaveatName: coreCaveat.Name,
+		Context:    caveatContext,
+	}
+
+	rev, err := common.WriteTuples(ctx, sourceDS, core.RelationTupleUpdate_CREATE,
+		makeTestTuple("first", "tom"),
+		makeTestTuple("second", "sarah"),
+		caveated,
+	)
+	req.NoError(err)
+
+	buf := &bytes.Buffer{}
+	_, err = datastore.Export(ctx, sourceDS, rev, buf, nil)
+	req.NoError(err)
+
+	targetDS, err := tester.New(0*time.Second, veryLargeGCWindow, 1)
+	req.NoError(err)
+	defer targetDS.Close()
+
+	result, err := datastore.Import(ctx, targetDS, bytes.NewReader(buf.Bytes()), datastore.ConflictFail)
+	req.NoError(err)
+	req.Equal(datastore.ImportResult{
+		Namespaces:    datastore.ImportCounts{Imported: 3},
+		Caveats:       datastore.ImportCounts{Imported: 1},
+		Relationships: datastore.ImportCounts{Imported: 3},
+	}, result)
+
+	targetRev, err := targetDS.HeadRevision(ctx)
+	req.NoError(err)
+
+	expected := readAllRelationships(req, sourceDS.SnapshotReader(rev))
+	found := readAllRelationships(req, targetDS.SnapshotReader(targetRev))
+	req.Empty(cmp.Diff(expected, found, protocmp.Transform()))
+
+	_, err = datastore.Import(ctx, targetDS, bytes.NewReader(buf.Bytes()), datastore.ConflictFail)
+	req.ErrorIs(err, datastore.ErrImportConflict)
+
+	// Relationships missing from the target are imported alongside the existing ones.
+	_, err = common.WriteTuples(ctx, targetDS, core.RelationTupleUpdate_DELETE, makeTestTuple("first", "tom"))
+	req.NoError(err)
+
+	result, err = datastore.Import(ctx, targetDS, bytes.NewReader(buf.Bytes()), datastore.ConflictSkip)
+	req.NoError(err)
+	req.Equal(datastore.ImportResult{
+		Namespaces:    datastore.ImportCounts{Skipped: 3},
+		Caveats:       datastore.ImportCounts{Skipped: 1},
+		Relationships: datastore.ImportCounts{Imported: 1, Skipped: 2},
+	}, result)
+
+	result, err = datastore.Import(ctx, targetDS, bytes.NewReader(buf.Bytes()), datastore.ConflictOverwrite)
+	req.NoError(err)
+	req.Equal(datastore.ImportResult{
+		Namespaces:    datastore.ImportCounts{Overwritten: 3},
+		Caveats:       datastore.ImportCounts{Overwritten: 1},
+		Relationships: datastore.ImportCounts{Overwritten: 3},
+	}, result)
+
+	targetRev, err = targetDS.HeadRevision(ctx)
+	req.NoError(err)
+	found = readAllRelationships(req, targetDS.SnapshotReader(targetRev))
+	req.Empty(cmp.Diff(expected, found, protocmp.Transform()))
+
+	// A relationship appearing twice in a batch is written once.
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	duplicated := strings.Join(append(lines, lines[len(lines)-1]), "\n")
+
+	duplicateDS, err := tester.New(0*time.Second, veryLargeGCWindow, 1)
+	req.NoError(err)
+	defer duplicateDS.Close()
+
+	result, err = datastore.Import(ctx, duplicateDS, strings.NewReader(duplicated), datastore.ConflictFail)
+	req.NoError(err)
+	req.Equal(datastore.ImportCounts{Imported: 3}, result.Relationships)
+
+	duplicateRev, err := duplicateDS.HeadRevision(ctx)
+	req.NoError(err)
+	found = readAllRelationships(req, duplicateDS.SnapshotReader(duplicateRev))
+	req.Empty(cmp.Diff(expected, found, protocmp.Transform()))
+}
+
+func readAllRelationships(req *require.Assertions, reader datastore.Reader) []*core.RelationTuple {
+	iter, err := reader.QueryRelationships(context.Background(), datastore.RelationshipsFilter{
+		ResourceType: testResourceNamespace,
+	})
+	req.NoError(err)
+	defer iter.Close()
+
+	var found []*core.RelationTuple
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		found = append(found, tpl)
+	}
+	req.NoError(iter.Err())
+
+	sort.Slice(found, func(i, j int) bool {
+		return tuple.StringWithoutCaveat(found[i]) < tuple.StringWithoutCaveat(found[j])
+	})
+	return found
+}
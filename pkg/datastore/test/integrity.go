@@ -0,0 +1,76 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// IntegrityCheckTest tests that the integrity check reports the relationships referencing
+// undefined definitions, and only those.
+func IntegrityCheckTest(t *testing.T, tester DatastoreTester) {
+	req := require.New(t)
+	ctx := context.Background()
+
+	ds, err := tester.New(0*time.Second, veryLargeGCWindow, 1)
+	req.NoError(err)
+	skipIfNotCaveatStorer(t, ds)
+	setupDatastore(ds, req)
+
+	undefinedResourceType := makeTestTuple("undefinedresourcetype", "tom")
+	undefinedResourceType.ResourceAndRelation.Namespace = "test/undefined"
+
+	undefinedRelation := makeTestTuple("undefinedrelation", "tom")
+	undefinedRelation.ResourceAndRelation.Relation = "writer"
+
+	undefinedSubjectType := makeTestTuple("undefinedsubjecttype", "tom")
+	undefinedSubjectType.Subject.Namespace = "test/undefined"
+
+	undefinedSubjectRelation := makeTestTuple("undefinedsubjectrelation", "")
+	undefinedSubjectRelation.Subject = &core.ObjectAndRelation{
+		Namespace: testGroupNamespace,
+		ObjectId:  "eng",
+		Relation:  "owner",
+	}
+
+	undefinedCaveat := makeTestTuple("undefinedcaveat", "tom")
+	undefinedCaveat.Caveat = &core.ContextualizedCaveat{CaveatName: "undefined"}
+
+	rev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE,
+		makeTestTuple("valid", "tom"),
+		undefinedResourceType,
+		undefinedRelation,
+		undefinedSubjectType,
+		undefinedSubjectRelation,
+		undefinedCaveat,
+	)
+	req.NoError(err)
+
+	violations := map[string]datastore.IntegrityViolationReason{}
+	checked, err := datastore.CheckIntegrity(ctx, ds.SnapshotReader(rev), func(violation datastore.IntegrityViolation) error {
+		violations[violation.Relationship.ResourceAndRelation.ObjectId] = violation.Reason
+		return nil
+	})
+	req.NoError(err)
+	req.Equal(uint64(6), checked)
+	req.Equal(map[string]datastore.IntegrityViolationReason{
+		"undefinedresourcetype":    datastore.UndefinedResourceType,
+		"undefinedrelation":        datastore.UndefinedRelation,
+		"undefinedsubjecttype":     datastore.UndefinedSubjectType,
+		"undefinedsubjectrelation": datastore.UndefinedSubjectRelation,
+		"undefinedcaveat":          datastore.UndefinedCaveat,
+	}, violations)
+
+	errStop := errors.New("stop")
+	_, err = datastore.CheckIntegrity(ctx, ds.SnapshotReader(rev), func(violation datastore.IntegrityViolation) error {
+		return errStop
+	})
+	req.ErrorIs(err, errStop)
+}
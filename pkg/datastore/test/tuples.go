@@ -595,6 +595,202 @@ func TouchAlreadyExistingTest(t *testing.T, tester DatastoreTester) {
 	require.NoError(err)
 }
 
+// BulkLoadTest tests loading relationships in bulk, across multiple batches.
+func BulkLoadTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCWindow, 1)
+	require.NoError(err)
+	defer ds.Close()
+
+	setupDatastore(ds, require)
+	ctx := context.Background()
+
+	const relCount = 2500
+	tuples := make([]*core.RelationTuple, 0, relCount)
+	for i := 0; i < relCount; i++ {
+		tuples = append(tuples, makeTestTuple(fmt.Sprintf("resource%d", i), "user"))
+	}
+
+	var loaded uint64
+	revision, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		var err error
+		loaded, err = rwt.BulkLoad(ctx, datastore.NewSliceRelationshipIterator(tuples))
+		return err
+	})
+	require.NoError(err)
+	require.Equal(uint64(relCount), loaded)
+
+	iter, err := ds.SnapshotReader(revision).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType: testResourceNamespace,
+	})
+	require.NoError(err)
+
+	tRequire := testfixtures.TupleChecker{Require: require, DS: ds}
+	tRequire.VerifyIteratorCount(iter, relCount)
+
+	// Loading an existing relationship fails the entire load.
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.BulkLoad(ctx, datastore.NewSliceRelationshipIterator([]*core.RelationTuple{
+			makeTestTuple("another", "user"),
+			tuples[0],
+		}))
+		return err
+	})
+	require.ErrorAs(err, &common.CreateRelationshipExistsError{})
+
+	head, err := ds.HeadRevision(ctx)
+	require.NoError(err)
+	tRequire.NoTupleExists(ctx, makeTestTuple("another", "user"), head)
+}
+
+// BulkDeleteRelationshipsTest tests deleting the relationships matching a filter in bulk, across
+// multiple batches and with a limit.
+func BulkDeleteRelationshipsTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCWindow, 1)
+	require.NoError(err)
+	defer ds.Close()
+
+	setupDatastore(ds, require)
+	ctx := context.Background()
+
+	const tenantCount = 2500
+	tuples := make([]*core.RelationTuple, 0, tenantCount+2)
+	for i := 0; i < tenantCount; i++ {
+		tuples = append(tuples, makeTestTuple(fmt.Sprintf("tenant_1/resource%d", i), "user"))
+	}
+	tenant11 := makeTestTuple("tenant_11/resource", "user")
+	other := makeTestTuple("tenant21/resource", "user")
+	tuples = append(tuples, tenant11, other)
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.BulkLoad(ctx, datastore.NewSliceRelationshipIterator(tuples))
+		return err
+	})
+	require.NoError(err)
+
+	filter := datastore.RelationshipsFilter{
+		ResourceType:             testResourceNamespace,
+		OptionalResourceIDPrefix: "tenant_1/",
+	}
+
+	var deleted uint64
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		var err error
+		deleted, err = rwt.BulkDeleteRelationships(ctx, filter, 1500)
+		return err
+	})
+	require.NoError(err)
+	require.Equal(uint64(1500), deleted)
+
+	revision, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		var err error
+		deleted, err = rwt.BulkDeleteRelationships(ctx, filter, 0)
+		return err
+	})
+	require.NoError(err)
+	require.Equal(uint64(tenantCount-1500), deleted)
+
+	tRequire := testfixtures.TupleChecker{Require: require, DS: ds}
+
+	iter, err := ds.SnapshotReader(revision).QueryRelationships(ctx, filter)
+	require.NoError(err)
+	tRequire.VerifyIteratorCount(iter, 0)
+
+	tRequire.TupleExists(ctx, tenant11, revision)
+	tRequire.TupleExists(ctx, other, revision)
+	tRequire.NoTupleExists(ctx, tuples[0], revision)
+
+	// Delete by subject, leaving the relationships of other subjects.
+	otherSubject := makeTestTuple("tenant21/resource", "otheruser")
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{tuple.Create(otherSubject)})
+	})
+	require.NoError(err)
+
+	revision, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		var err error
+		deleted, err = rwt.BulkDeleteRelationships(ctx, datastore.RelationshipsFilter{
+			ResourceType: testResourceNamespace,
+			OptionalSubjectsSelectors: []datastore.SubjectsSelector{{
+				OptionalSubjectType: testUserNamespace,
+				OptionalSubjectIds:  []string{"user"},
+			}},
+		}, 0)
+		return err
+	})
+	require.NoError(err)
+	require.Equal(uint64(2), deleted)
+
+	tRequire.NoTupleExists(ctx, tenant11, revision)
+	tRequire.NoTupleExists(ctx, other, revision)
+	tRequire.TupleExists(ctx, otherSubject, revision)
+}
+
+// QueryRelationshipsPageTest tests reading relationships page by page, with writes made between
+// pages.
+func QueryRelationshipsPageTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCWindow, 1)
+	require.NoError(err)
+	defer ds.Close()
+
+	setupDatastore(ds, require)
+	ctx := context.Background()
+
+	const relCount = 250
+	tuples := make([]*core.RelationTuple, 0, relCount)
+	for i := 0; i < relCount; i++ {
+		tuples = append(tuples, makeTestTuple(fmt.Sprintf("resource%d", i%50), fmt.Sprintf("user%d", i/50)))
+	}
+
+	revision, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.BulkLoad(ctx, datastore.NewSliceRelationshipIterator(tuples))
+		return err
+	})
+	require.NoError(err)
+
+	filter := datastore.RelationshipsFilter{ResourceType: testResourceNamespace}
+
+	var read []*core.RelationTuple
+	var cursor datastore.Cursor
+	for page := 0; ; page++ {
+		tpls, next, err := datastore.QueryRelationshipsPage(ctx, ds, "test", revision, filter, 100, cursor)
+		require.NoError(err)
+		read = append(read, tpls...)
+
+		if next == "" {
+			require.Equal(2, page)
+			break
+		}
+		cursor = next
+
+		// Writes made between pages are not seen by the following pages.
+		_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, makeTestTuple(fmt.Sprintf("added%d", page), "user"))
+		require.NoError(err)
+		_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_DELETE, tuples[page])
+		require.NoError(err)
+	}
+
+	require.Len(read, relCount)
+	seen := make(map[string]struct{}, relCount)
+	for i, tpl := range read {
+		key := tuple.StringWithoutCaveat(tpl)
+		require.NotContains(seen, key)
+		seen[key] = struct{}{}
+
+		if i > 0 {
+			require.Equal(read[i-1].ResourceAndRelation.Namespace, tpl.ResourceAndRelation.Namespace)
+		}
+	}
+
+	_, _, err = datastore.QueryRelationshipsPage(ctx, ds, "another", revision, filter, 100, cursor)
+	require.ErrorIs(err, datastore.ErrInvalidCursor)
+}
+
 // UsersetsTest tests whether or not the requirements for reading usersets hold
 // for a particular datastore.
 func UsersetsTest(t *testing.T, tester DatastoreTester) {
@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// KeyRange is the half-open range of integer keys [Start, End).
+type KeyRange struct {
+	Start uint64
+	End   uint64
+}
+
+// SplitKeyRange splits the half-open range of integer keys [start, end) into at most count
+// disjoint ranges of nearly equal size, covering it entirely.
+func SplitKeyRange(start, end uint64, count uint16) []KeyRange {
+	if end <= start {
+		return nil
+	}
+	if count == 0 {
+		count = 1
+	}
+
+	size := end - start
+	if uint64(count) > size {
+		count = uint16(size)
+	}
+
+	ranges := make([]KeyRange, 0, count)
+	step, remainder := size/uint64(count), size%uint64(count)
+	for i := uint64(0); i < uint64(count); i++ {
+		rangeEnd := start + step
+		if i < remainder {
+			rangeEnd++
+		}
+		ranges = append(ranges, KeyRange{Start: start, End: rangeEnd})
+		start = rangeEnd
+	}
+	return ranges
+}
+
+// BackfillBatchFunc backfills a single batch of rows within the key range, returning the number
+// of rows it processed. It is called repeatedly with the same range until it processes no row.
+type BackfillBatchFunc[R any] func(ctx context.Context, keyRange R) (uint64, error)
+
+// Backfill runs an incremental backfill over the key ranges, which must be disjoint, returning
+// the total number of rows processed. Up to the BackfillParallelism set in the context ranges
+// are backfilled concurrently, one batch at a time each, so that many transactions are in flight
+// at most. The progress is reported after each batch, as set with BackfillProgress.
+//
+// The first error returned by a batch, or the cancellation of the context, stops the backfill.
+// As batches commit independently, a stopped backfill must be safe to resume by running it again.
+func Backfill[R any](ctx context.Context, migrationName string, ranges []R, backfillBatch BackfillBatchFunc[R]) (uint64, error) {
+	parallelism, _ := ctx.Value(BackfillParallelism).(uint16)
+	if parallelism == 0 {
+		parallelism = 1
+	}
+
+	var lock sync.Mutex
+	var processed uint64
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(int(parallelism))
+	for _, keyRange := range ranges {
+		keyRange := keyRange
+		g.Go(func() error {
+			for {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
+				count, err := backfillBatch(ctx, keyRange)
+				if err != nil {
+					return err
+				}
+				if count == 0 {
+					return nil
+				}
+
+				lock.Lock()
+				processed += count
+				ReportBackfillProgress(ctx, migrationName, processed, 0)
+				lock.Unlock()
+			}
+		})
+	}
+
+	err := g.Wait()
+
+	lock.Lock()
+	defer lock.Unlock()
+	return processed, err
+}
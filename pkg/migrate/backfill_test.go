@@ -0,0 +1,100 @@
+package migrate
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitKeyRange(t *testing.T) {
+	testCases := []struct {
+		start, end uint64
+		count      uint16
+		expected   []KeyRange
+	}{
+		{0, 0, 4, nil},
+		{0, 10, 0, []KeyRange{{0, 10}}},
+		{0, 10, 1, []KeyRange{{0, 10}}},
+		{0, 10, 3, []KeyRange{{0, 4}, {4, 7}, {7, 10}}},
+		{5, 8, 5, []KeyRange{{5, 6}, {6, 7}, {7, 8}}},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, SplitKeyRange(tc.start, tc.end, tc.count))
+	}
+}
+
+func TestParallelBackfill(t *testing.T) {
+	req := require.New(t)
+
+	const rowCount = 10_000
+	const batchSize = 100
+	const parallelism = 4
+
+	var lock sync.Mutex
+	timesProcessed := make([]int, rowCount)
+
+	var inFlight, maxInFlight int64
+	backfillBatch := func(ctx context.Context, keyRange KeyRange) (uint64, error) {
+		current := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			previous := atomic.LoadInt64(&maxInFlight)
+			if current <= previous || atomic.CompareAndSwapInt64(&maxInFlight, previous, current) {
+				break
+			}
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		var count uint64
+		for key := keyRange.Start; key < keyRange.End && count < batchSize; key++ {
+			if timesProcessed[key] == 0 {
+				timesProcessed[key]++
+				count++
+			}
+		}
+		return count, nil
+	}
+
+	var lastReported uint64
+	ctx := context.WithValue(context.Background(), BackfillParallelism, uint16(parallelism))
+	ctx = context.WithValue(ctx, BackfillProgress, BackfillProgressFunc(
+		func(migrationName string, rowsProcessed, rowsTotalEstimate uint64) {
+			req.Equal("backfill", migrationName)
+			req.Greater(rowsProcessed, lastReported)
+			lastReported = rowsProcessed
+		},
+	))
+
+	processed, err := Backfill(ctx, "backfill", SplitKeyRange(0, rowCount, 16), backfillBatch)
+	req.NoError(err)
+	req.Equal(uint64(rowCount), processed)
+	req.Equal(uint64(rowCount), lastReported)
+	req.LessOrEqual(maxInFlight, int64(parallelism))
+
+	for key, times := range timesProcessed {
+		req.Equal(1, times, "row %d", key)
+	}
+}
+
+func TestParallelBackfillCancellation(t *testing.T) {
+	req := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = context.WithValue(ctx, BackfillParallelism, uint16(2))
+
+	var batches int64
+	_, err := Backfill(ctx, "backfill", SplitKeyRange(0, 100, 4), func(ctx context.Context, keyRange KeyRange) (uint64, error) {
+		if atomic.AddInt64(&batches, 1) == 1 {
+			cancel()
+		}
+		return 1, nil
+	})
+	req.ErrorIs(err, context.Canceled)
+	req.Less(atomic.LoadInt64(&batches), int64(100))
+}
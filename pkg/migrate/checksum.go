@@ -0,0 +1,156 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ChecksumDriver is implemented by drivers able to store a checksum of the definition of each
+// migration applied, so that migrations edited after being applied can be detected.
+//
+// Checksums are computed over the statements captured by dry-running the migrations, so drivers
+// implementing ChecksumDriver must also implement DryRunDriver for checksums to be verified. Of
+// the datastores, only postgres implements both: the migrations of the other datastores are not
+// verified, which Manager.Run logs a warning about.
+type ChecksumDriver[T any] interface {
+	// Checksums returns the stored checksums of the applied migrations, by version.
+	Checksums(ctx context.Context) (map[string]string, error)
+
+	// WriteChecksum stores the checksum of the applied migration with the version, replacing any
+	// checksum stored for it.
+	WriteChecksum(ctx context.Context, tx T, version string, checksum string) error
+}
+
+// checksummer is a driver able to both compute and store migration checksums.
+type checksummer[C any, T any] interface {
+	ChecksumDriver[T]
+	DryRunDriver[C, T]
+}
+
+// asChecksummer returns the driver as a checksummer, if it supports checksums.
+func (m *Manager[D, C, T]) asChecksummer(driver D) (checksummer[C, T], bool) {
+	c, ok := any(driver).(checksummer[C, T])
+	return c, ok
+}
+
+// checksum computes the checksum of the definition of the migration, from the statements captured
+// by dry-running it. Migrations marked as not replayable with MarkNonReplayable run through to
+// their end, as none of their statements updates any row when dry-run, such that all of their
+// statements are covered. Migrations returning a NonReplayableError, as they do when reading from
+// the database, are only covered up to that point.
+func (m *Manager[D, C, T]) checksum(ctx context.Context, driver DryRunDriver[C, T], toChecksum migration[C, T]) (string, error) {
+	recorder := &StatementRecorder{}
+	ctx, state := withDryRun(ctx)
+	err := func() error {
+		if toChecksum.up != nil {
+			if err := toChecksum.up(ctx, driver.DryRunConn(recorder)); err != nil {
+				return err
+			}
+		}
+		if toChecksum.upTx != nil {
+			return toChecksum.upTx(ctx, driver.DryRunTx(recorder))
+		}
+		return nil
+	}()
+
+	hash := sha256.New()
+	for _, stmt := range recorder.statements {
+		fmt.Fprintf(hash, "%s\x00%v\n", stmt.SQL, stmt.Args)
+	}
+
+	if err != nil {
+		var nonReplayable NonReplayableError
+		if !errors.As(err, &nonReplayable) {
+			return "", fmt.Errorf("unable to compute checksum of migration `%s`: %w", toChecksum.version, err)
+		}
+		fmt.Fprintf(hash, "non-replayable: %s\n", nonReplayable.Reason)
+	} else if state.nonReplayableReason != "" {
+		fmt.Fprintf(hash, "non-replayable: %s\n", state.nonReplayableReason)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// verifyChecksums verifies that the checksums stored for the migrations applied to the backing
+// datastore match their current definitions. Migrations applied without storing their checksum
+// are not verified.
+func (m *Manager[D, C, T]) verifyChecksums(ctx context.Context, driver D, checksums checksummer[C, T]) error {
+	current, err := driver.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to load version from driver: %w", err)
+	}
+
+	stored, err := checksums.Checksums(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to load migration checksums: %w", err)
+	}
+
+	for version := current; version != ""; {
+		applied, ok := m.migrations[version]
+		if !ok {
+			return fmt.Errorf("unable to find migration for revision: %s", version)
+		}
+
+		if storedChecksum, ok := stored[version]; ok {
+			checksum, err := m.checksum(ctx, checksums, applied)
+			if err != nil {
+				return err
+			}
+
+			if checksum != storedChecksum {
+				return fmt.Errorf(
+					"migration `%s` was modified after being applied: stored checksum %s does not match %s; if the change is intended, re-baseline the checksums",
+					version, storedChecksum, checksum,
+				)
+			}
+		}
+
+		version = applied.replaces
+	}
+
+	return nil
+}
+
+// RebaselineChecksums stores the checksums of the current definitions of all the migrations
+// applied to the backing datastore, replacing the stored ones. It is meant to accept changes to
+// applied migrations known not to alter their effect, such as reformatting. The driver must
+// implement both ChecksumDriver and DryRunDriver.
+func (m *Manager[D, C, T]) RebaselineChecksums(ctx context.Context, driver D) error {
+	checksums, ok := m.asChecksummer(driver)
+	if !ok {
+		return fmt.Errorf("migration driver %T does not support checksums", driver)
+	}
+
+	current, err := driver.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to load version from driver: %w", err)
+	}
+
+	computed := make(map[string]string)
+	for version := current; version != ""; {
+		applied, ok := m.migrations[version]
+		if !ok {
+			return fmt.Errorf("unable to find migration for revision: %s", version)
+		}
+
+		checksum, err := m.checksum(ctx, checksums, applied)
+		if err != nil {
+			return err
+		}
+		computed[version] = checksum
+
+		version = applied.replaces
+	}
+
+	return driver.RunTx(ctx, func(ctx context.Context, tx T) error {
+		for version, checksum := range computed {
+			if err := checksums.WriteChecksum(ctx, tx, version, checksum); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -1,11 +1,34 @@
 package migrate
 
+import "context"
+
 // MigrationVariable contains constants that can be used as context keys that might
 // be relevant in a number of different migration scenarios.
 type MigrationVariable int
 
 const (
 	// BackfillBatchSize represents the number of items that should be backfilled in a
-	// single step of an incremental backfill, and should be of type uint64.
+	// single step of an incremental backfill, and should be of type uint64. Prefer passing
+	// WithBackfillBatchSize to Manager.Run, which takes precedence over the context value.
 	BackfillBatchSize MigrationVariable = iota
+
+	// BackfillProgress represents the function to call with the progress of incremental
+	// backfills after each of their steps, and should be of type BackfillProgressFunc.
+	BackfillProgress
+
+	// BackfillParallelism represents the maximum number of steps of an incremental backfill
+	// that should run concurrently, over disjoint key ranges, and should be of type uint16.
+	BackfillParallelism
 )
+
+// BackfillProgressFunc is called with the number of rows processed so far by the backfill of
+// a migration, and an estimate of the total number of rows it will process. The estimate is
+// zero if it is not cheaply available.
+type BackfillProgressFunc func(migrationName string, rowsProcessed, rowsTotalEstimate uint64)
+
+// ReportBackfillProgress calls the BackfillProgressFunc set in the context, if any.
+func ReportBackfillProgress(ctx context.Context, migrationName string, rowsProcessed, rowsTotalEstimate uint64) {
+	if progress, ok := ctx.Value(BackfillProgress).(BackfillProgressFunc); ok && progress != nil {
+		progress(migrationName, rowsProcessed, rowsTotalEstimate)
+	}
+}
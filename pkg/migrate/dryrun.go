@@ -0,0 +1,196 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DryRunDriver is implemented by drivers able to dry-run migrations, by handing them connection
+// handlers which record the statements issued through them rather than executing them.
+type DryRunDriver[C any, T any] interface {
+	// DryRunConn returns a connection handler recording the statements issued through it.
+	DryRunConn(recorder *StatementRecorder) C
+
+	// DryRunTx returns a transaction recording the statements issued through it.
+	DryRunTx(recorder *StatementRecorder) T
+}
+
+// Statement is a statement issued by a migration, along with its arguments.
+type Statement struct {
+	SQL  string
+	Args []any
+}
+
+// StatementRecorder records the statements issued by a migration during a dry run.
+type StatementRecorder struct {
+	statements []Statement
+}
+
+// Record records a statement issued by the migration.
+func (sr *StatementRecorder) Record(sql string, args ...any) {
+	sr.statements = append(sr.statements, Statement{SQL: sql, Args: args})
+}
+
+// NonReplayableError is returned by a migration run as part of a dry run when the statements it
+// would execute depend on values only known while it runs, such as data read from the database,
+// and thus cannot be captured ahead of time. Migrations able to continue without such values,
+// such as those repeating statements until they no longer update any row, should call
+// MarkNonReplayable instead, so that all of their statements are covered by their checksum.
+type NonReplayableError struct {
+	error
+	Reason string
+}
+
+// NewNonReplayableErr constructs a new NonReplayableError for the specified reason.
+func NewNonReplayableErr(reason string) error {
+	return NonReplayableError{
+		error:  fmt.Errorf("migration cannot be replayed: %s", reason),
+		Reason: reason,
+	}
+}
+
+// DryRunStep is the outcome of dry-running a single migration, or the down migration reverting
+// it when rolling back.
+type DryRunStep struct {
+	From string
+	To   string
+
+	// Statements are the statements the migration would execute, excluding the update of the
+	// stored version. It is empty if the migration cannot be replayed.
+	Statements []Statement
+
+	// NonReplayableReason is set if the statements of the migration cannot be captured ahead of
+	// time, along with the reason.
+	NonReplayableReason string
+}
+
+type dryRunKey struct{}
+
+// dryRunState is the state of the dry run of a single migration.
+type dryRunState struct {
+	nonReplayableReason string
+}
+
+// IsDryRun returns whether the migration running with the context is being dry-run, in which case
+// the statements it issues are recorded rather than executed, and their results are empty.
+// Migrations whose statements depend on such results should call MarkNonReplayable or, if they
+// cannot continue without them, return a NonReplayableError.
+func IsDryRun(ctx context.Context) bool {
+	return ctx.Value(dryRunKey{}) != nil
+}
+
+// MarkNonReplayable marks the migration being dry-run with the context as not replayable, for the
+// reason given, such as repeating statements until they no longer update any row. Unlike
+// returning a NonReplayableError, the migration continues to run, such that the statements it
+// issues afterwards are covered by its checksum. Does nothing if the migration is not being
+// dry-run.
+func MarkNonReplayable(ctx context.Context, reason string) {
+	if state, ok := ctx.Value(dryRunKey{}).(*dryRunState); ok && state.nonReplayableReason == "" {
+		state.nonReplayableReason = reason
+	}
+}
+
+// dryRunBackfillBatchSize is the BackfillBatchSize with which migrations are dry-run.
+const dryRunBackfillBatchSize uint64 = 1000
+
+// withDryRun marks the context as dry-running a single migration. The values tuning backfills are
+// fixed, as they are not part of the definition of the migration covered by its checksum.
+func withDryRun(ctx context.Context) (context.Context, *dryRunState) {
+	state := &dryRunState{}
+	ctx = context.WithValue(ctx, BackfillBatchSize, dryRunBackfillBatchSize)
+	return context.WithValue(ctx, dryRunKey{}, state), state
+}
+
+// DryRun computes the statements that the migrations from the current revision of the backing
+// datastore through the specified revision would execute, without executing them, including the
+// down migrations when rolling back. The driver must implement DryRunDriver.
+func (m *Manager[D, C, T]) DryRun(ctx context.Context, driver D, throughRevision string) ([]DryRunStep, error) {
+	dryRunDriver, ok := any(driver).(DryRunDriver[C, T])
+	if !ok {
+		return nil, fmt.Errorf("migration driver %T does not support dry runs", driver)
+	}
+
+	toRun, err := m.stepsThrough(ctx, driver, throughRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]DryRunStep, 0, len(toRun))
+	for _, migrationToRun := range toRun {
+		step, err := dryRunStep(ctx, dryRunDriver, migrationToRun)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// dryRunStep dry-runs a single migration step.
+func dryRunStep[C any, T any](ctx context.Context, dryRunDriver DryRunDriver[C, T], migrationToRun migrationStep[C, T]) (DryRunStep, error) {
+	ctx, state := withDryRun(ctx)
+	recorder := &StatementRecorder{}
+	err := func() error {
+		if migrationToRun.run != nil {
+			if err := migrationToRun.run(ctx, dryRunDriver.DryRunConn(recorder)); err != nil {
+				return err
+			}
+		}
+		if migrationToRun.runTx != nil {
+			return migrationToRun.runTx(ctx, dryRunDriver.DryRunTx(recorder))
+		}
+		return nil
+	}()
+
+	step := DryRunStep{From: migrationToRun.from, To: migrationToRun.to}
+
+	var nonReplayable NonReplayableError
+	switch {
+	case errors.As(err, &nonReplayable):
+		step.NonReplayableReason = nonReplayable.Reason
+	case err != nil:
+		return step, fmt.Errorf("error dry-running migration `%s`: %w", migrationToRun.to, err)
+	case state.nonReplayableReason != "":
+		step.NonReplayableReason = state.nonReplayableReason
+	default:
+		step.Statements = recorder.statements
+	}
+
+	return step, nil
+}
+
+// WriteDryRunSteps writes the statements of the dry-run migrations to the writer, as SQL.
+func WriteDryRunSteps(w io.Writer, steps []DryRunStep) error {
+	for _, step := range steps {
+		if _, err := fmt.Fprintf(w, "-- migration from %q to %q\n", step.From, step.To); err != nil {
+			return err
+		}
+
+		if step.NonReplayableReason != "" {
+			if _, err := fmt.Fprintf(w, "-- NOT REPLAYABLE: %s\n\n", step.NonReplayableReason); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, stmt := range step.Statements {
+			if len(stmt.Args) > 0 {
+				if _, err := fmt.Fprintf(w, "-- arguments: %v\n", stmt.Args); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintf(w, "%s;\n", strings.TrimSuffix(strings.TrimSpace(stmt.SQL), ";")); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
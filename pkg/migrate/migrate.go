@@ -20,6 +20,30 @@ var (
 	LiveRun RunType = false
 )
 
+// RunOption configures a run of the migrations.
+type RunOption func(*runOptions)
+
+type runOptions struct {
+	backfillBatchSize *uint64
+}
+
+// WithBackfillBatchSize sets the number of items that should be backfilled in a single step of
+// an incremental backfill. It takes precedence over any BackfillBatchSize value set in the
+// context, which is still honored when the option is not set.
+func WithBackfillBatchSize(batchSize uint64) RunOption {
+	return func(ro *runOptions) {
+		ro.backfillBatchSize = &batchSize
+	}
+}
+
+// apply returns the context in which the migrations should run with the options.
+func (ro runOptions) apply(ctx context.Context) context.Context {
+	if ro.backfillBatchSize != nil {
+		ctx = context.WithValue(ctx, BackfillBatchSize, *ro.backfillBatchSize)
+	}
+	return ctx
+}
+
 // Driver represents the common interface for enabling the orchestration of migrations
 // for a specific type of datastore. The driver is parameterized with a type representing
 // a connection handler that will be forwarded by the Manager to the MigrationFunc to execute.
@@ -49,10 +73,23 @@ type MigrationFunc[C any] func(ctx context.Context, conn C) error
 type TxMigrationFunc[T any] func(ctx context.Context, tx T) error
 
 type migration[C any, T any] struct {
-	version  string
-	replaces string
-	up       MigrationFunc[C]
-	upTx     TxMigrationFunc[T]
+	version    string
+	replaces   string
+	up         MigrationFunc[C]
+	upTx       TxMigrationFunc[T]
+	down       MigrationFunc[C]
+	downTx     TxMigrationFunc[T]
+	reversible bool
+}
+
+// migrationStep is a migration run in either direction, moving the backing datastore from one
+// version to another.
+type migrationStep[C any, T any] struct {
+	from      string
+	to        string
+	run       MigrationFunc[C]
+	runTx     TxMigrationFunc[T]
+	reverting bool
 }
 
 // Manager is used to manage a self-contained set of migrations. Standard usage
@@ -96,78 +133,194 @@ func (m *Manager[D, C, T]) Register(version, replaces string, up MigrationFunc[C
 	return nil
 }
 
+// RegisterDown associates down migration functions with a registered migration, reverting its
+// changes when migrating the backing datastore to an earlier revision. Either function can be nil
+// if there is nothing to revert with it. Rolling back through a migration without down functions
+// registered fails before any migration is reverted.
+func (m *Manager[D, C, T]) RegisterDown(version string, down MigrationFunc[C], downTx TxMigrationFunc[T]) error {
+	registered, ok := m.migrations[version]
+	if !ok {
+		return fmt.Errorf("unable to find migration for revision: %s", version)
+	}
+
+	if registered.reversible {
+		return fmt.Errorf("down migration already exists: %s", version)
+	}
+
+	registered.down = down
+	registered.downTx = downTx
+	registered.reversible = true
+	m.migrations[version] = registered
+
+	return nil
+}
+
 // Run will actually perform the necessary migrations to bring the backing datastore
-// from its current revision to the specified revision.
-func (m *Manager[D, C, T]) Run(ctx context.Context, driver D, throughRevision string, dryRun RunType) error {
-	requestedRevision := throughRevision
-	starting, err := driver.Version(ctx)
-	if err != nil {
-		return fmt.Errorf("unable to compute target revision: %w", err)
+// from its current revision to the specified revision. If the specified revision precedes
+// the current one, the down migrations from the current revision back to it are run, in
+// reverse order.
+//
+// If the driver stores checksums, those of the migrations already applied are verified first,
+// and the checksum of each migration run is stored along with its version. Otherwise, a warning
+// is logged, as migrations modified after being applied cannot be detected.
+//
+// If dryRun is set, the statements the migrations would execute are logged instead, as computed
+// by DryRun.
+func (m *Manager[D, C, T]) Run(ctx context.Context, driver D, throughRevision string, dryRun RunType, opts ...RunOption) error {
+	var options runOptions
+	for _, opt := range opts {
+		opt(&options)
 	}
+	ctx = options.apply(ctx)
 
-	if strings.ToLower(throughRevision) == Head {
-		throughRevision, err = m.HeadRevision()
+	if dryRun {
+		steps, err := m.DryRun(ctx, driver, throughRevision)
 		if err != nil {
-			return fmt.Errorf("unable to compute head revision: %w", err)
+			return err
+		}
+
+		for _, step := range steps {
+			log.Ctx(ctx).Info().
+				Str("from", step.From).
+				Str("to", step.To).
+				Interface("statements", step.Statements).
+				Str("nonReplayableReason", step.NonReplayableReason).
+				Msg("dry-run migration")
 		}
+		return nil
 	}
 
-	toRun, err := collectMigrationsInRange(starting, throughRevision, m.migrations)
-	if err != nil {
-		return fmt.Errorf("unable to compute migration list: %w", err)
+	checksums, checksumsEnabled := m.asChecksummer(driver)
+	if checksumsEnabled {
+		if err := m.verifyChecksums(ctx, driver, checksums); err != nil {
+			return err
+		}
+	} else {
+		log.Ctx(ctx).Warn().
+			Str("driver", fmt.Sprintf("%T", driver)).
+			Msg("migration driver does not support checksums: migrations modified after being applied will not be detected")
 	}
-	if len(toRun) == 0 {
-		log.Ctx(ctx).Info().Str("targetRevision", requestedRevision).Msg("server already at requested revision")
+
+	toRun, err := m.stepsThrough(ctx, driver, throughRevision)
+	if err != nil {
+		return err
 	}
 
-	if !dryRun {
-		for _, migrationToRun := range toRun {
-			// Double check that the current version reported is the one we expect
-			currentVersion, err := driver.Version(ctx)
-			if err != nil {
-				return fmt.Errorf("unable to load version from driver: %w", err)
-			}
+	for _, migrationToRun := range toRun {
+		// Double check that the current version reported is the one we expect
+		currentVersion, err := driver.Version(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to load version from driver: %w", err)
+		}
 
-			if migrationToRun.replaces != currentVersion {
-				return fmt.Errorf("migration attempting to run out of order: %s != %s", currentVersion, migrationToRun.replaces)
-			}
+		if migrationToRun.from != currentVersion {
+			return fmt.Errorf("migration attempting to run out of order: %s != %s", currentVersion, migrationToRun.from)
+		}
 
-			log.Ctx(ctx).Info().Str("from", migrationToRun.replaces).Str("to", migrationToRun.version).Msg("migrating")
-			if migrationToRun.up != nil {
-				if err = migrationToRun.up(ctx, driver.Conn()); err != nil {
-					return fmt.Errorf("error executing migration function: %w", err)
-				}
+		var checksum string
+		if checksumsEnabled && !migrationToRun.reverting {
+			checksum, err = m.checksum(ctx, checksums, m.migrations[migrationToRun.to])
+			if err != nil {
+				return err
 			}
+		}
 
-			if err := driver.RunTx(ctx, func(ctx context.Context, tx T) error {
-				if migrationToRun.upTx != nil {
-					if err := migrationToRun.upTx(ctx, tx); err != nil {
-						return err
-					}
-				}
+		log.Ctx(ctx).Info().Str("from", migrationToRun.from).Str("to", migrationToRun.to).Msg("migrating")
+		if migrationToRun.run != nil {
+			if err = migrationToRun.run(ctx, driver.Conn()); err != nil {
+				return fmt.Errorf("error executing migration function: %w", err)
+			}
+		}
 
-				if err := driver.WriteVersion(ctx, tx, migrationToRun.version, migrationToRun.replaces); err != nil {
+		if err := driver.RunTx(ctx, func(ctx context.Context, tx T) error {
+			if migrationToRun.runTx != nil {
+				if err := migrationToRun.runTx(ctx, tx); err != nil {
 					return err
 				}
-
-				return nil
-			}); err != nil {
-				return fmt.Errorf("error executing migration `%s`: %w", migrationToRun.version, err)
 			}
 
-			currentVersion, err = driver.Version(ctx)
-			if err != nil {
-				return fmt.Errorf("unable to load version from driver: %w", err)
+			if err := driver.WriteVersion(ctx, tx, migrationToRun.to, migrationToRun.from); err != nil {
+				return err
 			}
-			if migrationToRun.version != currentVersion {
-				return fmt.Errorf("the migration function succeeded, but the driver did not report the expected version: %s", migrationToRun.version)
+
+			if checksum != "" {
+				return checksums.WriteChecksum(ctx, tx, migrationToRun.to, checksum)
 			}
+
+			return nil
+		}); err != nil {
+			return fmt.Errorf("error executing migration `%s`: %w", migrationToRun.to, err)
+		}
+
+		currentVersion, err = driver.Version(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to load version from driver: %w", err)
+		}
+		if migrationToRun.to != currentVersion {
+			return fmt.Errorf("the migration function succeeded, but the driver did not report the expected version: %s", migrationToRun.to)
 		}
 	}
 
 	return nil
 }
 
+// stepsThrough returns the migration steps to run to bring the backing datastore from its current
+// revision to the specified revision.
+func (m *Manager[D, C, T]) stepsThrough(ctx context.Context, driver D, throughRevision string) ([]migrationStep[C, T], error) {
+	requestedRevision := throughRevision
+	starting, err := driver.Version(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute target revision: %w", err)
+	}
+
+	if strings.ToLower(throughRevision) == Head {
+		throughRevision, err = m.HeadRevision()
+		if err != nil {
+			return nil, fmt.Errorf("unable to compute head revision: %w", err)
+		}
+	}
+
+	toRun, err := collectMigrationsInRange(starting, throughRevision, m.migrations)
+	if err != nil {
+		// The requested revision may precede the current one, in which case it is rolled back to.
+		toRevert, revertErr := collectMigrationsInRange(throughRevision, starting, m.migrations)
+		if revertErr != nil {
+			return nil, fmt.Errorf("unable to compute migration list: %w", err)
+		}
+
+		steps := make([]migrationStep[C, T], 0, len(toRevert))
+		for i := len(toRevert) - 1; i >= 0; i-- {
+			migrationToRevert := toRevert[i]
+			if !migrationToRevert.reversible {
+				return nil, fmt.Errorf("unable to roll back migration `%s`: no down migration registered", migrationToRevert.version)
+			}
+			steps = append(steps, migrationStep[C, T]{
+				from:      migrationToRevert.version,
+				to:        migrationToRevert.replaces,
+				run:       migrationToRevert.down,
+				runTx:     migrationToRevert.downTx,
+				reverting: true,
+			})
+		}
+		return steps, nil
+	}
+
+	if len(toRun) == 0 {
+		log.Ctx(ctx).Info().Str("targetRevision", requestedRevision).Msg("server already at requested revision")
+	}
+
+	steps := make([]migrationStep[C, T], 0, len(toRun))
+	for _, migrationToRun := range toRun {
+		steps = append(steps, migrationStep[C, T]{
+			from:  migrationToRun.replaces,
+			to:    migrationToRun.version,
+			run:   migrationToRun.up,
+			runTx: migrationToRun.upTx,
+		})
+	}
+	return steps, nil
+}
+
 func (m *Manager[D, C, T]) HeadRevision() (string, error) {
 	candidates := make(map[string]struct{}, len(m.migrations))
 	for candidate := range m.migrations {
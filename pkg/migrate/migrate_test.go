@@ -2,6 +2,7 @@ package migrate
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -40,9 +41,25 @@ func (*fakeDriver) Close(ctx context.Context) error {
 	return ctx.Err()
 }
 
-type fakeConnPool struct{}
+type fakeConnPool struct {
+	recorder *StatementRecorder
+}
+
+type fakeTx struct {
+	recorder *StatementRecorder
+}
+
+type fakeDryRunDriver struct {
+	fakeDriver
+}
 
-type fakeTx struct{}
+func (*fakeDryRunDriver) DryRunConn(recorder *StatementRecorder) fakeConnPool {
+	return fakeConnPool{recorder}
+}
+
+func (*fakeDryRunDriver) DryRunTx(recorder *StatementRecorder) fakeTx {
+	return fakeTx{recorder}
+}
 
 func TestContextError(t *testing.T) {
 	req := require.New(t)
@@ -193,27 +210,249 @@ func TestManagerEnsureVersionIsWritten(t *testing.T) {
 	req.Equal("", writtenVer)
 }
 
+func TestDryRun(t *testing.T) {
+	req := require.New(t)
+	m := NewManager[Driver[fakeConnPool, fakeTx], fakeConnPool, fakeTx]()
+
+	req.NoError(m.Register("1", "", func(ctx context.Context, conn fakeConnPool) error {
+		conn.recorder.Record("CREATE INDEX CONCURRENTLY ix_first ON first (id)")
+		return nil
+	}, func(ctx context.Context, tx fakeTx) error {
+		tx.recorder.Record("INSERT INTO first VALUES ($1)", 42)
+		return nil
+	}))
+	req.NoError(m.Register("2", "1", func(ctx context.Context, conn fakeConnPool) error {
+		conn.recorder.Record("CREATE INDEX CONCURRENTLY ix_second ON second (id)")
+		if IsDryRun(ctx) {
+			return NewNonReplayableErr("backfills rows")
+		}
+		return nil
+	}, noTxMigration))
+	req.NoError(m.Register("3", "2", func(ctx context.Context, conn fakeConnPool) error {
+		MarkNonReplayable(ctx, "repeats until done")
+		conn.recorder.Record("CREATE INDEX CONCURRENTLY ix_third ON third (id)")
+		return nil
+	}, noTxMigration))
+
+	drv := &fakeDryRunDriver{}
+	steps, err := m.DryRun(context.Background(), drv, Head)
+	req.NoError(err)
+	req.Equal([]DryRunStep{
+		{
+			From: "",
+			To:   "1",
+			Statements: []Statement{
+				{SQL: "CREATE INDEX CONCURRENTLY ix_first ON first (id)"},
+				{SQL: "INSERT INTO first VALUES ($1)", Args: []any{42}},
+			},
+		},
+		{From: "1", To: "2", NonReplayableReason: "backfills rows"},
+		{From: "2", To: "3", NonReplayableReason: "repeats until done"},
+	}, steps)
+	req.Equal("", drv.currentVersion)
+
+	buf := &strings.Builder{}
+	req.NoError(WriteDryRunSteps(buf, steps))
+	req.Equal(`-- migration from "" to "1"
+CREATE INDEX CONCURRENTLY ix_first ON first (id);
+-- arguments: [42]
+INSERT INTO first VALUES ($1);
+
+-- migration from "1" to "2"
+-- NOT REPLAYABLE: backfills rows
+
+-- migration from "2" to "3"
+-- NOT REPLAYABLE: repeats until done
+
+`, buf.String())
+
+	_, err = m.DryRun(context.Background(), &fakeDriver{}, Head)
+	req.ErrorContains(err, "does not support dry runs")
+}
+
+// fakeTxDriver is a fake driver which runs the transactional migrations.
+type fakeTxDriver struct {
+	fakeDriver
+}
+
+func (*fakeTxDriver) RunTx(ctx context.Context, f TxMigrationFunc[fakeTx]) error {
+	return f(ctx, fakeTx{})
+}
+
+func TestRollback(t *testing.T) {
+	req := require.New(t)
+	m := NewManager[Driver[fakeConnPool, fakeTx], fakeConnPool, fakeTx]()
+
+	var reverted []string
+	revert := func(version string) TxMigrationFunc[fakeTx] {
+		return func(ctx context.Context, tx fakeTx) error {
+			reverted = append(reverted, version)
+			return nil
+		}
+	}
+
+	for _, version := range []string{"1", "2", "3"} {
+		replaces := map[string]string{"1": "", "2": "1", "3": "2"}[version]
+		req.NoError(m.Register(version, replaces, noNonatomicMigration, noTxMigration))
+	}
+	req.NoError(m.RegisterDown("2", noNonatomicMigration, revert("2")))
+	req.NoError(m.RegisterDown("3", noNonatomicMigration, revert("3")))
+	req.Error(m.RegisterDown("3", noNonatomicMigration, revert("3")))
+	req.Error(m.RegisterDown("4", noNonatomicMigration, revert("4")))
+
+	drv := &fakeTxDriver{fakeDriver{currentVersion: "3"}}
+
+	err := m.Run(context.Background(), drv, "", LiveRun)
+	req.ErrorContains(err, "unable to roll back migration `1`")
+	req.Empty(reverted)
+	req.Equal("3", drv.currentVersion)
+
+	req.NoError(m.Run(context.Background(), drv, "1", LiveRun))
+	req.Equal([]string{"3", "2"}, reverted)
+	req.Equal("1", drv.currentVersion)
+
+	req.NoError(m.Run(context.Background(), drv, Head, LiveRun))
+	req.Equal("3", drv.currentVersion)
+}
+
+func TestReportBackfillProgress(t *testing.T) {
+	req := require.New(t)
+
+	// Reporting without a function set is a no-op.
+	ReportBackfillProgress(context.Background(), "backfill", 1, 0)
+
+	var reported []uint64
+	ctx := context.WithValue(context.Background(), BackfillProgress, BackfillProgressFunc(
+		func(migrationName string, rowsProcessed, rowsTotalEstimate uint64) {
+			req.Equal("backfill", migrationName)
+			req.Equal(uint64(10), rowsTotalEstimate)
+			reported = append(reported, rowsProcessed)
+		},
+	))
+	ReportBackfillProgress(ctx, "backfill", 5, 10)
+	ReportBackfillProgress(ctx, "backfill", 10, 10)
+	req.Equal([]uint64{5, 10}, reported)
+}
+
+func TestRunBackfillBatchSize(t *testing.T) {
+	req := require.New(t)
+	m := NewManager[Driver[fakeConnPool, fakeTx], fakeConnPool, fakeTx]()
+
+	var batchSizes []uint64
+	req.NoError(m.Register("1", "", func(ctx context.Context, conn fakeConnPool) error {
+		batchSizes = append(batchSizes, ctx.Value(BackfillBatchSize).(uint64))
+		return nil
+	}, noTxMigration))
+
+	ctx := context.WithValue(context.Background(), BackfillBatchSize, uint64(10))
+	req.NoError(m.Run(ctx, &fakeTxDriver{}, Head, LiveRun))
+	req.NoError(m.Run(ctx, &fakeTxDriver{}, Head, LiveRun, WithBackfillBatchSize(20)))
+	req.NoError(m.Run(context.Background(), &fakeTxDriver{}, Head, LiveRun, WithBackfillBatchSize(30)))
+	req.Equal([]uint64{10, 20, 30}, batchSizes)
+}
+
+// fakeChecksumDriver is a fake driver which runs the transactional migrations and stores their
+// checksums.
+type fakeChecksumDriver struct {
+	fakeTxDriver
+	checksums map[string]string
+}
+
+func (*fakeChecksumDriver) DryRunConn(recorder *StatementRecorder) fakeConnPool {
+	return fakeConnPool{recorder}
+}
+
+func (*fakeChecksumDriver) DryRunTx(recorder *StatementRecorder) fakeTx {
+	return fakeTx{recorder}
+}
+
+func (fcd *fakeChecksumDriver) Checksums(ctx context.Context) (map[string]string, error) {
+	return fcd.checksums, ctx.Err()
+}
+
+func (fcd *fakeChecksumDriver) WriteChecksum(ctx context.Context, tx fakeTx, version, checksum string) error {
+	fcd.checksums[version] = checksum
+	return ctx.Err()
+}
+
+func TestChecksums(t *testing.T) {
+	req := require.New(t)
+	m := NewManager[Driver[fakeConnPool, fakeTx], fakeConnPool, fakeTx]()
+
+	statement := "CREATE TABLE first"
+	req.NoError(m.Register("1", "", noNonatomicMigration, func(ctx context.Context, tx fakeTx) error {
+		if tx.recorder != nil {
+			tx.recorder.Record(statement)
+		}
+		return nil
+	}))
+	backfillStatement := "UPDATE first SET backfilled = true"
+	indexStatement := "CREATE INDEX ix_first ON first (backfilled)"
+	req.NoError(m.Register("2", "1", noNonatomicMigration, func(ctx context.Context, tx fakeTx) error {
+		MarkNonReplayable(ctx, "backfill")
+		if tx.recorder != nil {
+			tx.recorder.Record(backfillStatement, ctx.Value(BackfillBatchSize))
+			tx.recorder.Record(indexStatement)
+		}
+		return nil
+	}))
+
+	drv := &fakeChecksumDriver{checksums: map[string]string{}}
+	req.NoError(m.Run(context.Background(), drv, Head, LiveRun))
+	req.Equal("2", drv.currentVersion)
+	req.Len(drv.checksums, 2)
+	req.NotEqual(drv.checksums["1"], drv.checksums["2"])
+
+	// Running again with unchanged migrations passes verification.
+	req.NoError(m.Run(context.Background(), drv, Head, LiveRun))
+
+	statement = "CREATE TABLE renamed"
+	err := m.Run(context.Background(), drv, Head, LiveRun)
+	req.ErrorContains(err, "migration `1` was modified after being applied")
+
+	original := drv.checksums["1"]
+	req.NoError(m.RebaselineChecksums(context.Background(), drv))
+	req.NotEqual(original, drv.checksums["1"])
+	req.NoError(m.Run(context.Background(), drv, Head, LiveRun))
+
+	// Migrations applied without a stored checksum are not verified.
+	delete(drv.checksums, "1")
+	statement = "CREATE TABLE first"
+	req.NoError(m.Run(context.Background(), drv, Head, LiveRun))
+
+	// The backfill batch size is not part of the definition of the migrations.
+	req.NoError(m.Run(context.Background(), drv, Head, LiveRun, WithBackfillBatchSize(10)))
+
+	// The statements of non-replayable migrations are covered through to their end.
+	indexStatement = "CREATE INDEX ix_first ON first (backfilled, id)"
+	err = m.Run(context.Background(), drv, Head, LiveRun)
+	req.ErrorContains(err, "migration `2` was modified after being applied")
+
+	err = m.RebaselineChecksums(context.Background(), &fakeDriver{})
+	req.ErrorContains(err, "does not support checksums")
+}
+
 var noMigrations = map[string]migration[fakeConnPool, fakeTx]{}
 
 var simpleMigrations = map[string]migration[fakeConnPool, fakeTx]{
-	"123": {"123", "", noNonatomicMigration, noTxMigration},
+	"123": {"123", "", noNonatomicMigration, noTxMigration, noNonatomicMigration, noTxMigration, false},
 }
 
 var singleHeadedChain = map[string]migration[fakeConnPool, fakeTx]{
-	"123": {"123", "", noNonatomicMigration, noTxMigration},
-	"456": {"456", "123", noNonatomicMigration, noTxMigration},
-	"789": {"789", "456", noNonatomicMigration, noTxMigration},
+	"123": {"123", "", noNonatomicMigration, noTxMigration, noNonatomicMigration, noTxMigration, false},
+	"456": {"456", "123", noNonatomicMigration, noTxMigration, noNonatomicMigration, noTxMigration, false},
+	"789": {"789", "456", noNonatomicMigration, noTxMigration, noNonatomicMigration, noTxMigration, false},
 }
 
 var multiHeadedChain = map[string]migration[fakeConnPool, fakeTx]{
-	"123":  {"123", "", noNonatomicMigration, noTxMigration},
-	"456":  {"456", "123", noNonatomicMigration, noTxMigration},
-	"789a": {"789a", "456", noNonatomicMigration, noTxMigration},
-	"789b": {"789b", "456", noNonatomicMigration, noTxMigration},
+	"123":  {"123", "", noNonatomicMigration, noTxMigration, noNonatomicMigration, noTxMigration, false},
+	"456":  {"456", "123", noNonatomicMigration, noTxMigration, noNonatomicMigration, noTxMigration, false},
+	"789a": {"789a", "456", noNonatomicMigration, noTxMigration, noNonatomicMigration, noTxMigration, false},
+	"789b": {"789b", "456", noNonatomicMigration, noTxMigration, noNonatomicMigration, noTxMigration, false},
 }
 
 var missingEarlyMigrations = map[string]migration[fakeConnPool, fakeTx]{
-	"456": {"456", "123", noNonatomicMigration, noTxMigration},
-	"789": {"789", "456", noNonatomicMigration, noTxMigration},
-	"10":  {"10", "789", noNonatomicMigration, noTxMigration},
+	"456": {"456", "123", noNonatomicMigration, noTxMigration, noNonatomicMigration, noTxMigration, false},
+	"789": {"789", "456", noNonatomicMigration, noTxMigration, noNonatomicMigration, noTxMigration, false},
+	"10":  {"10", "789", noNonatomicMigration, noTxMigration, noNonatomicMigration, noTxMigration, false},
 }
@@ -747,10 +747,10 @@ func TestCompile(t *testing.T) {
 							testutil.RequireProtoEqual(t, expectedParam, foundParam, "mismatch type for parameter %s", expectedParamName)
 						}
 
-						expectedDecoded, err := caveats.DeserializeCaveat(expectedCaveatDef.SerializedExpression)
+						expectedDecoded, err := caveats.DeserializeCaveat(expectedCaveatDef.SerializedExpression, nil)
 						require.NoError(err)
 
-						foundDecoded, err := caveats.DeserializeCaveat(caveatDef.SerializedExpression)
+						foundDecoded, err := caveats.DeserializeCaveat(caveatDef.SerializedExpression, nil)
 						require.NoError(err)
 
 						expectedExprString, err := expectedDecoded.ExprString()
@@ -120,7 +120,7 @@ func (sg *sourceGenerator) emitCaveat(caveat *core.CaveatDefinition) error {
 	sg.indent()
 	sg.markNewScope()
 
-	deserializedExpression, err := caveats.DeserializeCaveat(caveat.SerializedExpression)
+	deserializedExpression, err := caveats.DeserializeCaveat(caveat.SerializedExpression, nil)
 	if err != nil {
 		return fmt.Errorf("invalid caveat expression bytes: %w", err)
 	}
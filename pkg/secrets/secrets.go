@@ -2,7 +2,21 @@ package secrets
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	// Base58Alphabet is the alphabet of the Bitcoin base58 encoding, which excludes characters
+	// easily confused with one another.
+	Base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	// Base62Alphabet is the alphabet of the alphanumeric characters.
+	Base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 )
 
 // TokenBytes returns a secure random token of the specified number of bytes
@@ -17,3 +31,63 @@ func TokenHex(nbytes uint8) (string, error) {
 	tokenBytes, err := TokenBytes(nbytes)
 	return hex.EncodeToString(tokenBytes), err
 }
+
+// TokenString returns a secure random token of the specified number of characters, each chosen
+// uniformly from the alphabet, which must be made of at least 2 distinct ASCII characters. The
+// token has length * log2(len(alphabet)) bits of entropy, e.g. about 5.95 bits per character for
+// base62, so a 22 character base62 token has about as much entropy as a 16 byte one.
+func TokenString(length int, alphabet string) (string, error) {
+	if length < 0 {
+		return "", fmt.Errorf("invalid token length %d", length)
+	}
+
+	if len(alphabet) < 2 {
+		return "", errors.New("token alphabet must have at least 2 characters")
+	}
+
+	var seen [utf8.RuneSelf]bool
+	for i := 0; i < len(alphabet); i++ {
+		if alphabet[i] >= utf8.RuneSelf {
+			return "", errors.New("token alphabet must only have ASCII characters")
+		}
+		if seen[alphabet[i]] {
+			return "", fmt.Errorf("token alphabet has duplicate character %q", alphabet[i])
+		}
+		seen[alphabet[i]] = true
+	}
+
+	// Random bytes at or above the largest multiple of the alphabet size are rejected, so that
+	// every character is equally likely.
+	limit := 256 - (256 % len(alphabet))
+
+	token := make([]byte, 0, length)
+	random := make([]byte, length+length/4+1)
+	for len(token) < length {
+		if _, err := rand.Read(random); err != nil {
+			return "", err
+		}
+
+		for _, b := range random {
+			if int(b) >= limit {
+				continue
+			}
+			token = append(token, alphabet[int(b)%len(alphabet)])
+			if len(token) == length {
+				break
+			}
+		}
+	}
+
+	return string(token), nil
+}
+
+// ConstantTimeCompare returns whether the two tokens are equal, in time independent of their
+// contents. Unlike subtle.ConstantTimeCompare, tokens of differing lengths are compared the same
+// way as tokens of equal length, so that the length of a secret token cannot be learned from the
+// time taken to reject guesses.
+func ConstantTimeCompare(a, b string) bool {
+	// Comparing fixed-length digests avoids returning early on differing lengths.
+	aDigest := sha256.Sum256([]byte(a))
+	bDigest := sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(aDigest[:], bDigest[:]) == 1
+}
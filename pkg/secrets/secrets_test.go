@@ -1,6 +1,7 @@
 package secrets
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,3 +28,34 @@ func TestTokenHex(t *testing.T) {
 		assert.Equal(int(nbytes)*2, len(res))
 	}
 }
+
+func TestTokenString(t *testing.T) {
+	assert := assert.New(t)
+	for _, alphabet := range []string{"01", "abc", Base58Alphabet, Base62Alphabet} {
+		for _, length := range []int{0, 1, 16, 255} {
+			res, err := TokenString(length, alphabet)
+			assert.Nil(err)
+			assert.Equal(length, len(res))
+			for _, c := range res {
+				assert.True(strings.ContainsRune(alphabet, c))
+			}
+		}
+	}
+
+	for _, alphabet := range []string{"", "a", "aba", "aé", "αβγ"} {
+		_, err := TokenString(16, alphabet)
+		assert.NotNil(err)
+	}
+
+	_, err := TokenString(-1, Base62Alphabet)
+	assert.NotNil(err)
+}
+
+func TestConstantTimeCompare(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(ConstantTimeCompare("", ""))
+	assert.True(ConstantTimeCompare("sometoken", "sometoken"))
+	assert.False(ConstantTimeCompare("sometoken", "othertoken"))
+	assert.False(ConstantTimeCompare("sometoken", "sometoke"))
+	assert.False(ConstantTimeCompare("sometoken", ""))
+}